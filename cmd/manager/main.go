@@ -20,6 +20,7 @@ import (
 	"flag"
 	"os"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"strings"
 
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -31,6 +32,7 @@ import (
 
 	elasticv1alpha1 "github.com/Carrefour-Group/elastic-phenix-operator/pkg/api/v1alpha1"
 	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/controllers"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -44,6 +46,14 @@ const (
 	EnableLeaderElectionFlag  = "enable-leader-election"
 	NamespacesFlag            = "namespaces"
 	NamespacesRegexFilterFlag = "namespaces-regex-filter"
+	ResyncIntervalFlag        = "resync-interval"
+	ResyncRepairFlag          = "resync-repair"
+	EsVersionTTLFlag          = "es-version-ttl"
+	ConnectionProbeFlag       = "connection-probe-interval"
+	RetryIntervalFlag         = "retry-interval"
+	ErrorIntervalFlag         = "error-interval"
+	MaxRetryIntervalFlag      = "max-retry-interval"
+	EsRequestTimeoutFlag      = "es-request-timeout"
 )
 
 func init() {
@@ -66,6 +76,22 @@ func main() {
 		"this operator should manage resources (defaults to all namespaces)")
 	pflag.String(NamespacesRegexFilterFlag, "", "Filter that will be applied before reconciliation "+
 		"on namespaces managed by namespaces flag (defaults to no filter applied)")
+	pflag.Duration(ResyncIntervalFlag, 0, "Interval between two comparisons of created objects with elasticsearch, "+
+		"overridable with spec.resync.interval (defaults to 0, no resync)")
+	pflag.Bool(ResyncRepairFlag, false, "Repair objects that differ from their model on resync, "+
+		"overridable with spec.resync.repair (defaults to false, drift is only reported)")
+	pflag.Duration(EsVersionTTLFlag, utils.DefaultVersionTTL, "Duration for which the detected version of an elasticsearch "+
+		"cluster is cached before being detected again (defaults to 10m)")
+	pflag.Duration(ConnectionProbeFlag, controllers.DefaultProbeInterval, "Interval between two probes of the elasticsearch "+
+		"cluster of an ElasticConnection or a ClusterElasticConnection (defaults to 1m)")
+	pflag.Duration(RetryIntervalFlag, controllers.DefaultRetryInterval, "Delay before applying a model again after a first "+
+		"Retry status, doubled on each consecutive failure (defaults to 30s)")
+	pflag.Duration(ErrorIntervalFlag, controllers.DefaultErrorInterval, "Delay before applying a model again after a first "+
+		"Error status, doubled on each consecutive failure (defaults to 5m)")
+	pflag.Duration(MaxRetryIntervalFlag, controllers.DefaultMaxRetryInterval, "Longest delay before applying a model again "+
+		"after consecutive failures (defaults to 10m)")
+	pflag.Duration(EsRequestTimeoutFlag, utils.ElasticMainFnTimeout, "Timeout of each elasticsearch API call, "+
+		"overridable with timeouts.request of the connection (defaults to 10s)")
 
 	pflag.Parse()
 	viper.BindPFlags(pflag.CommandLine)
@@ -74,6 +100,16 @@ func main() {
 	var enableLeaderElection = viper.GetBool(EnableLeaderElectionFlag)
 	var namespaces []string = viper.GetStringSlice(NamespacesFlag)
 	var namespacesRegexFilter string = viper.GetString(NamespacesRegexFilterFlag)
+	var resyncInterval = viper.GetDuration(ResyncIntervalFlag)
+	var resyncRepair = viper.GetBool(ResyncRepairFlag)
+	var esVersionTTL = viper.GetDuration(EsVersionTTLFlag)
+	var connectionProbeInterval = viper.GetDuration(ConnectionProbeFlag)
+	var retryOptions = controllers.RetryOptions{
+		RetryInterval: viper.GetDuration(RetryIntervalFlag),
+		ErrorInterval: viper.GetDuration(ErrorIntervalFlag),
+		MaxInterval:   viper.GetDuration(MaxRetryIntervalFlag),
+	}
+	var esRequestTimeout = viper.GetDuration(EsRequestTimeoutFlag)
 
 	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
 
@@ -104,13 +140,27 @@ func main() {
 
 	setupLog.Info("flags",
 		MetricsAddrFlag, metricsAddr, EnableLeaderElectionFlag, enableLeaderElection,
-		NamespacesFlag, namespaces, NamespacesRegexFilterFlag, namespacesRegexFilter)
+		NamespacesFlag, namespaces, NamespacesRegexFilterFlag, namespacesRegexFilter,
+		ResyncIntervalFlag, resyncInterval, ResyncRepairFlag, resyncRepair, EsVersionTTLFlag, esVersionTTL,
+		ConnectionProbeFlag, connectionProbeInterval, RetryIntervalFlag, retryOptions.RetryInterval,
+		ErrorIntervalFlag, retryOptions.ErrorInterval, MaxRetryIntervalFlag, retryOptions.MaxInterval,
+		EsRequestTimeoutFlag, esRequestTimeout)
+
+	utils.SetDefaultRequestTimeout(esRequestTimeout)
+
+	// all reconcilers share elasticsearch connections
+	esClients := utils.NewEsClientRegistry(esVersionTTL)
 
 	if err = (&controllers.ElasticIndexReconciler{
 		Client:                mgr.GetClient(),
 		Log:                   ctrl.Log.WithName("controllers").WithName("ElasticIndex"),
 		Scheme:                mgr.GetScheme(),
 		NamespacesRegexFilter: namespacesRegexFilter,
+		ResyncInterval:        resyncInterval,
+		ResyncRepair:          resyncRepair,
+		Recorder:              mgr.GetEventRecorderFor("elasticindex-controller"),
+		EsClients:             esClients,
+		Retry:                 retryOptions,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ElasticIndex")
 		os.Exit(1)
@@ -124,6 +174,11 @@ func main() {
 		Log:                   ctrl.Log.WithName("controllers").WithName("ElasticTemplate"),
 		Scheme:                mgr.GetScheme(),
 		NamespacesRegexFilter: namespacesRegexFilter,
+		ResyncInterval:        resyncInterval,
+		ResyncRepair:          resyncRepair,
+		Recorder:              mgr.GetEventRecorderFor("elastictemplate-controller"),
+		EsClients:             esClients,
+		Retry:                 retryOptions,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ElasticTemplate")
 		os.Exit(1)
@@ -132,6 +187,88 @@ func main() {
 		setupLog.Error(err, "unable to create webhook", "webhook", "ElasticTemplate")
 		os.Exit(1)
 	}
+	for _, kind := range []controllers.ElasticObjectKind{
+		controllers.ElasticComposableTemplateKind,
+		controllers.ElasticComponentTemplateKind,
+		controllers.ElasticILMPolicyKind,
+		controllers.ElasticIngestPipelineKind,
+		controllers.ElasticDataStreamKind,
+		controllers.ElasticSnapshotRepositoryKind,
+		controllers.ElasticSLMPolicyKind,
+	} {
+		if err = (&controllers.ElasticObjectReconciler{
+			Client:                mgr.GetClient(),
+			Log:                   ctrl.Log.WithName("controllers").WithName(kind.Name),
+			Scheme:                mgr.GetScheme(),
+			NamespacesRegexFilter: namespacesRegexFilter,
+			ResyncInterval:        resyncInterval,
+			ResyncRepair:          resyncRepair,
+			Recorder:              mgr.GetEventRecorderFor(strings.ToLower(kind.Name) + "-controller"),
+			EsClients:             esClients,
+			Retry:                 retryOptions,
+			Kind:                  kind,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", kind.Name)
+			os.Exit(1)
+		}
+	}
+	if err = (&elasticv1alpha1.ElasticComposableTemplate{}).SetupWebhookWithManager(mgr, namespaces); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ElasticComposableTemplate")
+		os.Exit(1)
+	}
+	if err = (&elasticv1alpha1.ElasticComponentTemplate{}).SetupWebhookWithManager(mgr, namespaces); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ElasticComponentTemplate")
+		os.Exit(1)
+	}
+	if err = (&elasticv1alpha1.ElasticILMPolicy{}).SetupWebhookWithManager(mgr, namespaces); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ElasticILMPolicy")
+		os.Exit(1)
+	}
+	if err = (&elasticv1alpha1.ElasticIngestPipeline{}).SetupWebhookWithManager(mgr, namespaces); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ElasticIngestPipeline")
+		os.Exit(1)
+	}
+	if err = (&elasticv1alpha1.ElasticDataStream{}).SetupWebhookWithManager(mgr, namespaces); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ElasticDataStream")
+		os.Exit(1)
+	}
+	if err = (&elasticv1alpha1.ElasticSnapshotRepository{}).SetupWebhookWithManager(mgr, namespaces); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ElasticSnapshotRepository")
+		os.Exit(1)
+	}
+	if err = (&elasticv1alpha1.ElasticSLMPolicy{}).SetupWebhookWithManager(mgr, namespaces); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ElasticSLMPolicy")
+		os.Exit(1)
+	}
+	if err = (&controllers.ElasticConnectionReconciler{
+		Client:                mgr.GetClient(),
+		Log:                   ctrl.Log.WithName("controllers").WithName("ElasticConnection"),
+		Scheme:                mgr.GetScheme(),
+		NamespacesRegexFilter: namespacesRegexFilter,
+		ProbeInterval:         connectionProbeInterval,
+		EsClients:             esClients,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ElasticConnection")
+		os.Exit(1)
+	}
+	if err = (&elasticv1alpha1.ElasticConnection{}).SetupWebhookWithManager(mgr, namespaces); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ElasticConnection")
+		os.Exit(1)
+	}
+	if err = (&controllers.ClusterElasticConnectionReconciler{
+		Client:        mgr.GetClient(),
+		Log:           ctrl.Log.WithName("controllers").WithName("ClusterElasticConnection"),
+		Scheme:        mgr.GetScheme(),
+		ProbeInterval: connectionProbeInterval,
+		EsClients:     esClients,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ClusterElasticConnection")
+		os.Exit(1)
+	}
+	if err = (&elasticv1alpha1.ClusterElasticConnection{}).SetupWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create webhook", "webhook", "ClusterElasticConnection")
+		os.Exit(1)
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")
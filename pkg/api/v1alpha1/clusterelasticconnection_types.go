@@ -0,0 +1,60 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterElasticConnectionSpec defines an elasticsearch cluster usable from all namespaces
+type ClusterElasticConnectionSpec struct {
+	ElasticConnectionSpec `json:",inline"`
+
+	// Namespace of the auth and TLS secrets
+	// +kubebuilder:validation:Required
+	SecretNamespace string `json:"secretNamespace"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=cec
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="BACKEND",type="string",JSONPath=".status.backend"
+// +kubebuilder:printcolumn:name="VERSION",type="string",JSONPath=".status.version"
+// +kubebuilder:printcolumn:name="HEALTH",type="string",JSONPath=".status.health"
+// +kubebuilder:printcolumn:name="REACHABLE",type="boolean",JSONPath=".status.reachable"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ClusterElasticConnection is the Schema for the clusterelasticconnections API, usable by objects of all namespaces
+type ClusterElasticConnection struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterElasticConnectionSpec `json:"spec,omitempty"`
+	Status ElasticConnectionStatus      `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterElasticConnectionList contains a list of ClusterElasticConnection
+type ClusterElasticConnectionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterElasticConnection `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterElasticConnection{}, &ClusterElasticConnectionList{})
+}
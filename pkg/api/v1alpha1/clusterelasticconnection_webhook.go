@@ -0,0 +1,86 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"reflect"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+var (
+	// log is for logging in this package.
+	clusterelasticconnectionlog       = logf.Log.WithName("clusterelasticconnection-resource")
+	clusterelasticconnectionK8sClient client.Client
+)
+
+func (r *ClusterElasticConnection) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	clusterelasticconnectionK8sClient = mgr.GetClient()
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-elastic-carrefour-com-v1alpha1-clusterelasticconnection,mutating=false,failurePolicy=fail,groups=elastic.carrefour.com,resources=clusterelasticconnections,versions=v1alpha1,name=vclusterelasticconnection.kb.io,sideEffects=none,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Validator = &ClusterElasticConnection{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ClusterElasticConnection) ValidateCreate() error {
+	clusterelasticconnectionlog.Info("[Webhook] validate create", "name", r.Name)
+
+	return r.validateSpec()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ClusterElasticConnection) ValidateUpdate(old runtime.Object) error {
+	clusterelasticconnectionlog.Info("[Webhook] validate update", "name", r.Name)
+
+	if reflect.DeepEqual(r.Spec, old.(*ClusterElasticConnection).Spec) {
+		return nil
+	}
+	return r.validateSpec()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ClusterElasticConnection) ValidateDelete() error {
+	return nil
+}
+
+// validateSpec checks the connection, whose secrets are read from secretNamespace
+func (r *ClusterElasticConnection) validateSpec() error {
+	var allErrs field.ErrorList
+
+	if r.Spec.SecretNamespace == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec").Child("secretNamespace"), "secretNamespace is required"))
+	} else {
+		allErrs = append(allErrs, validateConnectionSpec(&r.Spec.ElasticConnectionSpec, r.Spec.SecretNamespace, field.NewPath("spec"), clusterelasticconnectionK8sClient)...)
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+
+	return apierrors.NewInvalid(
+		schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ClusterElasticConnection"},
+		r.Name, allErrs)
+}
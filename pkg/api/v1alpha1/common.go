@@ -15,10 +15,17 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"context"
 	"fmt"
 	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -26,33 +33,552 @@ import (
 type ElasticURISource struct {
 	// +kubebuilder:validation:Required
 	SecretKeyRef *v1.SecretKeySelector `json:"secretKeyRef" protobuf:"bytes,4,opt,name=secretKeyRef"`
+
+	// Namespace of the uri and TLS secrets, the namespace of the object by default. Another namespace must allow the
+	// namespace of the object with an ElasticSecretGrant
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// TLS configuration of an https elasticsearch URI. Elasticsearch certificate is verified with system CAs by default
+	// +optional
+	TLS *ElasticTLS `json:"tls,omitempty"`
+
+	// Discover the nodes of the cluster from the nodes of the URI, calls being spread and retried on all of them
+	// +optional
+	Sniffing *ElasticSniffing `json:"sniffing,omitempty"`
+
+	// HTTP proxy, headers, compression and User-Agent of the requests
+	// +optional
+	HTTP *ElasticHTTP `json:"http,omitempty"`
+
+	// Timeouts of elasticsearch API calls
+	// +optional
+	Timeouts *ElasticTimeouts `json:"timeouts,omitempty"`
+}
+
+// ElasticHTTP customizes the HTTP requests sent to elasticsearch, for clusters reached through a proxy or a gateway
+type ElasticHTTP struct {
+	// URL of the proxy elasticsearch is reached through, like http://proxy.corp:3128. HTTP_PROXY and HTTPS_PROXY
+	// environment variables of the operator are not used
+	// +kubebuilder:validation:Pattern=`^(https?|socks5)://.+`
+	// +optional
+	ProxyURL string `json:"proxyUrl,omitempty"`
+
+	// Headers added to every request, like a tenant id expected by a gateway
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// Name of a secret whose keys are header names and values header values, for sensitive headers like an auth header
+	// expected by a gateway. They override the headers of the same name. An Authorization header is only sent when
+	// the connection has no credentials
+	// +optional
+	HeadersSecretName string `json:"headersSecretName,omitempty"`
+
+	// Compress request bodies with gzip
+	// +optional
+	Compression bool `json:"compression,omitempty"`
+
+	// User-Agent of the requests, the one of the elasticsearch client by default
+	// +optional
+	UserAgent string `json:"userAgent,omitempty"`
+}
+
+// ElasticSniffing configures the discovery of the nodes of the cluster
+type ElasticSniffing struct {
+	// Interval between two discoveries, like 5m. Nodes are only discovered when the connection is opened when not set
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// ElasticTLS configures the verification of elasticsearch certificate and the client certificate for mutual TLS
+type ElasticTLS struct {
+	// Name of a secret holding the CA certificate in ca.crt key, or tls.crt key when missing, like ECK <cluster>-es-http-certs-public secret
+	// +optional
+	CASecretName string `json:"caSecretName,omitempty"`
+
+	// Name of a kubernetes.io/tls secret holding the client certificate and key, in tls.crt and tls.key keys, for mutual TLS
+	// +optional
+	ClientCertSecretName string `json:"clientCertSecretName,omitempty"`
+
+	// Server name used to verify elasticsearch certificate when it differs from the URI host
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+
+	// Skip the verification of elasticsearch certificate. Insecure, the connection can be intercepted
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// GetEsTLSConfig reads the secrets of the TLS configuration, for an object of namespace
+func (s *ElasticURISource) GetEsTLSConfig(namespace string, k8sClient client.Client) (*utils.EsTLSConfig, error) {
+	return s.TLS.GetEsTLSConfig(s.GetSecretNamespace(namespace), k8sClient)
+}
+
+// GetSecretNamespace returns the namespace of the secrets, for an object of namespace
+func (s *ElasticURISource) GetSecretNamespace(namespace string) string {
+	if s.Namespace == "" {
+		return namespace
+	}
+	return s.Namespace
+}
+
+// getSecret checks that the secrets can be referenced from namespace and reads the uri secret
+func (s *ElasticURISource) getSecret(namespace string, k8sClient client.Client) (*v1.Secret, error) {
+	if err := CheckSecretGrant(namespace, s.GetSecretNamespace(namespace), s.GetSecretNames(), k8sClient); err != nil {
+		return nil, err
+	}
+	return utils.GetSecret(s.GetSecretNamespace(namespace), s.SecretKeyRef, k8sClient)
+}
+
+// GetEsTLSConfig reads the secrets of the TLS configuration from namespace, nil meaning the default configuration
+func (t *ElasticTLS) GetEsTLSConfig(namespace string, k8sClient client.Client) (*utils.EsTLSConfig, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return utils.BuildEsTLSConfig(namespace, t.CASecretName, t.ClientCertSecretName, t.ServerName, t.InsecureSkipVerify, k8sClient)
+}
+
+// GetEsConnection reads the elasticsearch uri secret, the TLS secrets and the headers secret, for an object of
+// namespace
+func (s *ElasticURISource) GetEsConnection(namespace string, k8sClient client.Client) (*utils.EsConnection, error) {
+	secret, err := s.getSecret(namespace, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := s.GetEsTLSConfig(namespace, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	connection := utils.NewEsConnectionFromSecret(secret, s.SecretKeyRef.Key, tlsConfig, s.GetEsSniffing())
+	httpConfig, resourceVersion, err := s.HTTP.GetEsHTTPConfig(s.GetSecretNamespace(namespace), k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	connection.Options.HTTP, connection.Options.RequestTimeout = httpConfig, s.Timeouts.GetRequestTimeout()
+	if resourceVersion != "" {
+		connection.ResourceVersion += "/" + resourceVersion
+	}
+	return connection, nil
+}
+
+// GetEsHTTPConfig reads the headers secret from namespace, and returns the HTTP configuration with the resource version
+// of the secret, nil meaning the default configuration
+func (h *ElasticHTTP) GetEsHTTPConfig(namespace string, k8sClient client.Client) (*utils.EsHTTPConfig, string, error) {
+	if h == nil {
+		return nil, "", nil
+	}
+	httpConfig := &utils.EsHTTPConfig{ProxyURL: h.ProxyURL, Compression: h.Compression, UserAgent: h.UserAgent, Headers: map[string]string{}}
+	for name, value := range h.Headers {
+		httpConfig.Headers[http.CanonicalHeaderKey(name)] = value
+	}
+	if h.HeadersSecretName == "" {
+		return httpConfig, "", nil
+	}
+	secret, err := utils.GetSecret(namespace, &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: h.HeadersSecretName}}, k8sClient)
+	if err != nil {
+		return nil, "", err
+	}
+	for name, value := range secret.Data {
+		httpConfig.Headers[http.CanonicalHeaderKey(name)] = strings.TrimRight(string(value), "\r\n")
+	}
+	return httpConfig, secret.ResourceVersion, nil
+}
+
+// GetSecretNames returns the name of the headers secret
+func (h *ElasticHTTP) GetSecretNames() []string {
+	if h == nil || h.HeadersSecretName == "" {
+		return nil
+	}
+	return []string{h.HeadersSecretName}
+}
+
+// GetEsSniffing returns the sniffing configuration of the connection, nil when sniffing is disabled
+func (s *ElasticURISource) GetEsSniffing() *utils.EsSniffing {
+	return s.Sniffing.GetEsSniffing()
+}
+
+// GetEsSniffing returns the sniffing configuration, nil when sniffing is disabled
+func (s *ElasticSniffing) GetEsSniffing() *utils.EsSniffing {
+	if s == nil {
+		return nil
+	}
+	sniffing := &utils.EsSniffing{}
+	if s.Interval != nil {
+		sniffing.Interval = s.Interval.Duration
+	}
+	return sniffing
+}
+
+// GetSecretNames returns the names of the secrets the connection is read from
+func (s *ElasticURISource) GetSecretNames() []string {
+	if s == nil {
+		return nil
+	}
+	var names []string
+	if s.SecretKeyRef != nil {
+		names = append(names, strings.TrimSpace(s.SecretKeyRef.Name))
+	}
+	names = append(names, s.TLS.GetSecretNames()...)
+	return append(names, s.HTTP.GetSecretNames()...)
+}
+
+// GetSecretNames returns the names of the CA and client certificate secrets
+func (t *ElasticTLS) GetSecretNames() []string {
+	var names []string
+	if t != nil && t.CASecretName != "" {
+		names = append(names, t.CASecretName)
+	}
+	if t != nil && t.ClientCertSecretName != "" {
+		names = append(names, t.ClientCertSecretName)
+	}
+	return names
+}
+
+// GetRequestTimeout returns the timeout of elasticsearch API calls, 0 meaning the default timeout
+func (t *ElasticTimeouts) GetRequestTimeout() time.Duration {
+	if t == nil || t.Request == nil {
+		return 0
+	}
+	return t.Request.Duration
+}
+
+// Kinds of the connections a connectionRef can reference
+const (
+	ElasticConnectionKind        = "ElasticConnection"
+	ClusterElasticConnectionKind = "ClusterElasticConnection"
+)
+
+// ElasticConnectionReference references an ElasticConnection of the namespace of the object, or a ClusterElasticConnection
+type ElasticConnectionReference struct {
+	// Kind of the connection, ElasticConnection by default
+	// +kubebuilder:validation:Enum=ElasticConnection;ClusterElasticConnection
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name of the connection
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+// GetKind returns the kind of the connection
+func (r *ElasticConnectionReference) GetKind() string {
+	if r.Kind == "" {
+		return ElasticConnectionKind
+	}
+	return r.Kind
+}
+
+// String returns the kind and the name of the connection, like ElasticConnection/production
+func (r *ElasticConnectionReference) String() string {
+	return r.GetKind() + "/" + r.Name
+}
+
+// GetRegistryRef returns the ref identifying the connection referenced from namespace in an EsClientRegistry, like
+// ElasticConnection/<namespace>/<name> or ClusterElasticConnection/<name>
+func (r *ElasticConnectionReference) GetRegistryRef(namespace string) string {
+	if r.GetKind() == ClusterElasticConnectionKind {
+		return r.String()
+	}
+	return fmt.Sprintf("%v/%v/%v", r.GetKind(), namespace, r.Name)
+}
+
+// GetEsConnection reads the connection referenced from namespace, and its secrets
+func (r *ElasticConnectionReference) GetEsConnection(namespace string, k8sClient client.Client) (*utils.EsConnection, error) {
+	if r.GetKind() == ClusterElasticConnectionKind {
+		var connection ClusterElasticConnection
+		if err := k8sClient.Get(context.Background(), client.ObjectKey{Name: r.Name}, &connection); err != nil {
+			return nil, err
+		}
+		return connection.Spec.GetEsConnection(r.GetRegistryRef(namespace), connection.Spec.SecretNamespace, connection.Generation, k8sClient)
+	}
+	var connection ElasticConnection
+	if err := k8sClient.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: r.Name}, &connection); err != nil {
+		return nil, err
+	}
+	return connection.Spec.GetEsConnection(r.GetRegistryRef(namespace), namespace, connection.Generation, k8sClient)
+}
+
+// Validate returns an error when the connection has neither addresses nor cloud id, or both
+func (s *ElasticConnectionSpec) Validate() error {
+	if len(s.Addresses) == 0 && s.CloudID == "" {
+		return fmt.Errorf("one of addresses or cloudId is required")
+	}
+	if len(s.Addresses) > 0 && s.CloudID != "" {
+		return fmt.Errorf("addresses and cloudId cannot be both set")
+	}
+	return nil
+}
+
+// GetSecretNames returns the names of the auth, TLS and headers secrets of the connection
+func (s *ElasticConnectionSpec) GetSecretNames() []string {
+	var names []string
+	if s.AuthSecretRef != nil {
+		names = append(names, s.AuthSecretRef.Name)
+	}
+	names = append(names, s.TLS.GetSecretNames()...)
+	return append(names, s.HTTP.GetSecretNames()...)
+}
+
+// GetEsConnection reads the auth, TLS and headers secrets of the connection from secretNamespace. ref identifies the
+// connection in an EsClientRegistry, and the generation of the connection is part of the resource version of the
+// EsConnection
+func (s *ElasticConnectionSpec) GetEsConnection(ref string, secretNamespace string, generation int64, k8sClient client.Client) (*utils.EsConnection, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	tlsConfig, err := s.TLS.GetEsTLSConfig(secretNamespace, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	httpConfig, headersResourceVersion, err := s.HTTP.GetEsHTTPConfig(secretNamespace, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+	options := &utils.EsConfig{TLS: tlsConfig, Sniffing: s.Sniffing.GetEsSniffing(), RequestTimeout: s.Timeouts.GetRequestTimeout(), HTTP: httpConfig}
+	resourceVersion := strconv.FormatInt(generation, 10)
+	if headersResourceVersion != "" {
+		resourceVersion += "/" + headersResourceVersion
+	}
+	if s.AuthSecretRef != nil {
+		secret, err := utils.GetSecret(secretNamespace, &v1.SecretKeySelector{LocalObjectReference: *s.AuthSecretRef}, k8sClient)
+		if err != nil {
+			return nil, err
+		}
+		utils.ReadSecretCredentials(options, secret)
+		resourceVersion += "/" + secret.ResourceVersion
+	}
+
+	uri := s.CloudID
+	if len(s.Addresses) > 0 {
+		uri = strings.Join(s.Addresses, ",")
+	}
+	return &utils.EsConnection{Ref: ref, URI: uri, Options: options, ExpectedVersion: s.ExpectedVersion, ResourceVersion: resourceVersion}, nil
+}
+
+// GetEsConnection returns the elasticsearch connection of an object, read from its connectionRef when set or from its
+// elasticURI secret
+func GetEsConnection(namespace string, elasticURI *ElasticURISource, connectionRef *ElasticConnectionReference, k8sClient client.Client) (*utils.EsConnection, error) {
+	if connectionRef != nil {
+		return connectionRef.GetEsConnection(namespace, k8sClient)
+	}
+	if elasticURI == nil {
+		return nil, fmt.Errorf("one of elasticURI or connectionRef is required")
+	}
+	return elasticURI.GetEsConnection(namespace, k8sClient)
+}
+
+// SecretNotGrantedError is returned when an object references a secret of another namespace without ElasticSecretGrant
+type SecretNotGrantedError struct {
+	Namespace       string
+	SecretNamespace string
+	SecretName      string
+}
+
+func (e *SecretNotGrantedError) Error() string {
+	return fmt.Sprintf(`secret "%v" of namespace "%v" is not granted to namespace "%v" by an ElasticSecretGrant`, e.SecretName, e.SecretNamespace, e.Namespace)
+}
+
+// CheckSecretGrant returns a SecretNotGrantedError when a secret of secretNamespace is not granted to objects of
+// namespace by an ElasticSecretGrant of secretNamespace. Secrets of the same namespace are always allowed
+func CheckSecretGrant(namespace string, secretNamespace string, secretNames []string, k8sClient client.Client) error {
+	if namespace == secretNamespace {
+		return nil
+	}
+	var grants ElasticSecretGrantList
+	if err := k8sClient.List(context.Background(), &grants, client.InNamespace(secretNamespace)); err != nil {
+		return err
+	}
+	for _, name := range secretNames {
+		granted := false
+		for i := range grants.Items {
+			granted = granted || grants.Items[i].Allows(namespace, name)
+		}
+		if !granted {
+			return &SecretNotGrantedError{Namespace: namespace, SecretNamespace: secretNamespace, SecretName: name}
+		}
+	}
+	return nil
+}
+
+// ElasticResync defines how an object is periodically compared with elasticsearch once its model is applied
+type ElasticResync struct {
+	// Interval between two comparisons with elasticsearch, like 10m. Overrides the operator resync-interval flag, 0s disables resync
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+
+	// Whether a drift is repaired by applying the model again. Overrides the operator resync-repair flag
+	// +optional
+	Repair *bool `json:"repair,omitempty"`
+}
+
+// ElasticClusterReference identifies the elasticsearch cluster a model is applied to
+type ElasticClusterReference struct {
+	// Elasticsearch URL, without credentials. Node URLs are separated by commas
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// UUID of the elasticsearch cluster, identifying the cluster whatever the nodes used to reach it
+	// +optional
+	UUID string `json:"uuid,omitempty"`
+
+	// Elasticsearch major version
+	// +optional
+	Version int `json:"version,omitempty"`
+
+	// Distribution of the cluster: elasticsearch or opensearch
+	// +optional
+	Distribution string `json:"distribution,omitempty"`
+
+	// Backend of the operator serving the cluster, like elasticsearch8 or opensearch
+	// +optional
+	Backend string `json:"backend,omitempty"`
+
+	// ResourceVersion of the elasticURI secret the model was applied with, or generation of the connection and
+	// resourceVersion of its auth secret
+	// +optional
+	SecretResourceVersion string `json:"secretResourceVersion,omitempty"`
 }
 
 type EsObjectInfo struct {
 	Namespace    string
 	Name         string
 	esObjectName string
-	Host         string
-	Port         string
-}
-
-func ValidateUpdateSecret(allErrs field.ErrorList, namespace string, newSecretSelector *v1.SecretKeySelector, oldSecretSelector *v1.SecretKeySelector, k8sClient client.Client) field.ErrorList {
-	if secret, err := utils.GetSecret(namespace, newSecretSelector, k8sClient); err != nil {
-		errMsg := fmt.Sprintf(`secret "%v" is required. %v`, newSecretSelector.Name, err.Error())
-		allErrs = append(allErrs, field.Required(field.NewPath("spec").Child("elasticUri").Child("secretKeyRef"), errMsg))
-	} else if secret != nil {
-		secretKey := newSecretSelector.Key
-		if esConfig, err := utils.BuildEsConfigFromExistingSecret(secret, secretKey); err != nil {
-			errMsg := fmt.Sprintf(`error while parsing elasticsearch URI from secret "%v". %v`, newSecretSelector.Name, err.Error())
-			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("elasticUri").Child("secretKeyRef"), string(secret.Data[secretKey]), errMsg))
-		} else {
-			if oldEsConfig, _ := utils.BuildEsConfigFromSecretSelector(namespace, oldSecretSelector, k8sClient); oldEsConfig != nil {
-				if esConfig.Host != oldEsConfig.Host || esConfig.Port != oldEsConfig.Port {
-					errMsg := fmt.Sprintf(`cannot update elasticsearch host:port from "%v:%v" to "%v:%v"`, oldEsConfig.Host, oldEsConfig.Port, esConfig.Host, esConfig.Port)
-					allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("elasticUri").Child("secretKeyRef"), errMsg))
-				}
+	Cluster      string
+}
+
+// checkEsObjectExists returns the object of a kind, listed into list, that manages the elasticsearch object
+// esObjectName on the cluster of esConfig, nil when there is none
+func checkEsObjectExists(esObjectName string, list ElasticObjectList, esConfig *utils.EsConfig, k8sClient client.Client) (*EsObjectInfo, error) {
+	if err := k8sClient.List(context.Background(), list); err != nil {
+		return nil, err
+	}
+	for _, object := range list.GetObjects() {
+		if esObjectName == object.GetEsObjectName() && isSameEsCluster(esConfig, object.GetObjectStatus().Cluster) {
+			return &EsObjectInfo{
+				Namespace:    object.GetNamespace(),
+				Name:         object.GetName(),
+				esObjectName: esObjectName,
+				Cluster:      esConfig.String()}, nil
+		}
+	}
+	return nil, nil
+}
+
+// isSameEsCluster returns true when an object, whose status records the cluster it was applied to, targets the cluster
+// of esConfig. Clusters are compared by the UUID recorded by the operator, without connecting to the cluster of the
+// object: objects that were not applied yet or whose UUID is unknown are skipped
+func isSameEsCluster(esConfig *utils.EsConfig, cluster *ElasticClusterReference) bool {
+	return esConfig.ClusterUUID != "" && cluster != nil && cluster.UUID == esConfig.ClusterUUID
+}
+
+// connectionPath returns the path of the field an object connects to elasticsearch with
+func connectionPath(connectionRef *ElasticConnectionReference) *field.Path {
+	if connectionRef != nil {
+		return field.NewPath("spec").Child("connectionRef")
+	}
+	return field.NewPath("spec").Child("elasticUri").Child("secretKeyRef")
+}
+
+// validateEsConnection reads the connection of an object, from its connectionRef or its elasticURI, and detects the
+// version of its cluster
+func validateEsConnection(namespace string, elasticURI *ElasticURISource, connectionRef *ElasticConnectionReference, k8sClient client.Client) (*utils.EsConfig, field.ErrorList) {
+	switch {
+	case elasticURI != nil && connectionRef != nil:
+		return nil, field.ErrorList{field.Forbidden(field.NewPath("spec").Child("connectionRef"), "elasticURI and connectionRef cannot be both set")}
+	case connectionRef != nil:
+		connection, err := connectionRef.GetEsConnection(namespace, k8sClient)
+		if err != nil {
+			errMsg := fmt.Sprintf(`error while reading connection "%v". %v`, connectionRef, err.Error())
+			return nil, field.ErrorList{field.Invalid(connectionPath(connectionRef), connectionRef.String(), errMsg)}
+		}
+		esConfig, err := connection.Probe()
+		if err == nil {
+			err = connection.CheckVersion(esConfig)
+		}
+		if err != nil {
+			errMsg := fmt.Sprintf(`error while connecting to elasticsearch with connection "%v". %v`, connectionRef, err.Error())
+			return nil, field.ErrorList{field.Invalid(connectionPath(connectionRef), connectionRef.String(), errMsg)}
+		}
+		return esConfig, nil
+	case elasticURI != nil:
+		secret, err := elasticURI.getSecret(namespace, k8sClient)
+		if err != nil {
+			return nil, field.ErrorList{secretError(elasticURI, err)}
+		}
+		tlsConfig, err := elasticURI.GetEsTLSConfig(namespace, k8sClient)
+		if err != nil {
+			errMsg := fmt.Sprintf("error while reading TLS secrets. %v", err.Error())
+			return nil, field.ErrorList{field.Invalid(field.NewPath("spec").Child("elasticUri").Child("tls"), elasticURI.TLS, errMsg)}
+		}
+		httpConfig, _, err := elasticURI.HTTP.GetEsHTTPConfig(elasticURI.GetSecretNamespace(namespace), k8sClient)
+		if err != nil {
+			errMsg := fmt.Sprintf("error while reading headers secret. %v", err.Error())
+			return nil, field.ErrorList{field.Invalid(field.NewPath("spec").Child("elasticUri").Child("http"), elasticURI.HTTP, errMsg)}
+		}
+		secretKey := elasticURI.SecretKeyRef.Key
+		connection := utils.NewEsConnectionFromSecret(secret, secretKey, tlsConfig, nil)
+		connection.Options.HTTP, connection.Options.RequestTimeout = httpConfig, elasticURI.Timeouts.GetRequestTimeout()
+		esConfig, err := connection.Probe()
+		if err != nil {
+			errMsg := fmt.Sprintf(`error while parsing elasticsearch URI from secret "%v". %v`, elasticURI.SecretKeyRef.Name, err.Error())
+			return nil, field.ErrorList{field.Invalid(connectionPath(nil), string(secret.Data[secretKey]), errMsg)}
+		}
+		return esConfig, nil
+	default:
+		return nil, field.ErrorList{field.Required(field.NewPath("spec").Child("elasticUri"), "one of elasticURI or connectionRef is required")}
+	}
+}
+
+// secretError returns the error of an elasticURI secret that cannot be read, forbidden when it is not granted
+func secretError(elasticURI *ElasticURISource, err error) *field.Error {
+	if _, ok := err.(*SecretNotGrantedError); ok {
+		return field.Forbidden(field.NewPath("spec").Child("elasticUri").Child("namespace"), err.Error())
+	}
+	errMsg := fmt.Sprintf(`secret "%v" is required. %v`, elasticURI.SecretKeyRef.Name, err.Error())
+	return field.Required(connectionPath(nil), errMsg)
+}
+
+// validateDeleteEsConnection checks that the connection of an object can still be read, to delete it from elasticsearch.
+// An object whose elasticURI secrets are no longer granted can be deleted, the operator keeping its elasticsearch object
+func validateDeleteEsConnection(namespace string, elasticURI *ElasticURISource, connectionRef *ElasticConnectionReference, k8sClient client.Client) field.ErrorList {
+	if connectionRef != nil {
+		if _, err := connectionRef.GetEsConnection(namespace, k8sClient); err != nil {
+			errMsg := fmt.Sprintf(`connection "%v" is required. %v`, connectionRef, err.Error())
+			return field.ErrorList{field.Required(connectionPath(connectionRef), errMsg)}
+		}
+	} else if elasticURI != nil {
+		if _, err := elasticURI.getSecret(namespace, k8sClient); err != nil {
+			if _, ok := err.(*SecretNotGrantedError); ok {
+				return nil
 			}
+			return field.ErrorList{secretError(elasticURI, err)}
 		}
 	}
+	return nil
+}
+
+// ValidateUpdateConnection checks that the new connection of an object, that may switch between elasticURI and
+// connectionRef, still targets the cluster the model was applied to, identified by the UUID recorded in its status. The
+// connection of an object that was not applied yet can be changed, and the update is rejected when the UUIDs cannot be
+// compared
+func ValidateUpdateConnection(allErrs field.ErrorList, namespace string, newElasticURI *ElasticURISource, newConnectionRef *ElasticConnectionReference,
+	oldElasticURI *ElasticURISource, oldConnectionRef *ElasticConnectionReference, oldCluster *ElasticClusterReference, k8sClient client.Client) field.ErrorList {
+	esConfig, errs := validateEsConnection(namespace, newElasticURI, newConnectionRef, k8sClient)
+	if len(errs) > 0 {
+		return append(allErrs, errs...)
+	}
+	if oldCluster == nil {
+		return allErrs
+	}
+	oldUUID := oldCluster.UUID
+	connectionChanged := !reflect.DeepEqual(newElasticURI, oldElasticURI) || !reflect.DeepEqual(newConnectionRef, oldConnectionRef)
+	switch {
+	case esConfig.ClusterUUID != "" && oldUUID != "" && esConfig.ClusterUUID != oldUUID:
+		errMsg := fmt.Sprintf(`cannot update elasticsearch cluster from uuid "%v" to "%v"`, oldUUID, esConfig.ClusterUUID)
+		allErrs = append(allErrs, field.Forbidden(connectionPath(newConnectionRef), errMsg))
+	case connectionChanged && (esConfig.ClusterUUID == "" || oldUUID == ""):
+		errMsg := fmt.Sprintf(`cannot check that elasticsearch nodes "%v" are in the cluster the model was applied to, as the cluster uuid cannot be detected`, esConfig)
+		allErrs = append(allErrs, field.Forbidden(connectionPath(newConnectionRef), errMsg))
+	}
 	return allErrs
 }
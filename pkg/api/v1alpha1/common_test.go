@@ -0,0 +1,145 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"testing"
+)
+
+// stubClient reads secrets and lists ElasticSecretGrants from memory, the other calls being unsupported
+type stubClient struct {
+	client.Client
+	secrets []v1.Secret
+	grants  []ElasticSecretGrant
+}
+
+func (c *stubClient) Get(ctx context.Context, key client.ObjectKey, obj runtime.Object) error {
+	if secret, ok := obj.(*v1.Secret); ok {
+		for _, item := range c.secrets {
+			if item.Namespace == key.Namespace && item.Name == key.Name {
+				item.DeepCopyInto(secret)
+				return nil
+			}
+		}
+		return apierrors.NewNotFound(schema.GroupResource{Resource: "secrets"}, key.Name)
+	}
+	return apierrors.NewNotFound(schema.GroupResource{}, key.Name)
+}
+
+func (c *stubClient) List(ctx context.Context, list runtime.Object, opts ...client.ListOption) error {
+	listOpts := (&client.ListOptions{}).ApplyOptions(opts)
+	if grants, ok := list.(*ElasticSecretGrantList); ok {
+		for _, item := range c.grants {
+			if listOpts.Namespace == "" || item.Namespace == listOpts.Namespace {
+				grants.Items = append(grants.Items, *item.DeepCopy())
+			}
+		}
+	}
+	return nil
+}
+
+func newGrant(namespace string, from string, to ...string) ElasticSecretGrant {
+	grant := ElasticSecretGrant{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "grant-" + from},
+		Spec: ElasticSecretGrantSpec{From: []ElasticSecretGrantFrom{{Namespace: from}}}}
+	for _, name := range to {
+		grant.Spec.To = append(grant.Spec.To, ElasticSecretGrantTo{Name: name})
+	}
+	return grant
+}
+
+func TestCheckSecretGrant(t *testing.T) {
+	assert := assert.New(t)
+	k8sClient := &stubClient{grants: []ElasticSecretGrant{
+		newGrant("elastic", "team-a"),
+		newGrant("elastic", "team-b", "credentials"),
+		newGrant("other", "team-c"),
+	}}
+	scenarios := []struct {
+		name            string
+		namespace       string
+		secretNamespace string
+		secretNames     []string
+		notGranted      string
+	}{
+		{name: "same namespace without grant", namespace: "team-c", secretNamespace: "team-c", secretNames: []string{"credentials"}},
+		{name: "grant with empty to", namespace: "team-a", secretNamespace: "elastic", secretNames: []string{"credentials", "ca"}},
+		{name: "listed secret", namespace: "team-b", secretNamespace: "elastic", secretNames: []string{"credentials"}},
+		{name: "secret not listed", namespace: "team-b", secretNamespace: "elastic", secretNames: []string{"credentials", "ca"}, notGranted: "ca"},
+		{name: "namespace not listed", namespace: "team-c", secretNamespace: "elastic", secretNames: []string{"credentials"}, notGranted: "credentials"},
+		{name: "grant of another namespace", namespace: "team-a", secretNamespace: "other", secretNames: []string{"credentials"}, notGranted: "credentials"},
+		{name: "no secret", namespace: "team-c", secretNamespace: "elastic"},
+	}
+
+	for _, s := range scenarios {
+		err := CheckSecretGrant(s.namespace, s.secretNamespace, s.secretNames, k8sClient)
+		if s.notGranted == "" {
+			assert.Nil(err, s.name)
+		} else {
+			assert.Equal(&SecretNotGrantedError{Namespace: s.namespace, SecretNamespace: s.secretNamespace, SecretName: s.notGranted}, err, s.name)
+		}
+	}
+}
+
+func TestValidateDeleteEsConnection(t *testing.T) {
+	assert := assert.New(t)
+	k8sClient := &stubClient{
+		secrets: []v1.Secret{
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "elastic", Name: "credentials"}, Data: map[string][]byte{"uri": []byte("http://es:9200")}},
+			{ObjectMeta: metav1.ObjectMeta{Namespace: "team-a", Name: "credentials"}, Data: map[string][]byte{"uri": []byte("http://es:9200")}},
+		},
+		grants: []ElasticSecretGrant{newGrant("elastic", "team-a")},
+	}
+	elasticURI := func(namespace string, name string) *ElasticURISource {
+		return &ElasticURISource{Namespace: namespace, SecretKeyRef: &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: name}, Key: "uri"}}
+	}
+
+	assert.Nil(validateDeleteEsConnection("team-a", elasticURI("", "credentials"), nil, k8sClient), "secret of the same namespace")
+	assert.Nil(validateDeleteEsConnection("team-a", elasticURI("elastic", "credentials"), nil, k8sClient), "granted secret")
+	assert.Nil(validateDeleteEsConnection("team-b", elasticURI("elastic", "credentials"), nil, k8sClient), "secret no longer granted")
+
+	errs := validateDeleteEsConnection("team-a", elasticURI("elastic", "missing"), nil, k8sClient)
+	assert.Equal(1, len(errs), "granted secret that does not exist")
+	errs = validateDeleteEsConnection("team-a", nil, &ElasticConnectionReference{Name: "missing"}, k8sClient)
+	assert.Equal(1, len(errs), "connection that does not exist")
+}
+
+func TestIsSameEsCluster(t *testing.T) {
+	assert := assert.New(t)
+	esConfig := &utils.EsConfig{ClusterUUID: "uuid-1"}
+	scenarios := []struct {
+		esConfig *utils.EsConfig
+		cluster  *ElasticClusterReference
+		expect   bool
+	}{
+		{esConfig: esConfig, cluster: &ElasticClusterReference{URL: "http://node-2:9200", UUID: "uuid-1"}, expect: true},
+		{esConfig: esConfig, cluster: &ElasticClusterReference{UUID: "uuid-2"}, expect: false},
+		{esConfig: esConfig, cluster: &ElasticClusterReference{URL: "http://node-1:9200"}, expect: false},
+		{esConfig: esConfig, cluster: nil, expect: false},
+		{esConfig: &utils.EsConfig{}, cluster: &ElasticClusterReference{}, expect: false},
+	}
+
+	for _, s := range scenarios {
+		assert.Equal(s.expect, isSameEsCluster(s.esConfig, s.cluster), s.cluster)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionReady is True when the current model is applied in elasticsearch
+	ConditionReady = "Ready"
+	// ConditionReconciling is True while the operator is still working to apply the model, like during a migration or a retry
+	ConditionReconciling = "Reconciling"
+	// ConditionDegraded is True when the last reconciliation failed
+	ConditionDegraded = "Degraded"
+	// ConditionDrifted is True when the elasticsearch object differs from the model since the last resync
+	ConditionDrifted = "Drifted"
+)
+
+// Condition contains details for one aspect of the current state of an object, like metav1.Condition
+// that is not available in this kubernetes api version
+type Condition struct {
+	// Type of condition
+	// +kubebuilder:validation:Required
+	Type string `json:"type"`
+
+	// Status of the condition, one of True, False, Unknown
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=True;False;Unknown
+	Status v1.ConditionStatus `json:"status"`
+
+	// The metadata.generation the condition was set from
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Last time the condition transitioned from one status to another
+	// +kubebuilder:validation:Required
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+
+	// Reason for the condition's last transition, in CamelCase
+	// +kubebuilder:validation:Required
+	Reason string `json:"reason"`
+
+	// Human readable message indicating details about the transition
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// FindCondition returns the condition with the given type, or nil
+func FindCondition(conditions []Condition, conditionType string) *Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// IsConditionTrue returns true when the condition with the given type exists and is True
+func IsConditionTrue(conditions []Condition, conditionType string) bool {
+	condition := FindCondition(conditions, conditionType)
+	return condition != nil && condition.Status == v1.ConditionTrue
+}
+
+// SetCondition adds or updates the condition with the same type, and returns true when conditions are updated.
+// LastTransitionTime is only updated when the status changes
+func SetCondition(conditions *[]Condition, newCondition Condition) bool {
+	existingCondition := FindCondition(*conditions, newCondition.Type)
+	if existingCondition == nil {
+		if newCondition.LastTransitionTime.IsZero() {
+			newCondition.LastTransitionTime = metav1.Now()
+		}
+		*conditions = append(*conditions, newCondition)
+		return true
+	}
+
+	if existingCondition.Status == newCondition.Status &&
+		existingCondition.Reason == newCondition.Reason &&
+		existingCondition.Message == newCondition.Message &&
+		existingCondition.ObservedGeneration == newCondition.ObservedGeneration {
+		return false
+	}
+
+	if existingCondition.Status != newCondition.Status {
+		existingCondition.LastTransitionTime = metav1.Now()
+		if !newCondition.LastTransitionTime.IsZero() {
+			existingCondition.LastTransitionTime = newCondition.LastTransitionTime
+		}
+	}
+	existingCondition.Status = newCondition.Status
+	existingCondition.Reason = newCondition.Reason
+	existingCondition.Message = newCondition.Message
+	existingCondition.ObservedGeneration = newCondition.ObservedGeneration
+	return true
+}
@@ -0,0 +1,103 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ElasticComponentTemplateSpec defines the desired state of ElasticComponentTemplate
+type ElasticComponentTemplateSpec struct {
+	// Component template name in elasticsearch server
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9-_\.]+$`
+	TemplateName *string `json:"templateName"`
+
+	// Elasticsearch URI with this format <scheme>://<user>:<password>@<hostname>:<port> from a key of a secret in the local namespace.
+	// Legacy alternative to connectionRef
+	// +optional
+	ElasticURI *ElasticURISource `json:"elasticURI,omitempty"`
+
+	// Connection to the elasticsearch cluster: an ElasticConnection of the local namespace, or a ClusterElasticConnection
+	// +optional
+	ConnectionRef *ElasticConnectionReference `json:"connectionRef,omitempty"`
+
+	// Component template template (settings, mappings and aliases), version and _meta
+	// +kubebuilder:validation:Required
+	Model *string `json:"model"`
+
+	// Resync defines how the object is periodically compared with elasticsearch, overriding the operator flags
+	// +optional
+	Resync *ElasticResync `json:"resync,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=ecmt
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="TEMPLATE_NAME",type="string",JSONPath=".spec.templateName"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.status"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ElasticComponentTemplate is the Schema for the elasticcomponenttemplates API, a component template of the
+// _component_template API that ElasticComposableTemplates are composed of
+type ElasticComponentTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ElasticComponentTemplateSpec `json:"spec,omitempty"`
+	Status ElasticObjectStatus          `json:"status,omitempty"`
+}
+
+func (r *ElasticComponentTemplate) GetEsObjectName() string {
+	return *r.Spec.TemplateName
+}
+
+func (r *ElasticComponentTemplate) GetConnection() (*ElasticURISource, *ElasticConnectionReference) {
+	return r.Spec.ElasticURI, r.Spec.ConnectionRef
+}
+
+func (r *ElasticComponentTemplate) GetModel() (string, error) {
+	return *r.Spec.Model, nil
+}
+
+func (r *ElasticComponentTemplate) GetResync() *ElasticResync {
+	return r.Spec.Resync
+}
+
+func (r *ElasticComponentTemplate) GetObjectStatus() *ElasticObjectStatus {
+	return &r.Status
+}
+
+// +kubebuilder:object:root=true
+
+// ElasticComponentTemplateList contains a list of ElasticComponentTemplate
+type ElasticComponentTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ElasticComponentTemplate `json:"items"`
+}
+
+func (l *ElasticComponentTemplateList) GetObjects() []ElasticObject {
+	var objects []ElasticObject
+	for i := range l.Items {
+		objects = append(objects, &l.Items[i])
+	}
+	return objects
+}
+
+func init() {
+	SchemeBuilder.Register(&ElasticComponentTemplate{}, &ElasticComponentTemplateList{})
+}
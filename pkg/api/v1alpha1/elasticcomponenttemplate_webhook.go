@@ -0,0 +1,158 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+var (
+	// log is for logging in this package.
+	elasticcomponenttemplatelog        = logf.Log.WithName("elasticcomponenttemplate-resource")
+	elasticcomponenttemplateK8sClient  client.Client
+	elasticcomponenttemplateNamespaces []string
+)
+
+func (r *ElasticComponentTemplate) SetupWebhookWithManager(mgr ctrl.Manager, namespaces []string) error {
+	elasticcomponenttemplateK8sClient = mgr.GetClient()
+	elasticcomponenttemplateNamespaces = namespaces
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-elastic-carrefour-com-v1alpha1-elasticcomponenttemplate,mutating=true,failurePolicy=fail,groups=elastic.carrefour.com,resources=elasticcomponenttemplates,verbs=create;update,versions=v1alpha1,name=melasticcomponenttemplate.kb.io,sideEffects=none,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Defaulter = &ElasticComponentTemplate{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *ElasticComponentTemplate) Default() {
+	elasticcomponenttemplatelog.Info("[Webhook] default", "namespace", r.Namespace, "name", r.Name)
+
+	if r.Spec.Model != nil {
+		if compactedModel, err := utils.CompactJson(*r.Spec.Model); err == nil {
+			*r.Spec.Model = compactedModel
+		}
+	}
+}
+
+// +kubebuilder:webhook:verbs=create;update;delete,path=/validate-elastic-carrefour-com-v1alpha1-elasticcomponenttemplate,mutating=false,failurePolicy=fail,groups=elastic.carrefour.com,resources=elasticcomponenttemplates,versions=v1alpha1,name=velasticcomponenttemplate.kb.io,sideEffects=none,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Validator = &ElasticComponentTemplate{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticComponentTemplate) ValidateCreate() error {
+	if len(elasticcomponenttemplateNamespaces) == 0 || utils.ContainsString(elasticcomponenttemplateNamespaces, r.ObjectMeta.Namespace) {
+		elasticcomponenttemplatelog.Info("[Webhook] validate create", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		_, err := (&utils.EsModel{Model: *r.Spec.Model}).IsValid(utils.ComponentTemplate)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("model"), r.Spec.Model, err.Error()))
+		}
+
+		if esConfig, errs := validateEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticcomponenttemplateK8sClient); len(errs) > 0 {
+			allErrs = append(allErrs, errs...)
+		} else {
+			if info, err := checkEsObjectExists(*r.Spec.TemplateName, &ElasticComponentTemplateList{}, esConfig, elasticcomponenttemplateK8sClient); err != nil {
+				errMsg := fmt.Sprintf(`error while checking component template "%v" existence from all kubernetes elasticcomponenttemplate objects. %v`, *r.Spec.TemplateName, err.Error())
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("templateName"), r.Spec.TemplateName, errMsg))
+			} else if info != nil {
+				errMsg := fmt.Sprintf(`component template "%v" for elasticsearch cluster "%v" was created by kubernetes elasticcomponenttemplate "%v" in namespace "%v"`, info.esObjectName, info.Cluster, info.Name, info.Namespace)
+				allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("templateName"), errMsg))
+			}
+		}
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticComponentTemplate"},
+			r.Name, allErrs)
+	}
+
+	elasticcomponenttemplatelog.Info("[Webhook] ignore validate create", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticComponentTemplate) ValidateUpdate(old runtime.Object) error {
+	if len(elasticcomponenttemplateNamespaces) == 0 || utils.ContainsString(elasticcomponenttemplateNamespaces, r.ObjectMeta.Namespace) {
+		elasticcomponenttemplatelog.Info("[Webhook] validate update", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		oldR := old.(*ElasticComponentTemplate)
+
+		if *r.Spec.TemplateName != *oldR.Spec.TemplateName {
+			errMsg := fmt.Sprintf(`Cannot update templateName from "%v" to "%v"`, *oldR.Spec.TemplateName, *r.Spec.TemplateName)
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("templateName"), r.Spec.TemplateName, errMsg))
+		}
+
+		if !utils.CompareJson(*r.Spec.Model, *oldR.Spec.Model) {
+			_, err := (&utils.EsModel{Model: *r.Spec.Model}).IsValid(utils.ComponentTemplate)
+			if err != nil {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("model"), r.Spec.Model, err.Error()))
+			}
+		}
+
+		allErrs = ValidateUpdateConnection(allErrs, r.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, oldR.Spec.ElasticURI, oldR.Spec.ConnectionRef, oldR.Status.Cluster, elasticcomponenttemplateK8sClient)
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticComponentTemplate"},
+			r.Name, allErrs)
+	}
+
+	elasticcomponenttemplatelog.Info("[Webhook] ignore validate update", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticComponentTemplate) ValidateDelete() error {
+	if len(elasticcomponenttemplateNamespaces) == 0 || utils.ContainsString(elasticcomponenttemplateNamespaces, r.ObjectMeta.Namespace) {
+		elasticcomponenttemplatelog.Info("[Webhook] validate delete", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		allErrs = append(allErrs, validateDeleteEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticcomponenttemplateK8sClient)...)
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticComponentTemplate"},
+			r.Name, allErrs)
+	}
+
+	elasticcomponenttemplatelog.Info("[Webhook] ignore validate delete", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
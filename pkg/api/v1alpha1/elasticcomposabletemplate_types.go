@@ -0,0 +1,136 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ElasticComposableTemplateSpec defines the desired state of ElasticComposableTemplate
+type ElasticComposableTemplateSpec struct {
+	// Composable index template name in elasticsearch server
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9-_\.]+$`
+	TemplateName *string `json:"templateName"`
+
+	// Elasticsearch URI with this format <scheme>://<user>:<password>@<hostname>:<port> from a key of a secret in the local namespace.
+	// Legacy alternative to connectionRef
+	// +optional
+	ElasticURI *ElasticURISource `json:"elasticURI,omitempty"`
+
+	// Connection to the elasticsearch cluster: an ElasticConnection of the local namespace, or a ClusterElasticConnection
+	// +optional
+	ConnectionRef *ElasticConnectionReference `json:"connectionRef,omitempty"`
+
+	// Template priority, the template with the highest priority being applied to the indices matching several templates
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	// +nullable
+	Priority *int `json:"priority,omitempty"`
+
+	// Names of the component templates merged into the template, in order. They are ElasticComponentTemplates of the
+	// same cluster, or component templates existing in elasticsearch
+	// +optional
+	ComposedOf []string `json:"composedOf,omitempty"`
+
+	// Makes the indices matching the template data streams
+	// +optional
+	DataStream *ElasticDataStreamTemplate `json:"dataStream,omitempty"`
+
+	// Template index_patterns, template (settings, mappings and aliases), version and _meta
+	// +kubebuilder:validation:Required
+	Model *string `json:"model"`
+
+	// Resync defines how the object is periodically compared with elasticsearch, overriding the operator flags
+	// +optional
+	Resync *ElasticResync `json:"resync,omitempty"`
+}
+
+// ElasticDataStreamTemplate configures the data streams created from a composable index template
+type ElasticDataStreamTemplate struct {
+	// Hidden data streams are not returned by wildcard expressions
+	// +optional
+	Hidden bool `json:"hidden,omitempty"`
+
+	// Allows custom routing of the writes, elasticsearch 8 or later
+	// +optional
+	AllowCustomRouting bool `json:"allowCustomRouting,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=ecpt
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="TEMPLATE_NAME",type="string",JSONPath=".spec.templateName"
+// +kubebuilder:printcolumn:name="PRIORITY",type="integer",JSONPath=".spec.priority"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.status"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ElasticComposableTemplate is the Schema for the elasticcomposabletemplates API, a composable index template of the
+// _index_template API
+type ElasticComposableTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ElasticComposableTemplateSpec `json:"spec,omitempty"`
+	Status ElasticObjectStatus           `json:"status,omitempty"`
+}
+
+func (r *ElasticComposableTemplate) GetEsObjectName() string {
+	return *r.Spec.TemplateName
+}
+
+func (r *ElasticComposableTemplate) GetConnection() (*ElasticURISource, *ElasticConnectionReference) {
+	return r.Spec.ElasticURI, r.Spec.ConnectionRef
+}
+
+// GetModel returns the model with the priority, composed_of and data_stream fields of the spec
+func (r *ElasticComposableTemplate) GetModel() (string, error) {
+	options := utils.EsIndexTemplateOptions{Priority: r.Spec.Priority, ComposedOf: r.Spec.ComposedOf}
+	if r.Spec.DataStream != nil {
+		options.DataStream = &utils.EsDataStreamOptions{Hidden: r.Spec.DataStream.Hidden, AllowCustomRouting: r.Spec.DataStream.AllowCustomRouting}
+	}
+	return (&utils.EsModel{Model: *r.Spec.Model}).WithIndexTemplateOptions(options)
+}
+
+func (r *ElasticComposableTemplate) GetResync() *ElasticResync {
+	return r.Spec.Resync
+}
+
+func (r *ElasticComposableTemplate) GetObjectStatus() *ElasticObjectStatus {
+	return &r.Status
+}
+
+// +kubebuilder:object:root=true
+
+// ElasticComposableTemplateList contains a list of ElasticComposableTemplate
+type ElasticComposableTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ElasticComposableTemplate `json:"items"`
+}
+
+func (l *ElasticComposableTemplateList) GetObjects() []ElasticObject {
+	var objects []ElasticObject
+	for i := range l.Items {
+		objects = append(objects, &l.Items[i])
+	}
+	return objects
+}
+
+func init() {
+	SchemeBuilder.Register(&ElasticComposableTemplate{}, &ElasticComposableTemplateList{})
+}
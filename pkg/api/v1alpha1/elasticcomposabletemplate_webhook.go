@@ -0,0 +1,220 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+var (
+	// log is for logging in this package.
+	elasticcomposabletemplatelog        = logf.Log.WithName("elasticcomposabletemplate-resource")
+	elasticcomposabletemplateK8sClient  client.Client
+	elasticcomposabletemplateNamespaces []string
+)
+
+func (r *ElasticComposableTemplate) SetupWebhookWithManager(mgr ctrl.Manager, namespaces []string) error {
+	elasticcomposabletemplateK8sClient = mgr.GetClient()
+	elasticcomposabletemplateNamespaces = namespaces
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-elastic-carrefour-com-v1alpha1-elasticcomposabletemplate,mutating=true,failurePolicy=fail,groups=elastic.carrefour.com,resources=elasticcomposabletemplates,verbs=create;update,versions=v1alpha1,name=melasticcomposabletemplate.kb.io,sideEffects=none,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Defaulter = &ElasticComposableTemplate{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *ElasticComposableTemplate) Default() {
+	elasticcomposabletemplatelog.Info("[Webhook] default", "namespace", r.Namespace, "name", r.Name)
+
+	if r.Spec.Model != nil {
+		if compactedModel, err := utils.CompactJson(*r.Spec.Model); err == nil {
+			*r.Spec.Model = compactedModel
+		}
+	}
+}
+
+// +kubebuilder:webhook:verbs=create;update;delete,path=/validate-elastic-carrefour-com-v1alpha1-elasticcomposabletemplate,mutating=false,failurePolicy=fail,groups=elastic.carrefour.com,resources=elasticcomposabletemplates,versions=v1alpha1,name=velasticcomposabletemplate.kb.io,sideEffects=none,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Validator = &ElasticComposableTemplate{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticComposableTemplate) ValidateCreate() error {
+	if len(elasticcomposabletemplateNamespaces) == 0 || utils.ContainsString(elasticcomposabletemplateNamespaces, r.ObjectMeta.Namespace) {
+		elasticcomposabletemplatelog.Info("[Webhook] validate create", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		_, err := (&utils.EsModel{Model: *r.Spec.Model}).IsValid(utils.IndexTemplate)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("model"), r.Spec.Model, err.Error()))
+		}
+
+		if esConfig, errs := validateEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticcomposabletemplateK8sClient); len(errs) > 0 {
+			allErrs = append(allErrs, errs...)
+		} else {
+			if info, err := checkEsObjectExists(*r.Spec.TemplateName, &ElasticComposableTemplateList{}, esConfig, elasticcomposabletemplateK8sClient); err != nil {
+				errMsg := fmt.Sprintf(`error while checking index template "%v" existence from all kubernetes elasticcomposabletemplate objects. %v`, *r.Spec.TemplateName, err.Error())
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("templateName"), r.Spec.TemplateName, errMsg))
+			} else if info != nil {
+				errMsg := fmt.Sprintf(`index template "%v" for elasticsearch cluster "%v" was created by kubernetes elasticcomposabletemplate "%v" in namespace "%v"`, info.esObjectName, info.Cluster, info.Name, info.Namespace)
+				allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("templateName"), errMsg))
+			}
+			allErrs = append(allErrs, validateComponentTemplates(r.Spec.ComposedOf, nil, esConfig, elasticcomposabletemplateK8sClient)...)
+		}
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticComposableTemplate"},
+			r.Name, allErrs)
+	}
+
+	elasticcomposabletemplatelog.Info("[Webhook] ignore validate create", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticComposableTemplate) ValidateUpdate(old runtime.Object) error {
+	if len(elasticcomposabletemplateNamespaces) == 0 || utils.ContainsString(elasticcomposabletemplateNamespaces, r.ObjectMeta.Namespace) {
+		elasticcomposabletemplatelog.Info("[Webhook] validate update", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		oldR := old.(*ElasticComposableTemplate)
+
+		if *r.Spec.TemplateName != *oldR.Spec.TemplateName {
+			errMsg := fmt.Sprintf(`Cannot update templateName from "%v" to "%v"`, *oldR.Spec.TemplateName, *r.Spec.TemplateName)
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("templateName"), r.Spec.TemplateName, errMsg))
+		}
+
+		if !utils.CompareJson(*r.Spec.Model, *oldR.Spec.Model) {
+			_, err := (&utils.EsModel{Model: *r.Spec.Model}).IsValid(utils.IndexTemplate)
+			if err != nil {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("model"), r.Spec.Model, err.Error()))
+			}
+		}
+
+		allErrs = ValidateUpdateConnection(allErrs, r.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, oldR.Spec.ElasticURI, oldR.Spec.ConnectionRef, oldR.Status.Cluster, elasticcomposabletemplateK8sClient)
+
+		// only the component templates added by the update are checked, the others were checked before
+		if len(allErrs) == 0 && !isSubset(r.Spec.ComposedOf, oldR.Spec.ComposedOf) {
+			if esConfig, errs := validateEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticcomposabletemplateK8sClient); len(errs) > 0 {
+				allErrs = append(allErrs, errs...)
+			} else {
+				allErrs = append(allErrs, validateComponentTemplates(r.Spec.ComposedOf, oldR.Spec.ComposedOf, esConfig, elasticcomposabletemplateK8sClient)...)
+			}
+		}
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticComposableTemplate"},
+			r.Name, allErrs)
+	}
+
+	elasticcomposabletemplatelog.Info("[Webhook] ignore validate update", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticComposableTemplate) ValidateDelete() error {
+	if len(elasticcomposabletemplateNamespaces) == 0 || utils.ContainsString(elasticcomposabletemplateNamespaces, r.ObjectMeta.Namespace) {
+		elasticcomposabletemplatelog.Info("[Webhook] validate delete", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		allErrs = append(allErrs, validateDeleteEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticcomposabletemplateK8sClient)...)
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticComposableTemplate"},
+			r.Name, allErrs)
+	}
+
+	elasticcomposabletemplatelog.Info("[Webhook] ignore validate delete", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// validateComponentTemplates checks that the component templates of composedOf, except the ones of checked, are
+// ElasticComponentTemplates of the cluster of esConfig or exist in elasticsearch
+func validateComponentTemplates(composedOf []string, checked []string, esConfig *utils.EsConfig, k8sClient client.Client) field.ErrorList {
+	var allErrs field.ErrorList
+	var indexTemplates utils.EsIndexTemplates
+
+	for i, name := range composedOf {
+		if utils.ContainsString(checked, name) {
+			continue
+		}
+		path := field.NewPath("spec").Child("composedOf").Index(i)
+
+		if info, err := checkEsObjectExists(name, &ElasticComponentTemplateList{}, esConfig, k8sClient); err != nil {
+			errMsg := fmt.Sprintf(`error while checking component template "%v" existence from all kubernetes elasticcomponenttemplate objects. %v`, name, err.Error())
+			allErrs = append(allErrs, field.Invalid(path, name, errMsg))
+			continue
+		} else if info != nil {
+			continue
+		}
+
+		if indexTemplates == nil {
+			elasticsearch, err := utils.NewElasticsearch(esConfig, elasticcomposabletemplatelog)
+			if err != nil {
+				return append(allErrs, field.Invalid(path, name, err.Error()))
+			}
+			var ok bool
+			if indexTemplates, ok = elasticsearch.(utils.EsIndexTemplates); !ok {
+				errMsg := fmt.Sprintf("component templates are not supported by %v %v", esConfig.Distribution, esConfig.VersionNumber)
+				return append(allErrs, field.Forbidden(field.NewPath("spec").Child("composedOf"), errMsg))
+			}
+		}
+		if exists, err := indexTemplates.ExistsComponentTemplate(context.Background(), name); err != nil {
+			errMsg := fmt.Sprintf(`error while checking component template "%v" existence. %v`, name, err.Error())
+			allErrs = append(allErrs, field.Invalid(path, name, errMsg))
+		} else if !exists {
+			allErrs = append(allErrs, field.NotFound(path, name))
+		}
+	}
+	return allErrs
+}
+
+// isSubset returns true when all the values are in others
+func isSubset(values []string, others []string) bool {
+	for _, value := range values {
+		if !utils.ContainsString(others, value) {
+			return false
+		}
+	}
+	return true
+}
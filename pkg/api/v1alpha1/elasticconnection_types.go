@@ -0,0 +1,134 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ElasticConnectionSpec defines an elasticsearch cluster and how to connect to it
+type ElasticConnectionSpec struct {
+	// Node URLs without credentials, like https://elastic-system-es-http.elastic-system.svc:9200. Required unless cloudId is set
+	// +optional
+	Addresses []string `json:"addresses,omitempty"`
+
+	// Cloud ID of an Elastic Cloud deployment, used instead of addresses
+	// +optional
+	CloudID string `json:"cloudId,omitempty"`
+
+	// Secret holding the credentials in username and password keys, apiKey key or bearerToken key
+	// +optional
+	AuthSecretRef *v1.LocalObjectReference `json:"authSecretRef,omitempty"`
+
+	// TLS configuration of https addresses. Elasticsearch certificate is verified with system CAs by default
+	// +optional
+	TLS *ElasticTLS `json:"tls,omitempty"`
+
+	// Discover the nodes of the cluster from the addresses, calls being spread and retried on all of them
+	// +optional
+	Sniffing *ElasticSniffing `json:"sniffing,omitempty"`
+
+	// Timeouts of elasticsearch API calls
+	// +optional
+	Timeouts *ElasticTimeouts `json:"timeouts,omitempty"`
+
+	// HTTP proxy, headers, compression and User-Agent of the requests
+	// +optional
+	HTTP *ElasticHTTP `json:"http,omitempty"`
+
+	// Expected elasticsearch version, like 8 or 8.4, matching the detected version number by prefix. Objects using the
+	// connection are not reconciled when the cluster runs another version
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+){0,2}$`
+	// +optional
+	ExpectedVersion string `json:"expectedVersion,omitempty"`
+}
+
+// ElasticTimeouts configures the timeouts of elasticsearch API calls
+type ElasticTimeouts struct {
+	// Timeout of each elasticsearch API call, like 2m for large mapping updates. Defaults to the es-request-timeout
+	// operator flag, 10s by default
+	// +optional
+	Request *metav1.Duration `json:"request,omitempty"`
+}
+
+// ElasticConnectionStatus defines the observed state of an elasticsearch cluster
+type ElasticConnectionStatus struct {
+	// The metadata.generation the status reflects
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions of the connection: Ready when the cluster is reachable with the expected version, and Degraded when
+	// its health is red
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// Whether the cluster answered the last probe
+	// +optional
+	Reachable bool `json:"reachable"`
+
+	// Health of the cluster: green, yellow or red
+	// +optional
+	Health string `json:"health,omitempty"`
+
+	// Elasticsearch version number, like 8.4.2
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Distribution of the cluster: elasticsearch or opensearch
+	// +optional
+	Distribution string `json:"distribution,omitempty"`
+
+	// Backend of the operator serving the cluster, like elasticsearch8 or opensearch
+	// +optional
+	Backend string `json:"backend,omitempty"`
+
+	// UUID of the elasticsearch cluster
+	// +optional
+	UUID string `json:"uuid,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=ec
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="BACKEND",type="string",JSONPath=".status.backend"
+// +kubebuilder:printcolumn:name="VERSION",type="string",JSONPath=".status.version"
+// +kubebuilder:printcolumn:name="HEALTH",type="string",JSONPath=".status.health"
+// +kubebuilder:printcolumn:name="REACHABLE",type="boolean",JSONPath=".status.reachable"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ElasticConnection is the Schema for the elasticconnections API. Its secrets are read from its namespace, and only
+// objects of its namespace can use it
+type ElasticConnection struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ElasticConnectionSpec   `json:"spec,omitempty"`
+	Status ElasticConnectionStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ElasticConnectionList contains a list of ElasticConnection
+type ElasticConnectionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ElasticConnection `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ElasticConnection{}, &ElasticConnectionList{})
+}
@@ -0,0 +1,164 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"net/url"
+	"reflect"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"strings"
+)
+
+var (
+	// log is for logging in this package.
+	elasticconnectionlog        = logf.Log.WithName("elasticconnection-resource")
+	elasticconnectionK8sClient  client.Client
+	elasticconnectionNamespaces []string
+)
+
+func (r *ElasticConnection) SetupWebhookWithManager(mgr ctrl.Manager, namespaces []string) error {
+	elasticconnectionK8sClient = mgr.GetClient()
+	elasticconnectionNamespaces = namespaces
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-elastic-carrefour-com-v1alpha1-elasticconnection,mutating=false,failurePolicy=fail,groups=elastic.carrefour.com,resources=elasticconnections,versions=v1alpha1,name=velasticconnection.kb.io,sideEffects=none,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Validator = &ElasticConnection{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticConnection) ValidateCreate() error {
+	if len(elasticconnectionNamespaces) == 0 || utils.ContainsString(elasticconnectionNamespaces, r.ObjectMeta.Namespace) {
+		elasticconnectionlog.Info("[Webhook] validate create", "namespace", r.Namespace, "name", r.Name)
+
+		allErrs := validateConnectionSpec(&r.Spec, r.Namespace, field.NewPath("spec"), elasticconnectionK8sClient)
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticConnection"},
+			r.Name, allErrs)
+	}
+
+	elasticconnectionlog.Info("[Webhook] ignore validate create", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticConnection) ValidateUpdate(old runtime.Object) error {
+	if len(elasticconnectionNamespaces) == 0 || utils.ContainsString(elasticconnectionNamespaces, r.ObjectMeta.Namespace) {
+		elasticconnectionlog.Info("[Webhook] validate update", "namespace", r.Namespace, "name", r.Name)
+
+		oldR := old.(*ElasticConnection)
+		if reflect.DeepEqual(r.Spec, oldR.Spec) {
+			return nil
+		}
+
+		allErrs := validateConnectionSpec(&r.Spec, r.Namespace, field.NewPath("spec"), elasticconnectionK8sClient)
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticConnection"},
+			r.Name, allErrs)
+	}
+
+	elasticconnectionlog.Info("[Webhook] ignore validate update", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticConnection) ValidateDelete() error {
+	return nil
+}
+
+// validateConnectionSpec checks the addresses or the cloud id of a connection, and reads its auth and TLS secrets from
+// secretNamespace, without connecting to elasticsearch as the cluster may not be reachable yet
+func validateConnectionSpec(spec *ElasticConnectionSpec, secretNamespace string, specPath *field.Path, k8sClient client.Client) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if err := spec.Validate(); err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("addresses"), spec.Addresses, err.Error()))
+	}
+	hasHTTPS := spec.CloudID != ""
+	for i, address := range spec.Addresses {
+		addressPath := specPath.Child("addresses").Index(i)
+		u, err := url.Parse(strings.TrimSpace(address))
+		switch {
+		case err != nil:
+			allErrs = append(allErrs, field.Invalid(addressPath, address, err.Error()))
+		case u.Scheme != "http" && u.Scheme != "https":
+			allErrs = append(allErrs, field.Invalid(addressPath, address, "address should be an http or https URL"))
+		case u.Hostname() == "":
+			allErrs = append(allErrs, field.Invalid(addressPath, address, "address has no host"))
+		case u.User != nil:
+			allErrs = append(allErrs, field.Forbidden(addressPath, "credentials should be set in the authSecretRef secret, not in addresses"))
+		}
+		hasHTTPS = hasHTTPS || (err == nil && u.Scheme == "https")
+	}
+	if spec.CloudID != "" {
+		if _, err := utils.ParseEsURI(spec.CloudID); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("cloudId"), spec.CloudID, err.Error()))
+		}
+	}
+
+	if spec.AuthSecretRef != nil {
+		authPath := specPath.Child("authSecretRef").Child("name")
+		if secret, err := utils.GetSecret(secretNamespace, &v1.SecretKeySelector{LocalObjectReference: *spec.AuthSecretRef}, k8sClient); err != nil {
+			errMsg := fmt.Sprintf(`error while reading auth secret "%v". %v`, spec.AuthSecretRef.Name, err.Error())
+			allErrs = append(allErrs, field.Invalid(authPath, spec.AuthSecretRef.Name, errMsg))
+		} else {
+			credentials := &utils.EsConfig{}
+			utils.ReadSecretCredentials(credentials, secret)
+			if credentials.Username == "" && credentials.APIKey == "" && credentials.BearerToken == "" {
+				errMsg := fmt.Sprintf(`auth secret "%v" has no %v, %v or %v key`, spec.AuthSecretRef.Name, utils.UsernameSecretKey, utils.APIKeySecretKey, utils.BearerTokenSecretKey)
+				allErrs = append(allErrs, field.Invalid(authPath, spec.AuthSecretRef.Name, errMsg))
+			}
+		}
+	}
+
+	if spec.TLS != nil {
+		tlsPath := specPath.Child("tls")
+		tlsConfig, err := spec.TLS.GetEsTLSConfig(secretNamespace, k8sClient)
+		if err == nil {
+			_, err = utils.BuildTLSConfig(tlsConfig)
+		}
+		if err != nil {
+			errMsg := fmt.Sprintf("error while reading TLS secrets. %v", err.Error())
+			allErrs = append(allErrs, field.Invalid(tlsPath, spec.TLS, errMsg))
+		} else if !hasHTTPS {
+			allErrs = append(allErrs, field.Invalid(tlsPath, spec.TLS, "tls is only used with https addresses"))
+		}
+	}
+
+	return allErrs
+}
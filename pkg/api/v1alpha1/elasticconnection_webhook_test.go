@@ -0,0 +1,63 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"testing"
+)
+
+func TestValidateConnectionSpec(t *testing.T) {
+	assert := assert.New(t)
+	k8sClient := &stubClient{secrets: []v1.Secret{
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "elastic", Name: "credentials"}, Data: map[string][]byte{"username": []byte("elastic"), "password": []byte("pass")}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "elastic", Name: "api-key"}, Data: map[string][]byte{"apiKey": []byte("id:key")}},
+		{ObjectMeta: metav1.ObjectMeta{Namespace: "elastic", Name: "empty"}, Data: map[string][]byte{"user": []byte("elastic")}},
+	}}
+	auth := func(name string) *v1.LocalObjectReference { return &v1.LocalObjectReference{Name: name} }
+	scenarios := []struct {
+		name      string
+		spec      ElasticConnectionSpec
+		errFields []string
+	}{
+		{name: "addresses with credentials secret", spec: ElasticConnectionSpec{Addresses: []string{"http://es-1:9200", "http://es-2:9200"}, AuthSecretRef: auth("credentials")}},
+		{name: "api key secret", spec: ElasticConnectionSpec{Addresses: []string{"https://es:9200"}, AuthSecretRef: auth("api-key")}},
+		{name: "no address", spec: ElasticConnectionSpec{}, errFields: []string{"spec.addresses"}},
+		{name: "addresses and cloud id", spec: ElasticConnectionSpec{Addresses: []string{"http://es:9200"}, CloudID: "name:ZXMuZXhhbXBsZS5jb20kYWJjJGRlZg=="},
+			errFields: []string{"spec.addresses"}},
+		{name: "invalid cloud id", spec: ElasticConnectionSpec{CloudID: "name:not-base64"}, errFields: []string{"spec.cloudId"}},
+		{name: "invalid scheme", spec: ElasticConnectionSpec{Addresses: []string{"ftp://es:9200"}}, errFields: []string{"spec.addresses[0]"}},
+		{name: "no host", spec: ElasticConnectionSpec{Addresses: []string{"http://es:9200", "http://:9200"}}, errFields: []string{"spec.addresses[1]"}},
+		{name: "credentials in address", spec: ElasticConnectionSpec{Addresses: []string{"http://elastic:pass@es:9200"}}, errFields: []string{"spec.addresses[0]"}},
+		{name: "missing auth secret", spec: ElasticConnectionSpec{Addresses: []string{"http://es:9200"}, AuthSecretRef: auth("missing")}, errFields: []string{"spec.authSecretRef.name"}},
+		{name: "auth secret without credentials", spec: ElasticConnectionSpec{Addresses: []string{"http://es:9200"}, AuthSecretRef: auth("empty")}, errFields: []string{"spec.authSecretRef.name"}},
+		{name: "tls with https address", spec: ElasticConnectionSpec{Addresses: []string{"https://es:9200"}, TLS: &ElasticTLS{InsecureSkipVerify: true}}},
+		{name: "tls without https address", spec: ElasticConnectionSpec{Addresses: []string{"http://es:9200"}, TLS: &ElasticTLS{InsecureSkipVerify: true}}, errFields: []string{"spec.tls"}},
+		{name: "missing ca secret", spec: ElasticConnectionSpec{Addresses: []string{"https://es:9200"}, TLS: &ElasticTLS{CASecretName: "ca"}}, errFields: []string{"spec.tls"}},
+		{name: "ca secret without certificate", spec: ElasticConnectionSpec{Addresses: []string{"https://es:9200"}, TLS: &ElasticTLS{CASecretName: "credentials"}}, errFields: []string{"spec.tls"}},
+	}
+
+	for _, s := range scenarios {
+		errs := validateConnectionSpec(&s.spec, "elastic", field.NewPath("spec"), k8sClient)
+		var errFields []string
+		for _, err := range errs {
+			errFields = append(errFields, err.Field)
+		}
+		assert.Equal(s.errFields, errFields, s.name)
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ElasticDataStreamSpec defines the desired state of ElasticDataStream
+type ElasticDataStreamSpec struct {
+	// Data stream name in elasticsearch server, matched by the index_patterns of a composable index template with
+	// data_stream
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9-_\.]+$`
+	DataStreamName *string `json:"dataStreamName"`
+
+	// Elasticsearch URI with this format <scheme>://<user>:<password>@<hostname>:<port> from a key of a secret in the local namespace.
+	// Legacy alternative to connectionRef
+	// +optional
+	ElasticURI *ElasticURISource `json:"elasticURI,omitempty"`
+
+	// Connection to the elasticsearch cluster: an ElasticConnection of the local namespace, or a ClusterElasticConnection
+	// +optional
+	ConnectionRef *ElasticConnectionReference `json:"connectionRef,omitempty"`
+
+	// Data stream lifecycle, elasticsearch 8.11 or later. The lifecycle set by elasticsearch, from the template or the
+	// _data_stream/<name>/_lifecycle API, is left as is when it is not set
+	// +optional
+	Lifecycle *ElasticDataStreamLifecycle `json:"lifecycle,omitempty"`
+
+	// Resync defines how the object is periodically compared with elasticsearch, overriding the operator flags
+	// +optional
+	Resync *ElasticResync `json:"resync,omitempty"`
+}
+
+// ElasticDataStreamLifecycle is the lifecycle of a data stream
+type ElasticDataStreamLifecycle struct {
+	// Time the documents are kept, like 30d. They are kept forever when it is not set
+	// +optional
+	DataRetention string `json:"dataRetention,omitempty"`
+}
+
+// ElasticDataStreamRollover is a rollover of a data stream requested with the carrefour.com/rollover annotation
+type ElasticDataStreamRollover struct {
+	// Value of the annotation that requested the rollover
+	Request string `json:"request"`
+
+	// Time of the rollover
+	Time metav1.Time `json:"time"`
+
+	// Result of the rollover, like rolled over from .ds-logs-2024.10.01-000001 to .ds-logs-2024.10.17-000002, or
+	// the reason of its failure
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ElasticDataStreamStatus defines the observed state of ElasticDataStream
+type ElasticDataStreamStatus struct {
+	ElasticObjectStatus `json:",inline"`
+
+	// Backing indices of the data stream, the write index being the last one
+	// +optional
+	BackingIndices []string `json:"backingIndices,omitempty"`
+
+	// Generation of the data stream, incremented by each rollover
+	// +optional
+	Generation int64 `json:"generation,omitempty"`
+
+	// Composable index template the data stream was created from
+	// +optional
+	Template string `json:"template,omitempty"`
+
+	// Last rollover requested with the carrefour.com/rollover annotation
+	// +optional
+	LastRollover *ElasticDataStreamRollover `json:"lastRollover,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=eds
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="DATA_STREAM_NAME",type="string",JSONPath=".spec.dataStreamName"
+// +kubebuilder:printcolumn:name="GENERATION",type="integer",JSONPath=".status.generation"
+// +kubebuilder:printcolumn:name="TEMPLATE",type="string",JSONPath=".status.template"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.status"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ElasticDataStream is the Schema for the elasticdatastreams API, a data stream of the _data_stream API
+type ElasticDataStream struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ElasticDataStreamSpec   `json:"spec,omitempty"`
+	Status ElasticDataStreamStatus `json:"status,omitempty"`
+}
+
+func (r *ElasticDataStream) GetEsObjectName() string {
+	return *r.Spec.DataStreamName
+}
+
+func (r *ElasticDataStream) GetConnection() (*ElasticURISource, *ElasticConnectionReference) {
+	return r.Spec.ElasticURI, r.Spec.ConnectionRef
+}
+
+// GetModel returns the lifecycle of the spec, like {"lifecycle":{"data_retention":"30d"}}, or {} when it is not set
+func (r *ElasticDataStream) GetModel() (string, error) {
+	if r.Spec.Lifecycle == nil {
+		return utils.BuildDataStreamModel(nil), nil
+	}
+	return utils.BuildDataStreamModel(&utils.EsDataStreamLifecycle{DataRetention: r.Spec.Lifecycle.DataRetention}), nil
+}
+
+func (r *ElasticDataStream) GetResync() *ElasticResync {
+	return r.Spec.Resync
+}
+
+func (r *ElasticDataStream) GetObjectStatus() *ElasticObjectStatus {
+	return &r.Status.ElasticObjectStatus
+}
+
+// +kubebuilder:object:root=true
+
+// ElasticDataStreamList contains a list of ElasticDataStream
+type ElasticDataStreamList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ElasticDataStream `json:"items"`
+}
+
+func (l *ElasticDataStreamList) GetObjects() []ElasticObject {
+	var objects []ElasticObject
+	for i := range l.Items {
+		objects = append(objects, &l.Items[i])
+	}
+	return objects
+}
+
+func init() {
+	SchemeBuilder.Register(&ElasticDataStream{}, &ElasticDataStreamList{})
+}
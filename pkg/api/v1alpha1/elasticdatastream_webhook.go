@@ -0,0 +1,239 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"reflect"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+var (
+	// log is for logging in this package.
+	elasticdatastreamlog        = logf.Log.WithName("elasticdatastream-resource")
+	elasticdatastreamK8sClient  client.Client
+	elasticdatastreamNamespaces []string
+)
+
+func (r *ElasticDataStream) SetupWebhookWithManager(mgr ctrl.Manager, namespaces []string) error {
+	elasticdatastreamK8sClient = mgr.GetClient()
+	elasticdatastreamNamespaces = namespaces
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-elastic-carrefour-com-v1alpha1-elasticdatastream,mutating=true,failurePolicy=fail,groups=elastic.carrefour.com,resources=elasticdatastreams,verbs=create;update,versions=v1alpha1,name=melasticdatastream.kb.io,sideEffects=none,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Defaulter = &ElasticDataStream{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *ElasticDataStream) Default() {
+	elasticdatastreamlog.Info("[Webhook] default", "namespace", r.Namespace, "name", r.Name)
+}
+
+// +kubebuilder:webhook:verbs=create;update;delete,path=/validate-elastic-carrefour-com-v1alpha1-elasticdatastream,mutating=false,failurePolicy=fail,groups=elastic.carrefour.com,resources=elasticdatastreams,versions=v1alpha1,name=velasticdatastream.kb.io,sideEffects=none,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Validator = &ElasticDataStream{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticDataStream) ValidateCreate() error {
+	if len(elasticdatastreamNamespaces) == 0 || utils.ContainsString(elasticdatastreamNamespaces, r.ObjectMeta.Namespace) {
+		elasticdatastreamlog.Info("[Webhook] validate create", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		allErrs = append(allErrs, validateDataStreamLifecycle(r.Spec.Lifecycle)...)
+
+		if esConfig, errs := validateEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticdatastreamK8sClient); len(errs) > 0 {
+			allErrs = append(allErrs, errs...)
+		} else {
+			if info, err := checkEsObjectExists(*r.Spec.DataStreamName, &ElasticDataStreamList{}, esConfig, elasticdatastreamK8sClient); err != nil {
+				errMsg := fmt.Sprintf(`error while checking data stream "%v" existence from all kubernetes elasticdatastream objects. %v`, *r.Spec.DataStreamName, err.Error())
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("dataStreamName"), r.Spec.DataStreamName, errMsg))
+			} else if info != nil {
+				errMsg := fmt.Sprintf(`data stream "%v" for elasticsearch cluster "%v" was created by kubernetes elasticdatastream "%v" in namespace "%v"`, info.esObjectName, info.Cluster, info.Name, info.Namespace)
+				allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("dataStreamName"), errMsg))
+			}
+			allErrs = append(allErrs, validateDataStreamLifecycleVersion(r.Spec.Lifecycle, esConfig)...)
+			allErrs = append(allErrs, validateDataStreamTemplate(*r.Spec.DataStreamName, esConfig, elasticdatastreamK8sClient)...)
+		}
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticDataStream"},
+			r.Name, allErrs)
+	}
+
+	elasticdatastreamlog.Info("[Webhook] ignore validate create", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticDataStream) ValidateUpdate(old runtime.Object) error {
+	if len(elasticdatastreamNamespaces) == 0 || utils.ContainsString(elasticdatastreamNamespaces, r.ObjectMeta.Namespace) {
+		elasticdatastreamlog.Info("[Webhook] validate update", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		oldR := old.(*ElasticDataStream)
+
+		if *r.Spec.DataStreamName != *oldR.Spec.DataStreamName {
+			errMsg := fmt.Sprintf(`Cannot update dataStreamName from "%v" to "%v"`, *oldR.Spec.DataStreamName, *r.Spec.DataStreamName)
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("dataStreamName"), r.Spec.DataStreamName, errMsg))
+		}
+
+		lifecycleUpdated := !reflect.DeepEqual(r.Spec.Lifecycle, oldR.Spec.Lifecycle)
+		if lifecycleUpdated {
+			allErrs = append(allErrs, validateDataStreamLifecycle(r.Spec.Lifecycle)...)
+		}
+
+		allErrs = ValidateUpdateConnection(allErrs, r.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, oldR.Spec.ElasticURI, oldR.Spec.ConnectionRef, oldR.Status.Cluster, elasticdatastreamK8sClient)
+
+		// the version of the cluster is only checked when a lifecycle is added, it was checked before otherwise
+		if len(allErrs) == 0 && lifecycleUpdated && oldR.Spec.Lifecycle == nil {
+			if esConfig, errs := validateEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticdatastreamK8sClient); len(errs) > 0 {
+				allErrs = append(allErrs, errs...)
+			} else {
+				allErrs = append(allErrs, validateDataStreamLifecycleVersion(r.Spec.Lifecycle, esConfig)...)
+			}
+		}
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticDataStream"},
+			r.Name, allErrs)
+	}
+
+	elasticdatastreamlog.Info("[Webhook] ignore validate update", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticDataStream) ValidateDelete() error {
+	if len(elasticdatastreamNamespaces) == 0 || utils.ContainsString(elasticdatastreamNamespaces, r.ObjectMeta.Namespace) {
+		elasticdatastreamlog.Info("[Webhook] validate delete", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		allErrs = append(allErrs, validateDeleteEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticdatastreamK8sClient)...)
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticDataStream"},
+			r.Name, allErrs)
+	}
+
+	elasticdatastreamlog.Info("[Webhook] ignore validate delete", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// validateDataStreamLifecycle checks the data retention of a data stream lifecycle
+func validateDataStreamLifecycle(lifecycle *ElasticDataStreamLifecycle) field.ErrorList {
+	if lifecycle == nil || lifecycle.DataRetention == "" {
+		return nil
+	}
+	if err := utils.ValidateDataRetention(lifecycle.DataRetention); err != nil {
+		return field.ErrorList{field.Invalid(field.NewPath("spec").Child("lifecycle").Child("dataRetention"), lifecycle.DataRetention, err.Error())}
+	}
+	return nil
+}
+
+// validateDataStreamLifecycleVersion checks that the cluster of esConfig supports data stream lifecycles, when the
+// lifecycle is set
+func validateDataStreamLifecycleVersion(lifecycle *ElasticDataStreamLifecycle, esConfig *utils.EsConfig) field.ErrorList {
+	if lifecycle == nil {
+		return nil
+	}
+	if esConfig.Distribution == utils.DistributionOpenSearch {
+		errMsg := fmt.Sprintf("data stream lifecycles are not supported by %v %v", esConfig.Distribution, esConfig.VersionNumber)
+		return field.ErrorList{field.Forbidden(field.NewPath("spec").Child("lifecycle"), errMsg)}
+	}
+	if err := esConfig.RequireVersion(utils.DataStreamLifecycleMinVersion, "data stream lifecycles"); err != nil {
+		return field.ErrorList{field.Forbidden(field.NewPath("spec").Child("lifecycle"), err.Error())}
+	}
+	return nil
+}
+
+// validateDataStreamTemplate checks that the composable index template applied to the data stream has data_stream: the
+// matching ElasticComposableTemplate of the cluster of esConfig with the highest priority, or the index template of
+// elasticsearch when none matches
+func validateDataStreamTemplate(dataStreamName string, esConfig *utils.EsConfig, k8sClient client.Client) field.ErrorList {
+	path := field.NewPath("spec").Child("dataStreamName")
+
+	var templates ElasticComposableTemplateList
+	if err := k8sClient.List(context.Background(), &templates); err != nil {
+		errMsg := fmt.Sprintf(`error while checking the index template of data stream "%v" from all kubernetes elasticcomposabletemplate objects. %v`, dataStreamName, err.Error())
+		return field.ErrorList{field.Invalid(path, dataStreamName, errMsg)}
+	}
+	models := map[string]string{}
+	for _, template := range templates.Items {
+		if template.Spec.TemplateName == nil || template.Spec.Model == nil || !utils.TemplateModelMatches(*template.Spec.Model, dataStreamName) ||
+			!isSameEsCluster(esConfig, template.Status.Cluster) {
+			continue
+		}
+		if model, err := template.GetModel(); err == nil {
+			models[*template.Spec.TemplateName] = model
+		}
+	}
+	if templateName, dataStream := utils.GetDataStreamTemplateModel(models, dataStreamName); dataStream {
+		return nil
+	} else if templateName != "" {
+		errMsg := fmt.Sprintf(`ElasticComposableTemplate "%v" applied to the data stream, with the highest priority, has no dataStream`, templateName)
+		return field.ErrorList{field.Invalid(path, dataStreamName, errMsg)}
+	}
+
+	elasticsearch, err := utils.NewElasticsearch(esConfig, elasticdatastreamlog)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, dataStreamName, err.Error())}
+	}
+	dataStreams, ok := elasticsearch.(utils.EsDataStreams)
+	if !ok {
+		errMsg := fmt.Sprintf("data streams are not supported by %v %v", esConfig.Distribution, esConfig.VersionNumber)
+		return field.ErrorList{field.Forbidden(path, errMsg)}
+	}
+	templateName, dataStream, err := dataStreams.GetDataStreamTemplate(context.Background(), dataStreamName)
+	switch {
+	case err != nil:
+		errMsg := fmt.Sprintf(`error while checking the index template of data stream "%v". %v`, dataStreamName, err.Error())
+		return field.ErrorList{field.Invalid(path, dataStreamName, errMsg)}
+	case templateName == "":
+		errMsg := "no composable index template matches the data stream, an ElasticComposableTemplate or an index template with data_stream is required"
+		return field.ErrorList{field.Invalid(path, dataStreamName, errMsg)}
+	case !dataStream:
+		errMsg := fmt.Sprintf(`composable index template "%v" matching the data stream has no data_stream`, templateName)
+		return field.ErrorList{field.Invalid(path, dataStreamName, errMsg)}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ElasticILMPolicySpec defines the desired state of ElasticILMPolicy
+type ElasticILMPolicySpec struct {
+	// ILM policy name in elasticsearch server, referenced by the index.lifecycle.name setting of indices and templates
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9-_\.]+$`
+	PolicyName *string `json:"policyName"`
+
+	// Elasticsearch URI with this format <scheme>://<user>:<password>@<hostname>:<port> from a key of a secret in the local namespace.
+	// Legacy alternative to connectionRef
+	// +optional
+	ElasticURI *ElasticURISource `json:"elasticURI,omitempty"`
+
+	// Connection to the elasticsearch cluster: an ElasticConnection of the local namespace, or a ClusterElasticConnection
+	// +optional
+	ConnectionRef *ElasticConnectionReference `json:"connectionRef,omitempty"`
+
+	// ILM policy body, like {"policy":{"phases":{"delete":{"min_age":"30d","actions":{"delete":{}}}}}}
+	// +kubebuilder:validation:Required
+	Model *string `json:"model"`
+
+	// Resync defines how the object is periodically compared with elasticsearch, overriding the operator flags
+	// +optional
+	Resync *ElasticResync `json:"resync,omitempty"`
+}
+
+// ElasticILMPolicyStatus defines the observed state of ElasticILMPolicy
+type ElasticILMPolicyStatus struct {
+	ElasticObjectStatus `json:",inline"`
+
+	// Number of indices managed by the policy, from _ilm/explain
+	// +optional
+	ManagedIndicesCount int32 `json:"managedIndicesCount,omitempty"`
+
+	// Names of the indices managed by the policy, the first 100 in alphabetical order
+	// +optional
+	ManagedIndices []string `json:"managedIndices,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=eilm
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="POLICY_NAME",type="string",JSONPath=".spec.policyName"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.status"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="INDICES",type="integer",JSONPath=".status.managedIndicesCount"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ElasticILMPolicy is the Schema for the elasticilmpolicies API, an index lifecycle management policy of the
+// _ilm/policy API
+type ElasticILMPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ElasticILMPolicySpec   `json:"spec,omitempty"`
+	Status ElasticILMPolicyStatus `json:"status,omitempty"`
+}
+
+func (r *ElasticILMPolicy) GetEsObjectName() string {
+	return *r.Spec.PolicyName
+}
+
+func (r *ElasticILMPolicy) GetConnection() (*ElasticURISource, *ElasticConnectionReference) {
+	return r.Spec.ElasticURI, r.Spec.ConnectionRef
+}
+
+func (r *ElasticILMPolicy) GetModel() (string, error) {
+	return *r.Spec.Model, nil
+}
+
+func (r *ElasticILMPolicy) GetResync() *ElasticResync {
+	return r.Spec.Resync
+}
+
+func (r *ElasticILMPolicy) GetObjectStatus() *ElasticObjectStatus {
+	return &r.Status.ElasticObjectStatus
+}
+
+// +kubebuilder:object:root=true
+
+// ElasticILMPolicyList contains a list of ElasticILMPolicy
+type ElasticILMPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ElasticILMPolicy `json:"items"`
+}
+
+func (l *ElasticILMPolicyList) GetObjects() []ElasticObject {
+	var objects []ElasticObject
+	for i := range l.Items {
+		objects = append(objects, &l.Items[i])
+	}
+	return objects
+}
+
+func init() {
+	SchemeBuilder.Register(&ElasticILMPolicy{}, &ElasticILMPolicyList{})
+}
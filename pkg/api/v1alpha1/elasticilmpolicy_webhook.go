@@ -0,0 +1,164 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+var (
+	// log is for logging in this package.
+	elasticilmpolicylog        = logf.Log.WithName("elasticilmpolicy-resource")
+	elasticilmpolicyK8sClient  client.Client
+	elasticilmpolicyNamespaces []string
+)
+
+func (r *ElasticILMPolicy) SetupWebhookWithManager(mgr ctrl.Manager, namespaces []string) error {
+	elasticilmpolicyK8sClient = mgr.GetClient()
+	elasticilmpolicyNamespaces = namespaces
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-elastic-carrefour-com-v1alpha1-elasticilmpolicy,mutating=true,failurePolicy=fail,groups=elastic.carrefour.com,resources=elasticilmpolicies,verbs=create;update,versions=v1alpha1,name=melasticilmpolicy.kb.io,sideEffects=none,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Defaulter = &ElasticILMPolicy{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *ElasticILMPolicy) Default() {
+	elasticilmpolicylog.Info("[Webhook] default", "namespace", r.Namespace, "name", r.Name)
+
+	if r.Spec.Model != nil {
+		if compactedModel, err := utils.CompactJson(*r.Spec.Model); err == nil {
+			*r.Spec.Model = compactedModel
+		}
+	}
+}
+
+// +kubebuilder:webhook:verbs=create;update;delete,path=/validate-elastic-carrefour-com-v1alpha1-elasticilmpolicy,mutating=false,failurePolicy=fail,groups=elastic.carrefour.com,resources=elasticilmpolicies,versions=v1alpha1,name=velasticilmpolicy.kb.io,sideEffects=none,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Validator = &ElasticILMPolicy{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticILMPolicy) ValidateCreate() error {
+	if len(elasticilmpolicyNamespaces) == 0 || utils.ContainsString(elasticilmpolicyNamespaces, r.ObjectMeta.Namespace) {
+		elasticilmpolicylog.Info("[Webhook] validate create", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		if err := validateILMPolicyModel(*r.Spec.Model); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("model"), r.Spec.Model, err.Error()))
+		}
+
+		if esConfig, errs := validateEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticilmpolicyK8sClient); len(errs) > 0 {
+			allErrs = append(allErrs, errs...)
+		} else {
+			if info, err := checkEsObjectExists(*r.Spec.PolicyName, &ElasticILMPolicyList{}, esConfig, elasticilmpolicyK8sClient); err != nil {
+				errMsg := fmt.Sprintf(`error while checking ILM policy "%v" existence from all kubernetes elasticilmpolicy objects. %v`, *r.Spec.PolicyName, err.Error())
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("policyName"), r.Spec.PolicyName, errMsg))
+			} else if info != nil {
+				errMsg := fmt.Sprintf(`ILM policy "%v" for elasticsearch cluster "%v" was created by kubernetes elasticilmpolicy "%v" in namespace "%v"`, info.esObjectName, info.Cluster, info.Name, info.Namespace)
+				allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("policyName"), errMsg))
+			}
+		}
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticILMPolicy"},
+			r.Name, allErrs)
+	}
+
+	elasticilmpolicylog.Info("[Webhook] ignore validate create", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticILMPolicy) ValidateUpdate(old runtime.Object) error {
+	if len(elasticilmpolicyNamespaces) == 0 || utils.ContainsString(elasticilmpolicyNamespaces, r.ObjectMeta.Namespace) {
+		elasticilmpolicylog.Info("[Webhook] validate update", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		oldR := old.(*ElasticILMPolicy)
+
+		if *r.Spec.PolicyName != *oldR.Spec.PolicyName {
+			errMsg := fmt.Sprintf(`Cannot update policyName from "%v" to "%v"`, *oldR.Spec.PolicyName, *r.Spec.PolicyName)
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("policyName"), r.Spec.PolicyName, errMsg))
+		}
+
+		if !utils.CompareJson(*r.Spec.Model, *oldR.Spec.Model) {
+			if err := validateILMPolicyModel(*r.Spec.Model); err != nil {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("model"), r.Spec.Model, err.Error()))
+			}
+		}
+
+		allErrs = ValidateUpdateConnection(allErrs, r.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, oldR.Spec.ElasticURI, oldR.Spec.ConnectionRef, oldR.Status.Cluster, elasticilmpolicyK8sClient)
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticILMPolicy"},
+			r.Name, allErrs)
+	}
+
+	elasticilmpolicylog.Info("[Webhook] ignore validate update", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticILMPolicy) ValidateDelete() error {
+	if len(elasticilmpolicyNamespaces) == 0 || utils.ContainsString(elasticilmpolicyNamespaces, r.ObjectMeta.Namespace) {
+		elasticilmpolicylog.Info("[Webhook] validate delete", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		allErrs = append(allErrs, validateDeleteEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticilmpolicyK8sClient)...)
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticILMPolicy"},
+			r.Name, allErrs)
+	}
+
+	elasticilmpolicylog.Info("[Webhook] ignore validate delete", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// validateILMPolicyModel checks the root fields of the model, then its phases and actions
+func validateILMPolicyModel(model string) error {
+	if _, err := (&utils.EsModel{Model: model}).IsValid(utils.ILMPolicy); err != nil {
+		return err
+	}
+	return utils.ValidateILMPolicy(model)
+}
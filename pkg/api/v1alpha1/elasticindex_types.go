@@ -31,11 +31,16 @@ type ElasticIndexSpec struct {
 	// +kubebuilder:validation:Pattern=`^[a-z0-9-_\.]+$`
 	IndexName *string `json:"indexName"`
 
-	// Elasticsearch URI with this format <scheme>://<user>:<password>@<hostname>:<port> from a key of a secret in the local namespace
-	// +kubebuilder:validation:Required
-	ElasticURI ElasticURISource `json:"elasticURI"`
+	// Elasticsearch URI with this format <scheme>://<user>:<password>@<hostname>:<port> from a key of a secret in the local namespace.
+	// Legacy alternative to connectionRef
+	// +optional
+	ElasticURI *ElasticURISource `json:"elasticURI,omitempty"`
+
+	// Connection to the elasticsearch cluster: an ElasticConnection of the local namespace, or a ClusterElasticConnection
+	// +optional
+	ConnectionRef *ElasticConnectionReference `json:"connectionRef,omitempty"`
 
-	// Number of elasticsearch shards
+	// Number of elasticsearch shards. On an existing index, it can only be updated to a multiple (split) or a factor (shrink) of the current value
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:validation:Maximum=500
 	// +kubebuilder:validation:Required
@@ -50,6 +55,67 @@ type ElasticIndexSpec struct {
 	// Index mappings, settings and aliases
 	// +kubebuilder:validation:Required
 	Model *string `json:"model"`
+
+	// Migration defines how model updates that cannot be applied on the existing index (removed fields, type changes, static settings) are handled
+	// +optional
+	Migration *ElasticIndexMigration `json:"migration,omitempty"`
+
+	// Resync defines how the object is periodically compared with elasticsearch, overriding the operator flags
+	// +optional
+	Resync *ElasticResync `json:"resync,omitempty"`
+}
+
+// ElasticIndexMigration defines how an index is migrated to a new versioned index when its model cannot be updated in place
+type ElasticIndexMigration struct {
+	// Strategy applied when the model cannot be updated in place. Possible values: Reject, Reindex.
+	// Reindex creates a new versioned index <indexName>-vN with the new model, reindexes documents from the old index
+	// and atomically moves the indexName alias to the new index. Writes are blocked on the old index during the migration
+	// +kubebuilder:validation:Enum=Reject;Reindex
+	// +optional
+	Strategy string `json:"strategy,omitempty"`
+
+	// What to do with the old index once the alias is moved, after a reindex or a numberOfShards split/shrink. Possible values: Delete, Retain.
+	// A retained index keeps its write block and loses its aliases
+	// +kubebuilder:validation:Enum=Delete;Retain
+	// +optional
+	OldIndexPolicy string `json:"oldIndexPolicy,omitempty"`
+
+	// How static settings updates (analysis, codec...) are applied on the existing index. Possible values: Reject, CloseAndReopen.
+	// CloseAndReopen closes the index, updates its settings and reopens it: the index is unavailable meanwhile
+	// +kubebuilder:validation:Enum=Reject;CloseAndReopen
+	// +optional
+	StaticSettingsUpdate string `json:"staticSettingsUpdate,omitempty"`
+}
+
+// ElasticIndexMigrationStatus defines the observed state of the last index migration
+type ElasticIndexMigrationStatus struct {
+	// Operation used to fill the new index. Possible values: Reindex, Split, Shrink
+	// +optional
+	Operation string `json:"operation,omitempty"`
+
+	// Phase of the migration. Possible values: Reindexing, Relocating, Resizing, Completed, Failed
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// Index documents are reindexed from
+	// +optional
+	SourceIndex string `json:"sourceIndex,omitempty"`
+
+	// Versioned index documents are reindexed to, and that is behind the indexName alias once the migration is completed
+	// +optional
+	TargetIndex string `json:"targetIndex,omitempty"`
+
+	// Elasticsearch reindex task id
+	// +optional
+	TaskID string `json:"taskId,omitempty"`
+
+	// Number of documents created in the target index
+	// +optional
+	Created int64 `json:"created,omitempty"`
+
+	// Number of documents to reindex
+	// +optional
+	Total int64 `json:"total,omitempty"`
 }
 
 // ElasticIndexStatus defines the observed state of ElasticIndex
@@ -57,7 +123,7 @@ type ElasticIndexStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 
-	// Status indicates whether index was created successfully in elasticsearch server. Possible values: Created, Error, Retry
+	// Status indicates whether index was created successfully in elasticsearch server. Possible values: Created, Error, Retry, Migrating
 	// +optional
 	Status string `json:"status,omitempty"`
 
@@ -68,6 +134,57 @@ type ElasticIndexStatus struct {
 	// The message returned by elasticsearch. Useful when Status is Error or Retry
 	// +optional
 	Message string `json:"message,omitempty"`
+
+	// The metadata.generation the status reflects
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions of the object: Ready, Reconciling, Degraded, and Drifted that is set on each resync
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// Sha256 of the last model applied successfully
+	// +optional
+	LastAppliedModelHash string `json:"lastAppliedModelHash,omitempty"`
+
+	// Last time the model was applied successfully, or compared with elasticsearch by a resync
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// Elasticsearch cluster the model was applied to
+	// +optional
+	Cluster *ElasticClusterReference `json:"cluster,omitempty"`
+
+	// The last index migration: a reindex when spec.migration.strategy is Reindex, or a numberOfShards split/shrink
+	// +optional
+	Migration *ElasticIndexMigrationStatus `json:"migration,omitempty"`
+
+	// Result of the reconciliation of each alias of the model
+	// +optional
+	Aliases []ElasticIndexAliasStatus `json:"aliases,omitempty"`
+
+	// Number of consecutive failed attempts to apply the model, the delay before the next attempt being doubled on
+	// each of them
+	// +optional
+	Retries int32 `json:"retries,omitempty"`
+
+	// Time of the next attempt to apply the model, after a Retry or Error status
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+}
+
+// ElasticIndexAliasStatus defines the observed state of an alias of the model
+type ElasticIndexAliasStatus struct {
+	// Alias name
+	Name string `json:"name"`
+
+	// Status indicates whether alias was created or updated successfully on the index. Possible values: Created, Error, Retry
+	// +optional
+	Status string `json:"status,omitempty"`
+
+	// The message returned by elasticsearch when the alias cannot be created or updated
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -77,6 +194,7 @@ type ElasticIndexStatus struct {
 // +kubebuilder:printcolumn:name="SHARDS",type="integer",JSONPath=".spec.numberOfShards"
 // +kubebuilder:printcolumn:name="REPLICAS",type="integer",JSONPath=".spec.numberOfReplicas"
 // +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.status"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 
 // ElasticIndex is the Schema for the elasticindices API
@@ -80,22 +80,15 @@ func (r *ElasticIndex) ValidateCreate() error {
 			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("model"), r.Spec.Model, err.Error()))
 		}
 
-		if secret, err := utils.GetSecret(r.ObjectMeta.Namespace, r.Spec.ElasticURI.SecretKeyRef, elasticindexK8sClient); err != nil {
-			errMsg := fmt.Sprintf(`secret "%v" is required. %v`, r.Spec.ElasticURI.SecretKeyRef.Name, err.Error())
-			allErrs = append(allErrs, field.Required(field.NewPath("spec").Child("elasticUri").Child("secretKeyRef"), errMsg))
+		if esConfig, errs := validateEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticindexK8sClient); len(errs) > 0 {
+			allErrs = append(allErrs, errs...)
 		} else {
-			secretKey := r.Spec.ElasticURI.SecretKeyRef.Key
-			if esConfig, err := utils.BuildEsConfigFromExistingSecret(secret, secretKey); err != nil {
-				errMsg := fmt.Sprintf(`error while parsing elasticsearch URI "%v" from secret. %v`, r.Spec.ElasticURI.SecretKeyRef.Name, err.Error())
-				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("elasticUri").Child("secretKeyRef"), string(secret.Data[secretKey]), errMsg))
-			} else {
-				if info, err := checkEsIndexExists(*r.Spec.IndexName, esConfig, elasticindexK8sClient); err != nil {
-					errMsg := fmt.Sprintf(`error while checking index "%v" existence from all kubernetes elasticindex objects. %v`, r.Spec.IndexName, err.Error())
-					allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("indexName"), r.Spec.IndexName, errMsg))
-				} else if info != nil {
-					errMsg := fmt.Sprintf(`index "%v" for elasticsearch URI "%v:%v" was created by kubernetes elasticindex "%v" in namespace "%v"`, info.esObjectName, info.Host, info.Port, info.Name, info.Namespace)
-					allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("indexName"), errMsg))
-				}
+			if info, err := checkEsIndexExists(*r.Spec.IndexName, esConfig, elasticindexK8sClient); err != nil {
+				errMsg := fmt.Sprintf(`error while checking index "%v" existence from all kubernetes elasticindex objects. %v`, r.Spec.IndexName, err.Error())
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("indexName"), r.Spec.IndexName, errMsg))
+			} else if info != nil {
+				errMsg := fmt.Sprintf(`index "%v" for elasticsearch cluster "%v" was created by kubernetes elasticindex "%v" in namespace "%v"`, info.esObjectName, info.Cluster, info.Name, info.Namespace)
+				allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("indexName"), errMsg))
 			}
 		}
 
@@ -131,11 +124,30 @@ func (r *ElasticIndex) ValidateUpdate(old runtime.Object) error {
 			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("indexName"), r.Spec.IndexName, errMsg))
 		}
 
+		var resizeOperation string
 		if *r.Spec.NumberOfShards != *oldR.Spec.NumberOfShards {
-			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("numberOfShards"), "cannot update numberOfShards setting for an Index"))
+			if resizeOperation = utils.GetResizeOperation(oldR.Spec.NumberOfShards, r.Spec.NumberOfShards); resizeOperation == "" {
+				errMsg := fmt.Sprintf("cannot update numberOfShards from %v to %v: new value should be a multiple (split) or a factor (shrink) of the old value", *oldR.Spec.NumberOfShards, *r.Spec.NumberOfShards)
+				allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("numberOfShards"), errMsg))
+			}
 		}
 
-		allErrs = ValidateUpdateSecret(allErrs, r.Namespace, r.Spec.ElasticURI.SecretKeyRef, oldR.Spec.ElasticURI.SecretKeyRef, elasticindexK8sClient)
+		if staticSettings := getUpdatedStaticSettings(*oldR.Spec.Model, *r.Spec.Model); len(staticSettings) > 0 &&
+			(r.Spec.Migration == nil || r.Spec.Migration.StaticSettingsUpdate != utils.StaticSettingsUpdateCloseAndReopen) {
+			errMsg := fmt.Sprintf("cannot update static settings %v on an open index: set migration.staticSettingsUpdate to %v to close and reopen the index", staticSettings, utils.StaticSettingsUpdateCloseAndReopen)
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("model"), errMsg))
+		}
+
+		allErrs = ValidateUpdateConnection(allErrs, r.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, oldR.Spec.ElasticURI, oldR.Spec.ConnectionRef, oldR.Status.Cluster, elasticindexK8sClient)
+
+		// a split is checked against the number_of_routing_shards of the index in the cluster
+		if len(allErrs) == 0 && resizeOperation == utils.MigrationOperationSplit {
+			if esConfig, errs := validateEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticindexK8sClient); len(errs) > 0 {
+				allErrs = append(allErrs, errs...)
+			} else {
+				allErrs = append(allErrs, validateIndexSplit(*r.Spec.IndexName, *r.Spec.NumberOfShards, esConfig)...)
+			}
+		}
 
 		if len(allErrs) == 0 {
 			return nil
@@ -157,10 +169,7 @@ func (r *ElasticIndex) ValidateDelete() error {
 
 		var allErrs field.ErrorList
 
-		if _, err := utils.GetSecret(r.ObjectMeta.Namespace, r.Spec.ElasticURI.SecretKeyRef, elasticindexK8sClient); err != nil {
-			errMsg := fmt.Sprintf(`secret "%v" is required. %v`, r.Spec.ElasticURI.SecretKeyRef.Name, err.Error())
-			allErrs = append(allErrs, field.Required(field.NewPath("spec").Child("elasticUri").Child("secretKeyRef"), errMsg))
-		}
+		allErrs = append(allErrs, validateDeleteEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticindexK8sClient)...)
 
 		if len(allErrs) == 0 {
 			return nil
@@ -181,15 +190,47 @@ func checkEsIndexExists(indexName string, esConfig *utils.EsConfig, k8sClient cl
 		return nil, err
 	}
 	for _, es := range allElasticIndex.Items {
-		esConfigToCheck, _ := utils.BuildEsConfigFromSecretSelector(es.Namespace, es.Spec.ElasticURI.SecretKeyRef, k8sClient)
-		if indexName == *es.Spec.IndexName && esConfig.Host == esConfigToCheck.Host && esConfig.Port == esConfigToCheck.Port {
+		if indexName == *es.Spec.IndexName && isSameEsCluster(esConfig, es.Status.Cluster) {
 			return &EsObjectInfo{
 				Namespace:    es.Namespace,
 				Name:         es.Name,
 				esObjectName: *es.Spec.IndexName,
-				Host:         esConfigToCheck.Host,
-				Port:         esConfigToCheck.Port}, nil
+				Cluster:      esConfig.String()}, nil
 		}
 	}
 	return nil, nil
 }
+
+// getUpdatedStaticSettings returns static settings updated from the old model to the new model
+func getUpdatedStaticSettings(oldModel string, newModel string) []string {
+	oldSettings, err := (&utils.EsModel{Model: oldModel}).GetFlatSettings()
+	if err != nil {
+		return nil
+	}
+	newSettings, err := (&utils.EsModel{Model: newModel}).GetFlatSettings()
+	if err != nil {
+		return nil
+	}
+	return utils.GetStaticSettings(utils.DiffSettings(oldSettings, newSettings, nil))
+}
+
+// validateIndexSplit checks that the index can be split into numShards shards, reading its number_of_routing_shards
+// from the cluster. An index that does not exist yet is created with numShards shards
+func validateIndexSplit(indexName string, numShards int32, esConfig *utils.EsConfig) field.ErrorList {
+	path := field.NewPath("spec").Child("numberOfShards")
+	elasticsearch, err := utils.NewElasticsearch(esConfig, elasticindexlog)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, numShards, err.Error())}
+	}
+	routingShards, err := elasticsearch.GetIndexRoutingShards(context.Background(), indexName)
+	if err != nil {
+		errMsg := fmt.Sprintf("error while getting number_of_routing_shards of index %v. %v", indexName, err.Error())
+		return field.ErrorList{field.Invalid(path, numShards, errMsg)}
+	} else if routingShards == nil {
+		return nil
+	}
+	if err := utils.ValidateSplit(indexName, numShards, *routingShards); err != nil {
+		return field.ErrorList{field.Forbidden(path, err.Error())}
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// SimulationSucceeded and SimulationFailed are the results of the simulation of the sample documents
+	SimulationSucceeded = "Succeeded"
+	SimulationFailed    = "Failed"
+)
+
+// ElasticIngestPipelineSpec defines the desired state of ElasticIngestPipeline
+type ElasticIngestPipelineSpec struct {
+	// Ingest pipeline id in elasticsearch server, referenced by the index.default_pipeline setting of indices and templates
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9-_\.]+$`
+	PipelineName *string `json:"pipelineName"`
+
+	// Elasticsearch URI with this format <scheme>://<user>:<password>@<hostname>:<port> from a key of a secret in the local namespace.
+	// Legacy alternative to connectionRef
+	// +optional
+	ElasticURI *ElasticURISource `json:"elasticURI,omitempty"`
+
+	// Connection to the elasticsearch cluster: an ElasticConnection of the local namespace, or a ClusterElasticConnection
+	// +optional
+	ConnectionRef *ElasticConnectionReference `json:"connectionRef,omitempty"`
+
+	// Ingest pipeline body: description, processors, on_failure, version and _meta
+	// +kubebuilder:validation:Required
+	Model *string `json:"model"`
+
+	// Sample documents, json objects run through the pipeline with the _simulate API. A model failing to process one
+	// of them is rejected
+	// +optional
+	SampleDocuments []string `json:"sampleDocuments,omitempty"`
+
+	// Resync defines how the object is periodically compared with elasticsearch, overriding the operator flags
+	// +optional
+	Resync *ElasticResync `json:"resync,omitempty"`
+}
+
+// ElasticPipelineSimulation is the result of the simulation of the sample documents of an ElasticIngestPipeline
+type ElasticPipelineSimulation struct {
+	// Time of the simulation
+	Time metav1.Time `json:"time"`
+
+	// Result of the simulation. Possible values: Succeeded, Failed
+	Result string `json:"result"`
+
+	// Failures of the documents that the pipeline could not process, or of the simulation
+	// +optional
+	Failures []string `json:"failures,omitempty"`
+
+	// Sha256 of the model and of the sample documents simulated
+	// +optional
+	ModelHash string `json:"modelHash,omitempty"`
+}
+
+// ElasticIngestPipelineStatus defines the observed state of ElasticIngestPipeline
+type ElasticIngestPipelineStatus struct {
+	ElasticObjectStatus `json:",inline"`
+
+	// Version of the pipeline in elasticsearch
+	// +optional
+	Version *int64 `json:"version,omitempty"`
+
+	// Last simulation of the sample documents with the applied model
+	// +optional
+	LastSimulation *ElasticPipelineSimulation `json:"lastSimulation,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=eip
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="PIPELINE_NAME",type="string",JSONPath=".spec.pipelineName"
+// +kubebuilder:printcolumn:name="VERSION",type="integer",JSONPath=".status.version"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.status"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="SIMULATION",type="string",JSONPath=".status.lastSimulation.result"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ElasticIngestPipeline is the Schema for the elasticingestpipelines API, an ingest pipeline of the _ingest/pipeline API
+type ElasticIngestPipeline struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ElasticIngestPipelineSpec   `json:"spec,omitempty"`
+	Status ElasticIngestPipelineStatus `json:"status,omitempty"`
+}
+
+func (r *ElasticIngestPipeline) GetEsObjectName() string {
+	return *r.Spec.PipelineName
+}
+
+func (r *ElasticIngestPipeline) GetConnection() (*ElasticURISource, *ElasticConnectionReference) {
+	return r.Spec.ElasticURI, r.Spec.ConnectionRef
+}
+
+func (r *ElasticIngestPipeline) GetModel() (string, error) {
+	return *r.Spec.Model, nil
+}
+
+func (r *ElasticIngestPipeline) GetResync() *ElasticResync {
+	return r.Spec.Resync
+}
+
+func (r *ElasticIngestPipeline) GetObjectStatus() *ElasticObjectStatus {
+	return &r.Status.ElasticObjectStatus
+}
+
+// +kubebuilder:object:root=true
+
+// ElasticIngestPipelineList contains a list of ElasticIngestPipeline
+type ElasticIngestPipelineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ElasticIngestPipeline `json:"items"`
+}
+
+func (l *ElasticIngestPipelineList) GetObjects() []ElasticObject {
+	var objects []ElasticObject
+	for i := range l.Items {
+		objects = append(objects, &l.Items[i])
+	}
+	return objects
+}
+
+func init() {
+	SchemeBuilder.Register(&ElasticIngestPipeline{}, &ElasticIngestPipelineList{})
+}
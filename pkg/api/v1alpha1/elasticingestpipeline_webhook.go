@@ -0,0 +1,225 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"reflect"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+var (
+	// log is for logging in this package.
+	elasticingestpipelinelog        = logf.Log.WithName("elasticingestpipeline-resource")
+	elasticingestpipelineK8sClient  client.Client
+	elasticingestpipelineNamespaces []string
+)
+
+func (r *ElasticIngestPipeline) SetupWebhookWithManager(mgr ctrl.Manager, namespaces []string) error {
+	elasticingestpipelineK8sClient = mgr.GetClient()
+	elasticingestpipelineNamespaces = namespaces
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-elastic-carrefour-com-v1alpha1-elasticingestpipeline,mutating=true,failurePolicy=fail,groups=elastic.carrefour.com,resources=elasticingestpipelines,verbs=create;update,versions=v1alpha1,name=melasticingestpipeline.kb.io,sideEffects=none,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Defaulter = &ElasticIngestPipeline{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *ElasticIngestPipeline) Default() {
+	elasticingestpipelinelog.Info("[Webhook] default", "namespace", r.Namespace, "name", r.Name)
+
+	if r.Spec.Model != nil {
+		if compactedModel, err := utils.CompactJson(*r.Spec.Model); err == nil {
+			*r.Spec.Model = compactedModel
+		}
+	}
+	for i, document := range r.Spec.SampleDocuments {
+		if compactedDocument, err := utils.CompactJson(document); err == nil {
+			r.Spec.SampleDocuments[i] = compactedDocument
+		}
+	}
+}
+
+// +kubebuilder:webhook:verbs=create;update;delete,path=/validate-elastic-carrefour-com-v1alpha1-elasticingestpipeline,mutating=false,failurePolicy=fail,groups=elastic.carrefour.com,resources=elasticingestpipelines,versions=v1alpha1,name=velasticingestpipeline.kb.io,sideEffects=none,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Validator = &ElasticIngestPipeline{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticIngestPipeline) ValidateCreate() error {
+	if len(elasticingestpipelineNamespaces) == 0 || utils.ContainsString(elasticingestpipelineNamespaces, r.ObjectMeta.Namespace) {
+		elasticingestpipelinelog.Info("[Webhook] validate create", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		_, err := (&utils.EsModel{Model: *r.Spec.Model}).IsValid(utils.IngestPipeline)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("model"), r.Spec.Model, err.Error()))
+		}
+		allErrs = append(allErrs, validateSampleDocuments(r.Spec.SampleDocuments)...)
+
+		if esConfig, errs := validateEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticingestpipelineK8sClient); len(errs) > 0 {
+			allErrs = append(allErrs, errs...)
+		} else {
+			if info, err := checkEsObjectExists(*r.Spec.PipelineName, &ElasticIngestPipelineList{}, esConfig, elasticingestpipelineK8sClient); err != nil {
+				errMsg := fmt.Sprintf(`error while checking ingest pipeline "%v" existence from all kubernetes elasticingestpipeline objects. %v`, *r.Spec.PipelineName, err.Error())
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("pipelineName"), r.Spec.PipelineName, errMsg))
+			} else if info != nil {
+				errMsg := fmt.Sprintf(`ingest pipeline "%v" for elasticsearch cluster "%v" was created by kubernetes elasticingestpipeline "%v" in namespace "%v"`, info.esObjectName, info.Cluster, info.Name, info.Namespace)
+				allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("pipelineName"), errMsg))
+			}
+			if len(allErrs) == 0 && len(r.Spec.SampleDocuments) > 0 {
+				allErrs = append(allErrs, simulateSampleDocuments(*r.Spec.Model, r.Spec.SampleDocuments, esConfig)...)
+			}
+		}
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticIngestPipeline"},
+			r.Name, allErrs)
+	}
+
+	elasticingestpipelinelog.Info("[Webhook] ignore validate create", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticIngestPipeline) ValidateUpdate(old runtime.Object) error {
+	if len(elasticingestpipelineNamespaces) == 0 || utils.ContainsString(elasticingestpipelineNamespaces, r.ObjectMeta.Namespace) {
+		elasticingestpipelinelog.Info("[Webhook] validate update", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		oldR := old.(*ElasticIngestPipeline)
+
+		if *r.Spec.PipelineName != *oldR.Spec.PipelineName {
+			errMsg := fmt.Sprintf(`Cannot update pipelineName from "%v" to "%v"`, *oldR.Spec.PipelineName, *r.Spec.PipelineName)
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("pipelineName"), r.Spec.PipelineName, errMsg))
+		}
+
+		modelUpdated := !utils.CompareJson(*r.Spec.Model, *oldR.Spec.Model)
+		if modelUpdated {
+			_, err := (&utils.EsModel{Model: *r.Spec.Model}).IsValid(utils.IngestPipeline)
+			if err != nil {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("model"), r.Spec.Model, err.Error()))
+			}
+		}
+		samplesUpdated := !reflect.DeepEqual(r.Spec.SampleDocuments, oldR.Spec.SampleDocuments)
+		if samplesUpdated {
+			allErrs = append(allErrs, validateSampleDocuments(r.Spec.SampleDocuments)...)
+		}
+
+		allErrs = ValidateUpdateConnection(allErrs, r.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, oldR.Spec.ElasticURI, oldR.Spec.ConnectionRef, oldR.Status.Cluster, elasticingestpipelineK8sClient)
+
+		// the sample documents are simulated again when the model or the documents change
+		if len(allErrs) == 0 && len(r.Spec.SampleDocuments) > 0 && (modelUpdated || samplesUpdated) {
+			if esConfig, errs := validateEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticingestpipelineK8sClient); len(errs) > 0 {
+				allErrs = append(allErrs, errs...)
+			} else {
+				allErrs = append(allErrs, simulateSampleDocuments(*r.Spec.Model, r.Spec.SampleDocuments, esConfig)...)
+			}
+		}
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticIngestPipeline"},
+			r.Name, allErrs)
+	}
+
+	elasticingestpipelinelog.Info("[Webhook] ignore validate update", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticIngestPipeline) ValidateDelete() error {
+	if len(elasticingestpipelineNamespaces) == 0 || utils.ContainsString(elasticingestpipelineNamespaces, r.ObjectMeta.Namespace) {
+		elasticingestpipelinelog.Info("[Webhook] validate delete", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		allErrs = append(allErrs, validateDeleteEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticingestpipelineK8sClient)...)
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticIngestPipeline"},
+			r.Name, allErrs)
+	}
+
+	elasticingestpipelinelog.Info("[Webhook] ignore validate delete", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// validateSampleDocuments checks that the sample documents are json objects
+func validateSampleDocuments(documents []string) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, document := range documents {
+		var source map[string]interface{}
+		if err := json.Unmarshal([]byte(document), &source); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("sampleDocuments").Index(i), document, "sample document is not a json object"))
+		}
+	}
+	return allErrs
+}
+
+// simulateSampleDocuments runs the sample documents through the pipeline model with the _simulate API, the model being
+// rejected when it fails to process one of them
+func simulateSampleDocuments(model string, documents []string, esConfig *utils.EsConfig) field.ErrorList {
+	path := field.NewPath("spec").Child("sampleDocuments")
+	elasticsearch, err := utils.NewElasticsearch(esConfig, elasticingestpipelinelog)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, documents, err.Error())}
+	}
+	pipelines, ok := elasticsearch.(utils.EsIngestPipelines)
+	if !ok {
+		errMsg := fmt.Sprintf("ingest pipelines are not supported by %v %v", esConfig.Distribution, esConfig.VersionNumber)
+		return field.ErrorList{field.Forbidden(path, errMsg)}
+	}
+
+	failures, err := pipelines.SimulateIngestPipeline(context.Background(), model, documents)
+	if err != nil {
+		errMsg := fmt.Sprintf("error while simulating the sample documents. %v", err.Error())
+		return field.ErrorList{field.Invalid(field.NewPath("spec").Child("model"), model, errMsg)}
+	}
+	var allErrs field.ErrorList
+	for i, failure := range failures {
+		if failure != "" {
+			errMsg := fmt.Sprintf("the pipeline failed to process the sample document. %v", failure)
+			allErrs = append(allErrs, field.Invalid(path.Index(i), documents[i], errMsg))
+		}
+	}
+	return allErrs
+}
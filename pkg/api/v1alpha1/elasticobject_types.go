@@ -0,0 +1,101 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ElasticObject is implemented by the kinds whose model is applied to a single elasticsearch object, like
+// ElasticComposableTemplate. They share their reconciliation, their uniqueness checks and their status
+// +kubebuilder:object:generate=false
+type ElasticObject interface {
+	runtime.Object
+	metav1.Object
+	// GetEsObjectName returns the name of the object in elasticsearch
+	GetEsObjectName() string
+	// GetConnection returns the elasticURI and the connectionRef of the object
+	GetConnection() (*ElasticURISource, *ElasticConnectionReference)
+	// GetModel returns the body applied to elasticsearch, the model completed with the fields of the spec
+	GetModel() (string, error)
+	// GetResync returns how the object is periodically compared with elasticsearch
+	GetResync() *ElasticResync
+	// GetObjectStatus returns the status shared by the kinds
+	GetObjectStatus() *ElasticObjectStatus
+}
+
+// ElasticObjectList is implemented by the lists of the ElasticObject kinds
+// +kubebuilder:object:generate=false
+type ElasticObjectList interface {
+	runtime.Object
+	GetObjects() []ElasticObject
+}
+
+// ElasticObjectStatus defines the observed state of the ElasticObject kinds
+type ElasticObjectStatus struct {
+	// Status indicates whether the model was applied successfully to elasticsearch. Possible values: Created, Error, Retry
+	// +optional
+	Status string `json:"status,omitempty"`
+
+	// The http code status returned by elasticsearch
+	// +optional
+	HttpCodeStatus string `json:"httpCodeStatus,omitempty"`
+
+	// The message returned by elasticsearch. Useful when Status is Error or Retry
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// The metadata.generation the status reflects
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions of the object: Ready, Reconciling, Degraded, and Drifted that is set on each resync
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// Sha256 of the last model applied successfully
+	// +optional
+	LastAppliedModelHash string `json:"lastAppliedModelHash,omitempty"`
+
+	// Last time the model was applied successfully, or compared with elasticsearch by a resync
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// Elasticsearch cluster the model was applied to
+	// +optional
+	Cluster *ElasticClusterReference `json:"cluster,omitempty"`
+
+	// Number of consecutive failed attempts to apply the model, the delay before the next attempt being doubled on
+	// each of them
+	// +optional
+	Retries int32 `json:"retries,omitempty"`
+
+	// Time of the next attempt to apply the model, after a Retry or Error status
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+}
+
+// ElasticObjectWithSecrets is implemented by the ElasticObject kinds whose model includes values read from secrets,
+// like the credentials of an ElasticSnapshotRepository. Their model is applied again when the secrets change
+// +kubebuilder:object:generate=false
+type ElasticObjectWithSecrets interface {
+	ElasticObject
+	// GetModelSecretNames returns the names of the secrets read by GetModelWithSecrets, in the namespace of the object
+	GetModelSecretNames() []string
+	// GetModelWithSecrets returns the model completed with the values read from the secrets
+	GetModelWithSecrets(k8sClient client.Client) (string, error)
+}
@@ -0,0 +1,89 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ElasticSecretGrantSpec lists the namespaces allowed to reference secrets of the namespace of the grant
+type ElasticSecretGrantSpec struct {
+	// Namespaces whose objects can reference the secrets
+	// +kubebuilder:validation:MinItems=1
+	From []ElasticSecretGrantFrom `json:"from"`
+
+	// Secrets that can be referenced, all the secrets of the namespace when empty
+	// +optional
+	To []ElasticSecretGrantTo `json:"to,omitempty"`
+}
+
+// ElasticSecretGrantFrom is a namespace allowed by a grant
+type ElasticSecretGrantFrom struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+}
+
+// ElasticSecretGrantTo is a secret granted by a grant
+type ElasticSecretGrantTo struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=esg
+
+// ElasticSecretGrant is the Schema for the elasticsecretgrants API. It allows objects of other namespaces to reference
+// secrets of its namespace in their elasticURI, like a Gateway API ReferenceGrant
+type ElasticSecretGrant struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ElasticSecretGrantSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ElasticSecretGrantList contains a list of ElasticSecretGrant
+type ElasticSecretGrantList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ElasticSecretGrant `json:"items"`
+}
+
+// Allows returns true when the grant allows objects of namespace to reference the secret
+func (g *ElasticSecretGrant) Allows(namespace string, secretName string) bool {
+	fromAllowed := false
+	for _, from := range g.Spec.From {
+		fromAllowed = fromAllowed || from.Namespace == namespace
+	}
+	if !fromAllowed {
+		return false
+	}
+	if len(g.Spec.To) == 0 {
+		return true
+	}
+	for _, to := range g.Spec.To {
+		if to.Name == secretName {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	SchemeBuilder.Register(&ElasticSecretGrant{}, &ElasticSecretGrantList{})
+}
@@ -0,0 +1,54 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestElasticSecretGrant_Allows(t *testing.T) {
+	assert := assert.New(t)
+	from := func(namespaces ...string) []ElasticSecretGrantFrom {
+		var items []ElasticSecretGrantFrom
+		for _, namespace := range namespaces {
+			items = append(items, ElasticSecretGrantFrom{Namespace: namespace})
+		}
+		return items
+	}
+	scenarios := []struct {
+		name       string
+		spec       ElasticSecretGrantSpec
+		namespace  string
+		secretName string
+		expect     bool
+	}{
+		{name: "empty to grants all the secrets", spec: ElasticSecretGrantSpec{From: from("team-a")}, namespace: "team-a", secretName: "credentials", expect: true},
+		{name: "listed secret", spec: ElasticSecretGrantSpec{From: from("team-a"), To: []ElasticSecretGrantTo{{Name: "ca"}, {Name: "credentials"}}},
+			namespace: "team-a", secretName: "credentials", expect: true},
+		{name: "secret not listed", spec: ElasticSecretGrantSpec{From: from("team-a"), To: []ElasticSecretGrantTo{{Name: "ca"}}},
+			namespace: "team-a", secretName: "credentials", expect: false},
+		{name: "one of the namespaces", spec: ElasticSecretGrantSpec{From: from("team-a", "team-b")}, namespace: "team-b", secretName: "credentials", expect: true},
+		{name: "namespace not listed", spec: ElasticSecretGrantSpec{From: from("team-a")}, namespace: "team-b", secretName: "credentials", expect: false},
+		{name: "namespace not listed with empty to", spec: ElasticSecretGrantSpec{From: from("team-a"), To: []ElasticSecretGrantTo{}},
+			namespace: "team-b", secretName: "credentials", expect: false},
+		{name: "no namespace", spec: ElasticSecretGrantSpec{}, namespace: "team-a", secretName: "credentials", expect: false},
+	}
+
+	for _, s := range scenarios {
+		grant := &ElasticSecretGrant{Spec: s.spec}
+		assert.Equal(s.expect, grant.Allows(s.namespace, s.secretName), s.name)
+	}
+}
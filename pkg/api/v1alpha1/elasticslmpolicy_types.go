@@ -0,0 +1,143 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ElasticSLMPolicySpec defines the desired state of ElasticSLMPolicy
+type ElasticSLMPolicySpec struct {
+	// SLM policy name in elasticsearch server
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9-_\.]+$`
+	PolicyName *string `json:"policyName"`
+
+	// Elasticsearch URI with this format <scheme>://<user>:<password>@<hostname>:<port> from a key of a secret in the local namespace.
+	// Legacy alternative to connectionRef
+	// +optional
+	ElasticURI *ElasticURISource `json:"elasticURI,omitempty"`
+
+	// Connection to the elasticsearch cluster: an ElasticConnection of the local namespace, or a ClusterElasticConnection
+	// +optional
+	ConnectionRef *ElasticConnectionReference `json:"connectionRef,omitempty"`
+
+	// SLM policy: name of the snapshots, schedule, repository, config and retention
+	// +kubebuilder:validation:Required
+	Model *string `json:"model"`
+
+	// Resync defines how the object is periodically compared with elasticsearch, overriding the operator flags
+	// +optional
+	Resync *ElasticResync `json:"resync,omitempty"`
+}
+
+// ElasticSnapshotExecution is a snapshot taken by an SLM policy
+type ElasticSnapshotExecution struct {
+	// Name of the snapshot
+	SnapshotName string `json:"snapshotName"`
+
+	// Time the snapshot completed, or failed
+	Time metav1.Time `json:"time"`
+
+	// Reason of the failure of the snapshot
+	// +optional
+	Details string `json:"details,omitempty"`
+}
+
+// ElasticSLMPolicyStatus defines the observed state of ElasticSLMPolicy
+type ElasticSLMPolicyStatus struct {
+	ElasticObjectStatus `json:",inline"`
+
+	// Last snapshot taken successfully by the policy
+	// +optional
+	LastSuccess *ElasticSnapshotExecution `json:"lastSuccess,omitempty"`
+
+	// Last snapshot of the policy that failed
+	// +optional
+	LastFailure *ElasticSnapshotExecution `json:"lastFailure,omitempty"`
+
+	// Time of the next snapshot
+	// +optional
+	NextExecution *metav1.Time `json:"nextExecution,omitempty"`
+
+	// Number of snapshots taken by the policy, from _slm/stats
+	// +optional
+	SnapshotsTaken int64 `json:"snapshotsTaken,omitempty"`
+
+	// Number of snapshots of the policy that failed, from _slm/stats
+	// +optional
+	SnapshotsFailed int64 `json:"snapshotsFailed,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=eslm
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="POLICY_NAME",type="string",JSONPath=".spec.policyName"
+// +kubebuilder:printcolumn:name="LAST_SUCCESS",type="date",JSONPath=".status.lastSuccess.time"
+// +kubebuilder:printcolumn:name="LAST_FAILURE",type="date",JSONPath=".status.lastFailure.time"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.status"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ElasticSLMPolicy is the Schema for the elasticslmpolicies API, a snapshot lifecycle management policy of the
+// _slm/policy API
+type ElasticSLMPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ElasticSLMPolicySpec   `json:"spec,omitempty"`
+	Status ElasticSLMPolicyStatus `json:"status,omitempty"`
+}
+
+func (r *ElasticSLMPolicy) GetEsObjectName() string {
+	return *r.Spec.PolicyName
+}
+
+func (r *ElasticSLMPolicy) GetConnection() (*ElasticURISource, *ElasticConnectionReference) {
+	return r.Spec.ElasticURI, r.Spec.ConnectionRef
+}
+
+func (r *ElasticSLMPolicy) GetModel() (string, error) {
+	return *r.Spec.Model, nil
+}
+
+func (r *ElasticSLMPolicy) GetResync() *ElasticResync {
+	return r.Spec.Resync
+}
+
+func (r *ElasticSLMPolicy) GetObjectStatus() *ElasticObjectStatus {
+	return &r.Status.ElasticObjectStatus
+}
+
+// +kubebuilder:object:root=true
+
+// ElasticSLMPolicyList contains a list of ElasticSLMPolicy
+type ElasticSLMPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ElasticSLMPolicy `json:"items"`
+}
+
+func (l *ElasticSLMPolicyList) GetObjects() []ElasticObject {
+	var objects []ElasticObject
+	for i := range l.Items {
+		objects = append(objects, &l.Items[i])
+	}
+	return objects
+}
+
+func init() {
+	SchemeBuilder.Register(&ElasticSLMPolicy{}, &ElasticSLMPolicyList{})
+}
@@ -0,0 +1,229 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+var (
+	// log is for logging in this package.
+	elasticslmpolicylog        = logf.Log.WithName("elasticslmpolicy-resource")
+	elasticslmpolicyK8sClient  client.Client
+	elasticslmpolicyNamespaces []string
+)
+
+func (r *ElasticSLMPolicy) SetupWebhookWithManager(mgr ctrl.Manager, namespaces []string) error {
+	elasticslmpolicyK8sClient = mgr.GetClient()
+	elasticslmpolicyNamespaces = namespaces
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-elastic-carrefour-com-v1alpha1-elasticslmpolicy,mutating=true,failurePolicy=fail,groups=elastic.carrefour.com,resources=elasticslmpolicies,verbs=create;update,versions=v1alpha1,name=melasticslmpolicy.kb.io,sideEffects=none,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Defaulter = &ElasticSLMPolicy{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *ElasticSLMPolicy) Default() {
+	elasticslmpolicylog.Info("[Webhook] default", "namespace", r.Namespace, "name", r.Name)
+
+	if r.Spec.Model != nil {
+		if compactedModel, err := utils.CompactJson(*r.Spec.Model); err == nil {
+			*r.Spec.Model = compactedModel
+		}
+	}
+}
+
+// +kubebuilder:webhook:verbs=create;update;delete,path=/validate-elastic-carrefour-com-v1alpha1-elasticslmpolicy,mutating=false,failurePolicy=fail,groups=elastic.carrefour.com,resources=elasticslmpolicies,versions=v1alpha1,name=velasticslmpolicy.kb.io,sideEffects=none,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Validator = &ElasticSLMPolicy{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticSLMPolicy) ValidateCreate() error {
+	if len(elasticslmpolicyNamespaces) == 0 || utils.ContainsString(elasticslmpolicyNamespaces, r.ObjectMeta.Namespace) {
+		elasticslmpolicylog.Info("[Webhook] validate create", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		modelErr := validateSLMPolicyModel(*r.Spec.Model)
+		if modelErr != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("model"), r.Spec.Model, modelErr.Error()))
+		}
+
+		if esConfig, errs := validateEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticslmpolicyK8sClient); len(errs) > 0 {
+			allErrs = append(allErrs, errs...)
+		} else if errs := validateSLMPolicyVersion(esConfig); len(errs) > 0 {
+			allErrs = append(allErrs, errs...)
+		} else {
+			if info, err := checkEsObjectExists(*r.Spec.PolicyName, &ElasticSLMPolicyList{}, esConfig, elasticslmpolicyK8sClient); err != nil {
+				errMsg := fmt.Sprintf(`error while checking SLM policy "%v" existence from all kubernetes elasticslmpolicy objects. %v`, *r.Spec.PolicyName, err.Error())
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("policyName"), r.Spec.PolicyName, errMsg))
+			} else if info != nil {
+				errMsg := fmt.Sprintf(`SLM policy "%v" for elasticsearch cluster "%v" was created by kubernetes elasticslmpolicy "%v" in namespace "%v"`, info.esObjectName, info.Cluster, info.Name, info.Namespace)
+				allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("policyName"), errMsg))
+			}
+			if modelErr == nil {
+				allErrs = append(allErrs, validateSLMPolicyRepository(*r.Spec.Model, esConfig, elasticslmpolicyK8sClient)...)
+			}
+		}
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticSLMPolicy"},
+			r.Name, allErrs)
+	}
+
+	elasticslmpolicylog.Info("[Webhook] ignore validate create", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticSLMPolicy) ValidateUpdate(old runtime.Object) error {
+	if len(elasticslmpolicyNamespaces) == 0 || utils.ContainsString(elasticslmpolicyNamespaces, r.ObjectMeta.Namespace) {
+		elasticslmpolicylog.Info("[Webhook] validate update", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		oldR := old.(*ElasticSLMPolicy)
+
+		if *r.Spec.PolicyName != *oldR.Spec.PolicyName {
+			errMsg := fmt.Sprintf(`Cannot update policyName from "%v" to "%v"`, *oldR.Spec.PolicyName, *r.Spec.PolicyName)
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("policyName"), r.Spec.PolicyName, errMsg))
+		}
+
+		repositoryUpdated := false
+		if !utils.CompareJson(*r.Spec.Model, *oldR.Spec.Model) {
+			if err := validateSLMPolicyModel(*r.Spec.Model); err != nil {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("model"), r.Spec.Model, err.Error()))
+			} else {
+				repositoryUpdated = utils.GetSLMPolicyRepository(*r.Spec.Model) != utils.GetSLMPolicyRepository(*oldR.Spec.Model)
+			}
+		}
+
+		allErrs = ValidateUpdateConnection(allErrs, r.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, oldR.Spec.ElasticURI, oldR.Spec.ConnectionRef, oldR.Status.Cluster, elasticslmpolicyK8sClient)
+
+		// the repository is only checked when it changes, it was checked before otherwise
+		if len(allErrs) == 0 && repositoryUpdated {
+			if esConfig, errs := validateEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticslmpolicyK8sClient); len(errs) > 0 {
+				allErrs = append(allErrs, errs...)
+			} else {
+				allErrs = append(allErrs, validateSLMPolicyRepository(*r.Spec.Model, esConfig, elasticslmpolicyK8sClient)...)
+			}
+		}
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticSLMPolicy"},
+			r.Name, allErrs)
+	}
+
+	elasticslmpolicylog.Info("[Webhook] ignore validate update", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticSLMPolicy) ValidateDelete() error {
+	if len(elasticslmpolicyNamespaces) == 0 || utils.ContainsString(elasticslmpolicyNamespaces, r.ObjectMeta.Namespace) {
+		elasticslmpolicylog.Info("[Webhook] validate delete", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		allErrs = append(allErrs, validateDeleteEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticslmpolicyK8sClient)...)
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticSLMPolicy"},
+			r.Name, allErrs)
+	}
+
+	elasticslmpolicylog.Info("[Webhook] ignore validate delete", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// validateSLMPolicyModel checks the root fields of the model, then its schedule and retention
+func validateSLMPolicyModel(model string) error {
+	if _, err := (&utils.EsModel{Model: model}).IsValid(utils.SLMPolicy); err != nil {
+		return err
+	}
+	return utils.ValidateSLMPolicy(model)
+}
+
+// validateSLMPolicyVersion checks that the cluster of esConfig supports snapshot lifecycle management
+func validateSLMPolicyVersion(esConfig *utils.EsConfig) field.ErrorList {
+	if esConfig.Distribution == utils.DistributionOpenSearch {
+		errMsg := fmt.Sprintf("SLM policies are not supported by %v %v", esConfig.Distribution, esConfig.VersionNumber)
+		return field.ErrorList{field.Forbidden(field.NewPath("spec"), errMsg)}
+	}
+	if err := esConfig.RequireVersion(utils.SLMMinVersion, "SLM policies"); err != nil {
+		return field.ErrorList{field.Forbidden(field.NewPath("spec"), err.Error())}
+	}
+	return nil
+}
+
+// validateSLMPolicyRepository checks that the repository of the model exists: an ElasticSnapshotRepository of the
+// cluster of esConfig, or a snapshot repository of elasticsearch
+func validateSLMPolicyRepository(model string, esConfig *utils.EsConfig, k8sClient client.Client) field.ErrorList {
+	path := field.NewPath("spec").Child("model")
+	repositoryName := utils.GetSLMPolicyRepository(model)
+
+	if info, err := checkEsObjectExists(repositoryName, &ElasticSnapshotRepositoryList{}, esConfig, k8sClient); err != nil {
+		errMsg := fmt.Sprintf(`error while checking snapshot repository "%v" existence from all kubernetes elasticsnapshotrepository objects. %v`, repositoryName, err.Error())
+		return field.ErrorList{field.Invalid(path, model, errMsg)}
+	} else if info != nil {
+		return nil
+	}
+
+	elasticsearch, err := utils.NewElasticsearch(esConfig, elasticslmpolicylog)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, model, err.Error())}
+	}
+	repositories, ok := elasticsearch.(utils.EsSnapshotRepositories)
+	if !ok {
+		errMsg := fmt.Sprintf("snapshot repositories are not supported by %v %v", esConfig.Distribution, esConfig.VersionNumber)
+		return field.ErrorList{field.Forbidden(path, errMsg)}
+	}
+	exists, err := repositories.ExistsSnapshotRepository(context.Background(), repositoryName)
+	switch {
+	case err != nil:
+		errMsg := fmt.Sprintf(`error while checking snapshot repository "%v" existence. %v`, repositoryName, err.Error())
+		return field.ErrorList{field.Invalid(path, model, errMsg)}
+	case !exists:
+		errMsg := fmt.Sprintf(`snapshot repository "%v" does not exist, an ElasticSnapshotRepository or a snapshot repository of elasticsearch is required`, repositoryName)
+		return field.ErrorList{field.Invalid(path, model, errMsg)}
+	}
+	return nil
+}
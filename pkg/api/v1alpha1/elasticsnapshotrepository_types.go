@@ -0,0 +1,187 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ElasticSnapshotRepositorySpec defines the desired state of ElasticSnapshotRepository
+type ElasticSnapshotRepositorySpec struct {
+	// Snapshot repository name in elasticsearch server
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9-_\.]+$`
+	RepositoryName *string `json:"repositoryName"`
+
+	// Elasticsearch URI with this format <scheme>://<user>:<password>@<hostname>:<port> from a key of a secret in the local namespace.
+	// Legacy alternative to connectionRef
+	// +optional
+	ElasticURI *ElasticURISource `json:"elasticURI,omitempty"`
+
+	// Connection to the elasticsearch cluster: an ElasticConnection of the local namespace, or a ClusterElasticConnection
+	// +optional
+	ConnectionRef *ElasticConnectionReference `json:"connectionRef,omitempty"`
+
+	// Type of the repository. The s3, gcs and azure types need the repository plugin on elasticsearch 7 and earlier
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=fs;s3;gcs;azure;url
+	Type string `json:"type"`
+
+	// Settings of the repository as a json object, like {"location":"/mnt/backups","compress":true}
+	// +optional
+	Settings *string `json:"settings,omitempty"`
+
+	// Settings read from keys of secrets in the local namespace, like the access_key and secret_key of a s3 repository.
+	// They are applied with the settings, and applied again when the secrets change
+	// +optional
+	SecretSettings []ElasticSecretSetting `json:"secretSettings,omitempty"`
+
+	// Resync defines how the object is periodically compared with elasticsearch, overriding the operator flags
+	// +optional
+	Resync *ElasticResync `json:"resync,omitempty"`
+}
+
+// ElasticSecretSetting is a setting of a snapshot repository read from a key of a secret
+type ElasticSecretSetting struct {
+	// Name of the setting, like secret_key
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+
+	// Key of a secret in the local namespace holding the value of the setting
+	// +kubebuilder:validation:Required
+	SecretKeyRef *v1.SecretKeySelector `json:"secretKeyRef"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=esr
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="REPOSITORY_NAME",type="string",JSONPath=".spec.repositoryName"
+// +kubebuilder:printcolumn:name="TYPE",type="string",JSONPath=".spec.type"
+// +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.status"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+
+// ElasticSnapshotRepository is the Schema for the elasticsnapshotrepositories API, a snapshot repository of the
+// _snapshot API that ElasticSLMPolicies take snapshots to
+type ElasticSnapshotRepository struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ElasticSnapshotRepositorySpec `json:"spec,omitempty"`
+	Status ElasticObjectStatus           `json:"status,omitempty"`
+}
+
+func (r *ElasticSnapshotRepository) GetEsObjectName() string {
+	return *r.Spec.RepositoryName
+}
+
+func (r *ElasticSnapshotRepository) GetConnection() (*ElasticURISource, *ElasticConnectionReference) {
+	return r.Spec.ElasticURI, r.Spec.ConnectionRef
+}
+
+// GetModel returns the type and the settings of the spec, like {"type":"fs","settings":{"location":"/mnt/backups"}},
+// without the settings read from secrets
+func (r *ElasticSnapshotRepository) GetModel() (string, error) {
+	return utils.BuildSnapshotRepositoryModel(r.Spec.Type, r.getSettings(), nil)
+}
+
+// GetModelSecretNames returns the names of the secrets of the secretSettings
+func (r *ElasticSnapshotRepository) GetModelSecretNames() []string {
+	var names []string
+	for _, setting := range r.Spec.SecretSettings {
+		if setting.SecretKeyRef != nil && !utils.ContainsString(names, setting.SecretKeyRef.Name) {
+			names = append(names, setting.SecretKeyRef.Name)
+		}
+	}
+	return names
+}
+
+// GetModelWithSecrets returns the model with the settings read from secrets
+func (r *ElasticSnapshotRepository) GetModelWithSecrets(k8sClient client.Client) (string, error) {
+	secretSettings := map[string]string{}
+	for _, setting := range r.Spec.SecretSettings {
+		value, err := getSecretSettingValue(r.Namespace, setting, k8sClient)
+		if err != nil {
+			return "", err
+		}
+		secretSettings[setting.Name] = value
+	}
+	return utils.BuildSnapshotRepositoryModel(r.Spec.Type, r.getSettings(), secretSettings)
+}
+
+func (r *ElasticSnapshotRepository) getSettings() string {
+	if r.Spec.Settings == nil {
+		return ""
+	}
+	return *r.Spec.Settings
+}
+
+// getSecretSettingNames returns the names of the settings read from secrets
+func (r *ElasticSnapshotRepository) getSecretSettingNames() []string {
+	var names []string
+	for _, setting := range r.Spec.SecretSettings {
+		names = append(names, setting.Name)
+	}
+	return names
+}
+
+func (r *ElasticSnapshotRepository) GetResync() *ElasticResync {
+	return r.Spec.Resync
+}
+
+func (r *ElasticSnapshotRepository) GetObjectStatus() *ElasticObjectStatus {
+	return &r.Status
+}
+
+// getSecretSettingValue reads the value of a setting from its secret in namespace
+func getSecretSettingValue(namespace string, setting ElasticSecretSetting, k8sClient client.Client) (string, error) {
+	if setting.SecretKeyRef == nil {
+		return "", fmt.Errorf("secretKeyRef of setting %v is not set", setting.Name)
+	}
+	secret, err := utils.GetSecret(namespace, setting.SecretKeyRef, k8sClient)
+	if err != nil {
+		return "", fmt.Errorf("unable to read setting %v from secret %v: %v", setting.Name, setting.SecretKeyRef.Name, err)
+	}
+	value, ok := secret.Data[setting.SecretKeyRef.Key]
+	if !ok {
+		return "", fmt.Errorf("secret %v has no key %v for setting %v", setting.SecretKeyRef.Name, setting.SecretKeyRef.Key, setting.Name)
+	}
+	return string(value), nil
+}
+
+// +kubebuilder:object:root=true
+
+// ElasticSnapshotRepositoryList contains a list of ElasticSnapshotRepository
+type ElasticSnapshotRepositoryList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ElasticSnapshotRepository `json:"items"`
+}
+
+func (l *ElasticSnapshotRepositoryList) GetObjects() []ElasticObject {
+	var objects []ElasticObject
+	for i := range l.Items {
+		objects = append(objects, &l.Items[i])
+	}
+	return objects
+}
+
+func init() {
+	SchemeBuilder.Register(&ElasticSnapshotRepository{}, &ElasticSnapshotRepositoryList{})
+}
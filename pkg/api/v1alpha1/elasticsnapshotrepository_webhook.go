@@ -0,0 +1,169 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"fmt"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"reflect"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/runtime/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+var (
+	// log is for logging in this package.
+	elasticsnapshotrepositorylog        = logf.Log.WithName("elasticsnapshotrepository-resource")
+	elasticsnapshotrepositoryK8sClient  client.Client
+	elasticsnapshotrepositoryNamespaces []string
+)
+
+func (r *ElasticSnapshotRepository) SetupWebhookWithManager(mgr ctrl.Manager, namespaces []string) error {
+	elasticsnapshotrepositoryK8sClient = mgr.GetClient()
+	elasticsnapshotrepositoryNamespaces = namespaces
+
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-elastic-carrefour-com-v1alpha1-elasticsnapshotrepository,mutating=true,failurePolicy=fail,groups=elastic.carrefour.com,resources=elasticsnapshotrepositories,verbs=create;update,versions=v1alpha1,name=melasticsnapshotrepository.kb.io,sideEffects=none,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Defaulter = &ElasticSnapshotRepository{}
+
+// Default implements webhook.Defaulter so a webhook will be registered for the type
+func (r *ElasticSnapshotRepository) Default() {
+	elasticsnapshotrepositorylog.Info("[Webhook] default", "namespace", r.Namespace, "name", r.Name)
+
+	if r.Spec.Settings != nil {
+		if compactedSettings, err := utils.CompactJson(*r.Spec.Settings); err == nil {
+			*r.Spec.Settings = compactedSettings
+		}
+	}
+}
+
+// +kubebuilder:webhook:verbs=create;update;delete,path=/validate-elastic-carrefour-com-v1alpha1-elasticsnapshotrepository,mutating=false,failurePolicy=fail,groups=elastic.carrefour.com,resources=elasticsnapshotrepositories,versions=v1alpha1,name=velasticsnapshotrepository.kb.io,sideEffects=none,admissionReviewVersions=v1;v1beta1
+
+var _ webhook.Validator = &ElasticSnapshotRepository{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticSnapshotRepository) ValidateCreate() error {
+	if len(elasticsnapshotrepositoryNamespaces) == 0 || utils.ContainsString(elasticsnapshotrepositoryNamespaces, r.ObjectMeta.Namespace) {
+		elasticsnapshotrepositorylog.Info("[Webhook] validate create", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		allErrs = append(allErrs, r.validateSettings()...)
+
+		if esConfig, errs := validateEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticsnapshotrepositoryK8sClient); len(errs) > 0 {
+			allErrs = append(allErrs, errs...)
+		} else {
+			if info, err := checkEsObjectExists(*r.Spec.RepositoryName, &ElasticSnapshotRepositoryList{}, esConfig, elasticsnapshotrepositoryK8sClient); err != nil {
+				errMsg := fmt.Sprintf(`error while checking snapshot repository "%v" existence from all kubernetes elasticsnapshotrepository objects. %v`, *r.Spec.RepositoryName, err.Error())
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("repositoryName"), r.Spec.RepositoryName, errMsg))
+			} else if info != nil {
+				errMsg := fmt.Sprintf(`snapshot repository "%v" for elasticsearch cluster "%v" was created by kubernetes elasticsnapshotrepository "%v" in namespace "%v"`, info.esObjectName, info.Cluster, info.Name, info.Namespace)
+				allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("repositoryName"), errMsg))
+			}
+		}
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticSnapshotRepository"},
+			r.Name, allErrs)
+	}
+
+	elasticsnapshotrepositorylog.Info("[Webhook] ignore validate create", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticSnapshotRepository) ValidateUpdate(old runtime.Object) error {
+	if len(elasticsnapshotrepositoryNamespaces) == 0 || utils.ContainsString(elasticsnapshotrepositoryNamespaces, r.ObjectMeta.Namespace) {
+		elasticsnapshotrepositorylog.Info("[Webhook] validate update", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		oldR := old.(*ElasticSnapshotRepository)
+
+		if *r.Spec.RepositoryName != *oldR.Spec.RepositoryName {
+			errMsg := fmt.Sprintf(`Cannot update repositoryName from "%v" to "%v"`, *oldR.Spec.RepositoryName, *r.Spec.RepositoryName)
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("repositoryName"), r.Spec.RepositoryName, errMsg))
+		}
+
+		if r.Spec.Type != oldR.Spec.Type || r.getSettings() != oldR.getSettings() || !reflect.DeepEqual(r.Spec.SecretSettings, oldR.Spec.SecretSettings) {
+			allErrs = append(allErrs, r.validateSettings()...)
+		}
+
+		allErrs = ValidateUpdateConnection(allErrs, r.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, oldR.Spec.ElasticURI, oldR.Spec.ConnectionRef, oldR.Status.Cluster, elasticsnapshotrepositoryK8sClient)
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticSnapshotRepository"},
+			r.Name, allErrs)
+	}
+
+	elasticsnapshotrepositorylog.Info("[Webhook] ignore validate update", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *ElasticSnapshotRepository) ValidateDelete() error {
+	if len(elasticsnapshotrepositoryNamespaces) == 0 || utils.ContainsString(elasticsnapshotrepositoryNamespaces, r.ObjectMeta.Namespace) {
+		elasticsnapshotrepositorylog.Info("[Webhook] validate delete", "namespace", r.Namespace, "name", r.Name)
+
+		var allErrs field.ErrorList
+
+		allErrs = append(allErrs, validateDeleteEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elasticsnapshotrepositoryK8sClient)...)
+
+		if len(allErrs) == 0 {
+			return nil
+		}
+
+		return apierrors.NewInvalid(
+			schema.GroupKind{Group: "elastic.carrefour.com", Kind: "ElasticSnapshotRepository"},
+			r.Name, allErrs)
+	}
+
+	elasticsnapshotrepositorylog.Info("[Webhook] ignore validate delete", "namespace", r.Namespace, "name", r.Name)
+	return nil
+}
+
+// validateSettings checks the settings of the type of the repository, and that the settings read from secrets can be
+// read
+func (r *ElasticSnapshotRepository) validateSettings() field.ErrorList {
+	var allErrs field.ErrorList
+
+	if err := utils.ValidateSnapshotRepository(r.Spec.Type, r.getSettings(), r.getSecretSettingNames()); err != nil {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("settings"), r.getSettings(), err.Error()))
+	}
+	for i, setting := range r.Spec.SecretSettings {
+		if _, err := getSecretSettingValue(r.Namespace, setting, elasticsnapshotrepositoryK8sClient); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("secretSettings").Index(i), setting.Name, err.Error()))
+		}
+	}
+	return allErrs
+}
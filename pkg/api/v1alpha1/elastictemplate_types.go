@@ -31,9 +31,14 @@ type ElasticTemplateSpec struct {
 	// +kubebuilder:validation:Pattern=`^[a-z0-9-_\.]+$`
 	TemplateName *string `json:"templateName"`
 
-	// Elasticsearch URI with this format <scheme>://<user>:<password>@<hostname>:<port> from a key of a secret in the local namespace
-	// +kubebuilder:validation:Required
-	ElasticURI ElasticURISource `json:"elasticURI"`
+	// Elasticsearch URI with this format <scheme>://<user>:<password>@<hostname>:<port> from a key of a secret in the local namespace.
+	// Legacy alternative to connectionRef
+	// +optional
+	ElasticURI *ElasticURISource `json:"elasticURI,omitempty"`
+
+	// Connection to the elasticsearch cluster: an ElasticConnection of the local namespace, or a ClusterElasticConnection
+	// +optional
+	ConnectionRef *ElasticConnectionReference `json:"connectionRef,omitempty"`
 
 	// Number of elasticsearch shards
 	// +kubebuilder:validation:Minimum=1
@@ -55,6 +60,10 @@ type ElasticTemplateSpec struct {
 	// Template mappings, settings, index_patterns and version
 	// +kubebuilder:validation:Required
 	Model *string `json:"model"`
+
+	// Resync defines how the object is periodically compared with elasticsearch, overriding the operator flags
+	// +optional
+	Resync *ElasticResync `json:"resync,omitempty"`
 }
 
 // ElasticTemplateStatus defines the observed state of ElasticTemplate
@@ -73,6 +82,35 @@ type ElasticTemplateStatus struct {
 	// The message returned by elasticsearch. Useful when Status is Error or Retry
 	// +optional
 	Message string `json:"message,omitempty"`
+
+	// The metadata.generation the status reflects
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions of the object: Ready, Reconciling, Degraded, and Drifted that is set on each resync
+	// +optional
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// Sha256 of the last model applied successfully
+	// +optional
+	LastAppliedModelHash string `json:"lastAppliedModelHash,omitempty"`
+
+	// Last time the model was applied successfully, or compared with elasticsearch by a resync
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// Elasticsearch cluster the model was applied to
+	// +optional
+	Cluster *ElasticClusterReference `json:"cluster,omitempty"`
+
+	// Number of consecutive failed attempts to apply the model, the delay before the next attempt being doubled on
+	// each of them
+	// +optional
+	Retries int32 `json:"retries,omitempty"`
+
+	// Time of the next attempt to apply the model, after a Retry or Error status
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -82,6 +120,7 @@ type ElasticTemplateStatus struct {
 // +kubebuilder:printcolumn:name="SHARDS",type="integer",JSONPath=".spec.numberOfShards"
 // +kubebuilder:printcolumn:name="REPLICAS",type="integer",JSONPath=".spec.numberOfReplicas"
 // +kubebuilder:printcolumn:name="STATUS",type="string",JSONPath=".status.status"
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 
 // ElasticTemplate is the Schema for the elastictemplates API
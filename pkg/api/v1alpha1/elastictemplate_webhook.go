@@ -80,22 +80,16 @@ func (r *ElasticTemplate) ValidateCreate() error {
 			allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("model"), r.Spec.Model, err.Error()))
 		}
 
-		if secret, err := utils.GetSecret(r.ObjectMeta.Namespace, r.Spec.ElasticURI.SecretKeyRef, elastictemplateK8sClient); err != nil {
-			errMsg := fmt.Sprintf(`secret "%v" is required. %v`, r.Spec.ElasticURI.SecretKeyRef.Name, err.Error())
-			allErrs = append(allErrs, field.Required(field.NewPath("spec").Child("elasticUri").Child("secretKeyRef"), errMsg))
+		if esConfig, errs := validateEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elastictemplateK8sClient); len(errs) > 0 {
+			allErrs = append(allErrs, errs...)
 		} else {
-			secretKey := r.Spec.ElasticURI.SecretKeyRef.Key
-			if esConfig, err := utils.BuildEsConfigFromExistingSecret(secret, secretKey); err != nil {
-				errMsg := fmt.Sprintf(`error while parsing elasticsearch URI from secret "%v". %v`, r.Spec.ElasticURI.SecretKeyRef.Name, err.Error())
-				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("elasticUri").Child("secretKeyRef"), string(secret.Data[secretKey]), errMsg))
-			} else {
-				if info, err := checkEsTemplateExists(*r.Spec.TemplateName, esConfig, elastictemplateK8sClient); err != nil {
-					errMsg := fmt.Sprintf(`error while checking template "%v" existence from all kubernetes elastictemplate objects. %v`, r.Spec.TemplateName, err.Error())
-					allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("templateName"), r.Spec.TemplateName, errMsg))
-				} else if info != nil {
-					errMsg := fmt.Sprintf(`template "%v" for elasticsearch URI "%v:%v" was created by kubernetes elastictemplate "%v" in namespace "%v"`, info.esObjectName, info.Host, info.Port, info.Name, info.Namespace)
-					allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("templateName"), errMsg))
-				}
+			allErrs = append(allErrs, r.validateOpenSearchModel(esConfig)...)
+			if info, err := checkEsTemplateExists(*r.Spec.TemplateName, esConfig, elastictemplateK8sClient); err != nil {
+				errMsg := fmt.Sprintf(`error while checking template "%v" existence from all kubernetes elastictemplate objects. %v`, r.Spec.TemplateName, err.Error())
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("templateName"), r.Spec.TemplateName, errMsg))
+			} else if info != nil {
+				errMsg := fmt.Sprintf(`template "%v" for elasticsearch cluster "%v" was created by kubernetes elastictemplate "%v" in namespace "%v"`, info.esObjectName, info.Cluster, info.Name, info.Namespace)
+				allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("templateName"), errMsg))
 			}
 		}
 
@@ -138,7 +132,16 @@ func (r *ElasticTemplate) ValidateUpdate(old runtime.Object) error {
 			}
 		}
 
-		allErrs = ValidateUpdateSecret(allErrs, r.Namespace, r.Spec.ElasticURI.SecretKeyRef, oldR.Spec.ElasticURI.SecretKeyRef, elastictemplateK8sClient)
+		allErrs = ValidateUpdateConnection(allErrs, r.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, oldR.Spec.ElasticURI, oldR.Spec.ConnectionRef, oldR.Status.Cluster, elastictemplateK8sClient)
+
+		// the distribution of the cluster is only detected when the model sets an ILM policy
+		if len(allErrs) == 0 && r.getIlmPolicyName() != "" {
+			if esConfig, errs := validateEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elastictemplateK8sClient); len(errs) > 0 {
+				allErrs = append(allErrs, errs...)
+			} else {
+				allErrs = append(allErrs, r.validateOpenSearchModel(esConfig)...)
+			}
+		}
 
 		if len(allErrs) == 0 {
 			return nil
@@ -160,10 +163,7 @@ func (r *ElasticTemplate) ValidateDelete() error {
 
 		var allErrs field.ErrorList
 
-		if _, err := utils.GetSecret(r.ObjectMeta.Namespace, r.Spec.ElasticURI.SecretKeyRef, elastictemplateK8sClient); err != nil {
-			errMsg := fmt.Sprintf(`secret "%v" is required. %v`, r.Spec.ElasticURI.SecretKeyRef.Name, err.Error())
-			allErrs = append(allErrs, field.Required(field.NewPath("spec").Child("elasticUri").Child("secretKeyRef"), errMsg))
-		}
+		allErrs = append(allErrs, validateDeleteEsConnection(r.ObjectMeta.Namespace, r.Spec.ElasticURI, r.Spec.ConnectionRef, elastictemplateK8sClient)...)
 
 		if len(allErrs) == 0 {
 			return nil
@@ -184,15 +184,35 @@ func checkEsTemplateExists(templateName string, esConfig *utils.EsConfig, k8sCli
 		return nil, err
 	}
 	for _, es := range allElasticTemplate.Items {
-		esConfigToCheck, _ := utils.BuildEsConfigFromSecretSelector(es.Namespace, es.Spec.ElasticURI.SecretKeyRef, k8sClient)
-		if templateName == *es.Spec.TemplateName && esConfig.Host == esConfigToCheck.Host && esConfig.Port == esConfigToCheck.Port {
+		if templateName == *es.Spec.TemplateName && isSameEsCluster(esConfig, es.Status.Cluster) {
 			return &EsObjectInfo{
 				Namespace:    es.Namespace,
 				Name:         es.Name,
 				esObjectName: *es.Spec.TemplateName,
-				Host:         esConfigToCheck.Host,
-				Port:         esConfigToCheck.Port}, nil
+				Cluster:      esConfig.String()}, nil
 		}
 	}
 	return nil, nil
 }
+
+// getIlmPolicyName returns the ILM policy of the index.lifecycle.name setting of the model, empty when it is not set
+func (r *ElasticTemplate) getIlmPolicyName() string {
+	_, policyName, err := (&utils.EsModel{Model: *r.Spec.Model}).ForOpenSearch()
+	if err != nil {
+		return ""
+	}
+	return policyName
+}
+
+// validateOpenSearchModel rejects the ILM index.lifecycle.name setting on opensearch, whose ISM policies select their
+// indices with an ism_template instead
+func (r *ElasticTemplate) validateOpenSearchModel(esConfig *utils.EsConfig) field.ErrorList {
+	if esConfig.Distribution != utils.DistributionOpenSearch {
+		return nil
+	}
+	if policyName := r.getIlmPolicyName(); policyName != "" {
+		errMsg := fmt.Sprintf("%v cannot be set on opensearch, add an ism_template to the ISM policy %v instead", utils.IlmPolicySetting, policyName)
+		return field.ErrorList{field.Forbidden(field.NewPath("spec").Child("model"), errMsg)}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestElasticTemplate_ValidateOpenSearchModel(t *testing.T) {
+	assert := assert.New(t)
+	template := func(model string) *ElasticTemplate {
+		return &ElasticTemplate{Spec: ElasticTemplateSpec{Model: &model}}
+	}
+	openSearch := &utils.EsConfig{Distribution: utils.DistributionOpenSearch}
+	elasticsearch := &utils.EsConfig{Distribution: utils.DistributionElasticsearch}
+
+	withPolicy := template(`{"index_patterns":["logs-*"],"settings":{"index.lifecycle.name":"logs"}}`)
+	assert.Equal(1, len(withPolicy.validateOpenSearchModel(openSearch)))
+	assert.Nil(withPolicy.validateOpenSearchModel(elasticsearch))
+
+	nested := template(`{"index_patterns":["logs-*"],"settings":{"index":{"lifecycle":{"name":"logs"}}}}`)
+	assert.Equal(1, len(nested.validateOpenSearchModel(openSearch)))
+
+	withoutPolicy := template(`{"index_patterns":["logs-*"],"settings":{"number_of_shards":1}}`)
+	assert.Nil(withoutPolicy.validateOpenSearchModel(openSearch))
+}
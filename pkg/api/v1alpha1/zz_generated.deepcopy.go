@@ -23,30 +23,1347 @@ package v1alpha1
 
 import (
 	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterElasticConnection) DeepCopyInto(out *ClusterElasticConnection) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterElasticConnection.
+func (in *ClusterElasticConnection) DeepCopy() *ClusterElasticConnection {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterElasticConnection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterElasticConnection) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterElasticConnectionList) DeepCopyInto(out *ClusterElasticConnectionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterElasticConnection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterElasticConnectionList.
+func (in *ClusterElasticConnectionList) DeepCopy() *ClusterElasticConnectionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterElasticConnectionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterElasticConnectionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterElasticConnectionSpec) DeepCopyInto(out *ClusterElasticConnectionSpec) {
+	*out = *in
+	in.ElasticConnectionSpec.DeepCopyInto(&out.ElasticConnectionSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterElasticConnectionSpec.
+func (in *ClusterElasticConnectionSpec) DeepCopy() *ClusterElasticConnectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterElasticConnectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Condition) DeepCopyInto(out *Condition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
+func (in *Condition) DeepCopy() *Condition {
+	if in == nil {
+		return nil
+	}
+	out := new(Condition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticClusterReference) DeepCopyInto(out *ElasticClusterReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticClusterReference.
+func (in *ElasticClusterReference) DeepCopy() *ElasticClusterReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticClusterReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticComponentTemplate) DeepCopyInto(out *ElasticComponentTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticComponentTemplate.
+func (in *ElasticComponentTemplate) DeepCopy() *ElasticComponentTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticComponentTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticComponentTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticComponentTemplateList) DeepCopyInto(out *ElasticComponentTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ElasticComponentTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticComponentTemplateList.
+func (in *ElasticComponentTemplateList) DeepCopy() *ElasticComponentTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticComponentTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticComponentTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticComponentTemplateSpec) DeepCopyInto(out *ElasticComponentTemplateSpec) {
+	*out = *in
+	if in.TemplateName != nil {
+		in, out := &in.TemplateName, &out.TemplateName
+		*out = new(string)
+		**out = **in
+	}
+	if in.ElasticURI != nil {
+		in, out := &in.ElasticURI, &out.ElasticURI
+		*out = new(ElasticURISource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectionRef != nil {
+		in, out := &in.ConnectionRef, &out.ConnectionRef
+		*out = new(ElasticConnectionReference)
+		**out = **in
+	}
+	if in.Model != nil {
+		in, out := &in.Model, &out.Model
+		*out = new(string)
+		**out = **in
+	}
+	if in.Resync != nil {
+		in, out := &in.Resync, &out.Resync
+		*out = new(ElasticResync)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticComponentTemplateSpec.
+func (in *ElasticComponentTemplateSpec) DeepCopy() *ElasticComponentTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticComponentTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticComposableTemplate) DeepCopyInto(out *ElasticComposableTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticComposableTemplate.
+func (in *ElasticComposableTemplate) DeepCopy() *ElasticComposableTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticComposableTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticComposableTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticComposableTemplateList) DeepCopyInto(out *ElasticComposableTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ElasticComposableTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticComposableTemplateList.
+func (in *ElasticComposableTemplateList) DeepCopy() *ElasticComposableTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticComposableTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticComposableTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticComposableTemplateSpec) DeepCopyInto(out *ElasticComposableTemplateSpec) {
+	*out = *in
+	if in.TemplateName != nil {
+		in, out := &in.TemplateName, &out.TemplateName
+		*out = new(string)
+		**out = **in
+	}
+	if in.ElasticURI != nil {
+		in, out := &in.ElasticURI, &out.ElasticURI
+		*out = new(ElasticURISource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectionRef != nil {
+		in, out := &in.ConnectionRef, &out.ConnectionRef
+		*out = new(ElasticConnectionReference)
+		**out = **in
+	}
+	if in.Priority != nil {
+		in, out := &in.Priority, &out.Priority
+		*out = new(int)
+		**out = **in
+	}
+	if in.ComposedOf != nil {
+		in, out := &in.ComposedOf, &out.ComposedOf
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DataStream != nil {
+		in, out := &in.DataStream, &out.DataStream
+		*out = new(ElasticDataStreamTemplate)
+		**out = **in
+	}
+	if in.Model != nil {
+		in, out := &in.Model, &out.Model
+		*out = new(string)
+		**out = **in
+	}
+	if in.Resync != nil {
+		in, out := &in.Resync, &out.Resync
+		*out = new(ElasticResync)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticComposableTemplateSpec.
+func (in *ElasticComposableTemplateSpec) DeepCopy() *ElasticComposableTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticComposableTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticConnection) DeepCopyInto(out *ElasticConnection) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticConnection.
+func (in *ElasticConnection) DeepCopy() *ElasticConnection {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticConnection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticConnection) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticConnectionList) DeepCopyInto(out *ElasticConnectionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ElasticConnection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticConnectionList.
+func (in *ElasticConnectionList) DeepCopy() *ElasticConnectionList {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticConnectionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticConnectionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticConnectionReference) DeepCopyInto(out *ElasticConnectionReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticConnectionReference.
+func (in *ElasticConnectionReference) DeepCopy() *ElasticConnectionReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticConnectionReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticConnectionSpec) DeepCopyInto(out *ElasticConnectionSpec) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AuthSecretRef != nil {
+		in, out := &in.AuthSecretRef, &out.AuthSecretRef
+		*out = new(v1.LocalObjectReference)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ElasticTLS)
+		**out = **in
+	}
+	if in.Sniffing != nil {
+		in, out := &in.Sniffing, &out.Sniffing
+		*out = new(ElasticSniffing)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeouts != nil {
+		in, out := &in.Timeouts, &out.Timeouts
+		*out = new(ElasticTimeouts)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(ElasticHTTP)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticConnectionSpec.
+func (in *ElasticConnectionSpec) DeepCopy() *ElasticConnectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticConnectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticConnectionStatus) DeepCopyInto(out *ElasticConnectionStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticConnectionStatus.
+func (in *ElasticConnectionStatus) DeepCopy() *ElasticConnectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticConnectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticDataStream) DeepCopyInto(out *ElasticDataStream) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticDataStream.
+func (in *ElasticDataStream) DeepCopy() *ElasticDataStream {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticDataStream)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticDataStream) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticDataStreamLifecycle) DeepCopyInto(out *ElasticDataStreamLifecycle) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticDataStreamLifecycle.
+func (in *ElasticDataStreamLifecycle) DeepCopy() *ElasticDataStreamLifecycle {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticDataStreamLifecycle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticDataStreamList) DeepCopyInto(out *ElasticDataStreamList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ElasticDataStream, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticDataStreamList.
+func (in *ElasticDataStreamList) DeepCopy() *ElasticDataStreamList {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticDataStreamList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticDataStreamList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticDataStreamRollover) DeepCopyInto(out *ElasticDataStreamRollover) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticDataStreamRollover.
+func (in *ElasticDataStreamRollover) DeepCopy() *ElasticDataStreamRollover {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticDataStreamRollover)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticDataStreamSpec) DeepCopyInto(out *ElasticDataStreamSpec) {
+	*out = *in
+	if in.DataStreamName != nil {
+		in, out := &in.DataStreamName, &out.DataStreamName
+		*out = new(string)
+		**out = **in
+	}
+	if in.ElasticURI != nil {
+		in, out := &in.ElasticURI, &out.ElasticURI
+		*out = new(ElasticURISource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectionRef != nil {
+		in, out := &in.ConnectionRef, &out.ConnectionRef
+		*out = new(ElasticConnectionReference)
+		**out = **in
+	}
+	if in.Lifecycle != nil {
+		in, out := &in.Lifecycle, &out.Lifecycle
+		*out = new(ElasticDataStreamLifecycle)
+		**out = **in
+	}
+	if in.Resync != nil {
+		in, out := &in.Resync, &out.Resync
+		*out = new(ElasticResync)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticDataStreamSpec.
+func (in *ElasticDataStreamSpec) DeepCopy() *ElasticDataStreamSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticDataStreamSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticDataStreamStatus) DeepCopyInto(out *ElasticDataStreamStatus) {
+	*out = *in
+	in.ElasticObjectStatus.DeepCopyInto(&out.ElasticObjectStatus)
+	if in.BackingIndices != nil {
+		in, out := &in.BackingIndices, &out.BackingIndices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastRollover != nil {
+		in, out := &in.LastRollover, &out.LastRollover
+		*out = new(ElasticDataStreamRollover)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticDataStreamStatus.
+func (in *ElasticDataStreamStatus) DeepCopy() *ElasticDataStreamStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticDataStreamStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticDataStreamTemplate) DeepCopyInto(out *ElasticDataStreamTemplate) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticDataStreamTemplate.
+func (in *ElasticDataStreamTemplate) DeepCopy() *ElasticDataStreamTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticDataStreamTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticHTTP) DeepCopyInto(out *ElasticHTTP) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticHTTP.
+func (in *ElasticHTTP) DeepCopy() *ElasticHTTP {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticHTTP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticILMPolicy) DeepCopyInto(out *ElasticILMPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticILMPolicy.
+func (in *ElasticILMPolicy) DeepCopy() *ElasticILMPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticILMPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticILMPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticILMPolicyList) DeepCopyInto(out *ElasticILMPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ElasticILMPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticILMPolicyList.
+func (in *ElasticILMPolicyList) DeepCopy() *ElasticILMPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticILMPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticILMPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticILMPolicySpec) DeepCopyInto(out *ElasticILMPolicySpec) {
+	*out = *in
+	if in.PolicyName != nil {
+		in, out := &in.PolicyName, &out.PolicyName
+		*out = new(string)
+		**out = **in
+	}
+	if in.ElasticURI != nil {
+		in, out := &in.ElasticURI, &out.ElasticURI
+		*out = new(ElasticURISource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectionRef != nil {
+		in, out := &in.ConnectionRef, &out.ConnectionRef
+		*out = new(ElasticConnectionReference)
+		**out = **in
+	}
+	if in.Model != nil {
+		in, out := &in.Model, &out.Model
+		*out = new(string)
+		**out = **in
+	}
+	if in.Resync != nil {
+		in, out := &in.Resync, &out.Resync
+		*out = new(ElasticResync)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticILMPolicySpec.
+func (in *ElasticILMPolicySpec) DeepCopy() *ElasticILMPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticILMPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticILMPolicyStatus) DeepCopyInto(out *ElasticILMPolicyStatus) {
+	*out = *in
+	in.ElasticObjectStatus.DeepCopyInto(&out.ElasticObjectStatus)
+	if in.ManagedIndices != nil {
+		in, out := &in.ManagedIndices, &out.ManagedIndices
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticILMPolicyStatus.
+func (in *ElasticILMPolicyStatus) DeepCopy() *ElasticILMPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticILMPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ElasticIndex) DeepCopyInto(out *ElasticIndex) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticIndex.
+func (in *ElasticIndex) DeepCopy() *ElasticIndex {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticIndex)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticIndex) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticIndexAliasStatus) DeepCopyInto(out *ElasticIndexAliasStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticIndexAliasStatus.
+func (in *ElasticIndexAliasStatus) DeepCopy() *ElasticIndexAliasStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticIndexAliasStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticIndexList) DeepCopyInto(out *ElasticIndexList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ElasticIndex, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticIndexList.
+func (in *ElasticIndexList) DeepCopy() *ElasticIndexList {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticIndexList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticIndexList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticIndexMigration) DeepCopyInto(out *ElasticIndexMigration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticIndexMigration.
+func (in *ElasticIndexMigration) DeepCopy() *ElasticIndexMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticIndexMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticIndexMigrationStatus) DeepCopyInto(out *ElasticIndexMigrationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticIndexMigrationStatus.
+func (in *ElasticIndexMigrationStatus) DeepCopy() *ElasticIndexMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticIndexMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticIndexSpec) DeepCopyInto(out *ElasticIndexSpec) {
+	*out = *in
+	if in.IndexName != nil {
+		in, out := &in.IndexName, &out.IndexName
+		*out = new(string)
+		**out = **in
+	}
+	if in.ElasticURI != nil {
+		in, out := &in.ElasticURI, &out.ElasticURI
+		*out = new(ElasticURISource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectionRef != nil {
+		in, out := &in.ConnectionRef, &out.ConnectionRef
+		*out = new(ElasticConnectionReference)
+		**out = **in
+	}
+	if in.NumberOfShards != nil {
+		in, out := &in.NumberOfShards, &out.NumberOfShards
+		*out = new(int32)
+		**out = **in
+	}
+	if in.NumberOfReplicas != nil {
+		in, out := &in.NumberOfReplicas, &out.NumberOfReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Model != nil {
+		in, out := &in.Model, &out.Model
+		*out = new(string)
+		**out = **in
+	}
+	if in.Migration != nil {
+		in, out := &in.Migration, &out.Migration
+		*out = new(ElasticIndexMigration)
+		**out = **in
+	}
+	if in.Resync != nil {
+		in, out := &in.Resync, &out.Resync
+		*out = new(ElasticResync)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticIndexSpec.
+func (in *ElasticIndexSpec) DeepCopy() *ElasticIndexSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticIndexSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticIndexStatus) DeepCopyInto(out *ElasticIndexStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Cluster != nil {
+		in, out := &in.Cluster, &out.Cluster
+		*out = new(ElasticClusterReference)
+		**out = **in
+	}
+	if in.Migration != nil {
+		in, out := &in.Migration, &out.Migration
+		*out = new(ElasticIndexMigrationStatus)
+		**out = **in
+	}
+	if in.Aliases != nil {
+		in, out := &in.Aliases, &out.Aliases
+		*out = make([]ElasticIndexAliasStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticIndexStatus.
+func (in *ElasticIndexStatus) DeepCopy() *ElasticIndexStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticIndexStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticIngestPipeline) DeepCopyInto(out *ElasticIngestPipeline) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticIngestPipeline.
+func (in *ElasticIngestPipeline) DeepCopy() *ElasticIngestPipeline {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticIngestPipeline)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticIngestPipeline) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticIngestPipelineList) DeepCopyInto(out *ElasticIngestPipelineList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ElasticIngestPipeline, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticIngestPipelineList.
+func (in *ElasticIngestPipelineList) DeepCopy() *ElasticIngestPipelineList {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticIngestPipelineList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticIngestPipelineList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticIngestPipelineSpec) DeepCopyInto(out *ElasticIngestPipelineSpec) {
+	*out = *in
+	if in.PipelineName != nil {
+		in, out := &in.PipelineName, &out.PipelineName
+		*out = new(string)
+		**out = **in
+	}
+	if in.ElasticURI != nil {
+		in, out := &in.ElasticURI, &out.ElasticURI
+		*out = new(ElasticURISource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectionRef != nil {
+		in, out := &in.ConnectionRef, &out.ConnectionRef
+		*out = new(ElasticConnectionReference)
+		**out = **in
+	}
+	if in.Model != nil {
+		in, out := &in.Model, &out.Model
+		*out = new(string)
+		**out = **in
+	}
+	if in.SampleDocuments != nil {
+		in, out := &in.SampleDocuments, &out.SampleDocuments
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Resync != nil {
+		in, out := &in.Resync, &out.Resync
+		*out = new(ElasticResync)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticIngestPipelineSpec.
+func (in *ElasticIngestPipelineSpec) DeepCopy() *ElasticIngestPipelineSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticIngestPipelineSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticIngestPipelineStatus) DeepCopyInto(out *ElasticIngestPipelineStatus) {
+	*out = *in
+	in.ElasticObjectStatus.DeepCopyInto(&out.ElasticObjectStatus)
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(int64)
+		**out = **in
+	}
+	if in.LastSimulation != nil {
+		in, out := &in.LastSimulation, &out.LastSimulation
+		*out = new(ElasticPipelineSimulation)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticIngestPipelineStatus.
+func (in *ElasticIngestPipelineStatus) DeepCopy() *ElasticIngestPipelineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticIngestPipelineStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticObjectStatus) DeepCopyInto(out *ElasticObjectStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Cluster != nil {
+		in, out := &in.Cluster, &out.Cluster
+		*out = new(ElasticClusterReference)
+		**out = **in
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticObjectStatus.
+func (in *ElasticObjectStatus) DeepCopy() *ElasticObjectStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticObjectStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticPipelineSimulation) DeepCopyInto(out *ElasticPipelineSimulation) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Failures != nil {
+		in, out := &in.Failures, &out.Failures
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticPipelineSimulation.
+func (in *ElasticPipelineSimulation) DeepCopy() *ElasticPipelineSimulation {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticPipelineSimulation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticResync) DeepCopyInto(out *ElasticResync) {
+	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Repair != nil {
+		in, out := &in.Repair, &out.Repair
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticResync.
+func (in *ElasticResync) DeepCopy() *ElasticResync {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticResync)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticSLMPolicy) DeepCopyInto(out *ElasticSLMPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticSLMPolicy.
+func (in *ElasticSLMPolicy) DeepCopy() *ElasticSLMPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticSLMPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticSLMPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticSLMPolicyList) DeepCopyInto(out *ElasticSLMPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ElasticSLMPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticSLMPolicyList.
+func (in *ElasticSLMPolicyList) DeepCopy() *ElasticSLMPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticSLMPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticSLMPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticSLMPolicySpec) DeepCopyInto(out *ElasticSLMPolicySpec) {
+	*out = *in
+	if in.PolicyName != nil {
+		in, out := &in.PolicyName, &out.PolicyName
+		*out = new(string)
+		**out = **in
+	}
+	if in.ElasticURI != nil {
+		in, out := &in.ElasticURI, &out.ElasticURI
+		*out = new(ElasticURISource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectionRef != nil {
+		in, out := &in.ConnectionRef, &out.ConnectionRef
+		*out = new(ElasticConnectionReference)
+		**out = **in
+	}
+	if in.Model != nil {
+		in, out := &in.Model, &out.Model
+		*out = new(string)
+		**out = **in
+	}
+	if in.Resync != nil {
+		in, out := &in.Resync, &out.Resync
+		*out = new(ElasticResync)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticSLMPolicySpec.
+func (in *ElasticSLMPolicySpec) DeepCopy() *ElasticSLMPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticSLMPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticSLMPolicyStatus) DeepCopyInto(out *ElasticSLMPolicyStatus) {
+	*out = *in
+	in.ElasticObjectStatus.DeepCopyInto(&out.ElasticObjectStatus)
+	if in.LastSuccess != nil {
+		in, out := &in.LastSuccess, &out.LastSuccess
+		*out = new(ElasticSnapshotExecution)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastFailure != nil {
+		in, out := &in.LastFailure, &out.LastFailure
+		*out = new(ElasticSnapshotExecution)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NextExecution != nil {
+		in, out := &in.NextExecution, &out.NextExecution
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticSLMPolicyStatus.
+func (in *ElasticSLMPolicyStatus) DeepCopy() *ElasticSLMPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticSLMPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticSecretGrant) DeepCopyInto(out *ElasticSecretGrant) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticIndex.
-func (in *ElasticIndex) DeepCopy() *ElasticIndex {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticSecretGrant.
+func (in *ElasticSecretGrant) DeepCopy() *ElasticSecretGrant {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticIndex)
+	out := new(ElasticSecretGrant)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ElasticIndex) DeepCopyObject() runtime.Object {
+func (in *ElasticSecretGrant) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -54,31 +1371,46 @@ func (in *ElasticIndex) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticIndexList) DeepCopyInto(out *ElasticIndexList) {
+func (in *ElasticSecretGrantFrom) DeepCopyInto(out *ElasticSecretGrantFrom) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticSecretGrantFrom.
+func (in *ElasticSecretGrantFrom) DeepCopy() *ElasticSecretGrantFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticSecretGrantFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticSecretGrantList) DeepCopyInto(out *ElasticSecretGrantList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]ElasticIndex, len(*in))
+		*out = make([]ElasticSecretGrant, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticIndexList.
-func (in *ElasticIndexList) DeepCopy() *ElasticIndexList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticSecretGrantList.
+func (in *ElasticSecretGrantList) DeepCopy() *ElasticSecretGrantList {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticIndexList)
+	out := new(ElasticSecretGrantList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *ElasticIndexList) DeepCopyObject() runtime.Object {
+func (in *ElasticSecretGrantList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -86,52 +1418,218 @@ func (in *ElasticIndexList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticIndexSpec) DeepCopyInto(out *ElasticIndexSpec) {
+func (in *ElasticSecretGrantSpec) DeepCopyInto(out *ElasticSecretGrantSpec) {
 	*out = *in
-	if in.IndexName != nil {
-		in, out := &in.IndexName, &out.IndexName
+	if in.From != nil {
+		in, out := &in.From, &out.From
+		*out = make([]ElasticSecretGrantFrom, len(*in))
+		copy(*out, *in)
+	}
+	if in.To != nil {
+		in, out := &in.To, &out.To
+		*out = make([]ElasticSecretGrantTo, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticSecretGrantSpec.
+func (in *ElasticSecretGrantSpec) DeepCopy() *ElasticSecretGrantSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticSecretGrantSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticSecretGrantTo) DeepCopyInto(out *ElasticSecretGrantTo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticSecretGrantTo.
+func (in *ElasticSecretGrantTo) DeepCopy() *ElasticSecretGrantTo {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticSecretGrantTo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticSecretSetting) DeepCopyInto(out *ElasticSecretSetting) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticSecretSetting.
+func (in *ElasticSecretSetting) DeepCopy() *ElasticSecretSetting {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticSecretSetting)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticSnapshotExecution) DeepCopyInto(out *ElasticSnapshotExecution) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticSnapshotExecution.
+func (in *ElasticSnapshotExecution) DeepCopy() *ElasticSnapshotExecution {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticSnapshotExecution)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticSnapshotRepository) DeepCopyInto(out *ElasticSnapshotRepository) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticSnapshotRepository.
+func (in *ElasticSnapshotRepository) DeepCopy() *ElasticSnapshotRepository {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticSnapshotRepository)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticSnapshotRepository) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticSnapshotRepositoryList) DeepCopyInto(out *ElasticSnapshotRepositoryList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ElasticSnapshotRepository, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticSnapshotRepositoryList.
+func (in *ElasticSnapshotRepositoryList) DeepCopy() *ElasticSnapshotRepositoryList {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticSnapshotRepositoryList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ElasticSnapshotRepositoryList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticSnapshotRepositorySpec) DeepCopyInto(out *ElasticSnapshotRepositorySpec) {
+	*out = *in
+	if in.RepositoryName != nil {
+		in, out := &in.RepositoryName, &out.RepositoryName
 		*out = new(string)
 		**out = **in
 	}
-	in.ElasticURI.DeepCopyInto(&out.ElasticURI)
-	if in.NumberOfShards != nil {
-		in, out := &in.NumberOfShards, &out.NumberOfShards
-		*out = new(int32)
-		**out = **in
+	if in.ElasticURI != nil {
+		in, out := &in.ElasticURI, &out.ElasticURI
+		*out = new(ElasticURISource)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.NumberOfReplicas != nil {
-		in, out := &in.NumberOfReplicas, &out.NumberOfReplicas
-		*out = new(int32)
+	if in.ConnectionRef != nil {
+		in, out := &in.ConnectionRef, &out.ConnectionRef
+		*out = new(ElasticConnectionReference)
 		**out = **in
 	}
-	if in.Model != nil {
-		in, out := &in.Model, &out.Model
+	if in.Settings != nil {
+		in, out := &in.Settings, &out.Settings
 		*out = new(string)
 		**out = **in
 	}
+	if in.SecretSettings != nil {
+		in, out := &in.SecretSettings, &out.SecretSettings
+		*out = make([]ElasticSecretSetting, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resync != nil {
+		in, out := &in.Resync, &out.Resync
+		*out = new(ElasticResync)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticIndexSpec.
-func (in *ElasticIndexSpec) DeepCopy() *ElasticIndexSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticSnapshotRepositorySpec.
+func (in *ElasticSnapshotRepositorySpec) DeepCopy() *ElasticSnapshotRepositorySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticIndexSpec)
+	out := new(ElasticSnapshotRepositorySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ElasticIndexStatus) DeepCopyInto(out *ElasticIndexStatus) {
+func (in *ElasticSniffing) DeepCopyInto(out *ElasticSniffing) {
 	*out = *in
+	if in.Interval != nil {
+		in, out := &in.Interval, &out.Interval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticIndexStatus.
-func (in *ElasticIndexStatus) DeepCopy() *ElasticIndexStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticSniffing.
+func (in *ElasticSniffing) DeepCopy() *ElasticSniffing {
 	if in == nil {
 		return nil
 	}
-	out := new(ElasticIndexStatus)
+	out := new(ElasticSniffing)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticTLS) DeepCopyInto(out *ElasticTLS) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticTLS.
+func (in *ElasticTLS) DeepCopy() *ElasticTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticTLS)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -142,7 +1640,7 @@ func (in *ElasticTemplate) DeepCopyInto(out *ElasticTemplate) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticTemplate.
@@ -203,7 +1701,16 @@ func (in *ElasticTemplateSpec) DeepCopyInto(out *ElasticTemplateSpec) {
 		*out = new(string)
 		**out = **in
 	}
-	in.ElasticURI.DeepCopyInto(&out.ElasticURI)
+	if in.ElasticURI != nil {
+		in, out := &in.ElasticURI, &out.ElasticURI
+		*out = new(ElasticURISource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConnectionRef != nil {
+		in, out := &in.ConnectionRef, &out.ConnectionRef
+		*out = new(ElasticConnectionReference)
+		**out = **in
+	}
 	if in.NumberOfShards != nil {
 		in, out := &in.NumberOfShards, &out.NumberOfShards
 		*out = new(int32)
@@ -224,6 +1731,11 @@ func (in *ElasticTemplateSpec) DeepCopyInto(out *ElasticTemplateSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Resync != nil {
+		in, out := &in.Resync, &out.Resync
+		*out = new(ElasticResync)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticTemplateSpec.
@@ -239,6 +1751,26 @@ func (in *ElasticTemplateSpec) DeepCopy() *ElasticTemplateSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ElasticTemplateStatus) DeepCopyInto(out *ElasticTemplateStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Cluster != nil {
+		in, out := &in.Cluster, &out.Cluster
+		*out = new(ElasticClusterReference)
+		**out = **in
+	}
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticTemplateStatus.
@@ -251,6 +1783,26 @@ func (in *ElasticTemplateStatus) DeepCopy() *ElasticTemplateStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ElasticTimeouts) DeepCopyInto(out *ElasticTimeouts) {
+	*out = *in
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticTimeouts.
+func (in *ElasticTimeouts) DeepCopy() *ElasticTimeouts {
+	if in == nil {
+		return nil
+	}
+	out := new(ElasticTimeouts)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ElasticURISource) DeepCopyInto(out *ElasticURISource) {
 	*out = *in
@@ -259,6 +1811,26 @@ func (in *ElasticURISource) DeepCopyInto(out *ElasticURISource) {
 		*out = new(v1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ElasticTLS)
+		**out = **in
+	}
+	if in.Sniffing != nil {
+		in, out := &in.Sniffing, &out.Sniffing
+		*out = new(ElasticSniffing)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(ElasticHTTP)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Timeouts != nil {
+		in, out := &in.Timeouts, &out.Timeouts
+		*out = new(ElasticTimeouts)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ElasticURISource.
@@ -285,3 +1857,18 @@ func (in *EsObjectInfo) DeepCopy() *EsObjectInfo {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretNotGrantedError) DeepCopyInto(out *SecretNotGrantedError) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretNotGrantedError.
+func (in *SecretNotGrantedError) DeepCopy() *SecretNotGrantedError {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretNotGrantedError)
+	in.DeepCopyInto(out)
+	return out
+}
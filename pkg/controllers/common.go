@@ -15,19 +15,438 @@ limitations under the License.
 package controllers
 
 import (
+	"context"
+	"fmt"
+	elasticv1alpha1 "github.com/Carrefour-Group/elastic-phenix-operator/pkg/api/v1alpha1"
 	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"net/http"
+	"reflect"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"strconv"
+	"strings"
 	"time"
 )
 
 const (
-	RetryInterval             time.Duration = time.Second * 30
-	ErrorInterval             time.Duration = time.Minute * 5
+	// DefaultRetryInterval and DefaultErrorInterval are the default delays after a first Retry or Error status, doubled
+	// on each consecutive failure up to DefaultMaxRetryInterval
+	DefaultRetryInterval      time.Duration = time.Second * 30
+	DefaultErrorInterval      time.Duration = time.Minute * 5
+	DefaultMaxRetryInterval   time.Duration = time.Minute * 10
 	DeleteInClusterAnnotation               = "carrefour.com/delete-in-cluster"
+	// RolloverAnnotation requests a rollover of an ElasticDataStream each time its value changes, like a timestamp
+	RolloverAnnotation = "carrefour.com/rollover"
+
+	// field index of objects by the namespace and name of their elasticURI and TLS secrets, like elasticsearch/credentials
+	elasticURISecretField = ".spec.elasticURI.secretKeyRef.name"
+	// field index of objects by the namespace of their elasticURI secrets, when read from another namespace
+	elasticURINamespaceField = ".spec.elasticURI.namespace"
+	// field index of objects by the kind and name of their connectionRef, like ElasticConnection/production
+	connectionRefField = ".spec.connectionRef"
+	// field index of connections by the names of their auth and TLS secrets, prefixed by the secret namespace for
+	// ClusterElasticConnection
+	connectionSecretField = ".spec.secretNames"
+
+	ReasonNoDrift       = "NoDrift"
+	ReasonDriftDetected = "DriftDetected"
+	ReasonDriftRepaired = "DriftRepaired"
+
+	// Event reasons, in addition to utils operations used as reasons of Normal events
+	EventReasonDeleteSkipped            = "DeleteSkipped"
+	EventReasonDeleted                  = "Deleted"
+	EventReasonDeleteFailed             = "DeleteFailed"
+	EventReasonElasticsearchUnreachable = "ElasticsearchUnreachable"
+	EventReasonElasticsearchError       = "ElasticsearchError"
+	EventReasonValidationFailed         = "ValidationFailed"
+	EventReasonRequestRejected          = "RequestRejected"
+	EventReasonApplyFailed              = "ApplyFailed"
 )
 
-func buildElasticsearchFromVersion(version int) utils.Elasticsearch {
-	if version == 8 {
-		return &utils.Elasticsearch8{}
+var operationMessages = map[string]string{
+	utils.OperationIndexCreated:         "index %v created",
+	utils.OperationReplicasUpdated:      "number_of_replicas of index %v updated",
+	utils.OperationSettingsUpdated:      "settings of index %v updated",
+	utils.OperationMappingUpdated:       "mapping of index %v updated",
+	utils.OperationAliasesUpdated:       "aliases of index %v updated",
+	utils.OperationTemplatePut:          "template %v put",
+	utils.OperationIsmPolicyAttached:    "ISM policy attached to index %v",
+	utils.OperationIndexTemplatePut:     "index template %v put",
+	utils.OperationComponentTemplatePut: "component template %v put",
+	utils.OperationILMPolicyPut:         "ILM policy %v put",
+	utils.OperationIngestPipelinePut:    "ingest pipeline %v put",
+	utils.OperationDataStreamCreated:    "data stream %v created",
+	utils.OperationDataLifecyclePut:     "lifecycle of data stream %v put",
+	utils.OperationRolledOver:           "data stream %v rolled over",
+	utils.OperationRepositoryPut:        "snapshot repository %v put",
+	utils.OperationSLMPolicyPut:         "SLM policy %v put",
+}
+
+// buildEsConfig returns the elasticsearch config from the connectionRef or the elasticURI secret of an object, with
+// the resourceVersion of the kubernetes objects it is read from. The transport and the version of the connection are
+// shared through the registry, where the object is its owner
+func buildEsConfig(owner string, namespace string, elasticURI *elasticv1alpha1.ElasticURISource, connectionRef *elasticv1alpha1.ElasticConnectionReference,
+	k8sClient client.Client, esClients *utils.EsClientRegistry) (*utils.EsConfig, string, error) {
+	connection, err := elasticv1alpha1.GetEsConnection(namespace, elasticURI, connectionRef, k8sClient)
+	if err != nil {
+		return nil, "", err
+	}
+	esConfig, err := esClients.Connect(connection, owner)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := connection.CheckVersion(esConfig); err != nil {
+		return nil, "", err
+	}
+	return esConfig, connection.ResourceVersion, nil
+}
+
+// registryOwner identifies an object of kind in the EsClientRegistry, like ElasticIndex/<namespace>/<name>
+func registryOwner(kind string, name types.NamespacedName) string {
+	return kind + "/" + name.String()
+}
+
+// deletableObject is an object whose finalizer deletes its elasticsearch object
+type deletableObject interface {
+	runtime.Object
+	metav1.Object
+}
+
+// releaseUngrantedObject removes the finalizer of an object being deleted whose connection cannot be read because its
+// secrets are no longer granted, as the webhook allows its deletion. Its elasticsearch object of esKind is kept, the
+// cluster being unreachable without the connection. It returns true when the object was being deleted and released
+func releaseUngrantedObject(ctx context.Context, k8sClient client.Client, recorder record.EventRecorder, object deletableObject, esKind string, connectionErr error) (bool, error) {
+	if _, ok := connectionErr.(*elasticv1alpha1.SecretNotGrantedError); !ok || object.GetDeletionTimestamp().IsZero() {
+		return false, nil
+	}
+	finalizerName := fmt.Sprintf("finalizer.%v", elasticv1alpha1.GroupVersion.Group)
+	if !utils.ContainsString(object.GetFinalizers(), finalizerName) {
+		return true, nil
+	}
+	recorder.Eventf(object, v1.EventTypeWarning, EventReasonDeleteSkipped, "%v kept in elasticsearch, the connection cannot be read: %v", esKind, connectionErr)
+	driftedObjects.DeleteLabelValues(esKind, object.GetNamespace(), object.GetName())
+	object.SetFinalizers(utils.RemoveString(object.GetFinalizers(), finalizerName))
+	return true, k8sClient.Update(ctx, object)
+}
+
+// connectionKey is a connection, and the namespace of the objects that can reference it, empty for all namespaces
+type connectionKey struct {
+	namespace string
+	ref       string
+}
+
+// connectionsForSecret returns the connections using a secret for authentication or TLS
+func connectionsForSecret(k8sClient client.Client, namespace string, name string) ([]connectionKey, error) {
+	var keys []connectionKey
+	var connections elasticv1alpha1.ElasticConnectionList
+	if err := k8sClient.List(context.Background(), &connections, client.InNamespace(namespace), client.MatchingFields{connectionSecretField: name}); err != nil {
+		return nil, err
+	}
+	for _, item := range connections.Items {
+		keys = append(keys, connectionKey{namespace: item.Namespace, ref: (&elasticv1alpha1.ElasticConnectionReference{Kind: elasticv1alpha1.ElasticConnectionKind, Name: item.Name}).String()})
+	}
+	var clusterConnections elasticv1alpha1.ClusterElasticConnectionList
+	if err := k8sClient.List(context.Background(), &clusterConnections, client.MatchingFields{connectionSecretField: namespace + "/" + name}); err != nil {
+		return nil, err
+	}
+	for _, item := range clusterConnections.Items {
+		keys = append(keys, connectionKey{ref: (&elasticv1alpha1.ElasticConnectionReference{Kind: elasticv1alpha1.ClusterElasticConnectionKind, Name: item.Name}).String()})
+	}
+	return keys, nil
+}
+
+// connectionKeyOf returns the key of an ElasticConnection or a ClusterElasticConnection
+func connectionKeyOf(object handler.MapObject) connectionKey {
+	if object.Meta.GetNamespace() == "" {
+		return connectionKey{ref: (&elasticv1alpha1.ElasticConnectionReference{Kind: elasticv1alpha1.ClusterElasticConnectionKind, Name: object.Meta.GetName()}).String()}
+	}
+	return connectionKey{namespace: object.Meta.GetNamespace(), ref: (&elasticv1alpha1.ElasticConnectionReference{Kind: elasticv1alpha1.ElasticConnectionKind, Name: object.Meta.GetName()}).String()}
+}
+
+// connectionChangedHandler enqueues the objects using a connection, ignoring the updates that do not change its
+// generation, like the status updates of its probes
+type connectionChangedHandler struct {
+	handler.EventHandler
+}
+
+// Update implements handler.EventHandler
+func (h *connectionChangedHandler) Update(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	if (predicate.GenerationChangedPredicate{}).Update(e) {
+		h.EventHandler.Update(e, q)
+	}
+}
+
+// statusUpdateFilter ignores the updates of the reconciled objects, of the type of object, that only change their status,
+// like the retry bookkeeping, so that writing the status does not trigger a reconciliation before the requeue delay.
+// Changes of the generation, the annotations, the finalizers and the deletion timestamp are kept, and the events of the
+// other watched kinds are not filtered
+func statusUpdateFilter(object runtime.Object) predicate.Funcs {
+	objectType := reflect.TypeOf(object)
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if reflect.TypeOf(e.ObjectNew) != objectType {
+				return true
+			}
+			return e.MetaNew.GetGeneration() != e.MetaOld.GetGeneration() ||
+				!reflect.DeepEqual(e.MetaNew.GetAnnotations(), e.MetaOld.GetAnnotations()) ||
+				!reflect.DeepEqual(e.MetaNew.GetFinalizers(), e.MetaOld.GetFinalizers()) ||
+				!e.MetaNew.GetDeletionTimestamp().Equal(e.MetaOld.GetDeletionTimestamp())
+		},
+	}
+}
+
+// namespacesRegexFilter ignores the events of the reconciled objects, of the type of object, whose namespace does not
+// match regex. The events of the other watched kinds are not filtered: secrets, grants and connections of any namespace
+// are mapped to the reconciled objects referencing them, whose namespace the map funcs do not check
+func namespacesRegexFilter(object runtime.Object, regex string, log logr.Logger) predicate.Funcs {
+	objectType := reflect.TypeOf(object)
+	filter := func(object runtime.Object, meta metav1.Object) bool {
+		return reflect.TypeOf(object) != objectType || utils.FilterByNamespacesRegex(meta, regex, log)
+	}
+	return predicate.Funcs{
+		CreateFunc:  func(e event.CreateEvent) bool { return filter(e.Object, e.Meta) },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return filter(e.Object, e.Meta) },
+		UpdateFunc:  func(e event.UpdateEvent) bool { return filter(e.ObjectNew, e.MetaNew) },
+		GenericFunc: func(e event.GenericEvent) bool { return filter(e.Object, e.Meta) },
+	}
+}
+
+// indexElasticURISecrets returns the field index values of the secrets of an elasticURI, as namespace/name
+func indexElasticURISecrets(namespace string, elasticURI *elasticv1alpha1.ElasticURISource) []string {
+	var values []string
+	for _, name := range elasticURI.GetSecretNames() {
+		values = append(values, elasticURI.GetSecretNamespace(namespace)+"/"+name)
+	}
+	return values
+}
+
+// indexModelSecrets returns the field index values of the secrets read in the model of an object, as namespace/name
+func indexModelSecrets(object elasticv1alpha1.ElasticObject) []string {
+	withSecrets, ok := object.(elasticv1alpha1.ElasticObjectWithSecrets)
+	if !ok {
+		return nil
+	}
+	var values []string
+	for _, name := range withSecrets.GetModelSecretNames() {
+		values = append(values, object.GetNamespace()+"/"+name)
+	}
+	return values
+}
+
+// buildModel returns the model of an object, completed with the values of its secrets for the kinds reading them
+func buildModel(object elasticv1alpha1.ElasticObject, k8sClient client.Client) (string, error) {
+	if withSecrets, ok := object.(elasticv1alpha1.ElasticObjectWithSecrets); ok {
+		return withSecrets.GetModelWithSecrets(k8sClient)
+	}
+	return object.GetModel()
+}
+
+// indexElasticURINamespace returns the field index value of the namespace of the secrets of an elasticURI, when they
+// are read from another namespace and need an ElasticSecretGrant
+func indexElasticURINamespace(namespace string, elasticURI *elasticv1alpha1.ElasticURISource) []string {
+	if elasticURI == nil || elasticURI.GetSecretNamespace(namespace) == namespace {
+		return nil
+	}
+	return []string{elasticURI.GetSecretNamespace(namespace)}
+}
+
+// indexConnectionRef returns the field index value of a connectionRef
+func indexConnectionRef(connectionRef *elasticv1alpha1.ElasticConnectionReference) []string {
+	if connectionRef == nil {
+		return nil
+	}
+	return []string{connectionRef.String()}
+}
+
+// buildClusterReference returns the reference of the elasticsearch cluster a model is applied to
+func buildClusterReference(esConfig *utils.EsConfig, secretResourceVersion string) *elasticv1alpha1.ElasticClusterReference {
+	return &elasticv1alpha1.ElasticClusterReference{URL: esConfig.String(), UUID: esConfig.ClusterUUID, Version: esConfig.Version,
+		Distribution: esConfig.Distribution, Backend: esConfig.Backend, SecretResourceVersion: secretResourceVersion}
+}
+
+// isSameCluster returns true when the model was applied with the same elasticURI secret
+func isSameCluster(cluster *elasticv1alpha1.ElasticClusterReference, esConfig *utils.EsConfig, secretResourceVersion string) bool {
+	return cluster != nil && cluster.URL == esConfig.String() && cluster.SecretResourceVersion == secretResourceVersion
+}
+
+// recordEsStatusEvents records a Normal event for each change made in elasticsearch, and a Warning event when the
+// model was rejected or elasticsearch failed
+func recordEsStatusEvents(recorder record.EventRecorder, object runtime.Object, name string, esStatus *utils.EsStatus) {
+	for _, operation := range esStatus.Operations {
+		recorder.Eventf(object, v1.EventTypeNormal, operation, operationMessages[operation]+" (http %v)", name, esStatus.HttpCodeStatus)
+	}
+	if esStatus.Status == utils.StatusError || esStatus.Status == utils.StatusRetry {
+		recorder.Event(object, v1.EventTypeWarning, esStatusEventReason(esStatus), describeEsStatus(esStatus))
+	}
+}
+
+// esStatusEventReason returns the reason of the Warning event of a failed elasticsearch status: ValidationFailed when
+// elasticsearch rejects the model with a 400 status, RequestRejected for the other 4xx statuses, ElasticsearchError for
+// 5xx statuses and retried failures, and ApplyFailed when the model could not be applied without http status
+func esStatusEventReason(esStatus *utils.EsStatus) string {
+	if esStatus.Status == utils.StatusRetry {
+		return EventReasonElasticsearchError
+	}
+	switch code, _ := strconv.Atoi(esStatus.HttpCodeStatus); {
+	case code == http.StatusBadRequest:
+		return EventReasonValidationFailed
+	case code > http.StatusBadRequest && code < http.StatusInternalServerError:
+		return EventReasonRequestRejected
+	case code >= http.StatusInternalServerError:
+		return EventReasonElasticsearchError
+	default:
+		return EventReasonApplyFailed
+	}
+}
+
+// describeEsStatus returns the http status and the reason of an elasticsearch status
+func describeEsStatus(esStatus *utils.EsStatus) string {
+	if esStatus.HttpCodeStatus == "" {
+		return esStatus.Reason()
+	}
+	return fmt.Sprintf("http %v: %v", esStatus.HttpCodeStatus, esStatus.Reason())
+}
+
+// statusConditionsUpdated sets Ready, Reconciling and Degraded conditions from the elasticsearch status, the status value
+// being the condition reason. It returns true when conditions are updated
+func statusConditionsUpdated(conditions *[]elasticv1alpha1.Condition, esStatus *utils.EsStatus, generation int64) bool {
+	ready, reconciling, degraded := v1.ConditionFalse, v1.ConditionFalse, v1.ConditionFalse
+	message := esStatus.Message
+	switch esStatus.Status {
+	case utils.StatusCreated:
+		ready, message = v1.ConditionTrue, ""
+	case utils.StatusMigrating:
+		reconciling = v1.ConditionTrue
+	case utils.StatusRetry:
+		reconciling, degraded = v1.ConditionTrue, v1.ConditionTrue
+	default:
+		degraded = v1.ConditionTrue
+	}
+
+	updated := false
+	for _, condition := range []elasticv1alpha1.Condition{
+		{Type: elasticv1alpha1.ConditionReady, Status: ready},
+		{Type: elasticv1alpha1.ConditionReconciling, Status: reconciling},
+		{Type: elasticv1alpha1.ConditionDegraded, Status: degraded},
+	} {
+		condition.ObservedGeneration, condition.Reason, condition.Message = generation, esStatus.Status, message
+		updated = elasticv1alpha1.SetCondition(conditions, condition) || updated
+	}
+	return updated
+}
+
+// isModelApplied returns true when the model of the given generation was applied successfully
+func isModelApplied(conditions []elasticv1alpha1.Condition, generation int64) bool {
+	ready := elasticv1alpha1.FindCondition(conditions, elasticv1alpha1.ConditionReady)
+	return ready != nil && ready.Status == v1.ConditionTrue && ready.ObservedGeneration == generation
+}
+
+// nextResync returns the duration before the next resync, or 0 when a resync is due
+func nextResync(lastSyncTime *metav1.Time, interval time.Duration) time.Duration {
+	if lastSyncTime == nil {
+		return 0
+	}
+	if wait := time.Until(lastSyncTime.Add(interval)); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// resyncOptions returns the resync interval and whether a drift is repaired, from the object spec or from the operator flags
+func resyncOptions(resync *elasticv1alpha1.ElasticResync, defaultInterval time.Duration, defaultRepair bool) (time.Duration, bool) {
+	interval, repair := defaultInterval, defaultRepair
+	if resync != nil && resync.Interval != nil {
+		interval = resync.Interval.Duration
+	}
+	if resync != nil && resync.Repair != nil {
+		repair = *resync.Repair
+	}
+	return interval, repair
+}
+
+// RetryOptions are the delays before applying a model again after a failure. They are doubled on each consecutive
+// failure of an object, and spread by a jitter so that the objects of an unreachable cluster do not retry in lockstep
+type RetryOptions struct {
+	// RetryInterval is the delay after a first Retry status, DefaultRetryInterval when 0
+	RetryInterval time.Duration
+	// ErrorInterval is the delay after a first Error status, DefaultErrorInterval when 0
+	ErrorInterval time.Duration
+	// MaxInterval caps the delays, DefaultMaxRetryInterval when 0
+	MaxInterval time.Duration
+}
+
+// getRetryInterval returns the delay after a first Retry status, also used to follow a migration
+func (o RetryOptions) getRetryInterval() time.Duration {
+	if o.RetryInterval <= 0 {
+		return DefaultRetryInterval
+	}
+	return o.RetryInterval
+}
+
+// backoff returns the backoff of the failures of status, Retry or Error
+func (o RetryOptions) backoff(status string) utils.Backoff {
+	backoff := utils.Backoff{Base: o.getRetryInterval(), Max: o.MaxInterval, Jitter: utils.DefaultBackoffJitter}
+	if status == utils.StatusError {
+		if backoff.Base = o.ErrorInterval; backoff.Base <= 0 {
+			backoff.Base = DefaultErrorInterval
+		}
+	}
+	if backoff.Max <= 0 {
+		backoff.Max = DefaultMaxRetryInterval
+	}
+	return backoff
+}
+
+// scheduleRetry counts the consecutive failures of an object whose status is Retry or Error, sets the time of its next
+// attempt and returns the delay before it. The failures are reset on other statuses, the delay being 0. updated is
+// true when retries or nextRetryTime changed
+func (o RetryOptions) scheduleRetry(status string, retries *int32, nextRetryTime **metav1.Time) (delay time.Duration, updated bool) {
+	if status != utils.StatusRetry && status != utils.StatusError {
+		updated = *retries != 0 || *nextRetryTime != nil
+		*retries, *nextRetryTime = 0, nil
+		return 0, updated
+	}
+	*retries++
+	delay = o.backoff(status).Delay(*retries)
+	next := metav1.NewTime(time.Now().Add(delay))
+	*nextRetryTime = &next
+	return delay, true
+}
+
+// buildDriftedCondition returns the Drifted condition of a resync, the drift being repaired or not
+func buildDriftedCondition(drift *utils.EsDrift, repaired bool, generation int64) elasticv1alpha1.Condition {
+	condition := elasticv1alpha1.Condition{Type: elasticv1alpha1.ConditionDrifted, ObservedGeneration: generation}
+	switch {
+	case !drift.IsDrifted():
+		condition.Status, condition.Reason = v1.ConditionFalse, ReasonNoDrift
+	case repaired:
+		condition.Status, condition.Reason, condition.Message = v1.ConditionFalse, ReasonDriftRepaired, strings.Join(drift.Reasons, "; ")
+	default:
+		condition.Status, condition.Reason, condition.Message = v1.ConditionTrue, ReasonDriftDetected, strings.Join(drift.Reasons, "; ")
+	}
+	return condition
+}
+
+// recordDrift updates drift metrics of an object
+func recordDrift(kind string, namespace string, name string, drift *utils.EsDrift, repaired bool) {
+	if drift.IsDrifted() {
+		driftDetectedTotal.WithLabelValues(kind).Inc()
+	}
+	if repaired {
+		driftRepairedTotal.WithLabelValues(kind).Inc()
+	}
+	if drift.IsDrifted() && !repaired {
+		driftedObjects.WithLabelValues(kind, namespace, name).Set(1)
+	} else {
+		driftedObjects.WithLabelValues(kind, namespace, name).Set(0)
 	}
-	return &utils.Elasticsearch7{}
 }
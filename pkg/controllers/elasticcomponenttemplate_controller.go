@@ -0,0 +1,53 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+
+	elasticv1alpha1 "github.com/Carrefour-Group/elastic-phenix-operator/pkg/api/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticcomponenttemplates,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticcomponenttemplates/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticcomponenttemplates/finalizers,verbs=get;list;watch;create;update;patch;delete
+
+// ElasticComponentTemplateKind applies ElasticComponentTemplates with the _component_template API
+var ElasticComponentTemplateKind = ElasticObjectKind{
+	Name:         "ElasticComponentTemplate",
+	EsKind:       utils.ComponentTemplate,
+	EsObjectType: "component template",
+	NewObject:    func() elasticv1alpha1.ElasticObject { return &elasticv1alpha1.ElasticComponentTemplate{} },
+	NewList:      func() elasticv1alpha1.ElasticObjectList { return &elasticv1alpha1.ElasticComponentTemplateList{} },
+	Apply: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string, model string) (*utils.EsStatus, error) {
+		if indexTemplates, ok := elasticsearch.(utils.EsIndexTemplates); ok {
+			return indexTemplates.CreateOrUpdateComponentTemplate(ctx, name, model)
+		}
+		return nil, errUnsupported
+	},
+	GetDrift: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string, model string) (*utils.EsDrift, error) {
+		if indexTemplates, ok := elasticsearch.(utils.EsIndexTemplates); ok {
+			return indexTemplates.GetComponentTemplateDrift(ctx, name, model)
+		}
+		return nil, errUnsupported
+	},
+	Delete: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string) error {
+		if indexTemplates, ok := elasticsearch.(utils.EsIndexTemplates); ok {
+			return indexTemplates.DeleteComponentTemplate(ctx, name)
+		}
+		return errUnsupported
+	},
+}
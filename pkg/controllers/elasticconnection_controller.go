@@ -0,0 +1,249 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	elasticv1alpha1 "github.com/Carrefour-Group/elastic-phenix-operator/pkg/api/v1alpha1"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"reflect"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"time"
+)
+
+const (
+	// Reasons of the Ready and Degraded conditions of connections
+	ReasonConnected       = "Connected"
+	ReasonInvalidSpec     = "InvalidSpec"
+	ReasonUnreachable     = "Unreachable"
+	ReasonVersionMismatch = "VersionMismatch"
+	ReasonHealthRed       = "HealthRed"
+	ReasonHealthy         = "Healthy"
+
+	// DefaultProbeInterval is the default interval between two probes of a connection
+	DefaultProbeInterval = time.Minute
+)
+
+// ElasticConnectionReconciler probes the elasticsearch cluster of an ElasticConnection and reports it in its status
+type ElasticConnectionReconciler struct {
+	client.Client
+	Log                   logr.Logger
+	Scheme                *runtime.Scheme
+	NamespacesRegexFilter string
+	ProbeInterval         time.Duration
+	EsClients             *utils.EsClientRegistry
+}
+
+// ClusterElasticConnectionReconciler probes the elasticsearch cluster of a ClusterElasticConnection and reports it in
+// its status
+type ClusterElasticConnectionReconciler struct {
+	client.Client
+	Log           logr.Logger
+	Scheme        *runtime.Scheme
+	ProbeInterval time.Duration
+	EsClients     *utils.EsClientRegistry
+}
+
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticconnections,verbs=get;list;watch
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticconnections/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=clusterelasticconnections,verbs=get;list;watch
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=clusterelasticconnections/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+
+func (r *ElasticConnectionReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("elasticconnection", req.NamespacedName)
+
+	var connection elasticv1alpha1.ElasticConnection
+	if err := r.Get(ctx, req.NamespacedName, &connection); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("ElasticConnection not found")
+			ref := &elasticv1alpha1.ElasticConnectionReference{Kind: elasticv1alpha1.ElasticConnectionKind, Name: req.Name}
+			r.EsClients.InvalidateConnection(ref.GetRegistryRef(req.Namespace))
+		} else {
+			log.Error(err, "unable to fetch ElasticConnection object")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	ref := &elasticv1alpha1.ElasticConnectionReference{Kind: elasticv1alpha1.ElasticConnectionKind, Name: connection.Name}
+	status := probeConnection(ctx, connection.Namespace, ref, connection.Generation, connection.Status, r.Client, r.EsClients, log)
+	if !reflect.DeepEqual(connection.Status, status) {
+		connection.Status = status
+		if err := r.Status().Update(ctx, &connection); err != nil && !apierrors.IsConflict(err) {
+			log.Error(err, "unable to update ElasticConnection status")
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: probeInterval(r.ProbeInterval)}, nil
+}
+
+func (r *ElasticConnectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(&elasticv1alpha1.ElasticConnection{}, connectionSecretField, func(obj runtime.Object) []string {
+		return obj.(*elasticv1alpha1.ElasticConnection).Spec.GetSecretNames()
+	}); err != nil {
+		return err
+	}
+
+	// connections are probed again when their secrets are created, fixed or rotated
+	secretHandler := &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(func(secret handler.MapObject) []reconcile.Request {
+		var connections elasticv1alpha1.ElasticConnectionList
+		if err := r.List(context.Background(), &connections, client.InNamespace(secret.Meta.GetNamespace()), client.MatchingFields{connectionSecretField: secret.Meta.GetName()}); err != nil {
+			r.Log.Error(err, "unable to list ElasticConnections referencing secret", "namespace", secret.Meta.GetNamespace(), "secret", secret.Meta.GetName())
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, item := range connections.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: item.Namespace, Name: item.Name}})
+		}
+		return requests
+	})}
+
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(&elasticv1alpha1.ElasticConnection{}).
+		Watches(&source.Kind{Type: &v1.Secret{}}, secretHandler)
+	if r.NamespacesRegexFilter != "" {
+		builder = builder.WithEventFilter(namespacesRegexFilter(&elasticv1alpha1.ElasticConnection{}, r.NamespacesRegexFilter, r.Log))
+	}
+	return builder.Complete(r)
+}
+
+func (r *ClusterElasticConnectionReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := r.Log.WithValues("clusterelasticconnection", req.Name)
+
+	var connection elasticv1alpha1.ClusterElasticConnection
+	if err := r.Get(ctx, req.NamespacedName, &connection); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("ClusterElasticConnection not found")
+			ref := &elasticv1alpha1.ElasticConnectionReference{Kind: elasticv1alpha1.ClusterElasticConnectionKind, Name: req.Name}
+			r.EsClients.InvalidateConnection(ref.GetRegistryRef(""))
+		} else {
+			log.Error(err, "unable to fetch ClusterElasticConnection object")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	ref := &elasticv1alpha1.ElasticConnectionReference{Kind: elasticv1alpha1.ClusterElasticConnectionKind, Name: connection.Name}
+	status := probeConnection(ctx, "", ref, connection.Generation, connection.Status, r.Client, r.EsClients, log)
+	if !reflect.DeepEqual(connection.Status, status) {
+		connection.Status = status
+		if err := r.Status().Update(ctx, &connection); err != nil && !apierrors.IsConflict(err) {
+			log.Error(err, "unable to update ClusterElasticConnection status")
+			return ctrl.Result{}, err
+		}
+	}
+	return ctrl.Result{RequeueAfter: probeInterval(r.ProbeInterval)}, nil
+}
+
+func (r *ClusterElasticConnectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(&elasticv1alpha1.ClusterElasticConnection{}, connectionSecretField, func(obj runtime.Object) []string {
+		connection := obj.(*elasticv1alpha1.ClusterElasticConnection)
+		var values []string
+		for _, name := range connection.Spec.GetSecretNames() {
+			values = append(values, connection.Spec.SecretNamespace+"/"+name)
+		}
+		return values
+	}); err != nil {
+		return err
+	}
+
+	// connections are probed again when their secrets are created, fixed or rotated
+	secretHandler := &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(func(secret handler.MapObject) []reconcile.Request {
+		var connections elasticv1alpha1.ClusterElasticConnectionList
+		if err := r.List(context.Background(), &connections, client.MatchingFields{connectionSecretField: secret.Meta.GetNamespace() + "/" + secret.Meta.GetName()}); err != nil {
+			r.Log.Error(err, "unable to list ClusterElasticConnections referencing secret", "namespace", secret.Meta.GetNamespace(), "secret", secret.Meta.GetName())
+			return nil
+		}
+		var requests []reconcile.Request
+		for _, item := range connections.Items {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: item.Name}})
+		}
+		return requests
+	})}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&elasticv1alpha1.ClusterElasticConnection{}).
+		Watches(&source.Kind{Type: &v1.Secret{}}, secretHandler).
+		Complete(r)
+}
+
+// probeConnection connects to the elasticsearch cluster of a connection and returns its new status, from its current status
+func probeConnection(ctx context.Context, namespace string, ref *elasticv1alpha1.ElasticConnectionReference, generation int64,
+	current elasticv1alpha1.ElasticConnectionStatus, k8sClient client.Client, esClients *utils.EsClientRegistry, log logr.Logger) elasticv1alpha1.ElasticConnectionStatus {
+	status := *current.DeepCopy()
+	status.ObservedGeneration = generation
+	ready := elasticv1alpha1.Condition{Type: elasticv1alpha1.ConditionReady, Status: v1.ConditionFalse, ObservedGeneration: generation}
+	degraded := elasticv1alpha1.Condition{Type: elasticv1alpha1.ConditionDegraded, Status: v1.ConditionFalse, ObservedGeneration: generation, Reason: ReasonHealthy}
+	setConditions := func() elasticv1alpha1.ElasticConnectionStatus {
+		elasticv1alpha1.SetCondition(&status.Conditions, ready)
+		elasticv1alpha1.SetCondition(&status.Conditions, degraded)
+		return status
+	}
+
+	connection, err := ref.GetEsConnection(namespace, k8sClient)
+	if err != nil {
+		log.Error(err, "invalid connection")
+		status.Reachable, status.Health = false, ""
+		ready.Reason, ready.Message = ReasonInvalidSpec, err.Error()
+		return setConditions()
+	}
+
+	// the connection owns its ref in the registry, which is invalidated when the connection is deleted
+	esConfig, err := esClients.Connect(connection, connection.Ref)
+	if err == nil {
+		var elasticsearch utils.Elasticsearch
+		if elasticsearch, err = utils.NewElasticsearch(esConfig, log); err == nil {
+			requestCtx, cancel := context.WithTimeout(ctx, esConfig.GetRequestTimeout())
+			status.Health, err = elasticsearch.GetClusterHealth(requestCtx)
+			cancel()
+		}
+	}
+	if err != nil {
+		log.Info("elasticsearch is unreachable", "error", err.Error())
+		status.Reachable, status.Health = false, ""
+		ready.Reason, ready.Message = ReasonUnreachable, err.Error()
+		return setConditions()
+	}
+
+	status.Reachable, status.Version, status.UUID = true, esConfig.VersionNumber, esConfig.ClusterUUID
+	status.Distribution, status.Backend = esConfig.Distribution, esConfig.Backend
+	if status.Health == "red" {
+		degraded.Status, degraded.Reason, degraded.Message = v1.ConditionTrue, ReasonHealthRed, "elasticsearch cluster health is red"
+	}
+	if err := connection.CheckVersion(esConfig); err != nil {
+		ready.Reason, ready.Message = ReasonVersionMismatch, err.Error()
+		return setConditions()
+	}
+	ready.Status, ready.Reason = v1.ConditionTrue, ReasonConnected
+	return setConditions()
+}
+
+// probeInterval returns the interval between two probes, DefaultProbeInterval when not set
+func probeInterval(interval time.Duration) time.Duration {
+	if interval <= 0 {
+		return DefaultProbeInterval
+	}
+	return interval
+}
@@ -0,0 +1,103 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+
+	elasticv1alpha1 "github.com/Carrefour-Group/elastic-phenix-operator/pkg/api/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticdatastreams,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticdatastreams/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticdatastreams/finalizers,verbs=get;list;watch;create;update;patch;delete
+
+// ElasticDataStreamKind creates ElasticDataStreams with the _data_stream API and manages their lifecycle, and rolls them
+// over on request of the RolloverAnnotation
+var ElasticDataStreamKind = ElasticObjectKind{
+	Name:         "ElasticDataStream",
+	EsKind:       utils.DataStream,
+	EsObjectType: "data stream",
+	NewObject:    func() elasticv1alpha1.ElasticObject { return &elasticv1alpha1.ElasticDataStream{} },
+	NewList:      func() elasticv1alpha1.ElasticObjectList { return &elasticv1alpha1.ElasticDataStreamList{} },
+	Apply: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string, model string) (*utils.EsStatus, error) {
+		if dataStreams, ok := elasticsearch.(utils.EsDataStreams); ok {
+			return dataStreams.CreateOrUpdateDataStream(ctx, name, model)
+		}
+		return nil, errUnsupported
+	},
+	GetDrift: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string, model string) (*utils.EsDrift, error) {
+		if dataStreams, ok := elasticsearch.(utils.EsDataStreams); ok {
+			return dataStreams.GetDataStreamDrift(ctx, name, model)
+		}
+		return nil, errUnsupported
+	},
+	Delete: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string) error {
+		if dataStreams, ok := elasticsearch.(utils.EsDataStreams); ok {
+			return dataStreams.DeleteDataStream(ctx, name)
+		}
+		return errUnsupported
+	},
+	Observe: observeDataStream,
+	Operate: rolloverDataStream,
+}
+
+// observeDataStream reads the backing indices, the generation and the template of the data stream
+func observeDataStream(ctx context.Context, elasticsearch utils.Elasticsearch, object elasticv1alpha1.ElasticObject) (bool, error) {
+	dataStreams, ok := elasticsearch.(utils.EsDataStreams)
+	if !ok {
+		return false, errUnsupported
+	}
+	dataStream := object.(*elasticv1alpha1.ElasticDataStream)
+	status := &dataStream.Status
+
+	live, err := dataStreams.GetDataStream(ctx, *dataStream.Spec.DataStreamName)
+	if err != nil {
+		return false, err
+	}
+	var indices []string
+	var generation int64
+	var template string
+	if live != nil {
+		indices, generation, template = live.Indices, live.Generation, live.Template
+	}
+	updated := !reflect.DeepEqual(status.BackingIndices, indices) || status.Generation != generation || status.Template != template
+	status.BackingIndices, status.Generation, status.Template = indices, generation, template
+	return updated, nil
+}
+
+// rolloverDataStream rolls the data stream over when the value of its RolloverAnnotation was not handled yet. Failed
+// rollovers are attempted again, unless elasticsearch rejected them. The handled value is recorded in lastRollover,
+// written by the reconciler with retries on conflict so that the data stream is not rolled over twice
+func rolloverDataStream(ctx context.Context, elasticsearch utils.Elasticsearch, object elasticv1alpha1.ElasticObject) (*utils.EsStatus, error) {
+	dataStream := object.(*elasticv1alpha1.ElasticDataStream)
+	request, requested := dataStream.GetAnnotations()[RolloverAnnotation]
+	if !requested || request == "" || (dataStream.Status.LastRollover != nil && dataStream.Status.LastRollover.Request == request) {
+		return nil, nil
+	}
+	dataStreams, ok := elasticsearch.(utils.EsDataStreams)
+	if !ok {
+		return nil, errUnsupported
+	}
+
+	esStatus, err := dataStreams.RolloverDataStream(ctx, *dataStream.Spec.DataStreamName)
+	if esStatus.Status != utils.StatusRetry {
+		dataStream.Status.LastRollover = &elasticv1alpha1.ElasticDataStreamRollover{Request: request, Time: metav1.Now(), Message: esStatus.Message}
+	}
+	return esStatus, err
+}
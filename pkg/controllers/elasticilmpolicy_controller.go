@@ -0,0 +1,80 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	"reflect"
+
+	elasticv1alpha1 "github.com/Carrefour-Group/elastic-phenix-operator/pkg/api/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticilmpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticilmpolicies/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticilmpolicies/finalizers,verbs=get;list;watch;create;update;patch;delete
+
+// maxListedManagedIndices caps the managed indices listed in the status of an ElasticILMPolicy
+const maxListedManagedIndices = 100
+
+// ElasticILMPolicyKind applies ElasticILMPolicies with the _ilm/policy API, and lists the indices they manage
+var ElasticILMPolicyKind = ElasticObjectKind{
+	Name:         "ElasticILMPolicy",
+	EsKind:       utils.ILMPolicy,
+	EsObjectType: "ILM policy",
+	NewObject:    func() elasticv1alpha1.ElasticObject { return &elasticv1alpha1.ElasticILMPolicy{} },
+	NewList:      func() elasticv1alpha1.ElasticObjectList { return &elasticv1alpha1.ElasticILMPolicyList{} },
+	Apply: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string, model string) (*utils.EsStatus, error) {
+		if policies, ok := elasticsearch.(utils.EsILMPolicies); ok {
+			return policies.CreateOrUpdateILMPolicy(ctx, name, model)
+		}
+		return nil, errUnsupported
+	},
+	GetDrift: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string, model string) (*utils.EsDrift, error) {
+		if policies, ok := elasticsearch.(utils.EsILMPolicies); ok {
+			return policies.GetILMPolicyDrift(ctx, name, model)
+		}
+		return nil, errUnsupported
+	},
+	Delete: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string) error {
+		if policies, ok := elasticsearch.(utils.EsILMPolicies); ok {
+			return policies.DeleteILMPolicy(ctx, name)
+		}
+		return errUnsupported
+	},
+	Observe: func(ctx context.Context, elasticsearch utils.Elasticsearch, object elasticv1alpha1.ElasticObject) (bool, error) {
+		policies, ok := elasticsearch.(utils.EsILMPolicies)
+		if !ok {
+			return false, errUnsupported
+		}
+		indices, err := policies.GetILMPolicyIndices(ctx, object.GetEsObjectName())
+		if err != nil {
+			return false, err
+		}
+		status := &object.(*elasticv1alpha1.ElasticILMPolicy).Status
+		count := int32(len(indices))
+		if len(indices) > maxListedManagedIndices {
+			indices = indices[:maxListedManagedIndices]
+		} else if len(indices) == 0 {
+			indices = nil
+		}
+		if status.ManagedIndicesCount == count && reflect.DeepEqual(status.ManagedIndices, indices) {
+			return false, nil
+		}
+		status.ManagedIndicesCount = count
+		status.ManagedIndices = indices
+		return true, nil
+	},
+}
@@ -20,12 +20,20 @@ import (
 	elasticv1alpha1 "github.com/Carrefour-Group/elastic-phenix-operator/pkg/api/v1alpha1"
 	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
 	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/event"
-	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"strings"
+	"time"
 )
 
 // ElasticIndexReconciler reconciles a ElasticIndex object
@@ -34,13 +42,20 @@ type ElasticIndexReconciler struct {
 	Log                   logr.Logger
 	Scheme                *runtime.Scheme
 	NamespacesRegexFilter string
+	ResyncInterval        time.Duration
+	ResyncRepair          bool
+	Recorder              record.EventRecorder
+	EsClients             *utils.EsClientRegistry
+	Retry                 RetryOptions
 }
 
 // +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticindices,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticindices/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticindices/finalizers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticsecretgrants,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 func (r *ElasticIndexReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	ctx := context.Background()
@@ -52,39 +67,114 @@ func (r *ElasticIndexReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error
 	if err := r.Get(ctx, req.NamespacedName, &elasticIndex); err != nil {
 		if apierrors.IsNotFound(err) {
 			log.Info("ElasticIndex not found")
+			r.EsClients.Release(registryOwner("ElasticIndex", req.NamespacedName))
 		} else {
 			log.Error(err, "unable to fetch elasticIndex object")
 		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	esConfig, err := utils.BuildEsConfigFromSecretSelector(elasticIndex.ObjectMeta.Namespace, elasticIndex.Spec.ElasticURI.SecretKeyRef, r.Client)
+	esConfig, secretResourceVersion, err := buildEsConfig(registryOwner("ElasticIndex", req.NamespacedName), elasticIndex.ObjectMeta.Namespace, elasticIndex.Spec.ElasticURI, elasticIndex.Spec.ConnectionRef, r.Client, r.EsClients)
+	if released, updateErr := releaseUngrantedObject(ctx, r.Client, r.Recorder, &elasticIndex, utils.Index, err); released {
+		log.Info("elasticindex is being deleted without its connection, the elasticsearch index is kept", "reason", err.Error())
+		return ctrl.Result{}, updateErr
+	}
 	if err != nil {
-		log.Error(err, "unable to build EsConfig from a secret")
-		if indexStatusUpdated(&elasticIndex.Status, &utils.EsStatus{Status: utils.StatusError, Message: err.Error()}, log) {
-			r.Status().Update(ctx, &elasticIndex)
+		log.Error(err, "unable to build EsConfig from the connection")
+		r.Recorder.Eventf(&elasticIndex, v1.EventTypeWarning, EventReasonValidationFailed, "invalid elasticsearch connection: %v", err)
+		delay, _ := r.Retry.scheduleRetry(utils.StatusError, &elasticIndex.Status.Retries, &elasticIndex.Status.NextRetryTime)
+		indexStatusUpdated(&elasticIndex.Status, &utils.EsStatus{Status: utils.StatusError, Message: err.Error()}, elasticIndex.Generation, log)
+		if err := r.Status().Update(ctx, &elasticIndex); err != nil && !apierrors.IsConflict(err) {
+			log.Error(err, "unable to update ElasticIndex status")
+			return ctrl.Result{}, err
 		}
-		return ctrl.Result{RequeueAfter: ErrorInterval}, nil
+		return ctrl.Result{RequeueAfter: delay}, nil
 	}
 
-	log.Info("esConfig generated from secret", "EsConfig", esConfig, "EsVersion", esConfig.Version)
-	var elasticsearch = buildElasticsearchFromVersion(esConfig.Version)
-	if err = elasticsearch.NewClient(esConfig, log); err != nil {
+	resyncInterval, resyncRepair := resyncOptions(elasticIndex.Spec.Resync, r.ResyncInterval, r.ResyncRepair)
+
+	log.Info("esConfig generated from connection", "EsConfig", esConfig, "EsVersion", esConfig.VersionNumber, "Distribution", esConfig.Distribution, "Backend", esConfig.Backend)
+	elasticsearch, err := utils.NewElasticsearch(esConfig, log)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
 
+	var retryDelay time.Duration
 	if deleteRequest, err := manageIndexFinalizer(ctx, elasticIndex, elasticsearch, log, r); err != nil {
 		return ctrl.Result{}, err
 	} else if !deleteRequest {
-		if err := elasticsearch.PingES(ctx); err != nil {
-			if indexStatusUpdated(&elasticIndex.Status, &utils.EsStatus{Status: utils.StatusRetry, Message: err.Error()}, log) {
-				r.Status().Update(ctx, &elasticIndex)
+		// a model applied with another elasticURI secret is applied again, the secret may have been fixed or rotated
+		modelApplied := isModelApplied(elasticIndex.Status.Conditions, elasticIndex.Generation) && isSameCluster(elasticIndex.Status.Cluster, esConfig, secretResourceVersion)
+		if wait := nextResync(elasticIndex.Status.LastSyncTime, resyncInterval); resyncInterval > 0 && modelApplied && wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+
+		err := elasticsearch.PingES(ctx)
+		recordPing(esConfig, err)
+		if err != nil {
+			r.Recorder.Eventf(&elasticIndex, v1.EventTypeWarning, EventReasonElasticsearchUnreachable, "elasticsearch %v is unreachable: %v", esConfig, err)
+			delay, _ := r.Retry.scheduleRetry(utils.StatusRetry, &elasticIndex.Status.Retries, &elasticIndex.Status.NextRetryTime)
+			indexStatusUpdated(&elasticIndex.Status, &utils.EsStatus{Status: utils.StatusRetry, Message: err.Error()}, elasticIndex.Generation, log)
+			if err := r.Status().Update(ctx, &elasticIndex); err != nil && !apierrors.IsConflict(err) {
+				log.Error(err, "unable to update ElasticIndex status")
+				return ctrl.Result{}, err
 			}
-			return ctrl.Result{RequeueAfter: RetryInterval}, nil
+			return ctrl.Result{RequeueAfter: delay}, nil
 		}
+		var drift *utils.EsDrift
+		if resyncInterval > 0 && modelApplied {
+			log.Info("resync ElasticIndex", "indexName", elasticIndex.Spec.IndexName)
+			if drift, err = elasticsearch.GetIndexDrift(ctx, *elasticIndex.Spec.IndexName, *elasticIndex.Spec.Model); err != nil {
+				log.Error(err, "unable to compare index with its model")
+				delay, _ := r.Retry.scheduleRetry(utils.StatusRetry, &elasticIndex.Status.Retries, &elasticIndex.Status.NextRetryTime)
+				if err := r.Status().Update(ctx, &elasticIndex); err != nil && !apierrors.IsConflict(err) {
+					log.Error(err, "unable to update ElasticIndex status")
+					return ctrl.Result{}, err
+				}
+				return ctrl.Result{RequeueAfter: delay}, nil
+			}
+			if !drift.IsDrifted() || !resyncRepair {
+				if drift.IsDrifted() {
+					log.Info("index drifted from its model", "reasons", drift.Reasons)
+					r.Recorder.Event(&elasticIndex, v1.EventTypeWarning, ReasonDriftDetected, strings.Join(drift.Reasons, ", "))
+				}
+				recordDrift(utils.Index, elasticIndex.Namespace, elasticIndex.Name, drift, false)
+				elasticv1alpha1.SetCondition(&elasticIndex.Status.Conditions, buildDriftedCondition(drift, false, elasticIndex.Generation))
+				now := metav1.Now()
+				elasticIndex.Status.LastSyncTime = &now
+				if err := r.Status().Update(ctx, &elasticIndex); err != nil && !apierrors.IsConflict(err) {
+					log.Error(err, "unable to update ElasticIndex status")
+					return ctrl.Result{}, err
+				}
+				return ctrl.Result{RequeueAfter: resyncInterval}, nil
+			}
+			log.Info("index drifted from its model and will be repaired", "reasons", drift.Reasons)
+		}
+
 		log.Info("create/update ElasticIndex", "indexName", elasticIndex.Spec.IndexName)
-		esStatus, err := elasticsearch.CreateOrUpdateIndex(ctx, *elasticIndex.Spec.IndexName, *elasticIndex.Spec.Model)
-		if indexStatusUpdated(&elasticIndex.Status, esStatus, log) {
+		migration := buildIndexMigration(&elasticIndex)
+		esStatus, err := elasticsearch.CreateOrUpdateIndex(ctx, *elasticIndex.Spec.IndexName, *elasticIndex.Spec.Model, migration)
+		recordEsStatusEvents(r.Recorder, &elasticIndex, *elasticIndex.Spec.IndexName, esStatus)
+		recordOperation(utils.Index, esStatus, elasticIndex.Status.LastAppliedModelHash)
+		migrationUpdated := indexMigrationUpdated(&elasticIndex.Status, migration, log)
+		aliasesUpdated := indexAliasesUpdated(&elasticIndex.Status, esStatus, log)
+		driftUpdated := false
+		if drift != nil {
+			repaired := esStatus.Status == utils.StatusCreated
+			recordDrift(utils.Index, elasticIndex.Namespace, elasticIndex.Name, drift, repaired)
+			driftUpdated = elasticv1alpha1.SetCondition(&elasticIndex.Status.Conditions, buildDriftedCondition(drift, repaired, elasticIndex.Generation))
+		}
+		var retryUpdated bool
+		retryDelay, retryUpdated = r.Retry.scheduleRetry(esStatus.Status, &elasticIndex.Status.Retries, &elasticIndex.Status.NextRetryTime)
+		syncUpdated := false
+		if esStatus.Status == utils.StatusCreated && (!modelApplied || drift != nil) {
+			now := metav1.Now()
+			elasticIndex.Status.LastAppliedModelHash = utils.HashString(*elasticIndex.Spec.Model)
+			elasticIndex.Status.LastSyncTime = &now
+			elasticIndex.Status.Cluster = buildClusterReference(esConfig, secretResourceVersion)
+			syncUpdated = true
+		}
+		if indexStatusUpdated(&elasticIndex.Status, esStatus, elasticIndex.Generation, log) || migrationUpdated || aliasesUpdated || driftUpdated || syncUpdated || retryUpdated {
 			if err := r.Status().Update(ctx, &elasticIndex); err != nil {
 				if apierrors.IsConflict(err) {
 					log.Info("conflict: operation cannot be fulfilled on ElasticIndex. Requeue to try again")
@@ -96,52 +186,209 @@ func (r *ElasticIndexReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error
 		}
 		if esStatus.Status == utils.StatusError {
 			//blocking error no need to Requeue or Requeue after a long interval
-			return ctrl.Result{RequeueAfter: ErrorInterval}, nil
+			return ctrl.Result{RequeueAfter: retryDelay}, nil
 		} else if err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
-	if elasticIndex.Status.Status == utils.StatusRetry {
-		return ctrl.Result{RequeueAfter: RetryInterval}, nil
+	if retryDelay > 0 {
+		return ctrl.Result{RequeueAfter: retryDelay}, nil
+	} else if elasticIndex.Status.Status == utils.StatusRetry || elasticIndex.Status.Status == utils.StatusMigrating {
+		return ctrl.Result{RequeueAfter: r.Retry.getRetryInterval()}, nil
+	}
+
+	if resyncInterval > 0 && elasticIndex.ObjectMeta.DeletionTimestamp.IsZero() {
+		return ctrl.Result{RequeueAfter: resyncInterval}, nil
 	}
 
 	return ctrl.Result{}, nil
 }
 
 func (r *ElasticIndexReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	if r.NamespacesRegexFilter == "" {
-		return ctrl.NewControllerManagedBy(mgr).
-			For(&elasticv1alpha1.ElasticIndex{}).
-			Complete(r)
-	}
+	registerManagedObjectsCollector(mgr.GetClient())
 
-	var regex = r.NamespacesRegexFilter
-	namespacesRegexFilter := predicate.Funcs{
-		CreateFunc:  func(ce event.CreateEvent) bool { return utils.FilterByNamespacesRegex(ce.Meta, regex, r.Log) },
-		DeleteFunc:  func(ce event.DeleteEvent) bool { return utils.FilterByNamespacesRegex(ce.Meta, regex, r.Log) },
-		UpdateFunc:  func(ce event.UpdateEvent) bool { return utils.FilterByNamespacesRegex(ce.MetaNew, regex, r.Log) },
-		GenericFunc: func(ce event.GenericEvent) bool { return utils.FilterByNamespacesRegex(ce.Meta, regex, r.Log) },
+	if err := mgr.GetFieldIndexer().IndexField(&elasticv1alpha1.ElasticIndex{}, elasticURISecretField, func(obj runtime.Object) []string {
+		object := obj.(*elasticv1alpha1.ElasticIndex)
+		return indexElasticURISecrets(object.Namespace, object.Spec.ElasticURI)
+	}); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(&elasticv1alpha1.ElasticIndex{}, elasticURINamespaceField, func(obj runtime.Object) []string {
+		object := obj.(*elasticv1alpha1.ElasticIndex)
+		return indexElasticURINamespace(object.Namespace, object.Spec.ElasticURI)
+	}); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(&elasticv1alpha1.ElasticIndex{}, connectionRefField, func(obj runtime.Object) []string {
+		return indexConnectionRef(obj.(*elasticv1alpha1.ElasticIndex).Spec.ConnectionRef)
+	}); err != nil {
+		return err
 	}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	// objects referencing a secret are reconciled when it is created, fixed or rotated
+	secretHandler := &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.requestsForSecret)}
+	// objects referencing a connection are reconciled when its spec changes, not on the status updates of its probes
+	connectionHandler := &connectionChangedHandler{&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.requestsForConnection)}}
+	// objects referencing secrets of another namespace are reconciled when its grants change
+	grantHandler := &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.requestsForGrant)}
+
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&elasticv1alpha1.ElasticIndex{}).
-		WithEventFilter(namespacesRegexFilter).
-		Complete(r)
+		Watches(&source.Kind{Type: &v1.Secret{}}, secretHandler).
+		Watches(&source.Kind{Type: &elasticv1alpha1.ElasticConnection{}}, connectionHandler).
+		Watches(&source.Kind{Type: &elasticv1alpha1.ClusterElasticConnection{}}, connectionHandler).
+		Watches(&source.Kind{Type: &elasticv1alpha1.ElasticSecretGrant{}}, grantHandler).
+		WithEventFilter(statusUpdateFilter(&elasticv1alpha1.ElasticIndex{}))
+	if r.NamespacesRegexFilter != "" {
+		builder = builder.WithEventFilter(namespacesRegexFilter(&elasticv1alpha1.ElasticIndex{}, r.NamespacesRegexFilter, r.Log))
+	}
+	return builder.Complete(r)
+}
+
+// requestsForSecret invalidates the connections read from the secret, whatever its namespace, so that the clients of
+// rotated credentials are renewed, and returns a request for each ElasticIndex referencing it
+func (r *ElasticIndexReconciler) requestsForSecret(secret handler.MapObject) []reconcile.Request {
+	r.EsClients.InvalidateSecret(secret.Meta.GetNamespace(), secret.Meta.GetName())
+
+	var elasticIndices elasticv1alpha1.ElasticIndexList
+	if err := r.List(context.Background(), &elasticIndices, client.MatchingFields{elasticURISecretField: secret.Meta.GetNamespace() + "/" + secret.Meta.GetName()}); err != nil {
+		r.Log.Error(err, "unable to list ElasticIndices referencing secret", "namespace", secret.Meta.GetNamespace(), "secret", secret.Meta.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, item := range elasticIndices.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: item.Namespace, Name: item.Name}})
+	}
+
+	connections, err := connectionsForSecret(r.Client, secret.Meta.GetNamespace(), secret.Meta.GetName())
+	if err != nil {
+		r.Log.Error(err, "unable to list connections referencing secret", "namespace", secret.Meta.GetNamespace(), "secret", secret.Meta.GetName())
+	}
+	for _, connection := range connections {
+		requests = append(requests, r.requestsForConnectionKey(connection)...)
+	}
+	return requests
 }
 
-func indexStatusUpdated(objectStatus *elasticv1alpha1.ElasticIndexStatus, esStatus *utils.EsStatus, log logr.Logger) bool {
-	if esStatus != nil &&
-		(objectStatus.Status != esStatus.Status ||
-			(objectStatus.Status != utils.StatusCreated && objectStatus.Message != esStatus.Message)) {
+// requestsForGrant returns a request for each ElasticIndex referencing secrets of the namespace of an ElasticSecretGrant
+// from another namespace
+func (r *ElasticIndexReconciler) requestsForGrant(grant handler.MapObject) []reconcile.Request {
+	var elasticIndices elasticv1alpha1.ElasticIndexList
+	if err := r.List(context.Background(), &elasticIndices, client.MatchingFields{elasticURINamespaceField: grant.Meta.GetNamespace()}); err != nil {
+		r.Log.Error(err, "unable to list ElasticIndices referencing secrets of namespace", "namespace", grant.Meta.GetNamespace())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, item := range elasticIndices.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: item.Namespace, Name: item.Name}})
+	}
+	return requests
+}
+
+// requestsForConnection returns a request for each ElasticIndex referencing an ElasticConnection or a ClusterElasticConnection
+func (r *ElasticIndexReconciler) requestsForConnection(connection handler.MapObject) []reconcile.Request {
+	return r.requestsForConnectionKey(connectionKeyOf(connection))
+}
+
+func (r *ElasticIndexReconciler) requestsForConnectionKey(connection connectionKey) []reconcile.Request {
+	var elasticIndices elasticv1alpha1.ElasticIndexList
+	if err := r.List(context.Background(), &elasticIndices, client.InNamespace(connection.namespace), client.MatchingFields{connectionRefField: connection.ref}); err != nil {
+		r.Log.Error(err, "unable to list ElasticIndices referencing connection", "namespace", connection.namespace, "connection", connection.ref)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, item := range elasticIndices.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: item.Namespace, Name: item.Name}})
+	}
+	return requests
+}
+
+func indexStatusUpdated(objectStatus *elasticv1alpha1.ElasticIndexStatus, esStatus *utils.EsStatus, generation int64, log logr.Logger) bool {
+	if esStatus == nil {
+		return false
+	}
+
+	updated := false
+	if objectStatus.Status != esStatus.Status ||
+		(objectStatus.Status != utils.StatusCreated && objectStatus.Message != esStatus.Message) {
 		log.Info("update status", "from", objectStatus.Status, "to", esStatus.Status)
 		objectStatus.Status = esStatus.Status
 		objectStatus.HttpCodeStatus = esStatus.HttpCodeStatus
 		objectStatus.Message = esStatus.Message
-		return true
+		updated = true
+	}
+	if objectStatus.ObservedGeneration != generation {
+		objectStatus.ObservedGeneration = generation
+		updated = true
 	}
+	return statusConditionsUpdated(&objectStatus.Conditions, esStatus, generation) || updated
+}
 
-	return false
+// buildIndexMigration returns the migration state from the object, a migration is always returned because
+// number_of_shards updates are migrated with a split or a shrink whatever the migration strategy is
+func buildIndexMigration(elasticIndex *elasticv1alpha1.ElasticIndex) *utils.EsMigration {
+	migration := &utils.EsMigration{}
+	if elasticIndex.Spec.Migration != nil {
+		migration.Strategy = elasticIndex.Spec.Migration.Strategy
+		migration.OldIndexPolicy = elasticIndex.Spec.Migration.OldIndexPolicy
+		migration.StaticSettingsUpdate = elasticIndex.Spec.Migration.StaticSettingsUpdate
+	}
+	if migrationStatus := elasticIndex.Status.Migration; migrationStatus != nil {
+		migration.Operation = migrationStatus.Operation
+		migration.Phase = migrationStatus.Phase
+		migration.SourceIndex = migrationStatus.SourceIndex
+		migration.TargetIndex = migrationStatus.TargetIndex
+		migration.TaskID = migrationStatus.TaskID
+		migration.Created = migrationStatus.Created
+		migration.Total = migrationStatus.Total
+	}
+	return migration
+}
+
+func indexMigrationUpdated(objectStatus *elasticv1alpha1.ElasticIndexStatus, migration *utils.EsMigration, log logr.Logger) bool {
+	if migration == nil || migration.Phase == "" {
+		return false
+	}
+
+	migrationStatus := &elasticv1alpha1.ElasticIndexMigrationStatus{
+		Operation:   migration.Operation,
+		Phase:       migration.Phase,
+		SourceIndex: migration.SourceIndex,
+		TargetIndex: migration.TargetIndex,
+		TaskID:      migration.TaskID,
+		Created:     migration.Created,
+		Total:       migration.Total,
+	}
+	if reflect.DeepEqual(objectStatus.Migration, migrationStatus) {
+		return false
+	}
+
+	log.Info("update migration status", "operation", migration.Operation, "phase", migration.Phase, "from", migration.SourceIndex, "to", migration.TargetIndex)
+	objectStatus.Migration = migrationStatus
+	return true
+}
+
+// indexAliasesUpdated copies aliases results to the object status, when aliases were reconciled
+func indexAliasesUpdated(objectStatus *elasticv1alpha1.ElasticIndexStatus, esStatus *utils.EsStatus, log logr.Logger) bool {
+	if esStatus == nil || esStatus.Aliases == nil {
+		return false
+	}
+
+	var aliasesStatus []elasticv1alpha1.ElasticIndexAliasStatus
+	for _, alias := range esStatus.Aliases {
+		aliasesStatus = append(aliasesStatus, elasticv1alpha1.ElasticIndexAliasStatus{Name: alias.Name, Status: alias.Status, Message: alias.Message})
+	}
+	if reflect.DeepEqual(objectStatus.Aliases, aliasesStatus) {
+		return false
+	}
+
+	log.Info("update aliases status", "aliases", aliasesStatus)
+	objectStatus.Aliases = aliasesStatus
+	return true
 }
 
 func manageIndexFinalizer(ctx context.Context, elasticIndex elasticv1alpha1.ElasticIndex, elasticsearch utils.Elasticsearch, log logr.Logger, r *ElasticIndexReconciler) (bool, error) {
@@ -163,11 +410,18 @@ func manageIndexFinalizer(ctx context.Context, elasticIndex elasticv1alpha1.Elas
 			if elasticIndex.Annotations[DeleteInClusterAnnotation] == "true" {
 				if err := elasticsearch.DeleteIndex(ctx, *elasticIndex.Spec.IndexName); err != nil {
 					log.Error(err, "error while deleting elasticIndex", "indexName", *elasticIndex.Spec.IndexName)
+					r.Recorder.Eventf(&elasticIndex, v1.EventTypeWarning, EventReasonDeleteFailed, "error while deleting index %v: %v", *elasticIndex.Spec.IndexName, err)
+				} else {
+					operationsTotal.WithLabelValues(utils.Index, OperationDelete).Inc()
+					r.Recorder.Eventf(&elasticIndex, v1.EventTypeNormal, EventReasonDeleted, "index %v deleted", *elasticIndex.Spec.IndexName)
 				}
 			} else {
 				log.Info("elasticindex deletion will not delete elasticsearch index", "indexName", *elasticIndex.Spec.IndexName)
+				r.Recorder.Eventf(&elasticIndex, v1.EventTypeNormal, EventReasonDeleteSkipped, "index %v kept in elasticsearch, annotation %v is not set to true", *elasticIndex.Spec.IndexName, DeleteInClusterAnnotation)
 			}
 
+			driftedObjects.DeleteLabelValues(utils.Index, elasticIndex.Namespace, elasticIndex.Name)
+
 			// remove finalizer from the list and update it.
 			elasticIndex.ObjectMeta.Finalizers = utils.RemoveString(elasticIndex.ObjectMeta.Finalizers, finalizerName)
 			if err := r.Update(ctx, &elasticIndex); err != nil {
@@ -0,0 +1,107 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	"strings"
+
+	elasticv1alpha1 "github.com/Carrefour-Group/elastic-phenix-operator/pkg/api/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticingestpipelines,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticingestpipelines/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticingestpipelines/finalizers,verbs=get;list;watch;create;update;patch;delete
+
+// ElasticIngestPipelineKind applies ElasticIngestPipelines with the _ingest/pipeline API, and simulates their sample
+// documents
+var ElasticIngestPipelineKind = ElasticObjectKind{
+	Name:         "ElasticIngestPipeline",
+	EsKind:       utils.IngestPipeline,
+	EsObjectType: "ingest pipeline",
+	NewObject:    func() elasticv1alpha1.ElasticObject { return &elasticv1alpha1.ElasticIngestPipeline{} },
+	NewList:      func() elasticv1alpha1.ElasticObjectList { return &elasticv1alpha1.ElasticIngestPipelineList{} },
+	Apply: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string, model string) (*utils.EsStatus, error) {
+		if pipelines, ok := elasticsearch.(utils.EsIngestPipelines); ok {
+			return pipelines.CreateOrUpdateIngestPipeline(ctx, name, model)
+		}
+		return nil, errUnsupported
+	},
+	GetDrift: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string, model string) (*utils.EsDrift, error) {
+		if pipelines, ok := elasticsearch.(utils.EsIngestPipelines); ok {
+			return pipelines.GetIngestPipelineDrift(ctx, name, model)
+		}
+		return nil, errUnsupported
+	},
+	Delete: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string) error {
+		if pipelines, ok := elasticsearch.(utils.EsIngestPipelines); ok {
+			return pipelines.DeleteIngestPipeline(ctx, name)
+		}
+		return errUnsupported
+	},
+	Observe: observeIngestPipeline,
+}
+
+// observeIngestPipeline reads the version of the pipeline, and simulates the sample documents once for each model and
+// documents
+func observeIngestPipeline(ctx context.Context, elasticsearch utils.Elasticsearch, object elasticv1alpha1.ElasticObject) (bool, error) {
+	pipelines, ok := elasticsearch.(utils.EsIngestPipelines)
+	if !ok {
+		return false, errUnsupported
+	}
+	pipeline := object.(*elasticv1alpha1.ElasticIngestPipeline)
+	status := &pipeline.Status
+
+	version, err := pipelines.GetIngestPipelineVersion(ctx, *pipeline.Spec.PipelineName)
+	if err != nil {
+		return false, err
+	}
+	updated := !reflect.DeepEqual(status.Version, version)
+	status.Version = version
+
+	documents := pipeline.Spec.SampleDocuments
+	if len(documents) == 0 {
+		updated = updated || status.LastSimulation != nil
+		status.LastSimulation = nil
+		return updated, nil
+	}
+	modelHash := utils.HashString(*pipeline.Spec.Model + "\n" + strings.Join(documents, "\n"))
+	if status.LastSimulation != nil && status.LastSimulation.ModelHash == modelHash {
+		return updated, nil
+	}
+
+	simulation := &elasticv1alpha1.ElasticPipelineSimulation{Time: metav1.Now(), Result: elasticv1alpha1.SimulationSucceeded}
+	failures, err := pipelines.SimulateIngestPipeline(ctx, *pipeline.Spec.Model, documents)
+	if err != nil {
+		// the model hash is not recorded, so that a simulation that could not run is attempted again
+		simulation.Result = elasticv1alpha1.SimulationFailed
+		simulation.Failures = []string{err.Error()}
+		status.LastSimulation = simulation
+		return true, err
+	}
+	for i, failure := range failures {
+		if failure != "" {
+			simulation.Result = elasticv1alpha1.SimulationFailed
+			simulation.Failures = append(simulation.Failures, fmt.Sprintf("document %v: %v", i, failure))
+		}
+	}
+	simulation.ModelHash = modelHash
+	status.LastSimulation = simulation
+	return true, nil
+}
@@ -0,0 +1,472 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"strings"
+	"time"
+
+	elasticv1alpha1 "github.com/Carrefour-Group/elastic-phenix-operator/pkg/api/v1alpha1"
+)
+
+// errUnsupported is returned by the functions of an ElasticObjectKind when the backend of the cluster does not support
+// the elasticsearch objects of the kind
+var errUnsupported = errors.New("not supported by the backend of the cluster")
+
+// observeInterval is the delay between two refreshes of the status fields read from elasticsearch, when the model is
+// applied and not compared by a resync before
+const observeInterval = 5 * time.Minute
+
+// ElasticObjectKind applies the objects of an ElasticObject kind to elasticsearch, for the ElasticObjectReconciler
+type ElasticObjectKind struct {
+	// Name of the kind, like ElasticComposableTemplate
+	Name string
+	// EsKind is the kind of the elasticsearch objects, like utils.IndexTemplate, used as metrics label
+	EsKind string
+	// EsObjectType names the elasticsearch objects in events, like "index template"
+	EsObjectType string
+	// NewObject and NewList return an empty object and an empty list of the kind
+	NewObject func() elasticv1alpha1.ElasticObject
+	NewList   func() elasticv1alpha1.ElasticObjectList
+	// Apply creates or updates the elasticsearch object from the model of the object
+	Apply func(ctx context.Context, elasticsearch utils.Elasticsearch, name string, model string) (*utils.EsStatus, error)
+	// GetDrift compares the elasticsearch object with the model of the object
+	GetDrift func(ctx context.Context, elasticsearch utils.Elasticsearch, name string, model string) (*utils.EsDrift, error)
+	// Delete deletes the elasticsearch object, when the object is deleted with the DeleteInClusterAnnotation
+	Delete func(ctx context.Context, elasticsearch utils.Elasticsearch, name string) error
+	// Observe is optional, it refreshes the status fields of the kind read from elasticsearch and returns true when
+	// they changed. It is called when the model is applied, on resync, and every observeInterval
+	Observe func(ctx context.Context, elasticsearch utils.Elasticsearch, object elasticv1alpha1.ElasticObject) (bool, error)
+	// Operate is optional, it performs the operations requested on an applied object, like a rollover requested by an
+	// annotation, records them in the status of the kind and returns their status, nil when there was none. It is
+	// called before Observe, that the kinds with Operate define too
+	Operate func(ctx context.Context, elasticsearch utils.Elasticsearch, object elasticv1alpha1.ElasticObject) (*utils.EsStatus, error)
+}
+
+// ElasticObjectReconciler reconciles the objects of an ElasticObject kind, whose model is applied to a single
+// elasticsearch object
+type ElasticObjectReconciler struct {
+	client.Client
+	Log                   logr.Logger
+	Scheme                *runtime.Scheme
+	NamespacesRegexFilter string
+	ResyncInterval        time.Duration
+	ResyncRepair          bool
+	Recorder              record.EventRecorder
+	EsClients             *utils.EsClientRegistry
+	Retry                 RetryOptions
+	Kind                  ElasticObjectKind
+}
+
+func (r *ElasticObjectReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+
+	ctx := context.Background()
+	log := r.Log.WithValues(strings.ToLower(r.Kind.Name), req.NamespacedName)
+
+	log.Info("new reconciliation request")
+
+	object := r.Kind.NewObject()
+	if err := r.Get(ctx, req.NamespacedName, object); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info(r.Kind.Name + " not found")
+			r.EsClients.Release(registryOwner(r.Kind.Name, req.NamespacedName))
+		} else {
+			log.Error(err, "unable to fetch "+r.Kind.Name+" object")
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	status := object.GetObjectStatus()
+	esObjectName := object.GetEsObjectName()
+	elasticURI, connectionRef := object.GetConnection()
+
+	esConfig, secretResourceVersion, err := buildEsConfig(registryOwner(r.Kind.Name, req.NamespacedName), object.GetNamespace(), elasticURI, connectionRef, r.Client, r.EsClients)
+	if released, updateErr := releaseUngrantedObject(ctx, r.Client, r.Recorder, object, r.Kind.EsKind, err); released {
+		log.Info(strings.ToLower(r.Kind.Name)+" is being deleted without its connection, the elasticsearch "+r.Kind.EsObjectType+" is kept", "reason", err.Error())
+		return ctrl.Result{}, updateErr
+	}
+	if err != nil {
+		log.Error(err, "unable to build EsConfig from the connection")
+		r.Recorder.Eventf(object, v1.EventTypeWarning, EventReasonValidationFailed, "invalid elasticsearch connection: %v", err)
+		delay, _ := r.Retry.scheduleRetry(utils.StatusError, &status.Retries, &status.NextRetryTime)
+		objectStatusUpdated(status, &utils.EsStatus{Status: utils.StatusError, Message: err.Error()}, object.GetGeneration(), log)
+		if err := r.Status().Update(ctx, object); err != nil && !apierrors.IsConflict(err) {
+			log.Error(err, "unable to update "+r.Kind.Name+" status")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+	resyncInterval, resyncRepair := resyncOptions(object.GetResync(), r.ResyncInterval, r.ResyncRepair)
+
+	log.Info("esConfig generated from connection", "EsConfig", esConfig, "EsVersion", esConfig.VersionNumber, "Distribution", esConfig.Distribution, "Backend", esConfig.Backend)
+	elasticsearch, err2 := utils.NewElasticsearch(esConfig, log)
+	if err2 != nil {
+		return ctrl.Result{}, err2
+	}
+
+	var retryDelay time.Duration
+	if deleteRequest, err := r.manageFinalizer(ctx, object, elasticsearch, log); err != nil {
+		return ctrl.Result{}, err
+	} else if !deleteRequest {
+		model, err := buildModel(object, r.Client)
+		if err != nil {
+			log.Error(err, "unable to build the model")
+			delay, _ := r.Retry.scheduleRetry(utils.StatusError, &status.Retries, &status.NextRetryTime)
+			objectStatusUpdated(status, &utils.EsStatus{Status: utils.StatusError, Message: err.Error()}, object.GetGeneration(), log)
+			if err := r.Status().Update(ctx, object); err != nil && !apierrors.IsConflict(err) {
+				log.Error(err, "unable to update "+r.Kind.Name+" status")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: delay}, nil
+		}
+
+		// a model applied with another elasticURI secret is applied again, the secret may have been fixed or rotated, as
+		// a model whose secret values changed
+		modelApplied := isModelApplied(status.Conditions, object.GetGeneration()) && isSameCluster(status.Cluster, esConfig, secretResourceVersion) &&
+			status.LastAppliedModelHash == utils.HashString(model)
+		wait := nextResync(status.LastSyncTime, resyncInterval)
+		if r.Kind.Observe != nil && modelApplied && (resyncInterval == 0 || wait > 0) {
+			// the model is not applied again, only the status read from elasticsearch is refreshed
+			if r.observe(ctx, elasticsearch, object, log) {
+				if err := r.updateObservedStatus(ctx, object); err != nil {
+					log.Error(err, "unable to update "+r.Kind.Name+" status")
+					return ctrl.Result{}, err
+				}
+			}
+			if wait > 0 && wait < observeInterval {
+				return ctrl.Result{RequeueAfter: wait}, nil
+			}
+			return ctrl.Result{RequeueAfter: observeInterval}, nil
+		}
+		if resyncInterval > 0 && modelApplied && wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+
+		err = elasticsearch.PingES(ctx)
+		recordPing(esConfig, err)
+		if err != nil {
+			r.Recorder.Eventf(object, v1.EventTypeWarning, EventReasonElasticsearchUnreachable, "elasticsearch %v is unreachable: %v", esConfig, err)
+			delay, _ := r.Retry.scheduleRetry(utils.StatusRetry, &status.Retries, &status.NextRetryTime)
+			objectStatusUpdated(status, &utils.EsStatus{Status: utils.StatusRetry, Message: err.Error()}, object.GetGeneration(), log)
+			if err := r.Status().Update(ctx, object); err != nil && !apierrors.IsConflict(err) {
+				log.Error(err, "unable to update "+r.Kind.Name+" status")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: delay}, nil
+		}
+		var drift *utils.EsDrift
+		if resyncInterval > 0 && modelApplied {
+			log.Info("resync "+r.Kind.Name, "name", esObjectName)
+			if drift, err = r.Kind.GetDrift(ctx, elasticsearch, esObjectName, model); err != nil {
+				log.Error(err, "unable to compare "+r.Kind.EsObjectType+" with its model")
+				delay, _ := r.Retry.scheduleRetry(utils.StatusRetry, &status.Retries, &status.NextRetryTime)
+				if err := r.Status().Update(ctx, object); err != nil && !apierrors.IsConflict(err) {
+					log.Error(err, "unable to update "+r.Kind.Name+" status")
+					return ctrl.Result{}, err
+				}
+				return ctrl.Result{RequeueAfter: delay}, nil
+			}
+			if !drift.IsDrifted() || !resyncRepair {
+				if drift.IsDrifted() {
+					log.Info(r.Kind.EsObjectType+" drifted from its model", "reasons", drift.Reasons)
+					r.Recorder.Event(object, v1.EventTypeWarning, ReasonDriftDetected, strings.Join(drift.Reasons, ", "))
+				}
+				recordDrift(r.Kind.EsKind, object.GetNamespace(), object.GetName(), drift, false)
+				elasticv1alpha1.SetCondition(&status.Conditions, buildDriftedCondition(drift, false, object.GetGeneration()))
+				r.observe(ctx, elasticsearch, object, log)
+				now := metav1.Now()
+				status.LastSyncTime = &now
+				if err := r.updateObservedStatus(ctx, object); err != nil {
+					log.Error(err, "unable to update "+r.Kind.Name+" status")
+					return ctrl.Result{}, err
+				}
+				return ctrl.Result{RequeueAfter: r.requeueDelay(resyncInterval)}, nil
+			}
+			log.Info(r.Kind.EsObjectType+" drifted from its model and will be repaired", "reasons", drift.Reasons)
+		}
+
+		log.Info("create/update "+r.Kind.Name, "name", esObjectName)
+		esStatus, err := r.Kind.Apply(ctx, elasticsearch, esObjectName, model)
+		if err == errUnsupported {
+			esStatus = &utils.EsStatus{Status: utils.StatusError, Message: fmt.Sprintf("%v %v does not support %v objects", esConfig.Distribution, esConfig.VersionNumber, r.Kind.Name)}
+		}
+		recordEsStatusEvents(r.Recorder, object, esObjectName, esStatus)
+		recordOperation(r.Kind.EsKind, esStatus, status.LastAppliedModelHash)
+		driftUpdated := false
+		if drift != nil {
+			repaired := esStatus.Status == utils.StatusCreated
+			recordDrift(r.Kind.EsKind, object.GetNamespace(), object.GetName(), drift, repaired)
+			driftUpdated = elasticv1alpha1.SetCondition(&status.Conditions, buildDriftedCondition(drift, repaired, object.GetGeneration()))
+		}
+		var retryUpdated bool
+		retryDelay, retryUpdated = r.Retry.scheduleRetry(esStatus.Status, &status.Retries, &status.NextRetryTime)
+		syncUpdated := false
+		if esStatus.Status == utils.StatusCreated && (!modelApplied || drift != nil) {
+			now := metav1.Now()
+			status.LastAppliedModelHash = utils.HashString(model)
+			status.LastSyncTime = &now
+			status.Cluster = buildClusterReference(esConfig, secretResourceVersion)
+			syncUpdated = true
+		}
+		observed := esStatus.Status == utils.StatusCreated && r.observe(ctx, elasticsearch, object, log)
+		if objectStatusUpdated(status, esStatus, object.GetGeneration(), log) || driftUpdated || syncUpdated || retryUpdated || observed {
+			if err := r.updateObservedStatus(ctx, object); err != nil {
+				log.Error(err, "unable to update "+r.Kind.Name+" status")
+				return ctrl.Result{}, err
+			}
+		}
+		if esStatus.Status == utils.StatusError {
+			//blocking error no need to Requeue or Requeue after a long interval
+			return ctrl.Result{RequeueAfter: retryDelay}, nil
+		} else if err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	if retryDelay > 0 {
+		return ctrl.Result{RequeueAfter: retryDelay}, nil
+	} else if status.Status == utils.StatusRetry {
+		return ctrl.Result{RequeueAfter: r.Retry.getRetryInterval()}, nil
+	}
+
+	if delay := r.requeueDelay(resyncInterval); delay > 0 && object.GetDeletionTimestamp().IsZero() {
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+func (r *ElasticObjectReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	registerManagedObjectsCollector(mgr.GetClient())
+	registerManagedObjectsKind(r.Kind)
+
+	if err := mgr.GetFieldIndexer().IndexField(r.Kind.NewObject(), elasticURISecretField, func(obj runtime.Object) []string {
+		object := obj.(elasticv1alpha1.ElasticObject)
+		elasticURI, _ := object.GetConnection()
+		return append(indexElasticURISecrets(object.GetNamespace(), elasticURI), indexModelSecrets(object)...)
+	}); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(r.Kind.NewObject(), elasticURINamespaceField, func(obj runtime.Object) []string {
+		object := obj.(elasticv1alpha1.ElasticObject)
+		elasticURI, _ := object.GetConnection()
+		return indexElasticURINamespace(object.GetNamespace(), elasticURI)
+	}); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(r.Kind.NewObject(), connectionRefField, func(obj runtime.Object) []string {
+		_, connectionRef := obj.(elasticv1alpha1.ElasticObject).GetConnection()
+		return indexConnectionRef(connectionRef)
+	}); err != nil {
+		return err
+	}
+
+	// objects referencing a secret are reconciled when it is created, fixed or rotated
+	secretHandler := &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.requestsForSecret)}
+	// objects referencing a connection are reconciled when its spec changes, not on the status updates of its probes
+	connectionHandler := &connectionChangedHandler{&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.requestsForConnection)}}
+	// objects referencing secrets of another namespace are reconciled when its grants change
+	grantHandler := &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.requestsForGrant)}
+
+	builder := ctrl.NewControllerManagedBy(mgr).
+		For(r.Kind.NewObject()).
+		Watches(&source.Kind{Type: &v1.Secret{}}, secretHandler).
+		Watches(&source.Kind{Type: &elasticv1alpha1.ElasticConnection{}}, connectionHandler).
+		Watches(&source.Kind{Type: &elasticv1alpha1.ClusterElasticConnection{}}, connectionHandler).
+		Watches(&source.Kind{Type: &elasticv1alpha1.ElasticSecretGrant{}}, grantHandler).
+		WithEventFilter(statusUpdateFilter(r.Kind.NewObject()))
+	if r.NamespacesRegexFilter != "" {
+		builder = builder.WithEventFilter(namespacesRegexFilter(r.Kind.NewObject(), r.NamespacesRegexFilter, r.Log))
+	}
+	return builder.Complete(r)
+}
+
+// requestsForSecret invalidates the connections read from the secret, whatever its namespace, so that the clients of
+// rotated credentials are renewed, and returns a request for each object of the kind referencing it
+func (r *ElasticObjectReconciler) requestsForSecret(secret handler.MapObject) []reconcile.Request {
+	r.EsClients.InvalidateSecret(secret.Meta.GetNamespace(), secret.Meta.GetName())
+
+	requests := r.listRequests(client.MatchingFields{elasticURISecretField: secret.Meta.GetNamespace() + "/" + secret.Meta.GetName()})
+
+	connections, err := connectionsForSecret(r.Client, secret.Meta.GetNamespace(), secret.Meta.GetName())
+	if err != nil {
+		r.Log.Error(err, "unable to list connections referencing secret", "namespace", secret.Meta.GetNamespace(), "secret", secret.Meta.GetName())
+	}
+	for _, connection := range connections {
+		requests = append(requests, r.requestsForConnectionKey(connection)...)
+	}
+	return requests
+}
+
+// requestsForGrant returns a request for each object of the kind referencing secrets of the namespace of an
+// ElasticSecretGrant from another namespace
+func (r *ElasticObjectReconciler) requestsForGrant(grant handler.MapObject) []reconcile.Request {
+	return r.listRequests(client.MatchingFields{elasticURINamespaceField: grant.Meta.GetNamespace()})
+}
+
+// requestsForConnection returns a request for each object of the kind referencing an ElasticConnection or a
+// ClusterElasticConnection
+func (r *ElasticObjectReconciler) requestsForConnection(connection handler.MapObject) []reconcile.Request {
+	return r.requestsForConnectionKey(connectionKeyOf(connection))
+}
+
+func (r *ElasticObjectReconciler) requestsForConnectionKey(connection connectionKey) []reconcile.Request {
+	return r.listRequests(client.InNamespace(connection.namespace), client.MatchingFields{connectionRefField: connection.ref})
+}
+
+// listRequests returns a request for each object of the kind matching the options
+func (r *ElasticObjectReconciler) listRequests(opts ...client.ListOption) []reconcile.Request {
+	list := r.Kind.NewList()
+	if err := r.List(context.Background(), list, opts...); err != nil {
+		r.Log.Error(err, "unable to list "+r.Kind.Name+" objects")
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, item := range list.GetObjects() {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: item.GetNamespace(), Name: item.GetName()}})
+	}
+	return requests
+}
+
+// requeueDelay returns the delay before the next reconciliation of an applied object, the resync interval, shortened to
+// observeInterval for the kinds observing their objects
+func (r *ElasticObjectReconciler) requeueDelay(resyncInterval time.Duration) time.Duration {
+	if r.Kind.Observe != nil && (resyncInterval == 0 || resyncInterval > observeInterval) {
+		return observeInterval
+	}
+	return resyncInterval
+}
+
+// observe performs the operations requested on the objects of the kinds operating them, refreshes the status fields
+// read from elasticsearch of the kinds observing their objects, and returns true when they changed. Failures are only
+// logged and recorded as events, they do not change the status of the object
+func (r *ElasticObjectReconciler) observe(ctx context.Context, elasticsearch utils.Elasticsearch, object elasticv1alpha1.ElasticObject, log logr.Logger) bool {
+	operated := false
+	if r.Kind.Operate != nil {
+		esStatus, err := r.Kind.Operate(ctx, elasticsearch, object)
+		if err != nil && err != errUnsupported {
+			log.Error(err, "unable to operate "+r.Kind.EsObjectType, "name", object.GetEsObjectName())
+		}
+		if esStatus != nil {
+			recordEsStatusEvents(r.Recorder, object, object.GetEsObjectName(), esStatus)
+			operated = true
+		}
+	}
+	if r.Kind.Observe == nil {
+		return operated
+	}
+	updated, err := r.Kind.Observe(ctx, elasticsearch, object)
+	if err != nil && err != errUnsupported {
+		log.Error(err, "unable to observe "+r.Kind.EsObjectType, "name", object.GetEsObjectName())
+	}
+	return updated || operated
+}
+
+// updateObservedStatus writes the status of an object once it was observed, retrying on conflict with the resource
+// version of the latest object: the status records the operations performed, like a rollover, which would be performed
+// again if the status was not written. The status is owned by the reconciler, the one of the latest object is replaced
+func (r *ElasticObjectReconciler) updateObservedStatus(ctx context.Context, object elasticv1alpha1.ElasticObject) error {
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		err := r.Status().Update(ctx, object)
+		if apierrors.IsConflict(err) {
+			latest := r.Kind.NewObject()
+			if getErr := r.Get(ctx, types.NamespacedName{Namespace: object.GetNamespace(), Name: object.GetName()}, latest); getErr != nil {
+				return getErr
+			}
+			object.SetResourceVersion(latest.GetResourceVersion())
+		}
+		return err
+	})
+}
+
+func objectStatusUpdated(objectStatus *elasticv1alpha1.ElasticObjectStatus, esStatus *utils.EsStatus, generation int64, log logr.Logger) bool {
+	if esStatus == nil {
+		return false
+	}
+
+	updated := false
+	if objectStatus.Status != esStatus.Status ||
+		(objectStatus.Status != utils.StatusCreated && objectStatus.Message != esStatus.Message) {
+		log.Info("update status", "from", objectStatus.Status, "to", esStatus.Status)
+		objectStatus.Status = esStatus.Status
+		objectStatus.HttpCodeStatus = esStatus.HttpCodeStatus
+		objectStatus.Message = esStatus.Message
+		updated = true
+	}
+	if objectStatus.ObservedGeneration != generation {
+		objectStatus.ObservedGeneration = generation
+		updated = true
+	}
+	return statusConditionsUpdated(&objectStatus.Conditions, esStatus, generation) || updated
+}
+
+func (r *ElasticObjectReconciler) manageFinalizer(ctx context.Context, object elasticv1alpha1.ElasticObject, elasticsearch utils.Elasticsearch, log logr.Logger) (bool, error) {
+	finalizerName := fmt.Sprintf("finalizer.%v", elasticv1alpha1.GroupVersion.Group)
+	deleteRequest := false
+	esObjectName := object.GetEsObjectName()
+
+	if object.GetDeletionTimestamp().IsZero() {
+		if !utils.ContainsString(object.GetFinalizers(), finalizerName) {
+			log.Info("register a finalizer")
+			object.SetFinalizers(append(object.GetFinalizers(), finalizerName))
+			if err := r.Update(ctx, object); err != nil {
+				return deleteRequest, err
+			}
+		}
+	} else {
+		log.Info(strings.ToLower(r.Kind.Name) + " is being deleted")
+		deleteRequest = true
+		if utils.ContainsString(object.GetFinalizers(), finalizerName) {
+			if object.GetAnnotations()[DeleteInClusterAnnotation] == "true" {
+				if err := r.Kind.Delete(ctx, elasticsearch, esObjectName); err != nil {
+					log.Error(err, "error while deleting "+r.Kind.EsObjectType, "name", esObjectName)
+					r.Recorder.Eventf(object, v1.EventTypeWarning, EventReasonDeleteFailed, "error while deleting %v %v: %v", r.Kind.EsObjectType, esObjectName, err)
+				} else {
+					operationsTotal.WithLabelValues(r.Kind.EsKind, OperationDelete).Inc()
+					r.Recorder.Eventf(object, v1.EventTypeNormal, EventReasonDeleted, "%v %v deleted", r.Kind.EsObjectType, esObjectName)
+				}
+			} else {
+				log.Info(strings.ToLower(r.Kind.Name)+" deletion will not delete elasticsearch "+r.Kind.EsObjectType, "name", esObjectName)
+				r.Recorder.Eventf(object, v1.EventTypeNormal, EventReasonDeleteSkipped, "%v %v kept in elasticsearch, annotation %v is not set to true", r.Kind.EsObjectType, esObjectName, DeleteInClusterAnnotation)
+			}
+
+			driftedObjects.DeleteLabelValues(r.Kind.EsKind, object.GetNamespace(), object.GetName())
+
+			// remove finalizer from the list and update it.
+			object.SetFinalizers(utils.RemoveString(object.GetFinalizers(), finalizerName))
+			if err := r.Update(ctx, object); err != nil {
+				return deleteRequest, err
+			}
+		}
+	}
+	return deleteRequest, nil
+}
@@ -0,0 +1,111 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"time"
+
+	elasticv1alpha1 "github.com/Carrefour-Group/elastic-phenix-operator/pkg/api/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticslmpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticslmpolicies/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticslmpolicies/finalizers,verbs=get;list;watch;create;update;patch;delete
+
+// ElasticSLMPolicyKind applies ElasticSLMPolicies with the _slm/policy API, and reads their last snapshots and stats
+var ElasticSLMPolicyKind = ElasticObjectKind{
+	Name:         "ElasticSLMPolicy",
+	EsKind:       utils.SLMPolicy,
+	EsObjectType: "SLM policy",
+	NewObject:    func() elasticv1alpha1.ElasticObject { return &elasticv1alpha1.ElasticSLMPolicy{} },
+	NewList:      func() elasticv1alpha1.ElasticObjectList { return &elasticv1alpha1.ElasticSLMPolicyList{} },
+	Apply: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string, model string) (*utils.EsStatus, error) {
+		if policies, ok := elasticsearch.(utils.EsSLMPolicies); ok {
+			return policies.CreateOrUpdateSLMPolicy(ctx, name, model)
+		}
+		return nil, errUnsupported
+	},
+	GetDrift: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string, model string) (*utils.EsDrift, error) {
+		if policies, ok := elasticsearch.(utils.EsSLMPolicies); ok {
+			return policies.GetSLMPolicyDrift(ctx, name, model)
+		}
+		return nil, errUnsupported
+	},
+	Delete: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string) error {
+		if policies, ok := elasticsearch.(utils.EsSLMPolicies); ok {
+			return policies.DeleteSLMPolicy(ctx, name)
+		}
+		return errUnsupported
+	},
+	Observe: observeSLMPolicy,
+}
+
+// observeSLMPolicy reads the last success and failure, the next execution and the stats of the policy
+func observeSLMPolicy(ctx context.Context, elasticsearch utils.Elasticsearch, object elasticv1alpha1.ElasticObject) (bool, error) {
+	policies, ok := elasticsearch.(utils.EsSLMPolicies)
+	if !ok {
+		return false, errUnsupported
+	}
+	status := &object.(*elasticv1alpha1.ElasticSLMPolicy).Status
+
+	live, err := policies.GetSLMPolicyStatus(ctx, object.GetEsObjectName())
+	if err != nil {
+		return false, err
+	}
+	if live == nil {
+		live = &utils.EsSLMPolicyStatus{}
+	}
+	lastSuccess, lastFailure := buildSnapshotExecution(live.LastSuccess), buildSnapshotExecution(live.LastFailure)
+	var nextExecution *metav1.Time
+	if live.NextExecution > 0 {
+		nextExecution = millisToTime(live.NextExecution)
+	}
+	updated := !snapshotExecutionEqual(status.LastSuccess, lastSuccess) || !snapshotExecutionEqual(status.LastFailure, lastFailure) ||
+		!timeEqual(status.NextExecution, nextExecution) || status.SnapshotsTaken != live.SnapshotsTaken || status.SnapshotsFailed != live.SnapshotsFailed
+	status.LastSuccess, status.LastFailure, status.NextExecution = lastSuccess, lastFailure, nextExecution
+	status.SnapshotsTaken, status.SnapshotsFailed = live.SnapshotsTaken, live.SnapshotsFailed
+	return updated, nil
+}
+
+// buildSnapshotExecution returns the status of a snapshot of an SLM policy, nil when there was none
+func buildSnapshotExecution(execution *utils.EsSnapshotExecution) *elasticv1alpha1.ElasticSnapshotExecution {
+	if execution == nil {
+		return nil
+	}
+	return &elasticv1alpha1.ElasticSnapshotExecution{SnapshotName: execution.SnapshotName, Time: *millisToTime(execution.Time), Details: execution.Details}
+}
+
+// millisToTime returns the time of epoch millis, truncated to the second like the times of the status once stored
+func millisToTime(millis int64) *metav1.Time {
+	t := metav1.NewTime(time.Unix(millis/1000, 0))
+	return &t
+}
+
+func snapshotExecutionEqual(a *elasticv1alpha1.ElasticSnapshotExecution, b *elasticv1alpha1.ElasticSnapshotExecution) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.SnapshotName == b.SnapshotName && a.Time.Equal(&b.Time) && a.Details == b.Details
+}
+
+func timeEqual(a *metav1.Time, b *metav1.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(b)
+}
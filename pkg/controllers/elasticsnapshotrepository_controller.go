@@ -0,0 +1,54 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+
+	elasticv1alpha1 "github.com/Carrefour-Group/elastic-phenix-operator/pkg/api/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticsnapshotrepositories,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticsnapshotrepositories/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticsnapshotrepositories/finalizers,verbs=get;list;watch;create;update;patch;delete
+
+// ElasticSnapshotRepositoryKind applies ElasticSnapshotRepositories with the _snapshot API, their settings read from
+// secrets included
+var ElasticSnapshotRepositoryKind = ElasticObjectKind{
+	Name:         "ElasticSnapshotRepository",
+	EsKind:       utils.SnapshotRepository,
+	EsObjectType: "snapshot repository",
+	NewObject:    func() elasticv1alpha1.ElasticObject { return &elasticv1alpha1.ElasticSnapshotRepository{} },
+	NewList:      func() elasticv1alpha1.ElasticObjectList { return &elasticv1alpha1.ElasticSnapshotRepositoryList{} },
+	Apply: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string, model string) (*utils.EsStatus, error) {
+		if repositories, ok := elasticsearch.(utils.EsSnapshotRepositories); ok {
+			return repositories.CreateOrUpdateSnapshotRepository(ctx, name, model)
+		}
+		return nil, errUnsupported
+	},
+	GetDrift: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string, model string) (*utils.EsDrift, error) {
+		if repositories, ok := elasticsearch.(utils.EsSnapshotRepositories); ok {
+			return repositories.GetSnapshotRepositoryDrift(ctx, name, model)
+		}
+		return nil, errUnsupported
+	},
+	Delete: func(ctx context.Context, elasticsearch utils.Elasticsearch, name string) error {
+		if repositories, ok := elasticsearch.(utils.EsSnapshotRepositories); ok {
+			return repositories.DeleteSnapshotRepository(ctx, name)
+		}
+		return errUnsupported
+	},
+}
@@ -19,12 +19,19 @@ import (
 	"fmt"
 	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
 	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/event"
-	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"strings"
+	"time"
 
 	elasticv1alpha1 "github.com/Carrefour-Group/elastic-phenix-operator/pkg/api/v1alpha1"
 )
@@ -35,13 +42,20 @@ type ElasticTemplateReconciler struct {
 	Log                   logr.Logger
 	Scheme                *runtime.Scheme
 	NamespacesRegexFilter string
+	ResyncInterval        time.Duration
+	ResyncRepair          bool
+	Recorder              record.EventRecorder
+	EsClients             *utils.EsClientRegistry
+	Retry                 RetryOptions
 }
 
 // +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elastictemplates,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elastictemplates/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elastictemplates/finalizers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=elastic.carrefour.com,resources=elasticsecretgrants,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch
 
 func (r *ElasticTemplateReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 
@@ -55,41 +69,110 @@ func (r *ElasticTemplateReconciler) Reconcile(req ctrl.Request) (ctrl.Result, er
 	if err := r.Get(ctx, req.NamespacedName, &elasticTemplate); err != nil {
 		if apierrors.IsNotFound(err) {
 			log.Info("ElasticTemplate not found")
+			r.EsClients.Release(registryOwner("ElasticTemplate", req.NamespacedName))
 		} else {
 			log.Error(err, "unable to fetch elasticTemplate object")
 		}
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	esConfig, err := utils.BuildEsConfigFromSecretSelector(elasticTemplate.ObjectMeta.Namespace, elasticTemplate.Spec.ElasticURI.SecretKeyRef, r.Client)
+	esConfig, secretResourceVersion, err := buildEsConfig(registryOwner("ElasticTemplate", req.NamespacedName), elasticTemplate.ObjectMeta.Namespace, elasticTemplate.Spec.ElasticURI, elasticTemplate.Spec.ConnectionRef, r.Client, r.EsClients)
+	if released, updateErr := releaseUngrantedObject(ctx, r.Client, r.Recorder, &elasticTemplate, utils.Template, err); released {
+		log.Info("elastictemplate is being deleted without its connection, the elasticsearch template is kept", "reason", err.Error())
+		return ctrl.Result{}, updateErr
+	}
 	if err != nil {
-		log.Error(err, "unable to build EsConfig from a secret")
-		if templateStatusUpdated(&elasticTemplate.Status, &utils.EsStatus{Status: utils.StatusError, Message: err.Error()}, log) {
-			r.Status().Update(ctx, &elasticTemplate)
+		log.Error(err, "unable to build EsConfig from the connection")
+		r.Recorder.Eventf(&elasticTemplate, v1.EventTypeWarning, EventReasonValidationFailed, "invalid elasticsearch connection: %v", err)
+		delay, _ := r.Retry.scheduleRetry(utils.StatusError, &elasticTemplate.Status.Retries, &elasticTemplate.Status.NextRetryTime)
+		templateStatusUpdated(&elasticTemplate.Status, &utils.EsStatus{Status: utils.StatusError, Message: err.Error()}, elasticTemplate.Generation, log)
+		if err := r.Status().Update(ctx, &elasticTemplate); err != nil && !apierrors.IsConflict(err) {
+			log.Error(err, "unable to update ElasticTemplate status")
+			return ctrl.Result{}, err
 		}
-		return ctrl.Result{RequeueAfter: ErrorInterval}, nil
+		return ctrl.Result{RequeueAfter: delay}, nil
 	}
-	log.Info("esConfig generated from secret", "EsConfig", esConfig, "EsVersion", esConfig.Version)
-	var elasticsearch = buildElasticsearchFromVersion(esConfig.Version)
-	err2 := elasticsearch.NewClient(esConfig, log)
+	resyncInterval, resyncRepair := resyncOptions(elasticTemplate.Spec.Resync, r.ResyncInterval, r.ResyncRepair)
+
+	log.Info("esConfig generated from connection", "EsConfig", esConfig, "EsVersion", esConfig.VersionNumber, "Distribution", esConfig.Distribution, "Backend", esConfig.Backend)
+	elasticsearch, err2 := utils.NewElasticsearch(esConfig, log)
 	if err2 != nil {
 		return ctrl.Result{}, err2
 	}
 
+	var retryDelay time.Duration
 	if deleteRequest, err := manageTemplateFinalizer(ctx, elasticTemplate, elasticsearch, log, r); err != nil {
 		return ctrl.Result{}, err
 	} else if !deleteRequest {
-		if err := elasticsearch.PingES(ctx); err != nil {
-			if templateStatusUpdated(&elasticTemplate.Status, &utils.EsStatus{Status: utils.StatusRetry, Message: err.Error()}, log) {
-				if err := r.Status().Update(ctx, &elasticTemplate); err != nil {
-					return ctrl.Result{RequeueAfter: RetryInterval}, nil
+		// a model applied with another elasticURI secret is applied again, the secret may have been fixed or rotated
+		modelApplied := isModelApplied(elasticTemplate.Status.Conditions, elasticTemplate.Generation) && isSameCluster(elasticTemplate.Status.Cluster, esConfig, secretResourceVersion)
+		if wait := nextResync(elasticTemplate.Status.LastSyncTime, resyncInterval); resyncInterval > 0 && modelApplied && wait > 0 {
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+
+		err := elasticsearch.PingES(ctx)
+		recordPing(esConfig, err)
+		if err != nil {
+			r.Recorder.Eventf(&elasticTemplate, v1.EventTypeWarning, EventReasonElasticsearchUnreachable, "elasticsearch %v is unreachable: %v", esConfig, err)
+			delay, _ := r.Retry.scheduleRetry(utils.StatusRetry, &elasticTemplate.Status.Retries, &elasticTemplate.Status.NextRetryTime)
+			templateStatusUpdated(&elasticTemplate.Status, &utils.EsStatus{Status: utils.StatusRetry, Message: err.Error()}, elasticTemplate.Generation, log)
+			if err := r.Status().Update(ctx, &elasticTemplate); err != nil && !apierrors.IsConflict(err) {
+				log.Error(err, "unable to update ElasticTemplate status")
+				return ctrl.Result{}, err
+			}
+			return ctrl.Result{RequeueAfter: delay}, nil
+		}
+		var drift *utils.EsDrift
+		if resyncInterval > 0 && modelApplied {
+			log.Info("resync ElasticTemplate", "templateName", elasticTemplate.Spec.TemplateName)
+			if drift, err = elasticsearch.GetTemplateDrift(ctx, *elasticTemplate.Spec.TemplateName, *elasticTemplate.Spec.Model, elasticTemplate.Spec.Order); err != nil {
+				log.Error(err, "unable to compare template with its model")
+				delay, _ := r.Retry.scheduleRetry(utils.StatusRetry, &elasticTemplate.Status.Retries, &elasticTemplate.Status.NextRetryTime)
+				if err := r.Status().Update(ctx, &elasticTemplate); err != nil && !apierrors.IsConflict(err) {
+					log.Error(err, "unable to update ElasticTemplate status")
+					return ctrl.Result{}, err
+				}
+				return ctrl.Result{RequeueAfter: delay}, nil
+			}
+			if !drift.IsDrifted() || !resyncRepair {
+				if drift.IsDrifted() {
+					log.Info("template drifted from its model", "reasons", drift.Reasons)
+					r.Recorder.Event(&elasticTemplate, v1.EventTypeWarning, ReasonDriftDetected, strings.Join(drift.Reasons, ", "))
+				}
+				recordDrift(utils.Template, elasticTemplate.Namespace, elasticTemplate.Name, drift, false)
+				elasticv1alpha1.SetCondition(&elasticTemplate.Status.Conditions, buildDriftedCondition(drift, false, elasticTemplate.Generation))
+				now := metav1.Now()
+				elasticTemplate.Status.LastSyncTime = &now
+				if err := r.Status().Update(ctx, &elasticTemplate); err != nil && !apierrors.IsConflict(err) {
+					log.Error(err, "unable to update ElasticTemplate status")
+					return ctrl.Result{}, err
 				}
+				return ctrl.Result{RequeueAfter: resyncInterval}, nil
 			}
-			return ctrl.Result{RequeueAfter: RetryInterval}, nil
+			log.Info("template drifted from its model and will be repaired", "reasons", drift.Reasons)
 		}
+
 		log.Info("create/update ElasticTemplate", "templateName", elasticTemplate.Spec.TemplateName)
 		esStatus, err := elasticsearch.CreateOrUpdateTemplate(ctx, *elasticTemplate.Spec.TemplateName, *elasticTemplate.Spec.Model, elasticTemplate.Spec.Order)
-		if templateStatusUpdated(&elasticTemplate.Status, esStatus, log) {
+		recordEsStatusEvents(r.Recorder, &elasticTemplate, *elasticTemplate.Spec.TemplateName, esStatus)
+		recordOperation(utils.Template, esStatus, elasticTemplate.Status.LastAppliedModelHash)
+		driftUpdated := false
+		if drift != nil {
+			repaired := esStatus.Status == utils.StatusCreated
+			recordDrift(utils.Template, elasticTemplate.Namespace, elasticTemplate.Name, drift, repaired)
+			driftUpdated = elasticv1alpha1.SetCondition(&elasticTemplate.Status.Conditions, buildDriftedCondition(drift, repaired, elasticTemplate.Generation))
+		}
+		var retryUpdated bool
+		retryDelay, retryUpdated = r.Retry.scheduleRetry(esStatus.Status, &elasticTemplate.Status.Retries, &elasticTemplate.Status.NextRetryTime)
+		syncUpdated := false
+		if esStatus.Status == utils.StatusCreated && (!modelApplied || drift != nil) {
+			now := metav1.Now()
+			elasticTemplate.Status.LastAppliedModelHash = utils.HashString(*elasticTemplate.Spec.Model)
+			elasticTemplate.Status.LastSyncTime = &now
+			elasticTemplate.Status.Cluster = buildClusterReference(esConfig, secretResourceVersion)
+			syncUpdated = true
+		}
+		if templateStatusUpdated(&elasticTemplate.Status, esStatus, elasticTemplate.Generation, log) || driftUpdated || syncUpdated || retryUpdated {
 			if err := r.Status().Update(ctx, &elasticTemplate); err != nil {
 				if apierrors.IsConflict(err) {
 					log.Info("conflict: operation cannot be fulfilled on ElasticTemplate. Requeue to try again")
@@ -101,52 +184,146 @@ func (r *ElasticTemplateReconciler) Reconcile(req ctrl.Request) (ctrl.Result, er
 		}
 		if esStatus.Status == utils.StatusError {
 			//blocking error no need to Requeue or Requeue after a long interval
-			return ctrl.Result{RequeueAfter: ErrorInterval}, nil
+			return ctrl.Result{RequeueAfter: retryDelay}, nil
 		} else if err != nil {
 			return ctrl.Result{}, err
 		}
 	}
 
-	if elasticTemplate.Status.Status == utils.StatusRetry {
-		return ctrl.Result{RequeueAfter: RetryInterval}, nil
+	if retryDelay > 0 {
+		return ctrl.Result{RequeueAfter: retryDelay}, nil
+	} else if elasticTemplate.Status.Status == utils.StatusRetry {
+		return ctrl.Result{RequeueAfter: r.Retry.getRetryInterval()}, nil
+	}
+
+	if resyncInterval > 0 && elasticTemplate.ObjectMeta.DeletionTimestamp.IsZero() {
+		return ctrl.Result{RequeueAfter: resyncInterval}, nil
 	}
 
 	return ctrl.Result{}, nil
 }
 
 func (r *ElasticTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	if r.NamespacesRegexFilter == "" {
-		return ctrl.NewControllerManagedBy(mgr).
-			For(&elasticv1alpha1.ElasticTemplate{}).
-			Complete(r)
-	}
+	registerManagedObjectsCollector(mgr.GetClient())
 
-	var regex = r.NamespacesRegexFilter
-	namespacesRegexFilter := predicate.Funcs{
-		CreateFunc:  func(ce event.CreateEvent) bool { return utils.FilterByNamespacesRegex(ce.Meta, regex, r.Log) },
-		DeleteFunc:  func(ce event.DeleteEvent) bool { return utils.FilterByNamespacesRegex(ce.Meta, regex, r.Log) },
-		UpdateFunc:  func(ce event.UpdateEvent) bool { return utils.FilterByNamespacesRegex(ce.MetaNew, regex, r.Log) },
-		GenericFunc: func(ce event.GenericEvent) bool { return utils.FilterByNamespacesRegex(ce.Meta, regex, r.Log) },
+	if err := mgr.GetFieldIndexer().IndexField(&elasticv1alpha1.ElasticTemplate{}, elasticURISecretField, func(obj runtime.Object) []string {
+		object := obj.(*elasticv1alpha1.ElasticTemplate)
+		return indexElasticURISecrets(object.Namespace, object.Spec.ElasticURI)
+	}); err != nil {
+		return err
+	}
+	if err := mgr.GetFieldIndexer().IndexField(&elasticv1alpha1.ElasticTemplate{}, elasticURINamespaceField, func(obj runtime.Object) []string {
+		object := obj.(*elasticv1alpha1.ElasticTemplate)
+		return indexElasticURINamespace(object.Namespace, object.Spec.ElasticURI)
+	}); err != nil {
+		return err
 	}
+	if err := mgr.GetFieldIndexer().IndexField(&elasticv1alpha1.ElasticTemplate{}, connectionRefField, func(obj runtime.Object) []string {
+		return indexConnectionRef(obj.(*elasticv1alpha1.ElasticTemplate).Spec.ConnectionRef)
+	}); err != nil {
+		return err
+	}
+
+	// objects referencing a secret are reconciled when it is created, fixed or rotated
+	secretHandler := &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.requestsForSecret)}
+	// objects referencing a connection are reconciled when its spec changes, not on the status updates of its probes
+	connectionHandler := &connectionChangedHandler{&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.requestsForConnection)}}
+	// objects referencing secrets of another namespace are reconciled when its grants change
+	grantHandler := &handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.requestsForGrant)}
 
-	return ctrl.NewControllerManagedBy(mgr).
+	builder := ctrl.NewControllerManagedBy(mgr).
 		For(&elasticv1alpha1.ElasticTemplate{}).
-		WithEventFilter(namespacesRegexFilter).
-		Complete(r)
+		Watches(&source.Kind{Type: &v1.Secret{}}, secretHandler).
+		Watches(&source.Kind{Type: &elasticv1alpha1.ElasticConnection{}}, connectionHandler).
+		Watches(&source.Kind{Type: &elasticv1alpha1.ClusterElasticConnection{}}, connectionHandler).
+		Watches(&source.Kind{Type: &elasticv1alpha1.ElasticSecretGrant{}}, grantHandler).
+		WithEventFilter(statusUpdateFilter(&elasticv1alpha1.ElasticTemplate{}))
+	if r.NamespacesRegexFilter != "" {
+		builder = builder.WithEventFilter(namespacesRegexFilter(&elasticv1alpha1.ElasticTemplate{}, r.NamespacesRegexFilter, r.Log))
+	}
+	return builder.Complete(r)
 }
 
-func templateStatusUpdated(objectStatus *elasticv1alpha1.ElasticTemplateStatus, esStatus *utils.EsStatus, log logr.Logger) bool {
-	if esStatus != nil &&
-		(objectStatus.Status != esStatus.Status ||
-			(objectStatus.Status != utils.StatusCreated && objectStatus.Message != esStatus.Message)) {
+// requestsForSecret invalidates the connections read from the secret, whatever its namespace, so that the clients of
+// rotated credentials are renewed, and returns a request for each ElasticTemplate referencing it
+func (r *ElasticTemplateReconciler) requestsForSecret(secret handler.MapObject) []reconcile.Request {
+	r.EsClients.InvalidateSecret(secret.Meta.GetNamespace(), secret.Meta.GetName())
+
+	var elasticTemplates elasticv1alpha1.ElasticTemplateList
+	if err := r.List(context.Background(), &elasticTemplates, client.MatchingFields{elasticURISecretField: secret.Meta.GetNamespace() + "/" + secret.Meta.GetName()}); err != nil {
+		r.Log.Error(err, "unable to list ElasticTemplates referencing secret", "namespace", secret.Meta.GetNamespace(), "secret", secret.Meta.GetName())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, item := range elasticTemplates.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: item.Namespace, Name: item.Name}})
+	}
+
+	connections, err := connectionsForSecret(r.Client, secret.Meta.GetNamespace(), secret.Meta.GetName())
+	if err != nil {
+		r.Log.Error(err, "unable to list connections referencing secret", "namespace", secret.Meta.GetNamespace(), "secret", secret.Meta.GetName())
+	}
+	for _, connection := range connections {
+		requests = append(requests, r.requestsForConnectionKey(connection)...)
+	}
+	return requests
+}
+
+// requestsForGrant returns a request for each ElasticTemplate referencing secrets of the namespace of an ElasticSecretGrant
+// from another namespace
+func (r *ElasticTemplateReconciler) requestsForGrant(grant handler.MapObject) []reconcile.Request {
+	var elasticTemplates elasticv1alpha1.ElasticTemplateList
+	if err := r.List(context.Background(), &elasticTemplates, client.MatchingFields{elasticURINamespaceField: grant.Meta.GetNamespace()}); err != nil {
+		r.Log.Error(err, "unable to list ElasticTemplates referencing secrets of namespace", "namespace", grant.Meta.GetNamespace())
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, item := range elasticTemplates.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: item.Namespace, Name: item.Name}})
+	}
+	return requests
+}
+
+// requestsForConnection returns a request for each ElasticTemplate referencing an ElasticConnection or a ClusterElasticConnection
+func (r *ElasticTemplateReconciler) requestsForConnection(connection handler.MapObject) []reconcile.Request {
+	return r.requestsForConnectionKey(connectionKeyOf(connection))
+}
+
+func (r *ElasticTemplateReconciler) requestsForConnectionKey(connection connectionKey) []reconcile.Request {
+	var elasticTemplates elasticv1alpha1.ElasticTemplateList
+	if err := r.List(context.Background(), &elasticTemplates, client.InNamespace(connection.namespace), client.MatchingFields{connectionRefField: connection.ref}); err != nil {
+		r.Log.Error(err, "unable to list ElasticTemplates referencing connection", "namespace", connection.namespace, "connection", connection.ref)
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for _, item := range elasticTemplates.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: item.Namespace, Name: item.Name}})
+	}
+	return requests
+}
+
+func templateStatusUpdated(objectStatus *elasticv1alpha1.ElasticTemplateStatus, esStatus *utils.EsStatus, generation int64, log logr.Logger) bool {
+	if esStatus == nil {
+		return false
+	}
+
+	updated := false
+	if objectStatus.Status != esStatus.Status ||
+		(objectStatus.Status != utils.StatusCreated && objectStatus.Message != esStatus.Message) {
 		log.Info("update status", "from", objectStatus.Status, "to", esStatus.Status)
 		objectStatus.Status = esStatus.Status
 		objectStatus.HttpCodeStatus = esStatus.HttpCodeStatus
 		objectStatus.Message = esStatus.Message
-		return true
+		updated = true
 	}
-
-	return false
+	if objectStatus.ObservedGeneration != generation {
+		objectStatus.ObservedGeneration = generation
+		updated = true
+	}
+	return statusConditionsUpdated(&objectStatus.Conditions, esStatus, generation) || updated
 }
 
 func manageTemplateFinalizer(ctx context.Context, elasticTemplate elasticv1alpha1.ElasticTemplate, elasticsearch utils.Elasticsearch, log logr.Logger, r *ElasticTemplateReconciler) (bool, error) {
@@ -168,11 +345,18 @@ func manageTemplateFinalizer(ctx context.Context, elasticTemplate elasticv1alpha
 			if elasticTemplate.Annotations[DeleteInClusterAnnotation] == "true" {
 				if err := elasticsearch.DeleteTemplate(ctx, *elasticTemplate.Spec.TemplateName); err != nil {
 					log.Error(err, "error while deleting elasticTemplate", "templateName", *elasticTemplate.Spec.TemplateName)
+					r.Recorder.Eventf(&elasticTemplate, v1.EventTypeWarning, EventReasonDeleteFailed, "error while deleting template %v: %v", *elasticTemplate.Spec.TemplateName, err)
+				} else {
+					operationsTotal.WithLabelValues(utils.Template, OperationDelete).Inc()
+					r.Recorder.Eventf(&elasticTemplate, v1.EventTypeNormal, EventReasonDeleted, "template %v deleted", *elasticTemplate.Spec.TemplateName)
 				}
 			} else {
 				log.Info("elastictemplate deletion will not delete elasticsearch template", "templateName", *elasticTemplate.Spec.TemplateName)
+				r.Recorder.Eventf(&elasticTemplate, v1.EventTypeNormal, EventReasonDeleteSkipped, "template %v kept in elasticsearch, annotation %v is not set to true", *elasticTemplate.Spec.TemplateName, DeleteInClusterAnnotation)
 			}
 
+			driftedObjects.DeleteLabelValues(utils.Template, elasticTemplate.Namespace, elasticTemplate.Name)
+
 			// remove finalizer from the list and update it.
 			elasticTemplate.ObjectMeta.Finalizers = utils.RemoveString(elasticTemplate.ObjectMeta.Finalizers, finalizerName)
 			if err := r.Update(ctx, &elasticTemplate); err != nil {
@@ -0,0 +1,173 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	elasticv1alpha1 "github.com/Carrefour-Group/elastic-phenix-operator/pkg/api/v1alpha1"
+	"github.com/Carrefour-Group/elastic-phenix-operator/pkg/utils"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sync"
+)
+
+const (
+	metricsNamespace = "elastic_phenix_operator"
+
+	OperationCreate = "create"
+	OperationUpdate = "update"
+	OperationDelete = "delete"
+)
+
+var (
+	driftDetectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "drift_detected_total",
+		Help:      "Number of resyncs that found an elasticsearch object different from its model",
+	}, []string{"kind"})
+
+	driftRepairedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "drift_repaired_total",
+		Help:      "Number of drifted elasticsearch objects repaired by applying their model again",
+	}, []string{"kind"})
+
+	driftedObjects = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "drifted_objects",
+		Help:      "Whether an object differs from its model at the last resync (1) or not (0)",
+	}, []string{"kind", "namespace", "name"})
+
+	operationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "operations_total",
+		Help:      "Number of elasticsearch objects created, updated or deleted by kind",
+	}, []string{"kind", "operation"})
+
+	elasticsearchUp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "elasticsearch_up",
+		Help:      "Whether the last ping of an elasticsearch cluster by the reconciliation of one of its objects succeeded (1) or failed (0)",
+	}, []string{"cluster"})
+
+	managedObjectsDesc = prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", "managed_objects"),
+		"Number of managed objects by kind, status and elasticsearch cluster",
+		[]string{"kind", "status", "cluster"}, nil)
+
+	managedObjectsRegistration sync.Once
+
+	// managedObjectsKinds are the ElasticObject kinds counted by the managedObjectsCollector, besides indices and templates
+	managedObjectsKinds      []ElasticObjectKind
+	managedObjectsKindsMutex sync.RWMutex
+)
+
+func init() {
+	metrics.Registry.MustRegister(driftDetectedTotal, driftRepairedTotal, driftedObjects, operationsTotal, elasticsearchUp)
+}
+
+// recordOperation counts the elasticsearch object created or updated by a successful reconciliation, created meaning
+// the model was never applied before
+func recordOperation(kind string, esStatus *utils.EsStatus, lastAppliedModelHash string) {
+	if esStatus.Status != utils.StatusCreated || len(esStatus.Operations) == 0 {
+		return
+	}
+	if lastAppliedModelHash == "" {
+		operationsTotal.WithLabelValues(kind, OperationCreate).Inc()
+	} else {
+		operationsTotal.WithLabelValues(kind, OperationUpdate).Inc()
+	}
+}
+
+// recordPing sets the health of an elasticsearch cluster from the ping made before the reconciliation of one of its
+// objects
+func recordPing(esConfig *utils.EsConfig, err error) {
+	if err != nil {
+		elasticsearchUp.WithLabelValues(esConfig.String()).Set(0)
+	} else {
+		elasticsearchUp.WithLabelValues(esConfig.String()).Set(1)
+	}
+}
+
+// managedObjectsCollector counts managed objects from the manager cache each time metrics are scraped
+type managedObjectsCollector struct {
+	client client.Reader
+}
+
+// registerManagedObjectsCollector registers the collector once, both reconcilers sharing the same metric
+func registerManagedObjectsCollector(client client.Reader) {
+	managedObjectsRegistration.Do(func() {
+		metrics.Registry.MustRegister(&managedObjectsCollector{client: client})
+	})
+}
+
+// registerManagedObjectsKind adds an ElasticObject kind to the managed objects metric
+func registerManagedObjectsKind(kind ElasticObjectKind) {
+	managedObjectsKindsMutex.Lock()
+	defer managedObjectsKindsMutex.Unlock()
+	managedObjectsKinds = append(managedObjectsKinds, kind)
+}
+
+func (c *managedObjectsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- managedObjectsDesc
+}
+
+func (c *managedObjectsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+	counts := map[[3]string]int{}
+
+	var indices elasticv1alpha1.ElasticIndexList
+	if err := c.client.List(ctx, &indices); err != nil {
+		ch <- prometheus.NewInvalidMetric(managedObjectsDesc, err)
+		return
+	}
+	for _, index := range indices.Items {
+		counts[[3]string{utils.Index, index.Status.Status, clusterLabel(index.Status.Cluster)}]++
+	}
+
+	var templates elasticv1alpha1.ElasticTemplateList
+	if err := c.client.List(ctx, &templates); err != nil {
+		ch <- prometheus.NewInvalidMetric(managedObjectsDesc, err)
+		return
+	}
+	for _, template := range templates.Items {
+		counts[[3]string{utils.Template, template.Status.Status, clusterLabel(template.Status.Cluster)}]++
+	}
+
+	managedObjectsKindsMutex.RLock()
+	defer managedObjectsKindsMutex.RUnlock()
+	for _, kind := range managedObjectsKinds {
+		list := kind.NewList()
+		if err := c.client.List(ctx, list); err != nil {
+			ch <- prometheus.NewInvalidMetric(managedObjectsDesc, err)
+			return
+		}
+		for _, object := range list.GetObjects() {
+			status := object.GetObjectStatus()
+			counts[[3]string{kind.EsKind, status.Status, clusterLabel(status.Cluster)}]++
+		}
+	}
+
+	for labels, count := range counts {
+		ch <- prometheus.MustNewConstMetric(managedObjectsDesc, prometheus.GaugeValue, float64(count), labels[0], labels[1], labels[2])
+	}
+}
+
+func clusterLabel(cluster *elasticv1alpha1.ElasticClusterReference) string {
+	if cluster == nil {
+		return ""
+	}
+	return cluster.URL
+}
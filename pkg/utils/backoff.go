@@ -0,0 +1,50 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"math/rand"
+	"time"
+)
+
+// DefaultBackoffJitter is the fraction of the delays randomly added or removed by default
+const DefaultBackoffJitter = 0.2
+
+// Backoff computes the delays between the attempts of an operation failing repeatedly: Base is doubled on each
+// consecutive failure up to Max, and spread by a random jitter so that operations failing together, like all the
+// objects of an unreachable cluster, are not attempted again in lockstep
+type Backoff struct {
+	// Base is the delay after the first failure
+	Base time.Duration
+	// Max caps the delays, which are always Base when Max is lower than Base
+	Max time.Duration
+	// Jitter is the fraction of the delay randomly added or removed, like 0.2 for +/-20%
+	Jitter float64
+}
+
+// Delay returns the delay before the attempt following a number of consecutive failures, at least 1
+func (b Backoff) Delay(failures int32) time.Duration {
+	delay := b.Base
+	for i := int32(1); i < failures && delay < b.Max; i++ {
+		delay *= 2
+	}
+	if delay > b.Max && b.Max >= b.Base {
+		delay = b.Max
+	}
+	if b.Jitter > 0 {
+		delay += time.Duration((rand.Float64()*2 - 1) * b.Jitter * float64(delay))
+	}
+	return delay
+}
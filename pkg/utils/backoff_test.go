@@ -0,0 +1,58 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	assert := assert.New(t)
+
+	backoff := Backoff{Base: 30 * time.Second, Max: 5 * time.Minute}
+	scenarios := []struct {
+		failures int32
+		want     time.Duration
+	}{
+		{failures: 0, want: 30 * time.Second},
+		{failures: 1, want: 30 * time.Second},
+		{failures: 2, want: time.Minute},
+		{failures: 3, want: 2 * time.Minute},
+		{failures: 4, want: 4 * time.Minute},
+		{failures: 5, want: 5 * time.Minute},
+		{failures: 1000, want: 5 * time.Minute},
+	}
+	for _, s := range scenarios {
+		assert.Equal(s.want, backoff.Delay(s.failures), "failures %v", s.failures)
+	}
+
+	assert.Equal(5*time.Minute, Backoff{Base: 5 * time.Minute, Max: time.Minute}.Delay(3), "base when max is lower")
+	assert.Equal(5*time.Minute, Backoff{Base: 5 * time.Minute}.Delay(3), "base when there is no max")
+}
+
+func TestBackoff_Delay_Jitter(t *testing.T) {
+	assert := assert.New(t)
+
+	backoff := Backoff{Base: time.Minute, Max: time.Hour, Jitter: DefaultBackoffJitter}
+	delays := map[time.Duration]bool{}
+	for i := 0; i < 20; i++ {
+		delay := backoff.Delay(3)
+		assert.True(delay >= 192*time.Second && delay <= 288*time.Second, "%v is 4m +/-20%%", delay)
+		delays[delay] = true
+	}
+	assert.True(len(delays) > 1, "delays are spread")
+}
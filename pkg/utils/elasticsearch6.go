@@ -0,0 +1,31 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"github.com/go-logr/logr"
+)
+
+// Elasticsearch6 manages indices and templates of elasticsearch 6 clusters, whose mappings have a type. Typeless
+// models are applied with the DefaultMappingType type. It uses the elasticsearch 7 client, whose paths are the same
+// for the APIs used
+type Elasticsearch6 struct {
+	esClient7
+}
+
+func (es *Elasticsearch6) NewClient(config *EsConfig, log logr.Logger) error {
+	es.mapping = mappingTyped
+	return es.newClient(config, log, config.clientKey(6), "elasticsearch")
+}
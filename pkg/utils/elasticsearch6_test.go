@@ -0,0 +1,304 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"testing"
+	"time"
+)
+
+const (
+	es6Scheme  = "http"
+	es6Host    = "elastic6"
+	es6Port    = "9200"
+	es6Version = 6
+)
+
+func buildElasticsearch6(t *testing.T) *Elasticsearch6 {
+	assert := assert.New(t)
+
+	esConfig, err1 := (&EsConfig{}).FromURI(fmt.Sprintf("%v://%v:%v", es6Scheme, es6Host, es6Port))
+	assert.Nil(err1)
+	assert.Equal(es6Version, esConfig.Version)
+	assert.Equal("elasticsearch6", esConfig.Backend)
+	log := zap.New(zap.UseDevMode(true))
+
+	elasticsearch := &Elasticsearch6{}
+	err2 := elasticsearch.NewClient(esConfig, log)
+	assert.Nil(err2)
+	assert.NotNil(elasticsearch)
+
+	return elasticsearch
+}
+
+func deleteAllES6(elasticsearch *Elasticsearch6) {
+	ctx := context.Background()
+	elasticsearch.DeleteIndex(ctx, "k8s_epo_*")
+	elasticsearch.DeleteTemplate(ctx, "k8s_epo_*")
+}
+
+func TestEsVersion_ES6(t *testing.T) {
+	assert := assert.New(t)
+
+	rawUrl := fmt.Sprintf("%v://%v:%v", es6Scheme, es6Host, es6Port)
+
+	esVersion, err := EsVersion(rawUrl)
+	assert.Nil(err)
+	assert.Equal(6, esVersion)
+}
+
+func TestElasticsearch6_PingES(t *testing.T) {
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch6(t)
+	defer deleteAllES6(elasticsearch)
+
+	err3 := elasticsearch.PingES(context.Background())
+	assert.Nil(err3)
+}
+
+func TestElasticsearch6_CreateOrUpdateIndex(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch6(t)
+	defer deleteAllES6(elasticsearch)
+
+	indexName := "k8s_epo_test_create_index"
+
+	//typeless mappings are created under the default type
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "3", "number_of_shards": "5"}, "mappings":{"properties":{"description":{"type":"keyword"}}}}`, nil)
+	assert.Nil(err)
+	assert.Equal("200", status.HttpCodeStatus)
+	assert.Equal(StatusCreated, status.Status)
+	assert.True(*elasticsearch.existsIndex(ctx, indexName))
+	replicas, shards := elasticsearch.getNumberOfReplicasAndShards(ctx, indexName)
+	assert.Equal(int32(3), *replicas)
+	assert.Equal(int32(5), *shards)
+	properties, mappingType, err := elasticsearch.getTypedProperties(ctx, indexName)
+	assert.Nil(err)
+	assert.Equal(DefaultMappingType, mappingType)
+	assert.True(CompareJson(*properties, `{"properties" :{"description":{"type":"keyword"}}}`))
+}
+
+func TestElasticsearch6_CreateOrUpdateIndex_WithType(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch6(t)
+	defer deleteAllES6(elasticsearch)
+
+	indexName := "k8s_epo_test_create_index_with_type"
+
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "0", "number_of_shards": "1"}, "mappings":{"doc": {"properties":{"description":{"type":"keyword"}}}}}`, nil)
+	assert.Nil(err)
+	assert.Equal("200", status.HttpCodeStatus)
+	assert.Equal(StatusCreated, status.Status)
+	_, mappingType, err := elasticsearch.getTypedProperties(ctx, indexName)
+	assert.Nil(err)
+	assert.Equal("doc", mappingType)
+
+	//the typed mapping is updated under its own type
+	status2, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "0", "number_of_shards": "1"}, "mappings":{"doc": {"properties":{"description":{"type":"keyword"}, "newField":{"type":"text"}}}}}`, nil)
+	assert.Nil(err)
+	assert.Equal(StatusCreated, status2.Status)
+	properties, mappingType2, err := elasticsearch.getTypedProperties(ctx, indexName)
+	assert.Nil(err)
+	assert.Equal("doc", mappingType2)
+	assert.True(CompareJson(*properties, `{"properties" :{"description":{"type":"keyword"}, "newField":{"type":"text"}}}`))
+}
+
+func TestElasticsearch6_UpdateIndexMapping(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch6(t)
+	defer deleteAllES6(elasticsearch)
+
+	indexName := "k8s_epo_test_update_settings"
+
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"mappings":{"properties":{"description":{"type":"keyword"}}}}`, nil)
+	assert.Nil(err)
+	assert.Equal(StatusCreated, status.Status)
+
+	//add new field in properties
+	status2, err := elasticsearch.updateIndexProperties(ctx, indexName, indexName, `{"mappings":{"properties":{"description":{"type":"keyword"}, "newField":{"type":"text"}}}}`, nil)
+	assert.Nil(err)
+	assert.Equal("200", status2.HttpCodeStatus)
+	assert.Equal(StatusCreated, status2.Status)
+	properties2, _, err := elasticsearch.getTypedProperties(ctx, indexName)
+	assert.Nil(err)
+	assert.True(CompareJson(*properties2, `{"properties" :{"description":{"type":"keyword"}, "newField":{"type":"text"}}}`))
+
+	//change field type in properties => bad request
+	status3, err := elasticsearch.updateIndexProperties(ctx, indexName, indexName, `{"mappings":{"properties":{"description":{"type":"text"}, "newField":{"type":"text"}}}}`, nil)
+	assert.NotNil(err)
+	assert.Equal(StatusError, status3.Status)
+	properties3, _, err := elasticsearch.getTypedProperties(ctx, indexName)
+	assert.Nil(err)
+	assert.True(CompareJson(*properties3, `{"properties" :{"description":{"type":"keyword"}, "newField":{"type":"text"}}}`))
+}
+
+func TestElasticsearch6_UpdateIndexSettings(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch6(t)
+	defer deleteAllES6(elasticsearch)
+
+	indexName := "k8s_epo_test_update_settings"
+
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "3", "number_of_shards": "5"}}`, nil)
+	assert.Nil(err)
+	assert.Equal(StatusCreated, status.Status)
+
+	//update replicas number
+	status2, err := elasticsearch.updateIndexSettings(ctx, indexName, indexName, `{"settings":{"number_of_replicas": "1", "number_of_shards": "5"}}`, nil)
+	assert.Nil(err)
+	assert.Equal(StatusCreated, status2.Status)
+	replicas2, shards2 := elasticsearch.getNumberOfReplicasAndShards(ctx, indexName)
+	assert.Equal(int32(1), *replicas2)
+	assert.Equal(int32(5), *shards2)
+}
+
+func TestElasticsearch6_GetIndexDrift(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch6(t)
+	defer deleteAllES6(elasticsearch)
+
+	indexName := "k8s_epo_test_index_drift"
+	model := `{"settings":{"number_of_replicas": "0", "number_of_shards": "1"}, "mappings":{"properties":{"description":{"type":"keyword"}}}, "aliases":{"k8s_epo_drift":{}}}`
+
+	_, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, model, nil)
+	assert.Nil(err)
+	drift, err := elasticsearch.GetIndexDrift(ctx, indexName, model)
+	assert.Nil(err)
+	assert.False(drift.IsDrifted())
+
+	//manual changes
+	assert.Nil(elasticsearch.putIndexSettings(ctx, indexName, `{"index.number_of_replicas": 1}`))
+	drift2, err := elasticsearch.GetIndexDrift(ctx, indexName, model)
+	assert.Nil(err)
+	assert.Equal([]string{"settings [index.number_of_replicas] differ"}, drift2.Reasons)
+}
+
+func TestElasticsearch6_MigrateIndex(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch6(t)
+	defer deleteAllES6(elasticsearch)
+
+	indexName := "k8s_epo_test_migrate_index"
+	migration := &EsMigration{Strategy: MigrationStrategyReindex, OldIndexPolicy: OldIndexPolicyDelete}
+
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "0", "number_of_shards": "1"}, "mappings":{"properties":{"description":{"type":"keyword"}}}}`, migration)
+	assert.Nil(err)
+	assert.Equal(StatusCreated, status.Status)
+
+	//the original index cannot be retained without clone API
+	model := `{"settings":{"number_of_replicas": "0", "number_of_shards": "1"}, "mappings":{"properties":{"description":{"type":"text"}}}}`
+	retain := &EsMigration{Strategy: MigrationStrategyReindex, OldIndexPolicy: OldIndexPolicyRetain}
+	status2, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, model, retain)
+	assert.NotNil(err)
+	assert.Equal(StatusError, status2.Status)
+
+	//change field type in properties => migration to a new index
+	status3, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, model, migration)
+	assert.Nil(err)
+	assert.Equal(StatusMigrating, status3.Status)
+	for i := 0; i < 10 && migration.Phase == MigrationPhaseReindexing; i++ {
+		time.Sleep(time.Second)
+		status3, err = elasticsearch.CreateOrUpdateIndex(ctx, indexName, model, migration)
+		assert.Nil(err)
+	}
+	assert.Equal(MigrationPhaseCompleted, migration.Phase)
+	assert.Equal(StatusCreated, status3.Status)
+	assert.Equal(indexName+"-v2", elasticsearch.getBackingIndex(ctx, indexName))
+	properties, mappingType, err := elasticsearch.getTypedProperties(ctx, indexName+"-v2")
+	assert.Nil(err)
+	assert.Equal(DefaultMappingType, mappingType)
+	assert.True(CompareJson(*properties, `{"properties" :{"description":{"type":"text"}}}`))
+}
+
+func TestElasticsearch6_DeleteIndex(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch6(t)
+	defer deleteAllES6(elasticsearch)
+
+	indexName := "k8s_epo_test_delete_index"
+
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "3"}}`, nil)
+	assert.Nil(err)
+	assert.Equal("200", status.HttpCodeStatus)
+	assert.True(*elasticsearch.existsIndex(ctx, indexName))
+
+	err2 := elasticsearch.DeleteIndex(ctx, indexName)
+	assert.Nil(err2)
+	assert.False(*elasticsearch.existsIndex(ctx, indexName))
+}
+
+func TestElasticsearch6_CreateOrUpdateTemplate(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch6(t)
+	defer deleteAllES6(elasticsearch)
+
+	templateName := "k8s_epo_test_create_template"
+
+	status, err := elasticsearch.CreateOrUpdateTemplate(ctx, templateName, `{"index_patterns": ["k8s_epo_test_*"], "mappings": {"properties": {}}}`, nil)
+	assert.Nil(err)
+	assert.Equal("200", status.HttpCodeStatus)
+	assert.True(*elasticsearch.existsTemplate(ctx, templateName))
+
+	status2, err := elasticsearch.CreateOrUpdateTemplate(ctx, templateName, `{"index_patterns": ["k8s_epo_test_*"], "mappings": {"doc": {"properties": {}}}}`, nil)
+	assert.Nil(err)
+	assert.Equal("200", status2.HttpCodeStatus)
+}
+
+func TestElasticsearch6_GetTemplateDrift(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch6(t)
+	defer deleteAllES6(elasticsearch)
+
+	templateName := "k8s_epo_test_template_drift"
+	model := `{"index_patterns": ["k8s_epo_test_drift_*"], "settings": {"number_of_shards": 1}, "mappings": {"properties": {"description":{"type":"keyword"}}}}`
+	order := 1
+
+	_, err := elasticsearch.CreateOrUpdateTemplate(ctx, templateName, model, &order)
+	assert.Nil(err)
+	drift, err := elasticsearch.GetTemplateDrift(ctx, templateName, model, &order)
+	assert.Nil(err)
+	assert.False(drift.IsDrifted())
+}
+
+func TestElasticsearch6_DeleteTemplate(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch6(t)
+	defer deleteAllES6(elasticsearch)
+
+	templateName := "k8s_epo_test_delete_template"
+
+	status, err := elasticsearch.CreateOrUpdateTemplate(ctx, templateName, `{"index_patterns": ["k8s_epo_test_*"]}`, nil)
+	assert.Nil(err)
+	assert.Equal("200", status.HttpCodeStatus)
+	assert.True(*elasticsearch.existsTemplate(ctx, templateName))
+
+	err2 := elasticsearch.DeleteTemplate(ctx, templateName)
+	assert.Nil(err2)
+	assert.False(*elasticsearch.existsTemplate(ctx, templateName))
+}
@@ -16,347 +16,260 @@ package utils
 
 import (
 	"context"
-	"crypto/tls"
-	"errors"
 	"fmt"
-	"github.com/elastic/go-elasticsearch/v7"
 	"github.com/elastic/go-elasticsearch/v7/esapi"
 	"github.com/go-logr/logr"
+	"github.com/tidwall/gjson"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
+// Elasticsearch7 manages indices, templates and the objects of the elasticsearch 7 APIs: composable templates, ILM
+// policies, data streams and SLM policies, whose minimum versions are checked
 type Elasticsearch7 struct {
-	Config *EsConfig
-	Client *elasticsearch.Client
-	log    logr.Logger
+	esClient7
 }
 
 func (es *Elasticsearch7) NewClient(config *EsConfig, log logr.Logger) error {
-	conf := elasticsearch.Config{
-		Addresses: []string{config.String()},
-		Username:  config.Username,
-		Password:  config.Password,
-		Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
-	}
-	client, err := elasticsearch.NewClient(conf)
-	log.Info("elasticsearch client created successfully", "host", config)
-	if err != nil {
-		log.Error(err, "error while creating elasticsearch client", "host", config)
-		return err
-	}
-
-	es.log = log
-	es.Config = config
-	es.Client = client
-	return nil
+	es.mapping = mappingIncludeTypeName
+	return es.newClient(config, log, config.clientKey(7), "elasticsearch")
 }
 
-func (es *Elasticsearch7) PingES(ctx context.Context) error {
-	ctx, cancel := context.WithTimeout(ctx, ElasticMainFnTimeout)
-	defer cancel()
-
-	info, err := esapi.InfoRequest{}.Do(ctx, es.Client)
-	if err != nil {
-		es.log.Error(err, "error while connecting to elasticsearch", "host", es.Config)
-		return err
+// CreateOrUpdateIndexTemplate puts a composable index template, whose model includes its priority, composed_of and
+// data_stream fields
+func (es *Elasticsearch7) CreateOrUpdateIndexTemplate(ctx context.Context, templateName string, model string) (*EsStatus, error) {
+	if err := es.Config.RequireVersion(IndexTemplatesMinVersion, "composable index templates"); err != nil {
+		return &EsStatus{Status: StatusError, Message: err.Error()}, err
 	}
-	defer info.Body.Close()
-	es.log.Info("connected successfully to elasticsearch")
-	return nil
+	return es.createOrUpdateIndexTemplate(ctx, templateName, model)
 }
 
-func (es *Elasticsearch7) existsIndex(ctx context.Context, indexName string) *bool {
-	response, err := esapi.IndicesExistsRequest{Index: []string{indexName}}.Do(ctx, es.Client)
-	if err != nil {
-		es.log.Error(err, "error while checking index exists", "indexName", indexName)
-		return nil
+// GetIndexTemplateDrift compares a composable index template with its model, and returns what differs
+func (es *Elasticsearch7) GetIndexTemplateDrift(ctx context.Context, templateName string, model string) (*EsDrift, error) {
+	if err := es.Config.RequireVersion(IndexTemplatesMinVersion, "composable index templates"); err != nil {
+		return nil, err
 	}
-	defer response.Body.Close()
-	is2xxStatusCode := is2xxStatusCode(response.StatusCode)
-	return &is2xxStatusCode
+	return es.getIndexTemplateDrift(ctx, templateName, model)
 }
 
-func (es *Elasticsearch7) existsTemplate(ctx context.Context, templateName string) *bool {
-	response, err := esapi.IndicesExistsTemplateRequest{Name: []string{templateName}}.Do(ctx, es.Client)
-	if err != nil {
-		es.log.Error(err, "error while checking template exists", "templateName", templateName)
-		return nil
-	}
-	defer response.Body.Close()
-	is2xxStatusCode := is2xxStatusCode(response.StatusCode)
-	return &is2xxStatusCode
+func (es *Elasticsearch7) DeleteIndexTemplate(ctx context.Context, templateName string) error {
+	return es.deleteIndexTemplate(ctx, templateName)
 }
 
-func (es *Elasticsearch7) getNumberOfReplicasAndShards(ctx context.Context, indexName string) (*int32, *int32) {
-	response, err := esapi.IndicesGetSettingsRequest{Index: []string{indexName}}.Do(ctx, es.Client)
-	if err != nil {
-		es.log.Error(err, "error while getting settings", "indexName", indexName)
-		return nil, nil
-	}
-	defer response.Body.Close()
-	settings, err2 := StreamToString(response.Body)
-	if err2 != nil {
-		es.log.Error(err2, "error while converting stream to string to get settings", "indexName", indexName)
-		return nil, nil
+// CreateOrUpdateComponentTemplate puts a component template
+func (es *Elasticsearch7) CreateOrUpdateComponentTemplate(ctx context.Context, templateName string, model string) (*EsStatus, error) {
+	if err := es.Config.RequireVersion(IndexTemplatesMinVersion, "component templates"); err != nil {
+		return &EsStatus{Status: StatusError, Message: err.Error()}, err
 	}
-	replicas, _ := (&EsSettings{Settings: settings}).GetNumberOfReplicas(indexName)
-	shards, _ := (&EsSettings{Settings: settings}).GetNumberOfShards(indexName)
-	return replicas, shards
+	return es.createOrUpdateComponentTemplate(ctx, templateName, model)
 }
 
-func (es *Elasticsearch7) getProperties(ctx context.Context, indexName string) (*string, error) {
-	no := false
-	response, err := esapi.IndicesGetMappingRequest{Index: []string{indexName}, IncludeTypeName: &no}.Do(ctx, es.Client)
-	if err != nil {
-		es.log.Error(err, "error while getting properties", "indexName", indexName)
+// GetComponentTemplateDrift compares a component template with its model, and returns what differs
+func (es *Elasticsearch7) GetComponentTemplateDrift(ctx context.Context, templateName string, model string) (*EsDrift, error) {
+	if err := es.Config.RequireVersion(IndexTemplatesMinVersion, "component templates"); err != nil {
 		return nil, err
 	}
-	defer response.Body.Close()
-	mappings, err2 := StreamToString(response.Body)
-	if err2 != nil {
-		es.log.Error(err2, "error while converting stream to string to get properties", "indexName", indexName)
-		return nil, err2
-	}
-
-	return (&EsMappings{Mappings: mappings}).GetProperties(indexName), nil
+	return es.getComponentTemplateDrift(ctx, templateName, model)
 }
 
-func (es *Elasticsearch7) updateIndexReplicas(ctx context.Context, indexName string, numReplicas int32) (int, string, error) {
-	settings := strings.NewReader(fmt.Sprintf(`{"index" : {"number_of_replicas" : %v}}`, numReplicas))
-	response, err := esapi.IndicesPutSettingsRequest{Index: []string{indexName}, Body: settings}.Do(ctx, es.Client)
-	if err != nil {
-		es.log.Error(err, "error while updating number_of_replicas", "indexName", indexName, "replicas", numReplicas)
-		return -1, "", err
-	}
-	defer response.Body.Close()
-	statusCode := response.StatusCode
-	responseStr := response.String()
-	return statusCode, responseStr, nil
+func (es *Elasticsearch7) DeleteComponentTemplate(ctx context.Context, templateName string) error {
+	return es.deleteComponentTemplate(ctx, templateName)
 }
 
-func (es *Elasticsearch7) updateIndexMapping(ctx context.Context, indexName string, properties string) (int, string, error) {
-	mappingReader := strings.NewReader(properties)
-	no := false
-	response, err := esapi.IndicesPutMappingRequest{Index: []string{indexName}, Body: mappingReader, IncludeTypeName: &no}.Do(ctx, es.Client)
-	if err != nil {
-		es.log.Error(err, "error while updating mapping", "indexName", indexName, "mapping", properties)
-		return -1, "", err
+// ExistsComponentTemplate returns true when the component template exists
+func (es *Elasticsearch7) ExistsComponentTemplate(ctx context.Context, templateName string) (bool, error) {
+	if err := es.Config.RequireVersion(IndexTemplatesMinVersion, "component templates"); err != nil {
+		return false, err
 	}
-	defer response.Body.Close()
-	statusCode := response.StatusCode
-	responseStr := response.String()
-	return statusCode, responseStr, nil
+	return es.existsComponentTemplate(ctx, templateName)
 }
 
-func (es *Elasticsearch7) CreateOrUpdateIndex(ctx context.Context, indexName string, model string) (*EsStatus, error) {
-	ctx, cancel := context.WithTimeout(ctx, ElasticMainFnTimeout)
-	defer cancel()
-
-	exists := es.existsIndex(ctx, indexName)
-	if exists == nil {
-		errMsg := "error while checking index exists"
-		es.log.Error(nil, errMsg, "indexName", indexName)
-		return &EsStatus{Status: StatusError, Message: errMsg}, errors.New(errMsg)
-	} else if *exists {
-		es.log.Info("index already exists", "indexName", indexName)
-
-		if status, err := es.updateIndexSettings(ctx, indexName, model); status != nil || err != nil {
-			return status, err
-		}
-
-		if status, err := es.updateIndexProperties(ctx, indexName, model); status != nil || err != nil {
-			return status, err
-		}
-
-		return &EsStatus{Status: StatusCreated, HttpCodeStatus: "200"}, nil
-	}
-
-	shouldIncludeTypeName := (&EsModel{Model: model}).IsMappingWithType()
-	response, err := esapi.IndicesCreateRequest{Index: indexName, Body: strings.NewReader(model), IncludeTypeName: shouldIncludeTypeName}.Do(ctx, es.Client)
+// CreateOrUpdateILMPolicy puts an ILM policy
+func (es *Elasticsearch7) CreateOrUpdateILMPolicy(ctx context.Context, policyName string, model string) (*EsStatus, error) {
+	request := esapi.ILMPutLifecycleRequest{Policy: policyName, Body: strings.NewReader(model)}
+	return es.putObject(ctx, "ILM policy", policyName, OperationILMPolicyPut, request)
+}
 
+// GetILMPolicyDrift compares an ILM policy with its model, and returns what differs
+func (es *Elasticsearch7) GetILMPolicyDrift(ctx context.Context, policyName string, model string) (*EsDrift, error) {
+	policies, found, err := es.getObject(ctx, "ILM policy", policyName, esapi.ILMGetLifecycleRequest{Policy: policyName})
 	if err != nil {
-		es.log.Error(err, "error while creating index", "indexName", indexName)
-		return &EsStatus{Status: StatusError, Message: err.Error()}, err
-	}
-
-	defer response.Body.Close()
-
-	if !is2xxStatusCode(response.StatusCode) {
-		es.log.Error(nil, "error while creating index", "indexName", indexName, "http-response", response)
-		status := BuildEsStatus(response.StatusCode, response.String())
-		return status, errors.New("error while creating index")
+		return nil, err
+	} else if !found {
+		return &EsDrift{Reasons: []string{fmt.Sprintf("ILM policy %v does not exist", policyName)}}, nil
 	}
-
-	es.log.Info("index was created successfully", "indexName", indexName)
-	return BuildEsStatus(response.StatusCode, response.String()), nil
+	return &EsDrift{Reasons: GetILMPolicyDriftReasons(policies, policyName, model)}, nil
 }
 
-func (es *Elasticsearch7) updateIndexSettings(ctx context.Context, indexName string, model string) (*EsStatus, error) {
-	oldNumReplicas, oldNumShards := es.getNumberOfReplicasAndShards(ctx, indexName)
-	numReplicas, err := (&EsModel{Model: model}).GetNumberOfReplicas()
-	numShards, err2 := (&EsModel{Model: model}).GetNumberOfShards()
+func (es *Elasticsearch7) DeleteILMPolicy(ctx context.Context, policyName string) error {
+	return es.deleteObject(ctx, "ILM policy", policyName, esapi.ILMDeleteLifecycleRequest{Policy: policyName})
+}
 
+// GetILMPolicyIndices returns the sorted names of the indices managed by an ILM policy, hidden indices like the
+// backing indices of data streams included
+func (es *Elasticsearch7) GetILMPolicyIndices(ctx context.Context, policyName string) ([]string, error) {
+	onlyManaged := true
+	request := esapi.ILMExplainLifecycleRequest{Index: "*,.*", OnlyManaged: &onlyManaged, FilterPath: []string{"indices.*.policy"}}
+	explain, _, err := es.getObject(ctx, "ILM explain", policyName, request)
 	if err != nil {
-		errMsg := fmt.Sprintf("error while getting number_of_repliacs from model %v", model)
-		es.log.Error(err, errMsg, "indexName", indexName)
-		return &EsStatus{Status: StatusError, Message: errMsg}, err
-	}
-
-	if err2 != nil {
-		errMsg := fmt.Sprintf("error while getting number_of_shards from model %v", model)
-		es.log.Error(err2, errMsg, "indexName", indexName)
-		return &EsStatus{Status: StatusError, Message: errMsg}, err2
+		return nil, err
 	}
+	return GetILMPolicyIndices(explain, policyName), nil
+}
 
-	isShardsUpdated := oldNumShards == nil || *oldNumShards != *numShards
-	if isShardsUpdated {
-		errMsg := fmt.Sprintf("you cannot update number_of_shards from %q to %q on existing index %v", ptrToString(oldNumShards), ptrToString(numShards), indexName)
-		es.log.Error(nil, errMsg, "indexName", indexName)
-		return &EsStatus{Status: StatusError, Message: errMsg}, errors.New(errMsg)
+// CreateOrUpdateDataStream creates a data stream when it does not exist, from the composable index template matching
+// its name, and puts the lifecycle of the model when it differs
+func (es *Elasticsearch7) CreateOrUpdateDataStream(ctx context.Context, dataStreamName string, model string) (*EsStatus, error) {
+	if err := es.Config.RequireVersion(DataStreamsMinVersion, "data streams"); err != nil {
+		return &EsStatus{Status: StatusError, Message: err.Error()}, err
 	}
-
-	isReplicasUpdated := (oldNumReplicas == nil || *oldNumReplicas != *numReplicas) && err == nil && err2 == nil
-	if isReplicasUpdated {
-		es.log.Info("index already exists and updating number_of_replicas", "indexName", indexName, "from", ptrToString(oldNumReplicas), "to", ptrToString(numReplicas))
-		statusCode, responseStr, err := es.updateIndexReplicas(ctx, indexName, *numReplicas)
-		if err != nil {
-			errMsg := fmt.Sprintf("error while updating number_of_replicas from %q to %q", ptrToString(oldNumReplicas), ptrToString(numReplicas))
-			es.log.Error(err, errMsg, "indexName", indexName)
-			return &EsStatus{Status: StatusError, Message: errMsg}, err
-		} else if !is2xxStatusCode(statusCode) {
-			status := BuildEsStatus(statusCode, responseStr)
-			errMsg := "error while updating index number_of_replicas"
-			es.log.Error(nil, errMsg, "indexName", indexName, "http-response", responseStr)
-			return status, errors.New(errMsg)
-		} else {
-			return BuildEsStatus(statusCode, responseStr), nil
+	lifecycle := gjson.Get(model, "lifecycle")
+	if lifecycle.Exists() {
+		if err := es.Config.RequireVersion(DataStreamLifecycleMinVersion, "data stream lifecycles"); err != nil {
+			return &EsStatus{Status: StatusError, Message: err.Error()}, err
 		}
 	}
-
-	return nil, nil
-}
-
-func (es *Elasticsearch7) updateIndexProperties(ctx context.Context, indexName string, model string) (*EsStatus, error) {
-	oldProperties, err := es.getProperties(ctx, indexName)
-	properties := (&EsModel{Model: model}).GetProperties()
-
+	dataStream, err := es.GetDataStream(ctx, dataStreamName)
 	if err != nil {
-		errMsg := fmt.Sprintf("error while getting old properties from index %v", indexName)
-		es.log.Error(err, errMsg, "indexName", indexName)
-		return &EsStatus{Status: StatusError, Message: errMsg}, err
+		es.log.Error(err, "error while getting data stream", "name", dataStreamName)
+		return &EsStatus{Status: StatusRetry, Message: err.Error()}, err
 	}
 
-	arePropertiesUpdates := oldProperties != nil && properties != nil && !CompareJson(*oldProperties, *properties)
-	if arePropertiesUpdates {
-		es.log.Info("index already exists and updating properties", "indexName", indexName, "from", *oldProperties, "to", *properties)
-
-		isValid := IsValidUpdateProperties(*oldProperties, *properties)
-		if !isValid {
-			errMsg := fmt.Sprintf("you cannot delete properties, error while updating properties from %v to %v", *oldProperties, *properties)
-			es.log.Error(nil, errMsg, "indexName", indexName)
-			return &EsStatus{Status: StatusError, Message: errMsg}, errors.New(errMsg)
+	var operations []string
+	if dataStream == nil {
+		status, err := es.putObject(ctx, "data stream", dataStreamName, OperationDataStreamCreated, esapi.IndicesCreateDataStreamRequest{Name: dataStreamName})
+		if err != nil {
+			return status, err
 		}
-
-		statusCode, responseStr, err := es.updateIndexMapping(ctx, indexName, *properties)
+		operations = status.Operations
+	}
+	if lifecycle.Exists() && (dataStream == nil || len(GetDataStreamDriftReasons(dataStream, dataStreamName, model)) > 0) {
+		request := esRequest7{Method: http.MethodPut, Path: fmt.Sprintf("/_data_stream/%v/_lifecycle", url.PathEscape(dataStreamName)), Body: lifecycle.Raw}
+		status, err := es.putObject(ctx, "data stream lifecycle", dataStreamName, OperationDataLifecyclePut, request)
 		if err != nil {
-			errMsg := fmt.Sprintf("error while updating properties from %v to %v", *oldProperties, *properties)
-			es.log.Error(err, errMsg, "indexName", indexName)
-			return &EsStatus{Status: StatusError, Message: errMsg}, err
-		} else if !is2xxStatusCode(statusCode) {
-			status := BuildEsStatus(statusCode, responseStr)
-			errMsg := "error while updating index properties"
-			es.log.Error(nil, errMsg, "indexName", indexName, "http-response", responseStr)
-			return status, errors.New(errMsg)
-		} else {
-			return BuildEsStatus(statusCode, responseStr), nil
+			status.Operations = operations
+			return status, err
 		}
+		operations = append(operations, status.Operations...)
 	}
-
-	return nil, nil
+	return &EsStatus{Status: StatusCreated, HttpCodeStatus: "200", Operations: operations}, nil
 }
 
-func (es *Elasticsearch7) DeleteIndex(ctx context.Context, indexName string) error {
-	ctx, cancel := context.WithTimeout(ctx, ElasticMainFnTimeout)
-	defer cancel()
-
-	exists := es.existsIndex(ctx, indexName)
-	if exists != nil && !*exists {
-		es.log.Info("index cannot be deleted because it does not exists", "indexName", indexName)
-		return nil
+// GetDataStreamDrift compares a data stream with its model, and returns what differs
+func (es *Elasticsearch7) GetDataStreamDrift(ctx context.Context, dataStreamName string, model string) (*EsDrift, error) {
+	dataStream, err := es.GetDataStream(ctx, dataStreamName)
+	if err != nil {
+		return nil, err
 	}
+	return &EsDrift{Reasons: GetDataStreamDriftReasons(dataStream, dataStreamName, model)}, nil
+}
 
-	response, err := esapi.IndicesDeleteRequest{Index: []string{indexName}}.Do(ctx, es.Client)
-	if err != nil {
-		es.log.Error(err, "error while deleting index", "indexName", indexName)
+// DeleteDataStream deletes a data stream and its backing indices
+func (es *Elasticsearch7) DeleteDataStream(ctx context.Context, dataStreamName string) error {
+	if err := es.Config.RequireVersion(DataStreamsMinVersion, "data streams"); err != nil {
 		return err
 	}
+	return es.deleteObject(ctx, "data stream", dataStreamName, esapi.IndicesDeleteDataStreamRequest{Name: dataStreamName})
+}
 
-	defer response.Body.Close()
-
-	if !is2xxStatusCode(response.StatusCode) {
-		es.log.Error(nil, "error while deleting index", "indexName", indexName, "http-response", response)
-		return fmt.Errorf("error while deleting index %v: %v", indexName, response)
+// GetDataStream returns a data stream, nil when it does not exist. The client requests GET _data_streams/<name>, which
+// was replaced by GET _data_stream/<name> in 7.9
+func (es *Elasticsearch7) GetDataStream(ctx context.Context, dataStreamName string) (*EsDataStream, error) {
+	if err := es.Config.RequireVersion(DataStreamsMinVersion, "data streams"); err != nil {
+		return nil, err
 	}
-
-	es.log.Info("index was deleted successfully", "indexName", indexName)
-	return nil
+	request := esRequest7{Method: http.MethodGet, Path: "/_data_stream/" + url.PathEscape(dataStreamName)}
+	dataStreams, found, err := es.getObject(ctx, "data stream", dataStreamName, request)
+	if err != nil || !found {
+		return nil, err
+	}
+	return ParseDataStream(dataStreams, dataStreamName), nil
 }
 
-func (es *Elasticsearch7) CreateOrUpdateTemplate(ctx context.Context, templateName string, model string, order *int) (*EsStatus, error) {
-	ctx, cancel := context.WithTimeout(ctx, ElasticMainFnTimeout)
-	defer cancel()
-
-	exists := es.existsTemplate(ctx, templateName)
-
-	shouldIncludeTypeName := (&EsModel{Model: model}).IsMappingWithType()
-	response, err := esapi.IndicesPutTemplateRequest{Name: templateName, Body: strings.NewReader(model), IncludeTypeName: shouldIncludeTypeName, Order: order}.Do(ctx, es.Client)
-	if err != nil || exists == nil {
-		es.log.Error(err, "error while creating template", "templateName", templateName)
+// RolloverDataStream creates a new write index for a data stream
+func (es *Elasticsearch7) RolloverDataStream(ctx context.Context, dataStreamName string) (*EsStatus, error) {
+	if err := es.Config.RequireVersion(DataStreamsMinVersion, "data streams"); err != nil {
 		return &EsStatus{Status: StatusError, Message: err.Error()}, err
 	}
+	ctx, cancel := context.WithTimeout(ctx, es.Config.GetRequestTimeout())
+	defer cancel()
 
+	response, err := esapi.IndicesRolloverRequest{Alias: dataStreamName}.Do(ctx, es.Client)
+	if err != nil {
+		es.log.Error(err, "error while rolling over data stream", "name", dataStreamName)
+		return &EsStatus{Status: StatusRetry, Message: err.Error()}, err
+	}
 	defer response.Body.Close()
-
+	body, err := StreamToString(response.Body)
+	if err != nil {
+		return &EsStatus{Status: StatusRetry, Message: err.Error()}, err
+	}
 	if !is2xxStatusCode(response.StatusCode) {
-		es.log.Error(nil, "error while creating template", "templateName", templateName, "http-response", response)
-		status := BuildEsStatus(response.StatusCode, response.String())
-		return status, errors.New("error while creating template")
+		es.log.Error(nil, "error while rolling over data stream", "name", dataStreamName, "http-response", body)
+		return BuildEsStatus(response.StatusCode, GetResponseError(body)), fmt.Errorf("error while rolling over data stream %v", dataStreamName)
 	}
+	oldIndex, newIndex := GetRolloverIndices(body)
+	es.log.Info("data stream was rolled over successfully", "name", dataStreamName, "oldIndex", oldIndex, "newIndex", newIndex)
+	status := BuildEsStatus(response.StatusCode, fmt.Sprintf("rolled over from %v to %v", oldIndex, newIndex))
+	status.Operations = []string{OperationRolledOver}
+	return status, nil
+}
 
-	if *exists {
-		es.log.Info("template already exists and was updated successfully", "templateName", templateName)
-	} else {
-		es.log.Info("template was created successfully", "templateName", templateName)
+// GetDataStreamTemplate returns the composable index template with the highest priority matching the name of a data
+// stream, and whether it has a data_stream field
+func (es *Elasticsearch7) GetDataStreamTemplate(ctx context.Context, dataStreamName string) (string, bool, error) {
+	if err := es.Config.RequireVersion(DataStreamsMinVersion, "data streams"); err != nil {
+		return "", false, err
 	}
-
-	return BuildEsStatus(response.StatusCode, response.String()), nil
+	templates, _, err := es.getObject(ctx, "index templates", dataStreamName, esapi.IndicesGetIndexTemplateRequest{})
+	if err != nil {
+		return "", false, err
+	}
+	templateName, dataStream := GetDataStreamTemplate(templates, dataStreamName)
+	return templateName, dataStream, nil
 }
 
-func (es *Elasticsearch7) DeleteTemplate(ctx context.Context, templateName string) error {
-	ctx, cancel := context.WithTimeout(ctx, ElasticMainFnTimeout)
-	defer cancel()
-
-	exists := es.existsTemplate(ctx, templateName)
-	if exists != nil && !*exists {
-		es.log.Info("template cannot be deleted because it does not exists", "templateName", templateName)
-		return nil
+// CreateOrUpdateSLMPolicy puts an SLM policy
+func (es *Elasticsearch7) CreateOrUpdateSLMPolicy(ctx context.Context, policyName string, model string) (*EsStatus, error) {
+	if err := es.Config.RequireVersion(SLMMinVersion, "SLM policies"); err != nil {
+		return &EsStatus{Status: StatusError, Message: err.Error()}, err
 	}
+	request := esapi.SlmPutLifecycleRequest{PolicyID: policyName, Body: strings.NewReader(model)}
+	return es.putObject(ctx, "SLM policy", policyName, OperationSLMPolicyPut, request)
+}
 
-	response, err := esapi.IndicesDeleteTemplateRequest{Name: templateName}.Do(ctx, es.Client)
-
+// GetSLMPolicyDrift compares an SLM policy with its model, and returns what differs
+func (es *Elasticsearch7) GetSLMPolicyDrift(ctx context.Context, policyName string, model string) (*EsDrift, error) {
+	if err := es.Config.RequireVersion(SLMMinVersion, "SLM policies"); err != nil {
+		return nil, err
+	}
+	policies, found, err := es.getObject(ctx, "SLM policy", policyName, esapi.SlmGetLifecycleRequest{PolicyID: []string{policyName}})
 	if err != nil {
-		es.log.Error(err, "error while deleting template", "templateName", templateName)
-		return err
+		return nil, err
+	} else if !found {
+		return &EsDrift{Reasons: []string{fmt.Sprintf("SLM policy %v does not exist", policyName)}}, nil
 	}
+	return &EsDrift{Reasons: GetSLMPolicyDriftReasons(policies, policyName, model)}, nil
+}
 
-	defer response.Body.Close()
+func (es *Elasticsearch7) DeleteSLMPolicy(ctx context.Context, policyName string) error {
+	return es.deleteObject(ctx, "SLM policy", policyName, esapi.SlmDeleteLifecycleRequest{PolicyID: policyName})
+}
 
-	if !is2xxStatusCode(response.StatusCode) {
-		es.log.Error(nil, "error while deleting template", "templateName", templateName, "http-response", response)
-		return fmt.Errorf("error while deleting template %v: %v", templateName, response)
+// GetSLMPolicyStatus returns the last snapshots of an SLM policy, from _slm/policy, and its counts of snapshots, from
+// _slm/stats. It is nil when the policy does not exist
+func (es *Elasticsearch7) GetSLMPolicyStatus(ctx context.Context, policyName string) (*EsSLMPolicyStatus, error) {
+	if err := es.Config.RequireVersion(SLMMinVersion, "SLM policies"); err != nil {
+		return nil, err
 	}
-
-	es.log.Info("template was deleted successfully", "templateName", templateName)
-	return nil
+	policies, found, err := es.getObject(ctx, "SLM policy", policyName, esapi.SlmGetLifecycleRequest{PolicyID: []string{policyName}})
+	if err != nil || !found {
+		return nil, err
+	}
+	stats, _, err := es.getObject(ctx, "SLM stats", policyName, esapi.SlmGetStatsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	return ParseSLMPolicyStatus(policies, stats, policyName), nil
 }
@@ -18,8 +18,11 @@ import (
 	"context"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"testing"
+	"time"
 )
 
 const (
@@ -34,7 +37,9 @@ func buildElasticsearch(t *testing.T) *Elasticsearch7 {
 
 	esConfig, err1 := (&EsConfig{}).FromURI(fmt.Sprintf("%v://%v:%v", es7Scheme, es7Host, es7Port))
 	assert.Nil(err1)
-	assert.Equal(esConfig, &EsConfig{Scheme: es7Scheme, Host: es7Host, Port: es7Port, Version: es7Version})
+	assert.Equal(es7Version, esConfig.Version)
+	assert.Equal(DistributionElasticsearch, esConfig.Distribution)
+	assert.Equal("elasticsearch7", esConfig.Backend)
 	log := zap.New(zap.UseDevMode(true))
 
 	elasticsearch := &Elasticsearch7{}
@@ -82,7 +87,7 @@ func TestElasticsearch7_CreateOrUpdateIndex(t *testing.T) {
 
 	indexName := "k8s_epo_test_create_index"
 
-	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "3", "number_of_shards": "5"}, "mappings":{"properties":{"description":{"type":"keyword"}}}}`)
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "3", "number_of_shards": "5"}, "mappings":{"properties":{"description":{"type":"keyword"}}}}`, nil)
 	assert.Nil(err)
 	assert.Equal("200", status.HttpCodeStatus)
 	assert.Equal(StatusCreated, status.Status)
@@ -103,7 +108,7 @@ func TestElasticsearch7_CreateOrUpdateIndex_WithType(t *testing.T) {
 
 	indexName := "k8s_epo_test_create_index_with_type"
 
-	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "3", "number_of_shards": "5"}, "mappings":{"_doc": {"properties":{"description":{"type":"keyword"}}}}}`)
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "3", "number_of_shards": "5"}, "mappings":{"_doc": {"properties":{"description":{"type":"keyword"}}}}}`, nil)
 	assert.Nil(err)
 	assert.Equal("200", status.HttpCodeStatus)
 	assert.Equal(StatusCreated, status.Status)
@@ -118,7 +123,7 @@ func TestElasticsearch7_CreateOrUpdateIndex_WithoutType(t *testing.T) {
 
 	indexName := "k8s_epo_test_create_index_without_type"
 
-	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "3", "number_of_shards": "5"}, "mappings":{"properties":{"description":{"type":"keyword"}}}}`)
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "3", "number_of_shards": "5"}, "mappings":{"properties":{"description":{"type":"keyword"}}}}`, nil)
 	assert.Nil(err)
 	assert.Equal("200", status.HttpCodeStatus)
 	assert.Equal(StatusCreated, status.Status)
@@ -133,7 +138,7 @@ func TestElasticsearch7_UpdateIndexMapping(t *testing.T) {
 
 	indexName := "k8s_epo_test_update_settings"
 
-	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"mappings":{"properties":{"description":{"type":"keyword"}}}}`)
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"mappings":{"properties":{"description":{"type":"keyword"}}}}`, nil)
 	assert.Nil(err)
 	assert.Equal("200", status.HttpCodeStatus)
 	assert.Equal(StatusCreated, status.Status)
@@ -142,7 +147,7 @@ func TestElasticsearch7_UpdateIndexMapping(t *testing.T) {
 	assert.True(CompareJson(*properties, `{"properties" :{"description":{"type":"keyword"}}}`))
 
 	//add new field in properties
-	status2, err := elasticsearch.updateIndexProperties(ctx, indexName, `{"mappings":{"properties":{"description":{"type":"keyword"}, "newField":{"type":"text"}}}}`)
+	status2, err := elasticsearch.updateIndexProperties(ctx, indexName, indexName, `{"mappings":{"properties":{"description":{"type":"keyword"}, "newField":{"type":"text"}}}}`, nil)
 	assert.Nil(err)
 	assert.Equal("200", status2.HttpCodeStatus)
 	assert.Equal(StatusCreated, status2.Status)
@@ -151,7 +156,7 @@ func TestElasticsearch7_UpdateIndexMapping(t *testing.T) {
 	assert.True(CompareJson(*properties2, `{"properties" :{"description":{"type":"keyword"}, "newField":{"type":"text"}}}`))
 
 	//change field type in properties => bad request
-	status3, err := elasticsearch.updateIndexProperties(ctx, indexName, `{"mappings":{"properties":{"description":{"type":"text"}, "newField":{"type":"text"}}}}`)
+	status3, err := elasticsearch.updateIndexProperties(ctx, indexName, indexName, `{"mappings":{"properties":{"description":{"type":"text"}, "newField":{"type":"text"}}}}`, nil)
 	assert.NotNil(err)
 	assert.Equal("400", status3.HttpCodeStatus)
 	assert.Equal(StatusError, status3.Status)
@@ -160,7 +165,7 @@ func TestElasticsearch7_UpdateIndexMapping(t *testing.T) {
 	assert.True(CompareJson(*properties3, `{"properties" :{"description":{"type":"keyword"}, "newField":{"type":"text"}}}`))
 
 	//update with less properties
-	status4, err := elasticsearch.updateIndexProperties(ctx, indexName, `{"mappings":{"properties":{"newField":{"type":"text"}}}}`)
+	status4, err := elasticsearch.updateIndexProperties(ctx, indexName, indexName, `{"mappings":{"properties":{"newField":{"type":"text"}}}}`, nil)
 	assert.NotNil(err)
 	assert.Equal(StatusError, status4.Status)
 	properties4, err := elasticsearch.getProperties(ctx, indexName)
@@ -176,7 +181,7 @@ func TestElasticsearch7_UpdateIndexSettings(t *testing.T) {
 
 	indexName := "k8s_epo_test_update_settings"
 
-	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "3", "number_of_shards": "5"}}`)
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "3", "number_of_shards": "5"}}`, nil)
 	assert.Nil(err)
 	assert.Equal("200", status.HttpCodeStatus)
 	assert.Equal(StatusCreated, status.Status)
@@ -185,7 +190,7 @@ func TestElasticsearch7_UpdateIndexSettings(t *testing.T) {
 	assert.Equal(int32(5), *shards)
 
 	//update replicas number
-	status2, err := elasticsearch.updateIndexSettings(ctx, indexName, `{"settings":{"number_of_replicas": "1", "number_of_shards": "5"}}`)
+	status2, err := elasticsearch.updateIndexSettings(ctx, indexName, indexName, `{"settings":{"number_of_replicas": "1", "number_of_shards": "5"}}`, nil)
 	assert.Nil(err)
 	assert.Equal("200", status2.HttpCodeStatus)
 	assert.Equal(StatusCreated, status2.Status)
@@ -194,7 +199,7 @@ func TestElasticsearch7_UpdateIndexSettings(t *testing.T) {
 	assert.Equal(int32(5), *shards2)
 
 	//cannot update shards number
-	status3, err := elasticsearch.updateIndexSettings(ctx, indexName, `{"settings":{"number_of_replicas": "1", "number_of_shards": "7"}}`)
+	status3, err := elasticsearch.updateIndexSettings(ctx, indexName, indexName, `{"settings":{"number_of_replicas": "1", "number_of_shards": "7"}}`, nil)
 	assert.NotNil(err)
 	assert.Equal(StatusError, status3.Status)
 	replicas3, shards3 := elasticsearch.getNumberOfReplicasAndShards(ctx, indexName)
@@ -202,6 +207,44 @@ func TestElasticsearch7_UpdateIndexSettings(t *testing.T) {
 	assert.Equal(int32(5), *shards3)
 }
 
+func TestElasticsearch7_UpdateIndexDynamicAndStaticSettings(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch(t)
+	defer deleteAll(elasticsearch)
+
+	indexName := "k8s_epo_test_update_dynamic_static_settings"
+
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "0", "number_of_shards": "1"}}`, nil)
+	assert.Nil(err)
+	assert.Equal(StatusCreated, status.Status)
+
+	//dynamic settings, nested and flat
+	status2, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "0", "number_of_shards": "1", "index":{"refresh_interval":"5s"}, "index.max_result_window":20000}}`, nil)
+	assert.Nil(err)
+	assert.Equal(StatusCreated, status2.Status)
+	settings, err := elasticsearch.getFlatSettings(ctx, indexName)
+	assert.Nil(err)
+	assert.Equal("5s", settings["index.refresh_interval"])
+	assert.Equal("20000", settings["index.max_result_window"])
+
+	//static settings without close and reopen => error
+	model := `{"settings":{"number_of_replicas": "0", "number_of_shards": "1", "codec":"best_compression"}}`
+	status3, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, model, &EsMigration{})
+	assert.NotNil(err)
+	assert.Equal(StatusError, status3.Status)
+	assert.Contains(status3.Message, "index.codec")
+
+	//static settings with close and reopen
+	status4, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, model, &EsMigration{StaticSettingsUpdate: StaticSettingsUpdateCloseAndReopen})
+	assert.Nil(err)
+	assert.Equal(StatusCreated, status4.Status)
+	settings2, err := elasticsearch.getFlatSettings(ctx, indexName)
+	assert.Nil(err)
+	assert.Equal("best_compression", settings2["index.codec"])
+	assert.Equal("5s", settings2["index.refresh_interval"])
+}
+
 func TestElasticsearch7_UpdateIndexReplicas(t *testing.T) {
 	ctx := context.Background()
 	assert := assert.New(t)
@@ -210,7 +253,7 @@ func TestElasticsearch7_UpdateIndexReplicas(t *testing.T) {
 
 	indexName := "k8s_epo_test_update_index_setting"
 
-	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "3", "number_of_shards": "5"}}`)
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "3", "number_of_shards": "5"}}`, nil)
 	assert.Nil(err)
 	assert.Equal("200", status.HttpCodeStatus)
 	assert.True(*elasticsearch.existsIndex(ctx, indexName))
@@ -226,6 +269,181 @@ func TestElasticsearch7_UpdateIndexReplicas(t *testing.T) {
 	assert.Equal(int32(5), *shards2)
 }
 
+func TestElasticsearch7_UpdateIndexAliases(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch(t)
+	defer deleteAll(elasticsearch)
+
+	indexName := "k8s_epo_test_update_index_aliases"
+
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "0", "number_of_shards": "1"}, "aliases":{"k8s_epo_search":{}, "k8s_epo_old":{}}}`, nil)
+	assert.Nil(err)
+	assert.Equal(StatusCreated, status.Status)
+	assert.Equal([]EsAliasStatus{{Name: "k8s_epo_old", Status: StatusCreated}, {Name: "k8s_epo_search", Status: StatusCreated}}, status.Aliases)
+
+	model := `{"settings":{"number_of_replicas": "0", "number_of_shards": "1"}, "aliases":{"k8s_epo_search":{"filter":{"term":{"active":true}},"routing":"1","is_hidden":true}, "k8s_epo_write":{"is_write_index":true}}}`
+	status2, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, model, nil)
+	assert.Nil(err)
+	assert.Equal(StatusCreated, status2.Status)
+	assert.Equal([]EsAliasStatus{{Name: "k8s_epo_search", Status: StatusCreated}, {Name: "k8s_epo_write", Status: StatusCreated}}, status2.Aliases)
+	aliases, err := elasticsearch.getAliases(ctx, indexName)
+	assert.Nil(err)
+	assert.JSONEq(`{"k8s_epo_test_update_index_aliases":{"aliases":{"k8s_epo_search":{"filter":{"term":{"active":true}},"index_routing":"1","search_routing":"1","is_hidden":true},"k8s_epo_write":{"is_write_index":true}}}}`, aliases)
+
+	//aliases are in sync, no update
+	actions, _, err := BuildUpdateAliasesActions(indexName, aliases, (&EsModel{Model: model}).GetAliases(), nil)
+	assert.Nil(err)
+	assert.Equal("", actions)
+
+	//invalid filter => atomic update is rejected
+	status3, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "0", "number_of_shards": "1"}, "aliases":{"k8s_epo_search":{"filter":{"unknown":{}}}, "k8s_epo_other":{}}}`, nil)
+	assert.NotNil(err)
+	assert.Equal(StatusError, status3.Status)
+	assert.Equal(2, len(status3.Aliases))
+	assert.Equal(StatusError, status3.Aliases[0].Status)
+	aliasNames, err := elasticsearch.getAliasNames(ctx, indexName)
+	assert.Nil(err)
+	assert.ElementsMatch([]string{"k8s_epo_search", "k8s_epo_write"}, aliasNames)
+}
+
+func TestElasticsearch7_GetIndexDrift(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch(t)
+	defer deleteAll(elasticsearch)
+
+	indexName := "k8s_epo_test_index_drift"
+	model := `{"settings":{"number_of_replicas": "0", "number_of_shards": "1"}, "mappings":{"properties":{"description":{"type":"keyword"}}}, "aliases":{"k8s_epo_drift":{}}}`
+
+	drift, err := elasticsearch.GetIndexDrift(ctx, indexName, model)
+	assert.Nil(err)
+	assert.Equal([]string{"index k8s_epo_test_index_drift does not exist"}, drift.Reasons)
+
+	_, err = elasticsearch.CreateOrUpdateIndex(ctx, indexName, model, nil)
+	assert.Nil(err)
+	drift2, err := elasticsearch.GetIndexDrift(ctx, indexName, model)
+	assert.Nil(err)
+	assert.False(drift2.IsDrifted())
+
+	//manual changes
+	assert.Nil(elasticsearch.putIndexSettings(ctx, indexName, `{"index.number_of_replicas": 1}`))
+	_, err = elasticsearch.updateIndexAliases(ctx, indexName, indexName, `{"aliases":{}}`)
+	assert.Nil(err)
+	drift3, err := elasticsearch.GetIndexDrift(ctx, indexName, model)
+	assert.Nil(err)
+	assert.Equal([]string{"settings [index.number_of_replicas] differ", "aliases [k8s_epo_drift] differ"}, drift3.Reasons)
+}
+
+func TestElasticsearch7_MigrateIndex(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch(t)
+	defer deleteAll(elasticsearch)
+
+	indexName := "k8s_epo_test_migrate_index"
+	migration := &EsMigration{Strategy: MigrationStrategyReindex, OldIndexPolicy: OldIndexPolicyDelete}
+
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "1", "number_of_shards": "1"}, "mappings":{"properties":{"description":{"type":"keyword"}}}}`, migration)
+	assert.Nil(err)
+	assert.Equal(StatusCreated, status.Status)
+
+	//change field type in properties => migration to a new index
+	model := `{"settings":{"number_of_replicas": "1", "number_of_shards": "1"}, "aliases":{"k8s_epo_test_migrate_search":{}}, "mappings":{"properties":{"description":{"type":"text"}}}}`
+	status2, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, model, migration)
+	assert.Nil(err)
+	assert.Equal(StatusMigrating, status2.Status)
+	assert.Equal(MigrationPhaseReindexing, migration.Phase)
+	assert.Equal(indexName, migration.SourceIndex)
+	assert.Equal(indexName+"-v2", migration.TargetIndex)
+
+	for i := 0; i < 10 && migration.Phase == MigrationPhaseReindexing; i++ {
+		time.Sleep(time.Second)
+		status2, err = elasticsearch.CreateOrUpdateIndex(ctx, indexName, model, migration)
+		assert.Nil(err)
+	}
+	assert.Equal(MigrationPhaseCompleted, migration.Phase)
+	assert.Equal(StatusCreated, status2.Status)
+	assert.Equal(indexName+"-v2", elasticsearch.getBackingIndex(ctx, indexName))
+	assert.Equal(indexName+"-v2", elasticsearch.getBackingIndex(ctx, "k8s_epo_test_migrate_search"))
+	properties, err := elasticsearch.getProperties(ctx, indexName+"-v2")
+	assert.Nil(err)
+	assert.True(CompareJson(*properties, `{"properties" :{"description":{"type":"text"}}}`))
+
+	//same model on the alias => nothing to do
+	status3, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, model, migration)
+	assert.Nil(err)
+	assert.Equal(StatusCreated, status3.Status)
+	assert.Equal(MigrationPhaseCompleted, migration.Phase)
+
+	//without migration strategy => bad request
+	status4, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "1", "number_of_shards": "1"}, "mappings":{"properties":{"other":{"type":"text"}}}}`, nil)
+	assert.NotNil(err)
+	assert.Equal(StatusError, status4.Status)
+}
+
+func TestElasticsearch7_ResizeIndex(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch(t)
+	defer deleteAll(elasticsearch)
+
+	indexName := "k8s_epo_test_resize_index"
+	migration := &EsMigration{}
+
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "0", "number_of_shards": "1"}, "mappings":{"properties":{"description":{"type":"keyword"}}}}`, migration)
+	assert.Nil(err)
+	assert.Equal(StatusCreated, status.Status)
+
+	//update number_of_shards from 1 to 3 => rejected, number_of_routing_shards is 1024
+	routingShards, err := elasticsearch.GetIndexRoutingShards(ctx, indexName)
+	assert.Nil(err)
+	assert.Equal(int32(1024), *routingShards)
+	_, err = elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "0", "number_of_shards": "3"}, "mappings":{"properties":{"description":{"type":"keyword"}}}}`, migration)
+	assert.NotNil(err)
+	assert.Equal("", migration.Operation)
+
+	//update number_of_shards from 1 to 4 => split
+	model := `{"settings":{"number_of_replicas": "0", "number_of_shards": "4"}, "mappings":{"properties":{"description":{"type":"keyword"}}}}`
+	status2, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, model, migration)
+	assert.Nil(err)
+	assert.Equal(StatusMigrating, status2.Status)
+	assert.Equal(MigrationOperationSplit, migration.Operation)
+	for i := 0; i < 10 && migration.Phase != MigrationPhaseCompleted && migration.Phase != MigrationPhaseFailed; i++ {
+		time.Sleep(time.Second)
+		status2, err = elasticsearch.CreateOrUpdateIndex(ctx, indexName, model, migration)
+		assert.Nil(err)
+	}
+	assert.Equal(MigrationPhaseCompleted, migration.Phase)
+	assert.Equal(StatusCreated, status2.Status)
+	assert.Equal(indexName+"-v2", elasticsearch.getBackingIndex(ctx, indexName))
+	_, shards := elasticsearch.getNumberOfReplicasAndShards(ctx, indexName+"-v2")
+	assert.Equal(int32(4), *shards)
+
+	//update number_of_shards from 4 to 2 => shrink
+	model = `{"settings":{"number_of_replicas": "0", "number_of_shards": "2"}, "mappings":{"properties":{"description":{"type":"keyword"}}}}`
+	status3, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, model, migration)
+	assert.Nil(err)
+	assert.Equal(StatusMigrating, status3.Status)
+	assert.Equal(MigrationOperationShrink, migration.Operation)
+	for i := 0; i < 10 && migration.Phase != MigrationPhaseCompleted && migration.Phase != MigrationPhaseFailed; i++ {
+		time.Sleep(time.Second)
+		status3, err = elasticsearch.CreateOrUpdateIndex(ctx, indexName, model, migration)
+		assert.Nil(err)
+	}
+	assert.Equal(MigrationPhaseCompleted, migration.Phase)
+	assert.Equal(StatusCreated, status3.Status)
+	assert.Equal(indexName+"-v3", elasticsearch.getBackingIndex(ctx, indexName))
+	_, shards = elasticsearch.getNumberOfReplicasAndShards(ctx, indexName+"-v3")
+	assert.Equal(int32(2), *shards)
+	assert.False(*elasticsearch.existsIndex(ctx, indexName+"-v2"))
+
+	//update number_of_shards from 2 to 3 => bad request
+	status4, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "0", "number_of_shards": "3"}, "mappings":{"properties":{"description":{"type":"keyword"}}}}`, migration)
+	assert.NotNil(err)
+	assert.Equal(StatusError, status4.Status)
+}
+
 func TestElasticsearch7_DeleteIndex(t *testing.T) {
 	ctx := context.Background()
 	assert := assert.New(t)
@@ -234,7 +452,7 @@ func TestElasticsearch7_DeleteIndex(t *testing.T) {
 
 	indexName := "k8s_epo_test_delete_index"
 
-	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "3"}}`)
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "3"}}`, nil)
 	assert.Nil(err)
 	assert.Equal("200", status.HttpCodeStatus)
 	assert.True(*elasticsearch.existsIndex(ctx, indexName))
@@ -255,7 +473,16 @@ func TestElasticsearch7_CreateOrUpdateTemplate(t *testing.T) {
 	status, err := elasticsearch.CreateOrUpdateTemplate(ctx, templateName, `{"index_patterns": ["k8s_epo_test_*"], "mappings": {"properties": {}}}`, nil)
 	assert.Nil(err)
 	assert.Equal("200", status.HttpCodeStatus)
+	assert.Equal([]string{OperationTemplatePut}, status.Operations)
 	assert.True(*elasticsearch.existsTemplate(ctx, templateName))
+
+	model := `{"index_patterns": ["k8s_epo_test_*"], "mappings": {"properties": {"description": {"type": "keyword"}}}}`
+	status2, err := elasticsearch.CreateOrUpdateTemplate(ctx, templateName, model, nil)
+	assert.Nil(err)
+	assert.Equal([]string{OperationTemplatePut}, status2.Operations)
+	status3, err := elasticsearch.CreateOrUpdateTemplate(ctx, templateName, model, nil)
+	assert.Nil(err)
+	assert.Empty(status3.Operations, "an unchanged template is not reported as put")
 }
 
 func TestElasticsearch7_CreateOrUpdateTemplate_WithType(t *testing.T) {
@@ -272,6 +499,34 @@ func TestElasticsearch7_CreateOrUpdateTemplate_WithType(t *testing.T) {
 	assert.True(*elasticsearch.existsTemplate(ctx, templateName))
 }
 
+func TestElasticsearch7_GetTemplateDrift(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch(t)
+	defer deleteAll(elasticsearch)
+
+	templateName := "k8s_epo_test_template_drift"
+	model := `{"index_patterns": ["k8s_epo_test_drift_*"], "settings": {"number_of_shards": 1}, "mappings": {"properties": {"description":{"type":"keyword"}}}}`
+	order := 1
+
+	drift, err := elasticsearch.GetTemplateDrift(ctx, templateName, model, &order)
+	assert.Nil(err)
+	assert.Equal([]string{"template k8s_epo_test_template_drift does not exist"}, drift.Reasons)
+
+	_, err = elasticsearch.CreateOrUpdateTemplate(ctx, templateName, model, &order)
+	assert.Nil(err)
+	drift2, err := elasticsearch.GetTemplateDrift(ctx, templateName, model, &order)
+	assert.Nil(err)
+	assert.False(drift2.IsDrifted())
+
+	//template overwritten
+	_, err = elasticsearch.CreateOrUpdateTemplate(ctx, templateName, `{"index_patterns": ["k8s_epo_test_drift_*"], "settings": {"number_of_shards": 2}}`, &order)
+	assert.Nil(err)
+	drift3, err := elasticsearch.GetTemplateDrift(ctx, templateName, model, &order)
+	assert.Nil(err)
+	assert.Equal([]string{"settings differ", "mappings differ"}, drift3.Reasons)
+}
+
 func TestElasticsearch7_DeleteTemplate(t *testing.T) {
 	ctx := context.Background()
 	assert := assert.New(t)
@@ -289,3 +544,172 @@ func TestElasticsearch7_DeleteTemplate(t *testing.T) {
 	assert.Nil(err2)
 	assert.False(*elasticsearch.existsTemplate(ctx, templateName))
 }
+
+func TestElasticsearch7_IndexTemplates(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch(t)
+	defer deleteAll(elasticsearch)
+
+	componentName, templateName := "k8s_epo_test_component", "k8s_epo_test_index_template"
+	defer elasticsearch.DeleteComponentTemplate(ctx, componentName)
+	defer elasticsearch.DeleteIndexTemplate(ctx, templateName)
+	component := `{"template": {"mappings": {"properties": {"message": {"type": "text"}}}}, "version": 1}`
+	model := `{"index_patterns": ["k8s_epo_test_composable_*"], "template": {"settings": {"number_of_shards": 1}}, "priority": 500, "composed_of": ["k8s_epo_test_component"]}`
+
+	status, err := elasticsearch.CreateOrUpdateComponentTemplate(ctx, componentName, component)
+	assert.Nil(err)
+	assert.Equal("200", status.HttpCodeStatus)
+	assert.Equal([]string{OperationComponentTemplatePut}, status.Operations)
+	exists, err := elasticsearch.ExistsComponentTemplate(ctx, componentName)
+	assert.Nil(err)
+	assert.True(exists)
+
+	drift, err := elasticsearch.GetIndexTemplateDrift(ctx, templateName, model)
+	assert.Nil(err)
+	assert.Equal([]string{"index template k8s_epo_test_index_template does not exist"}, drift.Reasons)
+	status, err = elasticsearch.CreateOrUpdateIndexTemplate(ctx, templateName, model)
+	assert.Nil(err)
+	assert.Equal([]string{OperationIndexTemplatePut}, status.Operations)
+	drift, err = elasticsearch.GetIndexTemplateDrift(ctx, templateName, model)
+	assert.Nil(err)
+	assert.False(drift.IsDrifted())
+	drift, err = elasticsearch.GetComponentTemplateDrift(ctx, componentName, `{"template": {"mappings": {"properties": {"message": {"type": "keyword"}}}}, "version": 1}`)
+	assert.Nil(err)
+	assert.Equal([]string{"mappings differ"}, drift.Reasons)
+
+	assert.Nil(elasticsearch.DeleteIndexTemplate(ctx, templateName))
+	assert.Nil(elasticsearch.DeleteComponentTemplate(ctx, componentName))
+	exists, err = elasticsearch.ExistsComponentTemplate(ctx, componentName)
+	assert.Nil(err)
+	assert.False(exists)
+	assert.Nil(elasticsearch.DeleteComponentTemplate(ctx, componentName), "already deleted")
+}
+
+func TestElasticsearch7_IndexTemplates_RequireVersion(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"version":{"number":"7.7.1"}}`)
+	}))
+	defer server.Close()
+
+	esConfig, err := (&EsConfig{}).FromURI(server.URL)
+	assert.Nil(err)
+	elasticsearch := &Elasticsearch7{}
+	assert.Nil(elasticsearch.NewClient(esConfig, zap.New(zap.UseDevMode(true))))
+
+	status, err := elasticsearch.CreateOrUpdateIndexTemplate(context.Background(), "logs", `{"index_patterns":["logs-*"]}`)
+	assert.NotNil(err)
+	assert.Equal(StatusError, status.Status)
+	assert.Equal("composable index templates require elasticsearch 7.8.0 or later, the cluster runs 7.7.1", status.Message)
+	_, err = elasticsearch.ExistsComponentTemplate(context.Background(), "mappings")
+	assert.NotNil(err)
+}
+
+func TestElasticsearch7_MigrateIndex_RequireVersion(t *testing.T) {
+	assert := assert.New(t)
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		fmt.Fprint(w, `{"version":{"number":"7.3.2"}}`)
+	}))
+	defer server.Close()
+
+	esConfig, err := (&EsConfig{}).FromURI(server.URL)
+	assert.Nil(err)
+	elasticsearch := &Elasticsearch7{}
+	assert.Nil(elasticsearch.NewClient(esConfig, zap.New(zap.UseDevMode(true))))
+	requests = nil
+
+	migration := &EsMigration{OldIndexPolicy: OldIndexPolicyRetain}
+	status := elasticsearch.prepareIndexMigration(context.Background(), "logs", "logs", MigrationOperationReindex, migration)
+	assert.NotNil(status)
+	assert.Equal(StatusError, status.Status)
+	assert.Equal("index logs cannot be retained, it is cloned to free its name for the alias and index clones require elasticsearch 7.4.0 or later, the cluster runs 7.3.2, set migration.oldIndexPolicy to Delete", status.Message)
+	assert.Equal(MigrationPhaseFailed, migration.Phase)
+	assert.Empty(requests, "the migration fails before blocking writes")
+}
+
+func TestElasticsearch7_ILMPolicies(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch(t)
+	defer deleteAll(elasticsearch)
+
+	policyName, indexName := "k8s_epo_test_policy", "k8s_epo_test_ilm_index"
+	defer elasticsearch.DeleteILMPolicy(ctx, policyName)
+	model := `{"policy": {"phases": {"hot": {"actions": {"rollover": {"max_age": "1d"}}}, "delete": {"min_age": "30d", "actions": {"delete": {}}}}}}`
+
+	drift, err := elasticsearch.GetILMPolicyDrift(ctx, policyName, model)
+	assert.Nil(err)
+	assert.Equal([]string{"ILM policy k8s_epo_test_policy does not exist"}, drift.Reasons)
+	status, err := elasticsearch.CreateOrUpdateILMPolicy(ctx, policyName, model)
+	assert.Nil(err)
+	assert.Equal("200", status.HttpCodeStatus)
+	assert.Equal([]string{OperationILMPolicyPut}, status.Operations)
+	drift, err = elasticsearch.GetILMPolicyDrift(ctx, policyName, model)
+	assert.Nil(err)
+	assert.False(drift.IsDrifted())
+	drift, err = elasticsearch.GetILMPolicyDrift(ctx, policyName, `{"policy": {"phases": {"hot": {"actions": {"rollover": {"max_age": "7d"}}}}}}`)
+	assert.Nil(err)
+	assert.Equal([]string{"action rollover of phase hot differs", "phase delete is not in the model"}, drift.Reasons)
+
+	_, err = elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings": {"index.lifecycle.name": "k8s_epo_test_policy"}}`, nil)
+	assert.Nil(err)
+	indices, err := elasticsearch.GetILMPolicyIndices(ctx, policyName)
+	assert.Nil(err)
+	assert.Equal([]string{indexName}, indices)
+
+	assert.Nil(elasticsearch.DeleteIndex(ctx, indexName))
+	assert.Nil(elasticsearch.DeleteILMPolicy(ctx, policyName))
+	assert.Nil(elasticsearch.DeleteILMPolicy(ctx, policyName), "already deleted")
+}
+
+func TestElasticsearch7_IngestPipelines(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch(t)
+
+	pipelineName := "k8s_epo_test_pipeline"
+	defer elasticsearch.DeleteIngestPipeline(ctx, pipelineName)
+	model := `{"description": "lowercase level", "processors": [{"lowercase": {"field": "level"}}], "version": 3}`
+
+	failures, err := elasticsearch.SimulateIngestPipeline(ctx, model, []string{`{"level": "INFO"}`, `{"message": "no level"}`})
+	assert.Nil(err)
+	assert.Equal("", failures[0])
+	assert.Contains(failures[1], "level")
+	_, err = elasticsearch.SimulateIngestPipeline(ctx, `{"processors": [{"unknown": {}}]}`, []string{`{}`})
+	assert.NotNil(err)
+
+	drift, err := elasticsearch.GetIngestPipelineDrift(ctx, pipelineName, model)
+	assert.Nil(err)
+	assert.Equal([]string{"ingest pipeline k8s_epo_test_pipeline does not exist"}, drift.Reasons)
+	status, err := elasticsearch.CreateOrUpdateIngestPipeline(ctx, pipelineName, model)
+	assert.Nil(err)
+	assert.Equal("200", status.HttpCodeStatus)
+	assert.Equal([]string{OperationIngestPipelinePut}, status.Operations)
+	drift, err = elasticsearch.GetIngestPipelineDrift(ctx, pipelineName, model)
+	assert.Nil(err)
+	assert.False(drift.IsDrifted())
+	version, err := elasticsearch.GetIngestPipelineVersion(ctx, pipelineName)
+	assert.Nil(err)
+	assert.Equal(int64(3), *version)
+
+	assert.Nil(elasticsearch.DeleteIngestPipeline(ctx, pipelineName))
+	version, err = elasticsearch.GetIngestPipelineVersion(ctx, pipelineName)
+	assert.Nil(err)
+	assert.Nil(version)
+	assert.Nil(elasticsearch.DeleteIngestPipeline(ctx, pipelineName), "already deleted")
+}
+
+func TestElasticsearch7_SnapshotRepositories(t *testing.T) {
+	testSnapshotRepositoryBackend(t, buildElasticsearch(t))
+}
+
+func TestElasticsearch7_SLMPolicies(t *testing.T) {
+	testSLMPolicyBackend(t, buildElasticsearch(t))
+}
+
+func TestElasticsearch7_DataStreams(t *testing.T) {
+	testDataStreamBackend(t, buildElasticsearch(t), false)
+}
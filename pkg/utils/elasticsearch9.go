@@ -0,0 +1,22 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+// Elasticsearch9 manages indices and templates of elasticsearch 9 clusters. The go-elasticsearch 9 client needs a newer
+// go toolchain and logr than the operator is built with, so the elasticsearch 8 client is used: the index, mapping,
+// alias, template, resize and reindex APIs used by the operator are unchanged in elasticsearch 9
+type Elasticsearch9 struct {
+	Elasticsearch8
+}
@@ -0,0 +1,139 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"testing"
+)
+
+const (
+	es9Scheme  = "http"
+	es9Host    = "elastic9"
+	es9Port    = "9200"
+	es9Version = 9
+)
+
+func buildElasticsearch9(t *testing.T) *Elasticsearch9 {
+	assert := assert.New(t)
+
+	esConfig, err1 := (&EsConfig{}).FromURI(fmt.Sprintf("%v://%v:%v", es9Scheme, es9Host, es9Port))
+	assert.Nil(err1)
+	assert.Equal(es9Version, esConfig.Version)
+	assert.Equal("elasticsearch9", esConfig.Backend)
+	log := zap.New(zap.UseDevMode(true))
+
+	elasticsearch, err2 := NewElasticsearch(esConfig, log)
+	assert.Nil(err2)
+	assert.IsType(&Elasticsearch9{}, elasticsearch)
+
+	return elasticsearch.(*Elasticsearch9)
+}
+
+func deleteAllES9(elasticsearch *Elasticsearch9) {
+	ctx := context.Background()
+	elasticsearch.DeleteIndex(ctx, "k8s_epo_*")
+	elasticsearch.DeleteTemplate(ctx, "k8s_epo_*")
+}
+
+func TestEsVersion_ES9(t *testing.T) {
+	assert := assert.New(t)
+
+	esVersion, err := EsVersion(fmt.Sprintf("%v://%v:%v", es9Scheme, es9Host, es9Port))
+	assert.Nil(err)
+	assert.Equal(es9Version, esVersion)
+}
+
+func TestElasticsearch9_PingES(t *testing.T) {
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch9(t)
+	defer deleteAllES9(elasticsearch)
+
+	err3 := elasticsearch.PingES(context.Background())
+	assert.Nil(err3)
+}
+
+func TestElasticsearch9_CreateOrUpdateIndex(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch9(t)
+	defer deleteAllES9(elasticsearch)
+
+	indexName := "k8s_epo_test_create_index"
+
+	status, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "0", "number_of_shards": "2"}, "mappings":{"properties":{"description":{"type":"keyword"}}}, "aliases":{"k8s_epo_search":{}}}`, nil)
+	assert.Nil(err)
+	assert.Equal("200", status.HttpCodeStatus)
+	assert.Equal(StatusCreated, status.Status)
+	assert.True(*elasticsearch.existsIndex(ctx, indexName))
+
+	//new field in properties and new replicas number
+	model := `{"settings":{"number_of_replicas": "1", "number_of_shards": "2"}, "mappings":{"properties":{"description":{"type":"keyword"}, "newField":{"type":"text"}}}, "aliases":{"k8s_epo_search":{}}}`
+	status2, err := elasticsearch.CreateOrUpdateIndex(ctx, indexName, model, nil)
+	assert.Nil(err)
+	assert.Equal(StatusCreated, status2.Status)
+	replicas, shards := elasticsearch.getNumberOfReplicasAndShards(ctx, indexName)
+	assert.Equal(int32(1), *replicas)
+	assert.Equal(int32(2), *shards)
+	properties, err := elasticsearch.getProperties(ctx, indexName)
+	assert.Nil(err)
+	assert.True(CompareJson(*properties, `{"properties" :{"description":{"type":"keyword"}, "newField":{"type":"text"}}}`))
+
+	drift, err := elasticsearch.GetIndexDrift(ctx, indexName, model)
+	assert.Nil(err)
+	assert.False(drift.IsDrifted())
+
+	err2 := elasticsearch.DeleteIndex(ctx, indexName)
+	assert.Nil(err2)
+	assert.False(*elasticsearch.existsIndex(ctx, indexName))
+}
+
+func TestElasticsearch9_CreateOrUpdateTemplate(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	elasticsearch := buildElasticsearch9(t)
+	defer deleteAllES9(elasticsearch)
+
+	templateName := "k8s_epo_test_create_template"
+	model := `{"index_patterns": ["k8s_epo_test_*"], "settings": {"number_of_shards": 1}, "mappings": {"properties": {"description":{"type":"keyword"}}}}`
+	order := 1
+
+	status, err := elasticsearch.CreateOrUpdateTemplate(ctx, templateName, model, &order)
+	assert.Nil(err)
+	assert.Equal("200", status.HttpCodeStatus)
+	assert.True(*elasticsearch.existsTemplate(ctx, templateName))
+	drift, err := elasticsearch.GetTemplateDrift(ctx, templateName, model, &order)
+	assert.Nil(err)
+	assert.False(drift.IsDrifted())
+
+	err2 := elasticsearch.DeleteTemplate(ctx, templateName)
+	assert.Nil(err2)
+	assert.False(*elasticsearch.existsTemplate(ctx, templateName))
+}
+
+func TestElasticsearch9_DataStreams(t *testing.T) {
+	testDataStreamBackend(t, buildElasticsearch9(t), true)
+}
+
+func TestElasticsearch9_SnapshotRepositories(t *testing.T) {
+	testSnapshotRepositoryBackend(t, buildElasticsearch9(t))
+}
+
+func TestElasticsearch9_SLMPolicies(t *testing.T) {
+	testSLMPolicyBackend(t, buildElasticsearch9(t))
+}
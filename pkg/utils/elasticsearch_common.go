@@ -16,25 +16,60 @@ package utils
 
 import (
 	"context"
-	"crypto/tls"
+	"encoding/base64"
 	"fmt"
 	"github.com/go-logr/logr"
+	funk "github.com/thoas/go-funk"
+	"github.com/tidwall/gjson"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 )
 
 const (
-	StatusCreated                      = "Created"
-	StatusRetry                        = "Retry"
-	StatusError                        = "Error"
-	Index                              = "Index"
-	Template                           = "Template"
-	ElasticMainFnTimeout time.Duration = 10 * time.Second
+	StatusCreated     = "Created"
+	StatusRetry       = "Retry"
+	StatusError       = "Error"
+	StatusMigrating   = "Migrating"
+	Index             = "Index"
+	Template          = "Template"
+	IndexTemplate     = "IndexTemplate"
+	ComponentTemplate = "ComponentTemplate"
+	ILMPolicy         = "ILMPolicy"
+	IngestPipeline    = "IngestPipeline"
+	DataStream        = "DataStream"
+	// SnapshotRepository and SLMPolicy are the kinds of the objects backing up the clusters
+	SnapshotRepository = "SnapshotRepository"
+	SLMPolicy          = "SLMPolicy"
+	// IndexTemplatesMinVersion is the first elasticsearch version with composable index templates and component templates
+	IndexTemplatesMinVersion               = "7.8.0"
+	ElasticMainFnTimeout     time.Duration = 10 * time.Second
+	// DefaultMappingType is the type of typeless mappings applied to elasticsearch 6
+	DefaultMappingType = "_doc"
+	// Distributions of the clusters, read from version.distribution of GET /
+	DistributionElasticsearch = "elasticsearch"
+	DistributionOpenSearch    = "opensearch"
+	// IlmPolicySetting and IlmRolloverAliasSetting are the ILM settings of a model, translated for opensearch ISM
+	IlmPolicySetting        = "index.lifecycle.name"
+	IlmRolloverAliasSetting = "index.lifecycle.rollover_alias"
+	IsmRolloverAliasSetting = "index.plugins.index_state_management.rollover_alias"
+
+	// DataStreamsMinVersion is the first elasticsearch version with the _data_stream API, and
+	// DataStreamLifecycleMinVersion the first one with the data stream lifecycle
+	DataStreamsMinVersion         = "7.9.0"
+	DataStreamLifecycleMinVersion = "8.11.0"
+	// SLMMinVersion is the first elasticsearch version with snapshot lifecycle management
+	SLMMinVersion = "7.4.0"
+	// CloneMinVersion is the first elasticsearch version with the clone index API, retaining the original index of a
+	// migration
+	CloneMinVersion = "7.4.0"
 )
 
+// EsConfig is the connection to an elasticsearch cluster. Scheme, Host and Port are the ones of the first node
 type EsConfig struct {
 	Scheme   string
 	Host     string
@@ -42,56 +77,414 @@ type EsConfig struct {
 	Username string
 	Password string
 	Version  int
+	// APIKey, as id:key or encoded, used instead of Username and Password when set
+	APIKey string
+	// BearerToken, like an elasticsearch service account token, used instead of Username and Password when set
+	BearerToken string
+	// TLS configuration of https connections, elasticsearch certificate being verified with system CAs when nil
+	TLS *EsTLSConfig
+	// Addresses of all the nodes of the uri, scheme://host:port without credentials
+	Addresses []string
+	// CloudID of the Elastic Cloud deployment, when the uri is a Cloud ID
+	CloudID string
+	// ClusterUUID detected with the version, empty when the cluster has no elected master yet
+	ClusterUUID string
+	// VersionNumber is the full elasticsearch version, like 8.4.2
+	VersionNumber string
+	// Distribution of the cluster, DistributionElasticsearch or DistributionOpenSearch, detected with the version
+	Distribution string
+	// Backend is the name of the EsBackend serving the cluster, detected with the version
+	Backend string
+	// RequestTimeout of each elasticsearch API call, the default request timeout when 0
+	RequestTimeout time.Duration
+	// Sniffing discovers the nodes of the cluster from the nodes of the uri, disabled when nil
+	Sniffing *EsSniffing
+	// HTTP configures the proxy, the headers, the compression and the User-Agent of the requests, defaults when nil
+	HTTP *EsHTTPConfig
+	// Transport shared by the clients of the same connection, a new one is created by NewClient when nil
+	Transport http.RoundTripper
+	// connection shares clients between reconciliations, nil when the config was not read from an EsClientRegistry
+	connection *esConnection
+}
+
+// EsSniffing discovers the nodes of the cluster when the client is created, and every Interval when it is not 0
+type EsSniffing struct {
+	Interval time.Duration
 }
 
 func (conf EsConfig) String() string {
-	return fmt.Sprintf("%v://%v:%v", conf.Scheme, conf.Host, conf.Port)
+	if len(conf.Addresses) > 1 {
+		return strings.Join(conf.Addresses, ",")
+	}
+	return nodeAddress(conf.Scheme, conf.Host, conf.Port)
+}
+
+// defaultRequestTimeout is the timeout of the elasticsearch API calls of the connections without request timeout
+var defaultRequestTimeout = ElasticMainFnTimeout
+
+// SetDefaultRequestTimeout sets the timeout of the elasticsearch API calls of the connections without request timeout,
+// ElasticMainFnTimeout when 0. It is called before opening connections, like from operator flags
+func SetDefaultRequestTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = ElasticMainFnTimeout
+	}
+	defaultRequestTimeout = timeout
 }
 
+// GetRequestTimeout returns the timeout of each elasticsearch API call
+func (conf *EsConfig) GetRequestTimeout() time.Duration {
+	if conf.RequestTimeout <= 0 {
+		return defaultRequestTimeout
+	}
+	return conf.RequestTimeout
+}
+
+// GetAddresses returns the addresses of the nodes of the uri
+func (conf *EsConfig) GetAddresses() []string {
+	if len(conf.Addresses) == 0 {
+		return []string{nodeAddress(conf.Scheme, conf.Host, conf.Port)}
+	}
+	return conf.Addresses
+}
+
+func nodeAddress(scheme string, host string, port string) string {
+	return fmt.Sprintf("%v://%v:%v", scheme, host, port)
+}
+
+// clientKey identifies the clients of a connection by distribution, version and sniffing configuration
+func (conf *EsConfig) clientKey(version int) string {
+	key := strconv.Itoa(version)
+	if conf.Distribution == DistributionOpenSearch {
+		key = DistributionOpenSearch + key
+	}
+	if conf.Sniffing == nil {
+		return key
+	}
+	return fmt.Sprintf("%v/sniffing/%v", key, conf.Sniffing.Interval)
+}
+
+// sharedClient returns the client of key shared by the reconciliations using the same connection, created by create
+// when it does not exist yet or when the config was not read from an EsClientRegistry. The nodes of a sniffing client
+// are discovered when it is created, and then periodically by the connection until it is removed from the registry
+func (conf *EsConfig) sharedClient(key string, create func() (interface{}, error)) (interface{}, error) {
+	if conf.connection == nil {
+		return create()
+	}
+	var sniffingInterval time.Duration
+	if conf.Sniffing != nil {
+		sniffingInterval = conf.Sniffing.Interval
+	}
+	return conf.connection.client(key, sniffingInterval, create)
+}
+
+// FromURI returns the config of an elasticsearch uri with its detected version, connecting with the credentials and
+// the TLS configuration of conf
 func (conf *EsConfig) FromURI(rawurl string) (*EsConfig, error) {
-	u, err := url.Parse(rawurl)
-	if err != nil {
-		return nil, err
+	return (&EsConnection{URI: rawurl, Options: conf}).Probe()
+}
+
+// ParseEsURI returns the config of an elasticsearch uri, without detecting the elasticsearch version. The uri is
+// either a comma separated list of node URLs, credentials being read from the first URL having some, or an Elastic
+// Cloud ID
+func ParseEsURI(rawurl string) (*EsConfig, error) {
+	rawurl = strings.TrimSpace(rawurl)
+	if rawurl != "" && !strings.Contains(rawurl, "://") {
+		return parseCloudID(rawurl)
 	}
 
 	var esConfig *EsConfig
-	if u.User != nil {
-		password, _ := u.User.Password()
-		esConfig = &EsConfig{Scheme: u.Scheme, Host: u.Hostname(), Port: u.Port(), Username: u.User.Username(), Password: password}
-	} else {
-		esConfig = &EsConfig{Scheme: u.Scheme, Host: u.Hostname(), Port: u.Port()}
-	}
+	for _, nodeURL := range strings.Split(rawurl, ",") {
+		u, err := url.Parse(strings.TrimSpace(nodeURL))
+		if err != nil {
+			return nil, err
+		}
+		if u.Hostname() == "" {
+			return nil, fmt.Errorf("Cannot retrieve host from rawurl %v", rawurl)
+		}
 
-	if esConfig.Host == "" {
-		return nil, fmt.Errorf("Cannot retrieve host from rawurl %v", rawurl)
+		if esConfig == nil {
+			esConfig = &EsConfig{Scheme: u.Scheme, Host: u.Hostname(), Port: u.Port()}
+		}
+		if u.User != nil {
+			password, _ := u.User.Password()
+			if esConfig.Username == "" {
+				esConfig.Username, esConfig.Password = u.User.Username(), password
+			} else if esConfig.Username != u.User.Username() || esConfig.Password != password {
+				return nil, fmt.Errorf("all the node URLs of an elasticsearch uri must have the same credentials")
+			}
+		}
+		esConfig.Addresses = append(esConfig.Addresses, nodeAddress(u.Scheme, u.Hostname(), u.Port()))
 	}
 
-	esVersion, err := EsVersion(rawurl)
+	return esConfig, nil
+}
+
+// parseCloudID returns the config of an Elastic Cloud ID, made of the deployment name and of
+// base64(host[:port]$elasticsearch-id$kibana-id)
+func parseCloudID(cloudID string) (*EsConfig, error) {
+	parts := strings.SplitN(cloudID, ":", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid elastic cloud id %v: expected <name>:<base64 data>", cloudID)
+	}
+	data, err := base64.StdEncoding.DecodeString(parts[1])
 	if err != nil {
-		return esConfig, err
+		return nil, fmt.Errorf("invalid elastic cloud id %v: %v", cloudID, err)
+	}
+	values := strings.Split(string(data), "$")
+	if len(values) < 2 || values[0] == "" || values[1] == "" {
+		return nil, fmt.Errorf("invalid elastic cloud id %v: cannot retrieve host and elasticsearch id", cloudID)
 	}
-	esConfig.Version = esVersion
 
+	host, port := values[0], "443"
+	if index := strings.LastIndex(host, ":"); index >= 0 {
+		host, port = host[:index], host[index+1:]
+	}
+	esConfig := &EsConfig{Scheme: "https", Host: values[1] + "." + host, Port: port, CloudID: cloudID}
+	esConfig.Addresses = []string{nodeAddress(esConfig.Scheme, esConfig.Host, esConfig.Port)}
 	return esConfig, nil
 }
 
+// Operations reported in EsStatus.Operations, one for each change made in elasticsearch
+const (
+	OperationIndexCreated         = "IndexCreated"
+	OperationReplicasUpdated      = "ReplicasUpdated"
+	OperationSettingsUpdated      = "SettingsUpdated"
+	OperationMappingUpdated       = "MappingUpdated"
+	OperationAliasesUpdated       = "AliasesUpdated"
+	OperationTemplatePut          = "TemplatePut"
+	OperationIsmPolicyAttached    = "IsmPolicyAttached"
+	OperationIndexTemplatePut     = "IndexTemplatePut"
+	OperationComponentTemplatePut = "ComponentTemplatePut"
+	OperationILMPolicyPut         = "ILMPolicyPut"
+	OperationIngestPipelinePut    = "IngestPipelinePut"
+	OperationDataStreamCreated    = "DataStreamCreated"
+	OperationDataLifecyclePut     = "DataLifecyclePut"
+	OperationRolledOver           = "RolledOver"
+	OperationRepositoryPut        = "RepositoryPut"
+	OperationSLMPolicyPut         = "SLMPolicyPut"
+)
+
 type EsStatus struct {
 	Status         string
 	HttpCodeStatus string
 	Message        string
+	Aliases        []EsAliasStatus
+	Operations     []string
+}
+
+// Reason returns the reason of the elasticsearch error found in Message, or Message itself when it is not an
+// elasticsearch error response
+func (s *EsStatus) Reason() string {
+	if index := strings.Index(s.Message, "{"); index >= 0 {
+		body := s.Message[index:]
+		if reason := gjson.Get(body, "error.reason"); reason.Exists() {
+			return reason.String()
+		}
+		if reason := gjson.Get(body, "error"); reason.Type == gjson.String {
+			return reason.String()
+		}
+	}
+	return s.Message
+}
+
+// EsDrift describes the differences between elasticsearch and the model of an object
+type EsDrift struct {
+	Reasons []string
+}
+
+func (d *EsDrift) IsDrifted() bool {
+	return d != nil && len(d.Reasons) > 0
+}
+
+// EsAliasStatus is the result of the reconciliation of an alias of the model
+type EsAliasStatus struct {
+	Name    string
+	Status  string
+	Message string
+}
+
+// BuildEsAliasesStatus returns the same status for every alias of the model, sorted by alias name.
+// A non nil slice is returned even if the model has no alias, nil meaning that aliases were not reconciled
+func BuildEsAliasesStatus(model string, status string, message string) []EsAliasStatus {
+	aliases := []EsAliasStatus{}
+	names := funk.Keys((&EsModel{Model: model}).GetAliases()).([]string)
+	sort.Strings(names)
+	for _, name := range names {
+		aliases = append(aliases, EsAliasStatus{Name: name, Status: status, Message: message})
+	}
+	return aliases
+}
+
+const (
+	MigrationStrategyReject    = "Reject"
+	MigrationStrategyReindex   = "Reindex"
+	OldIndexPolicyDelete       = "Delete"
+	OldIndexPolicyRetain       = "Retain"
+	MigrationOperationReindex  = "Reindex"
+	MigrationOperationSplit    = "Split"
+	MigrationOperationShrink   = "Shrink"
+	MigrationPhaseReindexing   = "Reindexing"
+	MigrationPhaseRelocating   = "Relocating"
+	MigrationPhaseResizing     = "Resizing"
+	MigrationPhaseCompleted    = "Completed"
+	MigrationPhaseFailed       = "Failed"
+	RetainedIndexInitialSuffix = "-v1"
+
+	StaticSettingsUpdateReject         = "Reject"
+	StaticSettingsUpdateCloseAndReopen = "CloseAndReopen"
+)
+
+// EsMigration describes a migration of an index to a new versioned index followed by an alias swap. The new index is
+// filled by a reindex when the mapping cannot be updated in place, or by a split/shrink when number_of_shards changes.
+// StaticSettingsUpdate allows closing and reopening the index to update static settings in place.
+// Strategy, OldIndexPolicy and StaticSettingsUpdate come from the object spec, other fields are updated while the migration goes on
+type EsMigration struct {
+	Strategy             string
+	OldIndexPolicy       string
+	StaticSettingsUpdate string
+	Operation            string
+	Phase                string
+	SourceIndex          string
+	TargetIndex          string
+	TaskID               string
+	Created              int64
+	Total                int64
 }
 
+func (m *EsMigration) isEnabled() bool {
+	return m != nil && m.Strategy == MigrationStrategyReindex
+}
+
+func (m *EsMigration) isCloseAndReopenAllowed() bool {
+	return m != nil && m.StaticSettingsUpdate == StaticSettingsUpdateCloseAndReopen
+}
+
+func (m *EsMigration) isInProgress() bool {
+	return m != nil && (m.Phase == MigrationPhaseReindexing || m.Phase == MigrationPhaseRelocating || m.Phase == MigrationPhaseResizing)
+}
+
+// GetResizeOperation returns Split when newShards is a multiple of oldShards, Shrink when newShards is a factor of oldShards,
+// or an empty string when number_of_shards cannot be changed from oldShards to newShards. A split also depends on the
+// number_of_routing_shards of the index, see ValidateSplit
+func GetResizeOperation(oldShards *int32, newShards *int32) string {
+	if oldShards == nil || newShards == nil || *oldShards <= 0 || *newShards <= 0 || *oldShards == *newShards {
+		return ""
+	}
+	if *newShards > *oldShards && *newShards%*oldShards == 0 {
+		return MigrationOperationSplit
+	}
+	if *newShards < *oldShards && *oldShards%*newShards == 0 {
+		return MigrationOperationShrink
+	}
+	return ""
+}
+
+// ValidateSplit checks that an index can be split into newShards shards: each shard is split along the routing of its
+// documents, so the number_of_routing_shards of the index, fixed when it was created, must be a multiple of newShards
+func ValidateSplit(indexName string, newShards int32, routingShards int32) error {
+	if newShards <= 0 || routingShards%newShards != 0 {
+		return fmt.Errorf("index %v cannot be split into %v shards: its number_of_routing_shards %v is not a multiple of %v, set index.number_of_routing_shards when creating an index to split it later",
+			indexName, newShards, routingShards, newShards)
+	}
+	return nil
+}
+
+// EsVersion returns the major version of an elasticsearch uri
 func EsVersion(rawurl string) (int, error) {
-	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
-	resp, err := client.Get(rawurl)
+	return (&EsConfig{}).EsVersion(rawurl)
+}
+
+// EsVersion returns the major version of an elasticsearch uri, connecting with the credentials, the TLS and the HTTP
+// configuration of conf
+func (conf *EsConfig) EsVersion(rawurl string) (int, error) {
+	esConfig, err := conf.FromURI(rawurl)
 	if err != nil {
 		return -1, err
 	}
+	return esConfig.Version, nil
+}
+
+// RequireVersion returns an error when the version of the cluster is lower than minimum, like 7.8.0, feature naming
+// what requires it. Configs whose version is not detected are not checked
+func (conf *EsConfig) RequireVersion(minimum string, feature string) error {
+	if conf.VersionNumber == "" {
+		return nil
+	}
+	version, err := ParseVersionNumber(conf.VersionNumber)
+	if err != nil {
+		return err
+	}
+	minimumVersion, err := ParseVersionNumber(minimum)
+	if err != nil {
+		return err
+	}
+	if version.Compare(minimumVersion) < 0 {
+		return fmt.Errorf("%v require %v %v or later, the cluster runs %v", feature, conf.Distribution, minimum, conf.VersionNumber)
+	}
+	return nil
+}
+
+// probeCluster sets the version and the cluster UUID of the config, trying each node until one answers
+func probeCluster(config *EsConfig, transport http.RoundTripper) error {
+	client := &http.Client{Transport: transport, Timeout: config.GetRequestTimeout()}
+	var err error
+	for _, address := range config.GetAddresses() {
+		var body string
+		if body, err = getNodeInfo(client, address, config); err != nil {
+			continue
+		}
+		var version int
+		if version, err = GetElasticsearchVersion(body); err != nil {
+			continue
+		}
+		config.Version, config.VersionNumber, config.ClusterUUID = version, gjson.Get(body, "version.number").String(), GetClusterUUID(body)
+		config.Distribution = GetDistribution(body)
+		if backend, err := GetEsBackend(config.Distribution, config.VersionNumber); err == nil {
+			config.Backend = backend.Name
+		}
+		return nil
+	}
+	return err
+}
+
+func getNodeInfo(client *http.Client, address string, config *EsConfig) (string, error) {
+	request, err := http.NewRequest(http.MethodGet, address, nil)
+	if err != nil {
+		return "", err
+	}
+	if config.Username != "" {
+		request.SetBasicAuth(config.Username, config.Password)
+	}
+	resp, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return -1, err
+		return "", err
+	}
+	return string(body), nil
+}
+
+// newTransport returns the transport of the clients of an elasticsearch connection
+func newTransport(config *EsConfig) (http.RoundTripper, error) {
+	tlsConfig, err := BuildTLSConfig(config.TLS)
+	if err != nil {
+		return nil, err
+	}
+	proxy, err := config.HTTP.GetProxy()
+	if err != nil {
+		return nil, err
 	}
-	return GetElasticsearchVersion(string(body))
+	var transport http.RoundTripper = &http.Transport{TLSClientConfig: tlsConfig, Proxy: proxy}
+	if authorization := config.GetAuthorization(); authorization != "" {
+		transport = &authTransport{next: transport, authorization: authorization}
+	}
+	if !config.HTTP.isEmpty() {
+		transport = &httpTransport{next: transport, config: config.HTTP}
+	}
+	return newInstrumentedTransport(transport, config), nil
 }
 
 func BuildEsStatus(statusCode int, message string) *EsStatus {
@@ -109,10 +502,81 @@ func BuildEsStatus(statusCode int, message string) *EsStatus {
 type Elasticsearch interface {
 	NewClient(config *EsConfig, log logr.Logger) error
 	PingES(ctx context.Context) error
-	CreateOrUpdateIndex(ctx context.Context, indexName string, model string) (*EsStatus, error)
+	CreateOrUpdateIndex(ctx context.Context, indexName string, model string, migration *EsMigration) (*EsStatus, error)
 	DeleteIndex(ctx context.Context, indexName string) error
+	GetIndexDrift(ctx context.Context, indexName string, model string) (*EsDrift, error)
 	CreateOrUpdateTemplate(ctx context.Context, templateName string, model string, order *int) (*EsStatus, error)
+	GetTemplateDrift(ctx context.Context, templateName string, model string, order *int) (*EsDrift, error)
 	DeleteTemplate(ctx context.Context, templateName string) error
+	GetClusterHealth(ctx context.Context) (string, error)
+	GetIndexRoutingShards(ctx context.Context, indexName string) (*int32, error)
+}
+
+// EsIndexTemplates is implemented by the backends of the clusters with composable index templates and component
+// templates, which are not supported by elasticsearch 6
+type EsIndexTemplates interface {
+	CreateOrUpdateIndexTemplate(ctx context.Context, templateName string, model string) (*EsStatus, error)
+	GetIndexTemplateDrift(ctx context.Context, templateName string, model string) (*EsDrift, error)
+	DeleteIndexTemplate(ctx context.Context, templateName string) error
+	CreateOrUpdateComponentTemplate(ctx context.Context, templateName string, model string) (*EsStatus, error)
+	GetComponentTemplateDrift(ctx context.Context, templateName string, model string) (*EsDrift, error)
+	DeleteComponentTemplate(ctx context.Context, templateName string) error
+	ExistsComponentTemplate(ctx context.Context, templateName string) (bool, error)
+}
+
+// EsILMPolicies is implemented by the backends of the elasticsearch clusters with index lifecycle management, opensearch
+// managing the lifecycle of indices with ISM policies instead
+type EsILMPolicies interface {
+	CreateOrUpdateILMPolicy(ctx context.Context, policyName string, model string) (*EsStatus, error)
+	GetILMPolicyDrift(ctx context.Context, policyName string, model string) (*EsDrift, error)
+	DeleteILMPolicy(ctx context.Context, policyName string) error
+	// GetILMPolicyIndices returns the sorted names of the indices managed by the policy
+	GetILMPolicyIndices(ctx context.Context, policyName string) ([]string, error)
+}
+
+// EsIngestPipelines is implemented by the backends of the clusters with ingest pipelines
+type EsIngestPipelines interface {
+	CreateOrUpdateIngestPipeline(ctx context.Context, pipelineName string, model string) (*EsStatus, error)
+	GetIngestPipelineDrift(ctx context.Context, pipelineName string, model string) (*EsDrift, error)
+	DeleteIngestPipeline(ctx context.Context, pipelineName string) error
+	// GetIngestPipelineVersion returns the version of the pipeline, nil when it has none or does not exist
+	GetIngestPipelineVersion(ctx context.Context, pipelineName string) (*int64, error)
+	// SimulateIngestPipeline runs the documents through the pipeline model, and returns for each of them the reason of
+	// its failure, empty when the document was processed
+	SimulateIngestPipeline(ctx context.Context, model string, documents []string) ([]string, error)
+}
+
+// EsDataStreams is implemented by the backends of the elasticsearch clusters with data streams
+type EsDataStreams interface {
+	// CreateOrUpdateDataStream creates the data stream when it does not exist, and puts the lifecycle of the model
+	CreateOrUpdateDataStream(ctx context.Context, dataStreamName string, model string) (*EsStatus, error)
+	GetDataStreamDrift(ctx context.Context, dataStreamName string, model string) (*EsDrift, error)
+	DeleteDataStream(ctx context.Context, dataStreamName string) error
+	// GetDataStream returns the data stream, nil when it does not exist
+	GetDataStream(ctx context.Context, dataStreamName string) (*EsDataStream, error)
+	// RolloverDataStream creates a new write index for the data stream
+	RolloverDataStream(ctx context.Context, dataStreamName string) (*EsStatus, error)
+	// GetDataStreamTemplate returns the composable index template that applies to the data stream, empty when there is
+	// none, and whether it has a data_stream field
+	GetDataStreamTemplate(ctx context.Context, dataStreamName string) (string, bool, error)
+}
+
+// EsSnapshotRepositories is implemented by the backends of the clusters with snapshot repositories
+type EsSnapshotRepositories interface {
+	CreateOrUpdateSnapshotRepository(ctx context.Context, repositoryName string, model string) (*EsStatus, error)
+	GetSnapshotRepositoryDrift(ctx context.Context, repositoryName string, model string) (*EsDrift, error)
+	DeleteSnapshotRepository(ctx context.Context, repositoryName string) error
+	ExistsSnapshotRepository(ctx context.Context, repositoryName string) (bool, error)
+}
+
+// EsSLMPolicies is implemented by the backends of the elasticsearch clusters with snapshot lifecycle management,
+// opensearch managing snapshots with snapshot management policies instead
+type EsSLMPolicies interface {
+	CreateOrUpdateSLMPolicy(ctx context.Context, policyName string, model string) (*EsStatus, error)
+	GetSLMPolicyDrift(ctx context.Context, policyName string, model string) (*EsDrift, error)
+	DeleteSLMPolicy(ctx context.Context, policyName string) error
+	// GetSLMPolicyStatus returns the last snapshots and the stats of the policy, nil when it does not exist
+	GetSLMPolicyStatus(ctx context.Context, policyName string) (*EsSLMPolicyStatus, error)
 }
 
 // ptrToString return (nil) if the ptr is nil or the value
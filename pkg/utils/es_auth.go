@@ -0,0 +1,79 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/base64"
+	v1 "k8s.io/api/core/v1"
+	"net/http"
+	"strings"
+)
+
+// Keys of the connection secret holding credentials used instead of the username and password of the uri
+const (
+	APIKeySecretKey      = "apiKey"
+	BearerTokenSecretKey = "bearerToken"
+	UsernameSecretKey    = "username"
+	PasswordSecretKey    = "password"
+)
+
+// ReadSecretCredentials sets the api key, the bearer token and the username and password of the connection secret, if
+// any. The username and password of the secret override the ones of the uri, like for Elastic Cloud ID uris
+func ReadSecretCredentials(esConfig *EsConfig, secret *v1.Secret) {
+	esConfig.APIKey = strings.TrimSpace(string(secret.Data[APIKeySecretKey]))
+	esConfig.BearerToken = strings.TrimSpace(string(secret.Data[BearerTokenSecretKey]))
+	if username := strings.TrimSpace(string(secret.Data[UsernameSecretKey])); username != "" {
+		esConfig.Username, esConfig.Password = username, strings.TrimRight(string(secret.Data[PasswordSecretKey]), "\r\n")
+	}
+}
+
+// GetAuthorization returns the Authorization header of the api key or the bearer token, or an empty string to use
+// basic authentication with the username and password of the uri. An api key can be given as id:key or encoded
+func (conf *EsConfig) GetAuthorization() string {
+	if conf.APIKey != "" {
+		apiKey := conf.APIKey
+		if strings.Contains(apiKey, ":") {
+			apiKey = base64.StdEncoding.EncodeToString([]byte(apiKey))
+		}
+		return "ApiKey " + apiKey
+	}
+	if conf.BearerToken != "" {
+		return "Bearer " + conf.BearerToken
+	}
+	return ""
+}
+
+// authTransport sets the Authorization header of api key and bearer token authentications
+type authTransport struct {
+	next          http.RoundTripper
+	authorization string
+}
+
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", t.authorization)
+	return t.next.RoundTrip(req)
+}
+
+func (t *authTransport) CloseIdleConnections() {
+	closeIdleConnections(t.next)
+}
+
+// closeIdleConnections closes idle connections of a transport, when it supports it
+func closeIdleConnections(transport http.RoundTripper) {
+	if closer, ok := transport.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
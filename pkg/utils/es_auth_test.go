@@ -0,0 +1,68 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestEsConfig_GetAuthorization(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("", (&EsConfig{Username: "elastic", Password: "pass"}).GetAuthorization())
+	assert.Equal("ApiKey VnVhQ2ZHY0JDZGJrUW0tZTVhT3g6dWkybHAyYXhUTm1zeWFrdzl0dk5udw==",
+		(&EsConfig{APIKey: "VuaCfGcBCdbkQm-e5aOx:ui2lp2axTNmsyakw9tvNnw"}).GetAuthorization())
+	assert.Equal("ApiKey VnVhQ2ZHY0JDZGJrUW0tZTVhT3g6dWkybHAyYXhUTm1zeWFrdzl0dk5udw==",
+		(&EsConfig{APIKey: "VnVhQ2ZHY0JDZGJrUW0tZTVhT3g6dWkybHAyYXhUTm1zeWFrdzl0dk5udw=="}).GetAuthorization())
+	assert.Equal("Bearer AAEAAWVsYXN0aWM", (&EsConfig{BearerToken: "AAEAAWVsYXN0aWM"}).GetAuthorization())
+	assert.Equal("ApiKey id-key", (&EsConfig{APIKey: "id-key", BearerToken: "token"}).GetAuthorization(), "api key wins")
+}
+
+func TestBuildEsConfigFromExistingSecret_Credentials(t *testing.T) {
+	assert := assert.New(t)
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		fmt.Fprint(w, `{"version":{"number":"8.4.0"}}`)
+	}))
+	defer server.Close()
+	uri := strings.Replace(server.URL, "http://", "http://elastic:pass@", 1)
+
+	esConfig, err := BuildEsConfigFromExistingSecret(&v1.Secret{Data: map[string][]byte{"uri": []byte(uri)}}, "uri", nil)
+	assert.Nil(err)
+	assert.Equal(8, esConfig.Version)
+	assert.True(strings.HasPrefix(authorization, "Basic "), "uri credentials are used by default")
+
+	esConfig, err = BuildEsConfigFromExistingSecret(&v1.Secret{Data: map[string][]byte{"uri": []byte(uri), BearerTokenSecretKey: []byte("AAEAAWVsYXN0aWM\n")}}, "uri", nil)
+	assert.Nil(err)
+	assert.Equal("AAEAAWVsYXN0aWM", esConfig.BearerToken)
+	assert.Equal("Bearer AAEAAWVsYXN0aWM", authorization, "version probe uses the bearer token")
+
+	esConfig, err = BuildEsConfigFromExistingSecret(&v1.Secret{Data: map[string][]byte{"uri": []byte(server.URL), APIKeySecretKey: []byte("id:key")}}, "uri", nil)
+	assert.Nil(err)
+	assert.Equal("id:key", esConfig.APIKey)
+	assert.Equal("ApiKey aWQ6a2V5", authorization, "version probe uses the api key")
+
+	esConfig, err = BuildEsConfigFromExistingSecret(&v1.Secret{Data: map[string][]byte{"uri": []byte(uri), UsernameSecretKey: []byte("admin"), PasswordSecretKey: []byte("secret\n")}}, "uri", nil)
+	assert.Nil(err)
+	assert.Equal("admin", esConfig.Username, "secret username overrides the one of the uri")
+	assert.Equal("secret", esConfig.Password)
+	assert.Equal("Basic YWRtaW46c2VjcmV0", authorization)
+}
@@ -0,0 +1,189 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"github.com/go-logr/logr"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// EsBackend is an Elasticsearch implementation serving the clusters of a distribution whose version is in a range
+type EsBackend struct {
+	// Name of the backend, logged and shown in the status of the objects it serves
+	Name string
+	// Distribution of the clusters, DistributionElasticsearch or DistributionOpenSearch
+	Distribution string
+	// Versions served, as space separated comparisons like ">=8.0.0 <9.0.0"
+	Versions string
+	// New returns a new implementation, connected by its NewClient method
+	New func() Elasticsearch
+}
+
+var (
+	esBackendsMutex sync.RWMutex
+	esBackends      = []EsBackend{
+		{Name: "elasticsearch6", Distribution: DistributionElasticsearch, Versions: ">=6.0.0 <7.0.0", New: func() Elasticsearch { return &Elasticsearch6{} }},
+		{Name: "elasticsearch7", Distribution: DistributionElasticsearch, Versions: ">=7.0.0 <8.0.0", New: func() Elasticsearch { return &Elasticsearch7{} }},
+		{Name: "elasticsearch8", Distribution: DistributionElasticsearch, Versions: ">=8.0.0 <9.0.0", New: func() Elasticsearch { return &Elasticsearch8{} }},
+		{Name: "elasticsearch9", Distribution: DistributionElasticsearch, Versions: ">=9.0.0 <10.0.0", New: func() Elasticsearch { return &Elasticsearch9{} }},
+		{Name: "opensearch", Distribution: DistributionOpenSearch, Versions: ">=1.0.0 <3.0.0", New: func() Elasticsearch { return &OpenSearch{} }},
+	}
+)
+
+// RegisterEsBackend adds a backend, which takes precedence over the backends registered before it for the same
+// distribution and versions
+func RegisterEsBackend(backend EsBackend) error {
+	if backend.Name == "" || backend.New == nil {
+		return fmt.Errorf("backend %q should have a name and a constructor", backend.Name)
+	}
+	if _, err := parseVersionRange(backend.Versions); err != nil {
+		return fmt.Errorf("backend %v has invalid versions: %v", backend.Name, err)
+	}
+
+	esBackendsMutex.Lock()
+	defer esBackendsMutex.Unlock()
+	esBackends = append([]EsBackend{backend}, esBackends...)
+	return nil
+}
+
+// GetEsBackend returns the backend serving the clusters of the distribution and the version number
+func GetEsBackend(distribution string, versionNumber string) (*EsBackend, error) {
+	version, err := ParseVersionNumber(versionNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	esBackendsMutex.RLock()
+	defer esBackendsMutex.RUnlock()
+	for _, backend := range esBackends {
+		if backend.Distribution != distribution {
+			continue
+		}
+		if versionRange, err := parseVersionRange(backend.Versions); err == nil && versionRange.contains(version) {
+			found := backend
+			return &found, nil
+		}
+	}
+	return nil, fmt.Errorf("%v version %v not supported", distribution, versionNumber)
+}
+
+// NewElasticsearch returns the implementation of the backend serving the cluster of the config, with its client
+func NewElasticsearch(config *EsConfig, log logr.Logger) (Elasticsearch, error) {
+	backend, err := GetEsBackend(config.Distribution, config.VersionNumber)
+	if err != nil {
+		return nil, err
+	}
+	elasticsearch := backend.New()
+	if err := elasticsearch.NewClient(config, log.WithValues("backend", backend.Name)); err != nil {
+		return nil, err
+	}
+	return elasticsearch, nil
+}
+
+// VersionNumber is a major.minor.patch version number
+type VersionNumber [3]int
+
+// ParseVersionNumber parses a version number like 8.4.2, missing minor and patch numbers being 0. Pre-release and build
+// suffixes, like -SNAPSHOT, are ignored
+func ParseVersionNumber(versionNumber string) (VersionNumber, error) {
+	var version VersionNumber
+	number := versionNumber
+	if index := strings.IndexAny(number, "-+"); index >= 0 {
+		number = number[:index]
+	}
+	parts := strings.Split(number, ".")
+	if len(parts) > len(version) {
+		return version, fmt.Errorf("invalid version number %q", versionNumber)
+	}
+	for i, part := range parts {
+		value, err := strconv.Atoi(part)
+		if err != nil || value < 0 {
+			return version, fmt.Errorf("invalid version number %q", versionNumber)
+		}
+		version[i] = value
+	}
+	return version, nil
+}
+
+// Compare returns -1, 0 or 1 when the version is lower than, equal to or greater than other
+func (v VersionNumber) Compare(other VersionNumber) int {
+	for i := range v {
+		if v[i] < other[i] {
+			return -1
+		} else if v[i] > other[i] {
+			return 1
+		}
+	}
+	return 0
+}
+
+type versionComparison struct {
+	operator string
+	version  VersionNumber
+}
+
+type versionRange []versionComparison
+
+// parseVersionRange parses space separated comparisons of a version with the operators >=, >, <=, < and =
+func parseVersionRange(versions string) (versionRange, error) {
+	var result versionRange
+	for _, comparison := range strings.Fields(versions) {
+		operator := comparison[:len(comparison)-len(strings.TrimLeft(comparison, "<>="))]
+		if !ContainsString([]string{">=", ">", "<=", "<", "=", ""}, operator) {
+			return nil, fmt.Errorf("invalid operator in %q", comparison)
+		}
+		version, err := ParseVersionNumber(comparison[len(operator):])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, versionComparison{operator: operator, version: version})
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("empty version range")
+	}
+	return result, nil
+}
+
+func (r versionRange) contains(version VersionNumber) bool {
+	for _, comparison := range r {
+		compare := version.Compare(comparison.version)
+		switch comparison.operator {
+		case ">=":
+			if compare < 0 {
+				return false
+			}
+		case ">":
+			if compare <= 0 {
+				return false
+			}
+		case "<=":
+			if compare > 0 {
+				return false
+			}
+		case "<":
+			if compare >= 0 {
+				return false
+			}
+		default:
+			if compare != 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"testing"
+)
+
+func TestParseVersionNumber(t *testing.T) {
+	assert := assert.New(t)
+	scenarios := []struct {
+		versionNumber string
+		expectVersion VersionNumber
+		error         bool
+	}{
+		{versionNumber: "8.4.2", expectVersion: VersionNumber{8, 4, 2}},
+		{versionNumber: "10.12.1", expectVersion: VersionNumber{10, 12, 1}},
+		{versionNumber: "9.0.0-SNAPSHOT", expectVersion: VersionNumber{9, 0, 0}},
+		{versionNumber: "8.4", expectVersion: VersionNumber{8, 4, 0}},
+		{versionNumber: "8.4.2.1", error: true},
+		{versionNumber: "v8", error: true},
+		{versionNumber: "", error: true},
+	}
+
+	for _, s := range scenarios {
+		version, err := ParseVersionNumber(s.versionNumber)
+		if s.error {
+			assert.NotNil(err, s.versionNumber)
+		} else {
+			assert.Nil(err, s.versionNumber)
+			assert.Equal(s.expectVersion, version)
+		}
+	}
+	assert.Equal(-1, VersionNumber{8, 4, 2}.Compare(VersionNumber{8, 10, 0}))
+	assert.Equal(1, VersionNumber{10, 0, 0}.Compare(VersionNumber{9, 9, 9}))
+	assert.Equal(0, VersionNumber{7, 17, 3}.Compare(VersionNumber{7, 17, 3}))
+}
+
+func TestGetEsBackend(t *testing.T) {
+	assert := assert.New(t)
+	scenarios := []struct {
+		distribution  string
+		versionNumber string
+		expectBackend string
+	}{
+		{distribution: DistributionElasticsearch, versionNumber: "6.8.23", expectBackend: "elasticsearch6"},
+		{distribution: DistributionElasticsearch, versionNumber: "7.17.3", expectBackend: "elasticsearch7"},
+		{distribution: DistributionElasticsearch, versionNumber: "8.11.0", expectBackend: "elasticsearch8"},
+		{distribution: DistributionElasticsearch, versionNumber: "9.0.0", expectBackend: "elasticsearch9"},
+		{distribution: DistributionOpenSearch, versionNumber: "1.3.14", expectBackend: "opensearch"},
+		{distribution: DistributionOpenSearch, versionNumber: "2.11.1", expectBackend: "opensearch"},
+		{distribution: DistributionElasticsearch, versionNumber: "5.6.16"},
+		{distribution: DistributionElasticsearch, versionNumber: "10.0.0"},
+		{distribution: DistributionElasticsearch, versionNumber: "1.7.6"},
+		{distribution: DistributionOpenSearch, versionNumber: "7.10.2"},
+	}
+
+	for _, s := range scenarios {
+		backend, err := GetEsBackend(s.distribution, s.versionNumber)
+		if s.expectBackend == "" {
+			assert.NotNil(err, s.versionNumber)
+		} else {
+			assert.Nil(err, s.versionNumber)
+			assert.Equal(s.expectBackend, backend.Name)
+		}
+	}
+}
+
+func TestRegisterEsBackend(t *testing.T) {
+	assert := assert.New(t)
+	backends := esBackends
+	defer func() { esBackends = backends }()
+
+	assert.NotNil(RegisterEsBackend(EsBackend{Name: "invalid", Distribution: DistributionElasticsearch, Versions: "~8", New: func() Elasticsearch { return &Elasticsearch8{} }}))
+	assert.NotNil(RegisterEsBackend(EsBackend{Name: "no-constructor", Distribution: DistributionElasticsearch, Versions: ">=8.0.0"}))
+
+	//a registered backend takes precedence over the default ones
+	assert.Nil(RegisterEsBackend(EsBackend{Name: "elasticsearch8-patched", Distribution: DistributionElasticsearch, Versions: ">=8.4.0 <=8.4.3", New: func() Elasticsearch { return &Elasticsearch8{} }}))
+	backend, err := GetEsBackend(DistributionElasticsearch, "8.4.2")
+	assert.Nil(err)
+	assert.Equal("elasticsearch8-patched", backend.Name)
+	backend, err = GetEsBackend(DistributionElasticsearch, "8.5.0")
+	assert.Nil(err)
+	assert.Equal("elasticsearch8", backend.Name)
+}
+
+func TestNewElasticsearch(t *testing.T) {
+	assert := assert.New(t)
+	log := zap.New(zap.UseDevMode(true))
+
+	elasticsearch, err := NewElasticsearch(&EsConfig{Scheme: "http", Host: "localhost", Port: "9200", Distribution: DistributionElasticsearch, VersionNumber: "9.1.0"}, log)
+	assert.Nil(err)
+	assert.IsType(&Elasticsearch9{}, elasticsearch)
+
+	elasticsearch, err = NewElasticsearch(&EsConfig{Scheme: "http", Host: "localhost", Port: "9200", Distribution: DistributionOpenSearch, VersionNumber: "2.11.1"}, log)
+	assert.Nil(err)
+	assert.IsType(&OpenSearch{}, elasticsearch)
+
+	_, err = NewElasticsearch(&EsConfig{Scheme: "http", Host: "localhost", Port: "9200", Distribution: DistributionElasticsearch, VersionNumber: "5.6.16"}, log)
+	assert.NotNil(err)
+}
@@ -0,0 +1,1408 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"github.com/go-logr/logr"
+	funk "github.com/thoas/go-funk"
+	"github.com/tidwall/gjson"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// mappingMode is how the mapping of the models is sent to a cluster requested with the elasticsearch 7 client
+type mappingMode int
+
+const (
+	// mappingIncludeTypeName sends include_type_name with the models whose mapping has a type, elasticsearch 7
+	mappingIncludeTypeName mappingMode = iota
+	// mappingTypeless sends the models as they are, opensearch having no mapping type
+	mappingTypeless
+	// mappingTyped adds the DefaultMappingType type to the typeless models, elasticsearch 6
+	mappingTyped
+)
+
+// esClient7 manages indices, templates, ingest pipelines and snapshot repositories with the requests of the
+// elasticsearch 7 client. It is embedded by Elasticsearch7 and OpenSearch, and by Elasticsearch6, the
+// requests used being the same in elasticsearch 6
+type esClient7 struct {
+	Config  *EsConfig
+	Client  esapi.Transport
+	log     logr.Logger
+	mapping mappingMode
+}
+
+// newClient creates the elasticsearch 7 client of the configuration, or gets the client shared with the other
+// reconciliations of the connection
+func (es *esClient7) newClient(config *EsConfig, log logr.Logger, key string, product string) error {
+	transport := config.Transport
+	if transport == nil {
+		var err error
+		if transport, err = newTransport(config); err != nil {
+			log.Error(err, "error while creating "+product+" transport", "host", config)
+			return err
+		}
+	}
+	conf := elasticsearch.Config{
+		Addresses: config.GetAddresses(),
+		Username:  config.Username,
+		Password:  config.Password,
+		Transport: transport,
+	}
+	if config.Sniffing != nil {
+		conf.DiscoverNodesOnStart = true
+	}
+	client, err := config.sharedClient(key, func() (interface{}, error) {
+		return elasticsearch.NewClient(conf)
+	})
+	log.Info(product+" client created successfully", "host", config)
+	if err != nil {
+		log.Error(err, "error while creating "+product+" client", "host", config)
+		return err
+	}
+
+	es.log = log
+	es.Config = config
+	es.Client = client.(*elasticsearch.Client)
+	return nil
+}
+
+func (es *esClient7) PingES(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, es.Config.GetRequestTimeout())
+	defer cancel()
+
+	info, err := esapi.InfoRequest{}.Do(ctx, es.Client)
+	if err != nil {
+		es.log.Error(err, "error while connecting to elasticsearch", "host", es.Config)
+		return err
+	}
+	defer info.Body.Close()
+	es.log.Info("connected successfully to elasticsearch")
+	return nil
+}
+
+func (es *esClient7) existsIndex(ctx context.Context, indexName string) *bool {
+	response, err := esapi.IndicesExistsRequest{Index: []string{indexName}}.Do(ctx, es.Client)
+	if err != nil {
+		es.log.Error(err, "error while checking index exists", "indexName", indexName)
+		return nil
+	}
+	defer response.Body.Close()
+	is2xxStatusCode := is2xxStatusCode(response.StatusCode)
+	return &is2xxStatusCode
+}
+
+func (es *esClient7) existsTemplate(ctx context.Context, templateName string) *bool {
+	response, err := esapi.IndicesExistsTemplateRequest{Name: []string{templateName}}.Do(ctx, es.Client)
+	if err != nil {
+		es.log.Error(err, "error while checking template exists", "templateName", templateName)
+		return nil
+	}
+	defer response.Body.Close()
+	is2xxStatusCode := is2xxStatusCode(response.StatusCode)
+	return &is2xxStatusCode
+}
+
+func (es *esClient7) getNumberOfReplicasAndShards(ctx context.Context, indexName string) (*int32, *int32) {
+	response, err := esapi.IndicesGetSettingsRequest{Index: []string{indexName}}.Do(ctx, es.Client)
+	if err != nil {
+		es.log.Error(err, "error while getting settings", "indexName", indexName)
+		return nil, nil
+	}
+	defer response.Body.Close()
+	settings, err2 := StreamToString(response.Body)
+	if err2 != nil {
+		es.log.Error(err2, "error while converting stream to string to get settings", "indexName", indexName)
+		return nil, nil
+	}
+	replicas, _ := (&EsSettings{Settings: settings}).GetNumberOfReplicas(indexName)
+	shards, _ := (&EsSettings{Settings: settings}).GetNumberOfShards(indexName)
+	return replicas, shards
+}
+
+func (es *esClient7) getFlatSettings(ctx context.Context, indexName string) (map[string]interface{}, error) {
+	yes := true
+	response, err := esapi.IndicesGetSettingsRequest{Index: []string{indexName}, IncludeDefaults: &yes}.Do(ctx, es.Client)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if !is2xxStatusCode(response.StatusCode) {
+		return nil, fmt.Errorf("error while getting settings of index %v: %v", indexName, response)
+	}
+	settings, err := StreamToString(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	return (&EsSettings{Settings: settings}).GetFlatSettings(indexName)
+}
+
+func (es *esClient7) getProperties(ctx context.Context, indexName string) (*string, error) {
+	properties, _, err := es.getTypedProperties(ctx, indexName)
+	return properties, err
+}
+
+// getTypedProperties returns the properties of the index and the type of its mapping, the type being empty when the
+// mapping is typeless or the index has no mapping yet
+func (es *esClient7) getTypedProperties(ctx context.Context, indexName string) (*string, string, error) {
+	response, err := esapi.IndicesGetMappingRequest{Index: []string{indexName}, IncludeTypeName: es.includeTypeName(false)}.Do(ctx, es.Client)
+	if err != nil {
+		es.log.Error(err, "error while getting properties", "indexName", indexName)
+		return nil, "", err
+	}
+	defer response.Body.Close()
+	mappings, err2 := StreamToString(response.Body)
+	if err2 != nil {
+		es.log.Error(err2, "error while converting stream to string to get properties", "indexName", indexName)
+		return nil, "", err2
+	}
+
+	if es.mapping == mappingTyped {
+		properties, mappingType := (&EsMappings{Mappings: mappings}).GetTypedProperties(indexName)
+		return properties, mappingType, nil
+	}
+	return (&EsMappings{Mappings: mappings}).GetProperties(indexName), "", nil
+}
+
+func (es *esClient7) updateIndexReplicas(ctx context.Context, indexName string, numReplicas int32) (int, string, error) {
+	settings := strings.NewReader(fmt.Sprintf(`{"index" : {"number_of_replicas" : %v}}`, numReplicas))
+	response, err := esapi.IndicesPutSettingsRequest{Index: []string{indexName}, Body: settings}.Do(ctx, es.Client)
+	if err != nil {
+		es.log.Error(err, "error while updating number_of_replicas", "indexName", indexName, "replicas", numReplicas)
+		return -1, "", err
+	}
+	defer response.Body.Close()
+	statusCode := response.StatusCode
+	responseStr := response.String()
+	return statusCode, responseStr, nil
+}
+
+func (es *esClient7) updateIndexMapping(ctx context.Context, indexName string, mappingType string, properties string) (int, string, error) {
+	mappingReader := strings.NewReader(properties)
+	request := esapi.IndicesPutMappingRequest{Index: []string{indexName}, DocumentType: mappingType, Body: mappingReader, IncludeTypeName: es.includeTypeName(false)}
+	response, err := request.Do(ctx, es.Client)
+	if err != nil {
+		es.log.Error(err, "error while updating mapping", "indexName", indexName, "mapping", properties)
+		return -1, "", err
+	}
+	defer response.Body.Close()
+	statusCode := response.StatusCode
+	responseStr := response.String()
+	return statusCode, responseStr, nil
+}
+
+func (es *esClient7) CreateOrUpdateIndex(ctx context.Context, indexName string, model string, migration *EsMigration) (*EsStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, es.Config.GetRequestTimeout())
+	defer cancel()
+
+	exists := es.existsIndex(ctx, indexName)
+	if exists == nil {
+		errMsg := "error while checking index exists"
+		es.log.Error(nil, errMsg, "indexName", indexName)
+		return &EsStatus{Status: StatusError, Message: errMsg}, errors.New(errMsg)
+	} else if *exists {
+		es.log.Info("index already exists", "indexName", indexName)
+
+		if migration.isInProgress() {
+			return es.followIndexMigration(ctx, indexName, model, migration)
+		}
+
+		backingIndex := es.getBackingIndex(ctx, indexName)
+
+		// successful settings and properties updates do not stop here, so that a model change touching settings,
+		// properties and aliases is fully applied at once
+		var operations []string
+		if status, err := es.updateIndexSettings(ctx, indexName, backingIndex, model, migration); err != nil || (status != nil && status.Status != StatusCreated) {
+			return status, err
+		} else if status != nil {
+			operations = append(operations, status.Operations...)
+		}
+
+		if status, err := es.updateIndexProperties(ctx, indexName, backingIndex, model, migration); err != nil || (status != nil && status.Status != StatusCreated) {
+			return status, err
+		} else if status != nil {
+			operations = append(operations, status.Operations...)
+		}
+
+		if status, err := es.updateIndexAliases(ctx, indexName, backingIndex, model); status != nil || err != nil {
+			if err == nil {
+				status.Operations = append(operations, status.Operations...)
+			}
+			return status, err
+		}
+
+		return &EsStatus{Status: StatusCreated, HttpCodeStatus: "200", Aliases: BuildEsAliasesStatus(model, StatusCreated, ""), Operations: operations}, nil
+	}
+
+	indexModel, includeTypeName, err := es.withMapping(model)
+	if err != nil {
+		es.log.Error(err, "error while adding a type to the mapping", "indexName", indexName)
+		return &EsStatus{Status: StatusError, Message: err.Error()}, err
+	}
+	response, err := esapi.IndicesCreateRequest{Index: indexName, Body: strings.NewReader(indexModel), IncludeTypeName: includeTypeName}.Do(ctx, es.Client)
+
+	if err != nil {
+		es.log.Error(err, "error while creating index", "indexName", indexName)
+		return &EsStatus{Status: StatusError, Message: err.Error()}, err
+	}
+
+	defer response.Body.Close()
+
+	if !is2xxStatusCode(response.StatusCode) {
+		es.log.Error(nil, "error while creating index", "indexName", indexName, "http-response", response)
+		status := BuildEsStatus(response.StatusCode, response.String())
+		return status, errors.New("error while creating index")
+	}
+
+	es.log.Info("index was created successfully", "indexName", indexName)
+	status := BuildEsStatus(response.StatusCode, response.String())
+	status.Aliases = BuildEsAliasesStatus(model, status.Status, "")
+	status.Operations = []string{OperationIndexCreated}
+	return status, nil
+}
+
+func (es *esClient7) updateIndexSettings(ctx context.Context, indexName string, backingIndex string, model string, migration *EsMigration) (*EsStatus, error) {
+	oldNumReplicas, oldNumShards := es.getNumberOfReplicasAndShards(ctx, backingIndex)
+	numReplicas, err := (&EsModel{Model: model}).GetNumberOfReplicas()
+	numShards, err2 := (&EsModel{Model: model}).GetNumberOfShards()
+
+	if err != nil {
+		errMsg := fmt.Sprintf("error while getting number_of_repliacs from model %v", model)
+		es.log.Error(err, errMsg, "indexName", indexName)
+		return &EsStatus{Status: StatusError, Message: errMsg}, err
+	}
+
+	if err2 != nil {
+		errMsg := fmt.Sprintf("error while getting number_of_shards from model %v", model)
+		es.log.Error(err2, errMsg, "indexName", indexName)
+		return &EsStatus{Status: StatusError, Message: errMsg}, err2
+	}
+
+	isShardsUpdated := oldNumShards == nil || *oldNumShards != *numShards
+	if isShardsUpdated {
+		if operation := GetResizeOperation(oldNumShards, numShards); operation != "" && migration != nil {
+			es.log.Info("index already exists and resizing number_of_shards", "indexName", indexName, "operation", operation, "from", ptrToString(oldNumShards), "to", ptrToString(numShards))
+			return es.startIndexResize(ctx, indexName, backingIndex, operation, *numShards, migration)
+		}
+		errMsg := fmt.Sprintf("you cannot update number_of_shards from %q to %q on existing index %v", ptrToString(oldNumShards), ptrToString(numShards), indexName)
+		es.log.Error(nil, errMsg, "indexName", indexName)
+		return &EsStatus{Status: StatusError, Message: errMsg}, errors.New(errMsg)
+	}
+
+	var status *EsStatus
+	isReplicasUpdated := (oldNumReplicas == nil || *oldNumReplicas != *numReplicas) && err == nil && err2 == nil
+	if isReplicasUpdated {
+		es.log.Info("index already exists and updating number_of_replicas", "indexName", indexName, "from", ptrToString(oldNumReplicas), "to", ptrToString(numReplicas))
+		statusCode, responseStr, err := es.updateIndexReplicas(ctx, backingIndex, *numReplicas)
+		if err != nil {
+			errMsg := fmt.Sprintf("error while updating number_of_replicas from %q to %q", ptrToString(oldNumReplicas), ptrToString(numReplicas))
+			es.log.Error(err, errMsg, "indexName", indexName)
+			return &EsStatus{Status: StatusError, Message: errMsg}, err
+		} else if !is2xxStatusCode(statusCode) {
+			status := BuildEsStatus(statusCode, responseStr)
+			errMsg := "error while updating index number_of_replicas"
+			es.log.Error(nil, errMsg, "indexName", indexName, "http-response", responseStr)
+			return status, errors.New(errMsg)
+		}
+		status = BuildEsStatus(statusCode, responseStr)
+		status.Operations = []string{OperationReplicasUpdated}
+	}
+
+	if settingsStatus, err := es.applyIndexSettings(ctx, indexName, backingIndex, model, migration); settingsStatus != nil || err != nil {
+		if err == nil && status != nil {
+			settingsStatus.Operations = append(status.Operations, settingsStatus.Operations...)
+		}
+		return settingsStatus, err
+	}
+
+	return status, nil
+}
+
+// applyIndexSettings updates the model settings, other than number_of_shards and number_of_replicas, that differ from the
+// index settings. Static settings are applied by closing and reopening the index, only when the migration allows it
+func (es *esClient7) applyIndexSettings(ctx context.Context, indexName string, backingIndex string, model string, migration *EsMigration) (*EsStatus, error) {
+	oldSettings, err := es.getFlatSettings(ctx, backingIndex)
+	if err != nil {
+		errMsg := fmt.Sprintf("error while getting settings from index %v", indexName)
+		es.log.Error(err, errMsg, "indexName", indexName)
+		return &EsStatus{Status: StatusError, Message: errMsg}, err
+	}
+
+	newSettings, err := (&EsModel{Model: model}).GetFlatSettings()
+	if err != nil {
+		errMsg := fmt.Sprintf("error while getting settings from model %v", model)
+		es.log.Error(err, errMsg, "indexName", indexName)
+		return &EsStatus{Status: StatusError, Message: errMsg}, err
+	}
+
+	settings := DiffSettings(oldSettings, newSettings, []string{"index.number_of_shards", "index.number_of_replicas"})
+	if len(settings) == 0 {
+		return nil, nil
+	}
+
+	body, err := json.Marshal(settings)
+	if err != nil {
+		errMsg := fmt.Sprintf("error while building settings %v", settings)
+		es.log.Error(err, errMsg, "indexName", indexName)
+		return &EsStatus{Status: StatusError, Message: errMsg}, err
+	}
+
+	if staticSettings := GetStaticSettings(settings); len(staticSettings) > 0 {
+		if !migration.isCloseAndReopenAllowed() {
+			errMsg := fmt.Sprintf("static settings %v can only be updated on a closed index, set migration.staticSettingsUpdate to %v to close and reopen index %v", staticSettings, StaticSettingsUpdateCloseAndReopen, indexName)
+			es.log.Error(nil, errMsg, "indexName", indexName)
+			return &EsStatus{Status: StatusError, Message: errMsg}, errors.New(errMsg)
+		}
+		es.log.Info("index already exists and updating static settings, index will be closed and reopened", "indexName", indexName, "settings", settings)
+		status, err := es.updateIndexStaticSettings(ctx, indexName, backingIndex, string(body))
+		if err == nil {
+			status.Operations = []string{OperationSettingsUpdated}
+		}
+		return status, err
+	}
+
+	es.log.Info("index already exists and updating settings", "indexName", indexName, "settings", settings)
+	response, err := esapi.IndicesPutSettingsRequest{Index: []string{backingIndex}, Body: strings.NewReader(string(body))}.Do(ctx, es.Client)
+	if err != nil {
+		errMsg := fmt.Sprintf("error while updating settings %v", settings)
+		es.log.Error(err, errMsg, "indexName", indexName)
+		return &EsStatus{Status: StatusError, Message: errMsg}, err
+	}
+	defer response.Body.Close()
+
+	status := BuildEsStatus(response.StatusCode, response.String())
+	if !is2xxStatusCode(response.StatusCode) {
+		errMsg := "error while updating index settings"
+		es.log.Error(nil, errMsg, "indexName", indexName, "http-response", response)
+		return status, errors.New(errMsg)
+	}
+	status.Operations = []string{OperationSettingsUpdated}
+	return status, nil
+}
+
+// updateIndexStaticSettings closes the index, updates its settings and reopens it. The index is reopened even if
+// the settings update fails
+func (es *esClient7) updateIndexStaticSettings(ctx context.Context, indexName string, backingIndex string, settings string) (*EsStatus, error) {
+	closeResponse, err := esapi.IndicesCloseRequest{Index: []string{backingIndex}}.Do(ctx, es.Client)
+	if err != nil {
+		errMsg := "error while closing index to update static settings"
+		es.log.Error(err, errMsg, "indexName", indexName)
+		return &EsStatus{Status: StatusError, Message: errMsg}, err
+	}
+	defer closeResponse.Body.Close()
+	if !is2xxStatusCode(closeResponse.StatusCode) {
+		errMsg := "error while closing index to update static settings"
+		es.log.Error(nil, errMsg, "indexName", indexName, "http-response", closeResponse)
+		return BuildEsStatus(closeResponse.StatusCode, closeResponse.String()), errors.New(errMsg)
+	}
+
+	var status *EsStatus
+	var statusErr error
+	response, err := esapi.IndicesPutSettingsRequest{Index: []string{backingIndex}, Body: strings.NewReader(settings)}.Do(ctx, es.Client)
+	if err != nil {
+		errMsg := "error while updating static settings"
+		es.log.Error(err, errMsg, "indexName", indexName)
+		status, statusErr = &EsStatus{Status: StatusError, Message: errMsg}, err
+	} else {
+		defer response.Body.Close()
+		status = BuildEsStatus(response.StatusCode, response.String())
+		if !is2xxStatusCode(response.StatusCode) {
+			errMsg := "error while updating index static settings"
+			es.log.Error(nil, errMsg, "indexName", indexName, "http-response", response)
+			statusErr = errors.New(errMsg)
+		}
+	}
+
+	openResponse, err := esapi.IndicesOpenRequest{Index: []string{backingIndex}}.Do(ctx, es.Client)
+	if err != nil {
+		errMsg := fmt.Sprintf("error while reopening index %v, index is closed", backingIndex)
+		es.log.Error(err, errMsg, "indexName", indexName)
+		return &EsStatus{Status: StatusError, Message: errMsg}, err
+	}
+	defer openResponse.Body.Close()
+	if !is2xxStatusCode(openResponse.StatusCode) {
+		errMsg := fmt.Sprintf("error while reopening index %v, index is closed", backingIndex)
+		es.log.Error(nil, errMsg, "indexName", indexName, "http-response", openResponse)
+		return BuildEsStatus(openResponse.StatusCode, openResponse.String()), errors.New(errMsg)
+	}
+
+	if statusErr == nil {
+		es.log.Info("index static settings were updated successfully", "indexName", indexName)
+	}
+	return status, statusErr
+}
+
+func (es *esClient7) updateIndexProperties(ctx context.Context, indexName string, backingIndex string, model string, migration *EsMigration) (*EsStatus, error) {
+	oldProperties, mappingType, err := es.getTypedProperties(ctx, backingIndex)
+	properties := (&EsModel{Model: model}).GetProperties()
+
+	if err != nil {
+		errMsg := fmt.Sprintf("error while getting old properties from index %v", indexName)
+		es.log.Error(err, errMsg, "indexName", indexName)
+		return &EsStatus{Status: StatusError, Message: errMsg}, err
+	}
+
+	arePropertiesUpdates := oldProperties != nil && properties != nil && !CompareJson(*oldProperties, *properties)
+	if arePropertiesUpdates {
+		es.log.Info("index already exists and updating properties", "indexName", indexName, "from", *oldProperties, "to", *properties)
+
+		isValid := IsValidUpdateProperties(*oldProperties, *properties)
+		if migration.isEnabled() && (!isValid || IsPropertiesTypeUpdated(*oldProperties, *properties)) {
+			es.log.Info("properties cannot be updated in place, index will be migrated", "indexName", indexName, "backingIndex", backingIndex)
+			return es.startIndexMigration(ctx, indexName, backingIndex, model, migration)
+		}
+
+		if !isValid {
+			errMsg := fmt.Sprintf("you cannot delete properties, error while updating properties from %v to %v", *oldProperties, *properties)
+			es.log.Error(nil, errMsg, "indexName", indexName)
+			return &EsStatus{Status: StatusError, Message: errMsg}, errors.New(errMsg)
+		}
+
+		if es.mapping == mappingTyped && mappingType == "" {
+			mappingType = (&EsModel{Model: model}).GetMappingType(DefaultMappingType)
+		}
+		statusCode, responseStr, err := es.updateIndexMapping(ctx, backingIndex, mappingType, *properties)
+		if err != nil {
+			errMsg := fmt.Sprintf("error while updating properties from %v to %v", *oldProperties, *properties)
+			es.log.Error(err, errMsg, "indexName", indexName)
+			return &EsStatus{Status: StatusError, Message: errMsg}, err
+		} else if !is2xxStatusCode(statusCode) {
+			status := BuildEsStatus(statusCode, responseStr)
+			errMsg := "error while updating index properties"
+			es.log.Error(nil, errMsg, "indexName", indexName, "http-response", responseStr)
+			return status, errors.New(errMsg)
+		} else {
+			status := BuildEsStatus(statusCode, responseStr)
+			status.Operations = []string{OperationMappingUpdated}
+			return status, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// updateIndexAliases adds, updates and removes aliases of the backing index with a single atomic call, so that they match
+// the model aliases. The indexName alias created by a migration is left untouched
+func (es *esClient7) updateIndexAliases(ctx context.Context, indexName string, backingIndex string, model string) (*EsStatus, error) {
+	liveAliases, err := es.getAliases(ctx, backingIndex)
+	if err != nil {
+		errMsg := fmt.Sprintf("error while getting aliases from index %v", indexName)
+		es.log.Error(err, errMsg, "indexName", indexName)
+		return &EsStatus{Status: StatusError, Message: errMsg, Aliases: BuildEsAliasesStatus(model, StatusError, errMsg)}, err
+	}
+
+	var ignored []string
+	if backingIndex != indexName {
+		ignored = append(ignored, indexName)
+	}
+	actions, updates, err := BuildUpdateAliasesActions(backingIndex, liveAliases, (&EsModel{Model: model}).GetAliases(), ignored)
+	if err != nil {
+		errMsg := fmt.Sprintf("error while building aliases update: %v", err)
+		es.log.Error(err, errMsg, "indexName", indexName)
+		return &EsStatus{Status: StatusError, Message: errMsg, Aliases: BuildEsAliasesStatus(model, StatusError, errMsg)}, err
+	}
+	if actions == "" {
+		return nil, nil
+	}
+
+	es.log.Info("index already exists and updating aliases", "indexName", indexName, "aliases", updates)
+	response, err := esapi.IndicesUpdateAliasesRequest{Body: strings.NewReader(actions)}.Do(ctx, es.Client)
+	if err != nil {
+		errMsg := "error while updating aliases"
+		es.log.Error(err, errMsg, "indexName", indexName, "actions", actions)
+		return &EsStatus{Status: StatusError, Message: errMsg, Aliases: BuildEsAliasesStatus(model, StatusError, err.Error())}, err
+	}
+	defer response.Body.Close()
+
+	status := BuildEsStatus(response.StatusCode, response.String())
+	if !is2xxStatusCode(response.StatusCode) {
+		status.Aliases = BuildEsAliasesStatus(model, status.Status, status.Message)
+		errMsg := "error while updating index aliases"
+		es.log.Error(nil, errMsg, "indexName", indexName, "http-response", response)
+		return status, errors.New(errMsg)
+	}
+	status.Aliases = BuildEsAliasesStatus(model, status.Status, "")
+	status.Operations = []string{OperationAliasesUpdated}
+	return status, nil
+}
+
+// GetIndexDrift compares the index with the model and returns what differs: missing index, settings, missing or
+// updated properties and aliases
+func (es *esClient7) GetIndexDrift(ctx context.Context, indexName string, model string) (*EsDrift, error) {
+	ctx, cancel := context.WithTimeout(ctx, es.Config.GetRequestTimeout())
+	defer cancel()
+
+	exists := es.existsIndex(ctx, indexName)
+	if exists == nil {
+		return nil, errors.New("error while checking index exists")
+	} else if !*exists {
+		return &EsDrift{Reasons: []string{fmt.Sprintf("index %v does not exist", indexName)}}, nil
+	}
+
+	return es.getIndexDrift(ctx, indexName, es.getBackingIndex(ctx, indexName), model)
+}
+
+func (es *esClient7) getIndexDrift(ctx context.Context, indexName string, backingIndex string, model string) (*EsDrift, error) {
+	drift := &EsDrift{}
+	oldSettings, err := es.getFlatSettings(ctx, backingIndex)
+	if err != nil {
+		return nil, err
+	}
+	newSettings, err := (&EsModel{Model: model}).GetFlatSettings()
+	if err != nil {
+		return nil, err
+	}
+	if settings := DiffSettings(oldSettings, newSettings, nil); len(settings) > 0 {
+		keys := funk.Keys(settings).([]string)
+		sort.Strings(keys)
+		drift.Reasons = append(drift.Reasons, fmt.Sprintf("settings %v differ", keys))
+	}
+
+	oldProperties, err := es.getProperties(ctx, backingIndex)
+	if err != nil {
+		return nil, err
+	}
+	if properties := (&EsModel{Model: model}).GetProperties(); properties != nil {
+		liveProperties := `{"properties":{}}`
+		if oldProperties != nil {
+			liveProperties = *oldProperties
+		}
+		if fields := GetMissingOrUpdatedProperties(liveProperties, *properties); len(fields) > 0 {
+			drift.Reasons = append(drift.Reasons, fmt.Sprintf("properties %v are missing or have another type", fields))
+		}
+	}
+
+	liveAliases, err := es.getAliases(ctx, backingIndex)
+	if err != nil {
+		return nil, err
+	}
+	var ignored []string
+	if backingIndex != indexName {
+		ignored = append(ignored, indexName)
+	}
+	if _, updates, err := BuildUpdateAliasesActions(backingIndex, liveAliases, (&EsModel{Model: model}).GetAliases(), ignored); err != nil {
+		return nil, err
+	} else if len(updates) > 0 {
+		names := funk.Keys(updates).([]string)
+		sort.Strings(names)
+		drift.Reasons = append(drift.Reasons, fmt.Sprintf("aliases %v differ", names))
+	}
+
+	return drift, nil
+}
+
+func (es *esClient7) DeleteIndex(ctx context.Context, indexName string) error {
+	ctx, cancel := context.WithTimeout(ctx, es.Config.GetRequestTimeout())
+	defer cancel()
+
+	indexName = es.getBackingIndex(ctx, indexName)
+
+	exists := es.existsIndex(ctx, indexName)
+	if exists != nil && !*exists {
+		es.log.Info("index cannot be deleted because it does not exists", "indexName", indexName)
+		return nil
+	}
+
+	response, err := esapi.IndicesDeleteRequest{Index: []string{indexName}}.Do(ctx, es.Client)
+	if err != nil {
+		es.log.Error(err, "error while deleting index", "indexName", indexName)
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if !is2xxStatusCode(response.StatusCode) {
+		es.log.Error(nil, "error while deleting index", "indexName", indexName, "http-response", response)
+		return fmt.Errorf("error while deleting index %v: %v", indexName, response)
+	}
+
+	es.log.Info("index was deleted successfully", "indexName", indexName)
+	return nil
+}
+
+// CreateOrUpdateTemplate puts the template, the TemplatePut operation being reported only when the template is created
+// or differs from the model
+func (es *esClient7) CreateOrUpdateTemplate(ctx context.Context, templateName string, model string, order *int) (*EsStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, es.Config.GetRequestTimeout())
+	defer cancel()
+
+	liveTemplates, exists, err := es.getObject(ctx, "template", templateName, esapi.IndicesGetTemplateRequest{Name: []string{templateName}})
+	if err != nil {
+		errMsg := "error while checking template exists"
+		es.log.Error(err, errMsg, "templateName", templateName)
+		return &EsStatus{Status: StatusError, Message: errMsg}, errors.New(errMsg)
+	}
+
+	templateModel, includeTypeName, err := es.withMapping(model)
+	if err != nil {
+		es.log.Error(err, "error while adding a type to the mapping", "templateName", templateName)
+		return &EsStatus{Status: StatusError, Message: err.Error()}, err
+	}
+	response, err := esapi.IndicesPutTemplateRequest{Name: templateName, Body: strings.NewReader(templateModel), IncludeTypeName: includeTypeName, Order: order}.Do(ctx, es.Client)
+	if err != nil {
+		es.log.Error(err, "error while creating template", "templateName", templateName)
+		return &EsStatus{Status: StatusError, Message: err.Error()}, err
+	}
+
+	defer response.Body.Close()
+
+	if !is2xxStatusCode(response.StatusCode) {
+		es.log.Error(nil, "error while creating template", "templateName", templateName, "http-response", response)
+		status := BuildEsStatus(response.StatusCode, response.String())
+		return status, errors.New("error while creating template")
+	}
+
+	status := BuildEsStatus(response.StatusCode, response.String())
+	if !exists {
+		es.log.Info("template was created successfully", "templateName", templateName)
+		status.Operations = []string{OperationTemplatePut}
+	} else if reasons, err := GetTemplateDriftReasons(liveTemplates, templateName, model, order); err != nil || len(reasons) > 0 {
+		es.log.Info("template already exists and was updated successfully", "templateName", templateName, "reasons", reasons)
+		status.Operations = []string{OperationTemplatePut}
+	} else {
+		es.log.Info("template already exists and is up to date", "templateName", templateName)
+	}
+	return status, nil
+}
+
+// GetTemplateDrift compares the template with the model and the order, and returns what differs
+func (es *esClient7) GetTemplateDrift(ctx context.Context, templateName string, model string, order *int) (*EsDrift, error) {
+	ctx, cancel := context.WithTimeout(ctx, es.Config.GetRequestTimeout())
+	defer cancel()
+
+	response, err := esapi.IndicesGetTemplateRequest{Name: []string{templateName}}.Do(ctx, es.Client)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return &EsDrift{Reasons: []string{fmt.Sprintf("template %v does not exist", templateName)}}, nil
+	} else if !is2xxStatusCode(response.StatusCode) {
+		return nil, fmt.Errorf("error while getting template %v: %v", templateName, response)
+	}
+	templates, err := StreamToString(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	reasons, err := GetTemplateDriftReasons(templates, templateName, model, order)
+	if err != nil {
+		return nil, err
+	}
+	return &EsDrift{Reasons: reasons}, nil
+}
+
+func (es *esClient7) DeleteTemplate(ctx context.Context, templateName string) error {
+	ctx, cancel := context.WithTimeout(ctx, es.Config.GetRequestTimeout())
+	defer cancel()
+
+	exists := es.existsTemplate(ctx, templateName)
+	if exists != nil && !*exists {
+		es.log.Info("template cannot be deleted because it does not exists", "templateName", templateName)
+		return nil
+	}
+
+	response, err := esapi.IndicesDeleteTemplateRequest{Name: templateName}.Do(ctx, es.Client)
+
+	if err != nil {
+		es.log.Error(err, "error while deleting template", "templateName", templateName)
+		return err
+	}
+
+	defer response.Body.Close()
+
+	if !is2xxStatusCode(response.StatusCode) {
+		es.log.Error(nil, "error while deleting template", "templateName", templateName, "http-response", response)
+		return fmt.Errorf("error while deleting template %v: %v", templateName, response)
+	}
+
+	es.log.Info("template was deleted successfully", "templateName", templateName)
+	return nil
+}
+
+// GetClusterHealth returns the health of the cluster: green, yellow or red
+func (es *esClient7) GetClusterHealth(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, es.Config.GetRequestTimeout())
+	defer cancel()
+
+	response, err := esapi.ClusterHealthRequest{}.Do(ctx, es.Client)
+	if err != nil {
+		es.log.Error(err, "error while getting cluster health")
+		return "", err
+	}
+	defer response.Body.Close()
+	health, err := StreamToString(response.Body)
+	if err != nil {
+		return "", err
+	}
+	if !is2xxStatusCode(response.StatusCode) {
+		return "", fmt.Errorf("error while getting cluster health: %v", health)
+	}
+	return gjson.Get(health, "status").String(), nil
+}
+
+// getBackingIndex returns the index behind indexName when indexName is an alias created by a migration, or indexName otherwise
+func (es *esClient7) getBackingIndex(ctx context.Context, indexName string) string {
+	response, err := esapi.IndicesGetAliasRequest{Name: []string{indexName}}.Do(ctx, es.Client)
+	if err != nil {
+		es.log.Error(err, "error while getting alias", "alias", indexName)
+		return indexName
+	}
+	defer response.Body.Close()
+	if !is2xxStatusCode(response.StatusCode) {
+		return indexName
+	}
+	aliases, err := StreamToString(response.Body)
+	if err != nil {
+		es.log.Error(err, "error while converting stream to string to get alias", "alias", indexName)
+		return indexName
+	}
+	if backingIndex := GetWriteIndexFromAliases(aliases, indexName); backingIndex != nil {
+		return *backingIndex
+	}
+	return indexName
+}
+
+func (es *esClient7) getAliases(ctx context.Context, indexName string) (string, error) {
+	response, err := esapi.IndicesGetAliasRequest{Index: []string{indexName}}.Do(ctx, es.Client)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if !is2xxStatusCode(response.StatusCode) {
+		return "", fmt.Errorf("error while getting aliases of index %v: %v", indexName, response)
+	}
+	return StreamToString(response.Body)
+}
+
+func (es *esClient7) getAliasNames(ctx context.Context, indexName string) ([]string, error) {
+	aliases, err := es.getAliases(ctx, indexName)
+	if err != nil {
+		return nil, err
+	}
+	return GetAliasNames(aliases, indexName), nil
+}
+
+func (es *esClient7) putIndexSettings(ctx context.Context, indexName string, settings string) error {
+	response, err := esapi.IndicesPutSettingsRequest{Index: []string{indexName}, Body: strings.NewReader(settings)}.Do(ctx, es.Client)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if !is2xxStatusCode(response.StatusCode) {
+		return fmt.Errorf("error while updating settings of index %v: %v", indexName, response)
+	}
+	return nil
+}
+
+// prepareIndexMigration initializes the migration towards the next versioned index, deletes this index if it was left
+// by a previous migration and blocks writes on the backing index
+func (es *esClient7) prepareIndexMigration(ctx context.Context, indexName string, backingIndex string, operation string, migration *EsMigration) *EsStatus {
+	migration.Operation = operation
+	migration.Phase = ""
+	migration.SourceIndex = backingIndex
+	migration.TargetIndex = NextBackingIndexName(indexName, backingIndex)
+	migration.TaskID = ""
+	migration.Created = 0
+	migration.Total = 0
+
+	if es.mapping == mappingTyped && migration.OldIndexPolicy == OldIndexPolicyRetain && backingIndex == indexName {
+		errMsg := fmt.Sprintf("index %v cannot be retained, elasticsearch 6 has no clone API to free its name for the alias, set migration.oldIndexPolicy to %v", indexName, OldIndexPolicyDelete)
+		es.log.Error(nil, errMsg, "indexName", indexName)
+		migration.Phase = MigrationPhaseFailed
+		return &EsStatus{Status: StatusError, Message: errMsg}
+	}
+	if es.Config.Distribution != DistributionOpenSearch && migration.OldIndexPolicy == OldIndexPolicyRetain && backingIndex == indexName {
+		if err := es.Config.RequireVersion(CloneMinVersion, "index clones"); err != nil {
+			errMsg := fmt.Sprintf("index %v cannot be retained, it is cloned to free its name for the alias and %v, set migration.oldIndexPolicy to %v", indexName, err.Error(), OldIndexPolicyDelete)
+			es.log.Error(nil, errMsg, "indexName", indexName)
+			migration.Phase = MigrationPhaseFailed
+			return &EsStatus{Status: StatusError, Message: errMsg}
+		}
+	}
+
+	es.log.Info("starting index migration", "indexName", indexName, "operation", operation, "from", migration.SourceIndex, "to", migration.TargetIndex)
+
+	if exists := es.existsIndex(ctx, migration.TargetIndex); exists != nil && *exists {
+		es.log.Info("deleting target index left by a previous migration", "indexName", indexName, "targetIndex", migration.TargetIndex)
+		response, err := esapi.IndicesDeleteRequest{Index: []string{migration.TargetIndex}}.Do(ctx, es.Client)
+		if err != nil {
+			status, _ := es.failIndexMigration(ctx, migration, fmt.Sprintf("error while deleting target index %v", migration.TargetIndex), err)
+			return status
+		}
+		response.Body.Close()
+	}
+
+	if err := es.putIndexSettings(ctx, backingIndex, `{"index.blocks.write": true}`); err != nil {
+		status, _ := es.failIndexMigration(ctx, migration, fmt.Sprintf("error while blocking writes on index %v", backingIndex), err)
+		return status
+	}
+	return nil
+}
+
+// startIndexMigration blocks writes on the backing index, creates the next versioned index with the new model
+// and starts an asynchronous reindex into it
+func (es *esClient7) startIndexMigration(ctx context.Context, indexName string, backingIndex string, model string, migration *EsMigration) (*EsStatus, error) {
+	if status := es.prepareIndexMigration(ctx, indexName, backingIndex, MigrationOperationReindex, migration); status != nil {
+		return status, errors.New(status.Message)
+	}
+	targetIndex := migration.TargetIndex
+
+	targetModel, err := (&EsModel{Model: model}).WithoutAliases()
+	if err != nil {
+		return es.failIndexMigration(ctx, migration, "error while removing aliases from model", err)
+	}
+	targetModel, includeTypeName, err := es.withMapping(targetModel)
+	if err != nil {
+		return es.failIndexMigration(ctx, migration, "error while adding a type to the mapping", err)
+	}
+	response, err := esapi.IndicesCreateRequest{Index: targetIndex, Body: strings.NewReader(targetModel), IncludeTypeName: includeTypeName}.Do(ctx, es.Client)
+	if err != nil {
+		return es.failIndexMigration(ctx, migration, fmt.Sprintf("error while creating index %v", targetIndex), err)
+	}
+	defer response.Body.Close()
+	if !is2xxStatusCode(response.StatusCode) {
+		return es.failIndexMigration(ctx, migration, fmt.Sprintf("error while creating index %v: %v", targetIndex, response.String()), nil)
+	}
+
+	no := false
+	body := strings.NewReader(fmt.Sprintf(`{"source":{"index":%q},"dest":{"index":%q}}`, backingIndex, targetIndex))
+	reindexResponse, err := esapi.ReindexRequest{Body: body, WaitForCompletion: &no}.Do(ctx, es.Client)
+	if err != nil {
+		return es.failIndexMigration(ctx, migration, fmt.Sprintf("error while reindexing %v into %v", backingIndex, targetIndex), err)
+	}
+	defer reindexResponse.Body.Close()
+	reindex, err := StreamToString(reindexResponse.Body)
+	if err != nil || !is2xxStatusCode(reindexResponse.StatusCode) {
+		return es.failIndexMigration(ctx, migration, fmt.Sprintf("error while reindexing %v into %v: %v", backingIndex, targetIndex, reindex), err)
+	}
+
+	migration.Phase = MigrationPhaseReindexing
+	migration.TaskID = gjson.Get(reindex, "task").String()
+	es.log.Info("reindex started", "indexName", indexName, "from", backingIndex, "to", targetIndex, "task", migration.TaskID)
+	return &EsStatus{Status: StatusMigrating, HttpCodeStatus: strconv.Itoa(reindexResponse.StatusCode), Message: fmt.Sprintf("reindexing %v into %v", backingIndex, targetIndex)}, nil
+}
+
+// startIndexResize blocks writes on the backing index and splits it right away, or first relocates
+// a copy of every shard on a single node before shrinking it
+func (es *esClient7) startIndexResize(ctx context.Context, indexName string, backingIndex string, operation string, numShards int32, migration *EsMigration) (*EsStatus, error) {
+	if operation == MigrationOperationSplit {
+		routingShards, err := es.GetIndexRoutingShards(ctx, backingIndex)
+		if err == nil && routingShards != nil {
+			err = ValidateSplit(indexName, numShards, *routingShards)
+		}
+		if err != nil {
+			es.log.Error(err, "index cannot be split", "indexName", indexName, "numberOfShards", numShards)
+			return &EsStatus{Status: StatusError, Message: err.Error()}, err
+		}
+	}
+
+	if status := es.prepareIndexMigration(ctx, indexName, backingIndex, operation, migration); status != nil {
+		return status, errors.New(status.Message)
+	}
+
+	if operation == MigrationOperationSplit {
+		return es.resizeIndex(ctx, indexName, numShards, migration)
+	}
+
+	shards, err := es.getShards(ctx, backingIndex)
+	if err != nil {
+		return es.failIndexMigration(ctx, migration, fmt.Sprintf("error while getting shards of index %v", backingIndex), err)
+	}
+	node := GetShrinkNode(shards)
+	if node == nil {
+		return es.failIndexMigration(ctx, migration, fmt.Sprintf("no node found to shrink index %v", backingIndex), nil)
+	}
+	if err := es.putIndexSettings(ctx, backingIndex, fmt.Sprintf(`{"index.routing.allocation.require._name": %q}`, *node)); err != nil {
+		return es.failIndexMigration(ctx, migration, fmt.Sprintf("error while relocating shards of index %v on node %v", backingIndex, *node), err)
+	}
+
+	migration.Phase = MigrationPhaseRelocating
+	es.log.Info("relocating shards before shrink", "indexName", indexName, "backingIndex", backingIndex, "node", *node)
+	return &EsStatus{Status: StatusMigrating, Message: fmt.Sprintf("relocating shards of %v on node %v before shrinking it", backingIndex, *node)}, nil
+}
+
+// GetIndexRoutingShards returns the number_of_routing_shards of the index, or of the backing index of the alias, which
+// bounds the number of shards it can be split into. It is nil when the index does not exist
+func (es *esClient7) GetIndexRoutingShards(ctx context.Context, indexName string) (*int32, error) {
+	request := esapi.ClusterStateRequest{Metric: []string{"metadata"}, Index: []string{indexName}, FilterPath: []string{"metadata.indices.*.routing_num_shards"}}
+	state, found, err := es.getObject(ctx, "cluster state", indexName, request)
+	if err != nil || !found {
+		return nil, err
+	}
+	return GetRoutingNumShards(state)
+}
+
+func (es *esClient7) getShards(ctx context.Context, indexName string) (string, error) {
+	response, err := esapi.CatShardsRequest{Index: []string{indexName}, Format: "json"}.Do(ctx, es.Client)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	if !is2xxStatusCode(response.StatusCode) {
+		return "", fmt.Errorf("error while getting shards of index %v: %v", indexName, response)
+	}
+	return StreamToString(response.Body)
+}
+
+// resizeIndex splits or shrinks the backing index into the next versioned index
+func (es *esClient7) resizeIndex(ctx context.Context, indexName string, numShards int32, migration *EsMigration) (*EsStatus, error) {
+	source, target := migration.SourceIndex, migration.TargetIndex
+	body := strings.NewReader(fmt.Sprintf(`{"settings":{"index.number_of_shards":%v,"index.routing.allocation.require._name":null,"index.blocks.write":null}}`, numShards))
+
+	var response *esapi.Response
+	var err error
+	if migration.Operation == MigrationOperationSplit {
+		response, err = esapi.IndicesSplitRequest{Index: source, Target: target, Body: body}.Do(ctx, es.Client)
+	} else {
+		response, err = esapi.IndicesShrinkRequest{Index: source, Target: target, Body: body}.Do(ctx, es.Client)
+	}
+	if err != nil {
+		return es.failIndexMigration(ctx, migration, fmt.Sprintf("error while resizing %v into %v", source, target), err)
+	}
+	defer response.Body.Close()
+	if !is2xxStatusCode(response.StatusCode) {
+		return es.failIndexMigration(ctx, migration, fmt.Sprintf("error while resizing %v into %v: %v", source, target, response.String()), nil)
+	}
+
+	migration.Phase = MigrationPhaseResizing
+	es.log.Info("resize started", "indexName", indexName, "operation", migration.Operation, "from", source, "to", target, "shards", numShards)
+	return &EsStatus{Status: StatusMigrating, HttpCodeStatus: strconv.Itoa(response.StatusCode),
+		Message: fmt.Sprintf("resizing %v into %v with %v shards", source, target, numShards)}, nil
+}
+
+// followIndexMigration follows the migration in progress, and moves the alias to the new index once it is ready
+func (es *esClient7) followIndexMigration(ctx context.Context, indexName string, model string, migration *EsMigration) (*EsStatus, error) {
+	switch migration.Phase {
+	case MigrationPhaseRelocating:
+		return es.followShrinkRelocation(ctx, indexName, model, migration)
+	case MigrationPhaseResizing:
+		return es.followIndexResize(ctx, indexName, model, migration)
+	default:
+		return es.followIndexReindex(ctx, indexName, model, migration)
+	}
+}
+
+// followShrinkRelocation shrinks the backing index once a copy of every shard is on the shrink node
+func (es *esClient7) followShrinkRelocation(ctx context.Context, indexName string, model string, migration *EsMigration) (*EsStatus, error) {
+	numShards, err := (&EsModel{Model: model}).GetNumberOfShards()
+	if err != nil {
+		return es.failIndexMigration(ctx, migration, "error while getting number_of_shards from model", err)
+	}
+	response, err := esapi.IndicesGetSettingsRequest{Index: []string{migration.SourceIndex}, Name: []string{"index.routing.allocation.require._name"}}.Do(ctx, es.Client)
+	if err != nil {
+		es.log.Error(err, "error while getting shrink node", "indexName", indexName)
+		return &EsStatus{Status: StatusRetry, Message: err.Error()}, nil
+	}
+	defer response.Body.Close()
+	settings, err := StreamToString(response.Body)
+	if err != nil || !is2xxStatusCode(response.StatusCode) {
+		return es.failIndexMigration(ctx, migration, fmt.Sprintf("error while getting shrink node of index %v: %v", migration.SourceIndex, settings), err)
+	}
+	node := (&EsSettings{Settings: settings}).GetAllocationRequireName(migration.SourceIndex)
+	if node == nil {
+		return es.failIndexMigration(ctx, migration, fmt.Sprintf("no shrink node found on index %v", migration.SourceIndex), nil)
+	}
+
+	shards, err := es.getShards(ctx, migration.SourceIndex)
+	if err != nil {
+		es.log.Error(err, "error while getting shards", "indexName", indexName)
+		return &EsStatus{Status: StatusRetry, Message: err.Error()}, nil
+	}
+	if !AreAllShardsOnNode(shards, *node) {
+		es.log.Info("shards relocation in progress", "indexName", indexName, "backingIndex", migration.SourceIndex, "node", *node)
+		return &EsStatus{Status: StatusMigrating, Message: fmt.Sprintf("relocating shards of %v on node %v before shrinking it", migration.SourceIndex, *node)}, nil
+	}
+
+	return es.resizeIndex(ctx, indexName, *numShards, migration)
+}
+
+// followIndexResize moves the alias once the primary shards of the resized index are allocated
+func (es *esClient7) followIndexResize(ctx context.Context, indexName string, model string, migration *EsMigration) (*EsStatus, error) {
+	response, err := esapi.ClusterHealthRequest{Index: []string{migration.TargetIndex}}.Do(ctx, es.Client)
+	if err != nil {
+		es.log.Error(err, "error while getting index health", "indexName", indexName, "targetIndex", migration.TargetIndex)
+		return &EsStatus{Status: StatusRetry, Message: err.Error()}, nil
+	}
+	defer response.Body.Close()
+	health, err := StreamToString(response.Body)
+	if err != nil || !is2xxStatusCode(response.StatusCode) {
+		return es.failIndexMigration(ctx, migration, fmt.Sprintf("error while getting health of index %v: %v", migration.TargetIndex, health), err)
+	}
+	if status := gjson.Get(health, "status").String(); status == "red" {
+		es.log.Info("resize in progress", "indexName", indexName, "targetIndex", migration.TargetIndex, "health", status)
+		return &EsStatus{Status: StatusMigrating, HttpCodeStatus: strconv.Itoa(response.StatusCode),
+			Message: fmt.Sprintf("resizing %v into %v: waiting for primary shards", migration.SourceIndex, migration.TargetIndex)}, nil
+	}
+
+	return es.swapIndexAlias(ctx, indexName, model, migration)
+}
+
+// followIndexReindex checks the reindex task progress, and moves the alias to the new index once the reindex is over
+func (es *esClient7) followIndexReindex(ctx context.Context, indexName string, model string, migration *EsMigration) (*EsStatus, error) {
+	response, err := esapi.TasksGetRequest{TaskID: migration.TaskID}.Do(ctx, es.Client)
+	if err != nil {
+		es.log.Error(err, "error while getting reindex task", "indexName", indexName, "task", migration.TaskID)
+		return &EsStatus{Status: StatusRetry, Message: err.Error()}, nil
+	}
+	defer response.Body.Close()
+	taskBody, err := StreamToString(response.Body)
+	if err != nil || !is2xxStatusCode(response.StatusCode) {
+		return es.failIndexMigration(ctx, migration, fmt.Sprintf("error while getting reindex task %v: %v", migration.TaskID, taskBody), err)
+	}
+
+	task := &EsTask{Task: taskBody}
+	completed, created, total := task.IsCompleted()
+	migration.Created = created
+	migration.Total = total
+	if !completed {
+		es.log.Info("reindex in progress", "indexName", indexName, "task", migration.TaskID, "created", created, "total", total)
+		return &EsStatus{Status: StatusMigrating, HttpCodeStatus: strconv.Itoa(response.StatusCode),
+			Message: fmt.Sprintf("reindexing %v into %v: %v/%v documents", migration.SourceIndex, migration.TargetIndex, created, total)}, nil
+	}
+	if taskError := task.GetError(); taskError != nil {
+		return es.failIndexMigration(ctx, migration, fmt.Sprintf("reindex of %v into %v failed: %v", migration.SourceIndex, migration.TargetIndex, *taskError), nil)
+	}
+
+	return es.swapIndexAlias(ctx, indexName, model, migration)
+}
+
+// swapIndexAlias atomically moves the alias and the model aliases to the new index and removes the old index according to the policy.
+// An old index holding the alias name is always removed from the alias call, a copy is cloned first when it should be retained
+// (elasticsearch 6 never retains it, see prepareIndexMigration)
+func (es *esClient7) swapIndexAlias(ctx context.Context, indexName string, model string, migration *EsMigration) (*EsStatus, error) {
+	source, target := migration.SourceIndex, migration.TargetIndex
+	retain := migration.OldIndexPolicy == OldIndexPolicyRetain
+
+	if retain && source == indexName {
+		retainedIndex := indexName + RetainedIndexInitialSuffix
+		es.log.Info("cloning index to retain it", "indexName", indexName, "retainedIndex", retainedIndex)
+		body := strings.NewReader(`{"settings":{"index.routing.allocation.require._name":null}}`)
+		response, err := esapi.IndicesCloneRequest{Index: source, Target: retainedIndex, Body: body}.Do(ctx, es.Client)
+		if err != nil {
+			return es.failIndexMigration(ctx, migration, fmt.Sprintf("error while cloning %v into %v", source, retainedIndex), err)
+		}
+		defer response.Body.Close()
+		if !is2xxStatusCode(response.StatusCode) {
+			return es.failIndexMigration(ctx, migration, fmt.Sprintf("error while cloning %v into %v: %v", source, retainedIndex, response.String()), nil)
+		}
+	}
+
+	sourceAliases, err := es.getAliasNames(ctx, source)
+	if err != nil {
+		return es.failIndexMigration(ctx, migration, fmt.Sprintf("error while getting aliases of %v", source), err)
+	}
+	actions, err := BuildSwapAliasActions(indexName, source, target, sourceAliases, (&EsModel{Model: model}).GetAliases(), !retain)
+	if err != nil {
+		return es.failIndexMigration(ctx, migration, "error while building alias actions", err)
+	}
+
+	response, err := esapi.IndicesUpdateAliasesRequest{Body: strings.NewReader(actions)}.Do(ctx, es.Client)
+	if err != nil {
+		return es.failIndexMigration(ctx, migration, fmt.Sprintf("error while moving alias %v from %v to %v", indexName, source, target), err)
+	}
+	defer response.Body.Close()
+	if !is2xxStatusCode(response.StatusCode) {
+		return es.failIndexMigration(ctx, migration, fmt.Sprintf("error while moving alias %v from %v to %v: %v", indexName, source, target, response.String()), nil)
+	}
+
+	if retain && migration.Operation == MigrationOperationShrink {
+		if err := es.putIndexSettings(ctx, source, `{"index.routing.allocation.require._name": null}`); err != nil {
+			es.log.Error(err, "error while releasing shards allocation of retained index", "indexName", source)
+		}
+	}
+
+	migration.Phase = MigrationPhaseCompleted
+	es.log.Info("index was migrated successfully", "indexName", indexName, "from", source, "to", target)
+	status := BuildEsStatus(response.StatusCode, fmt.Sprintf("index %v migrated from %v to %v", indexName, source, target))
+	status.Aliases = BuildEsAliasesStatus(model, status.Status, "")
+	return status, nil
+}
+
+// failIndexMigration marks the migration as failed and gives writes and shards allocation back to the old index
+func (es *esClient7) failIndexMigration(ctx context.Context, migration *EsMigration, errMsg string, err error) (*EsStatus, error) {
+	es.log.Error(err, errMsg, "from", migration.SourceIndex, "to", migration.TargetIndex)
+	migration.Phase = MigrationPhaseFailed
+	if err := es.putIndexSettings(ctx, migration.SourceIndex, `{"index.blocks.write": false, "index.routing.allocation.require._name": null}`); err != nil {
+		es.log.Error(err, "error while removing write block", "indexName", migration.SourceIndex)
+	}
+	if err != nil {
+		errMsg = fmt.Sprintf("%v: %v", errMsg, err.Error())
+	}
+	return &EsStatus{Status: StatusError, Message: errMsg}, errors.New(errMsg)
+}
+
+// withMapping returns the model to create an index or put a template with, and the include_type_name parameter of the
+// request
+func (es *esClient7) withMapping(model string) (string, *bool, error) {
+	switch es.mapping {
+	case mappingTyped:
+		typedModel, err := (&EsModel{Model: model}).WithMappingType(DefaultMappingType)
+		return typedModel, nil, err
+	case mappingTypeless:
+		return model, nil, nil
+	default:
+		return model, (&EsModel{Model: model}).IsMappingWithType(), nil
+	}
+}
+
+// includeTypeName returns the include_type_name parameter of the mapping requests, only sent to elasticsearch 7
+func (es *esClient7) includeTypeName(value bool) *bool {
+	if es.mapping != mappingIncludeTypeName {
+		return nil
+	}
+	return &value
+}
+
+// createOrUpdateIndexTemplate puts a composable index template, whose model includes its priority, composed_of and
+// data_stream fields. Elasticsearch 6 has no composable templates, the template methods are exported by Elasticsearch7
+// and OpenSearch only
+func (es *esClient7) createOrUpdateIndexTemplate(ctx context.Context, templateName string, model string) (*EsStatus, error) {
+	request := esapi.IndicesPutIndexTemplateRequest{Name: templateName, Body: strings.NewReader(model)}
+	return es.putObject(ctx, "index template", templateName, OperationIndexTemplatePut, request)
+}
+
+// getIndexTemplateDrift compares a composable index template with its model, and returns what differs
+func (es *esClient7) getIndexTemplateDrift(ctx context.Context, templateName string, model string) (*EsDrift, error) {
+	templates, found, err := es.getObject(ctx, "index template", templateName, esapi.IndicesGetIndexTemplateRequest{Name: []string{templateName}})
+	if err != nil {
+		return nil, err
+	} else if !found {
+		return &EsDrift{Reasons: []string{fmt.Sprintf("index template %v does not exist", templateName)}}, nil
+	}
+	reasons, err := GetIndexTemplateDriftReasons(templates, templateName, model)
+	if err != nil {
+		return nil, err
+	}
+	return &EsDrift{Reasons: reasons}, nil
+}
+
+func (es *esClient7) deleteIndexTemplate(ctx context.Context, templateName string) error {
+	return es.deleteObject(ctx, "index template", templateName, esapi.IndicesDeleteIndexTemplateRequest{Name: templateName})
+}
+
+// createOrUpdateComponentTemplate puts a component template
+func (es *esClient7) createOrUpdateComponentTemplate(ctx context.Context, templateName string, model string) (*EsStatus, error) {
+	request := esapi.ClusterPutComponentTemplateRequest{Name: templateName, Body: strings.NewReader(model)}
+	return es.putObject(ctx, "component template", templateName, OperationComponentTemplatePut, request)
+}
+
+// getComponentTemplateDrift compares a component template with its model, and returns what differs
+func (es *esClient7) getComponentTemplateDrift(ctx context.Context, templateName string, model string) (*EsDrift, error) {
+	templates, found, err := es.getObject(ctx, "component template", templateName, esapi.ClusterGetComponentTemplateRequest{Name: []string{templateName}})
+	if err != nil {
+		return nil, err
+	} else if !found {
+		return &EsDrift{Reasons: []string{fmt.Sprintf("component template %v does not exist", templateName)}}, nil
+	}
+	reasons, err := GetComponentTemplateDriftReasons(templates, templateName, model)
+	if err != nil {
+		return nil, err
+	}
+	return &EsDrift{Reasons: reasons}, nil
+}
+
+func (es *esClient7) deleteComponentTemplate(ctx context.Context, templateName string) error {
+	return es.deleteObject(ctx, "component template", templateName, esapi.ClusterDeleteComponentTemplateRequest{Name: templateName})
+}
+
+// existsComponentTemplate returns true when the component template exists
+func (es *esClient7) existsComponentTemplate(ctx context.Context, templateName string) (bool, error) {
+	_, found, err := es.getObject(ctx, "component template", templateName, esapi.ClusterGetComponentTemplateRequest{Name: []string{templateName}})
+	return found, err
+}
+
+// CreateOrUpdateIngestPipeline puts an ingest pipeline
+func (es *esClient7) CreateOrUpdateIngestPipeline(ctx context.Context, pipelineName string, model string) (*EsStatus, error) {
+	request := esapi.IngestPutPipelineRequest{PipelineID: pipelineName, Body: strings.NewReader(model)}
+	return es.putObject(ctx, "ingest pipeline", pipelineName, OperationIngestPipelinePut, request)
+}
+
+// GetIngestPipelineDrift compares an ingest pipeline with its model, and returns what differs
+func (es *esClient7) GetIngestPipelineDrift(ctx context.Context, pipelineName string, model string) (*EsDrift, error) {
+	pipelines, found, err := es.getObject(ctx, "ingest pipeline", pipelineName, esapi.IngestGetPipelineRequest{PipelineID: pipelineName})
+	if err != nil {
+		return nil, err
+	} else if !found {
+		return &EsDrift{Reasons: []string{fmt.Sprintf("ingest pipeline %v does not exist", pipelineName)}}, nil
+	}
+	return &EsDrift{Reasons: GetIngestPipelineDriftReasons(pipelines, pipelineName, model)}, nil
+}
+
+func (es *esClient7) DeleteIngestPipeline(ctx context.Context, pipelineName string) error {
+	return es.deleteObject(ctx, "ingest pipeline", pipelineName, esapi.IngestDeletePipelineRequest{PipelineID: pipelineName})
+}
+
+// GetIngestPipelineVersion returns the version of an ingest pipeline, nil when it has none or does not exist
+func (es *esClient7) GetIngestPipelineVersion(ctx context.Context, pipelineName string) (*int64, error) {
+	pipelines, found, err := es.getObject(ctx, "ingest pipeline", pipelineName, esapi.IngestGetPipelineRequest{PipelineID: pipelineName})
+	if err != nil || !found {
+		return nil, err
+	}
+	return GetIngestPipelineVersion(pipelines, pipelineName), nil
+}
+
+// SimulateIngestPipeline runs the documents through the pipeline model with the _simulate API, and returns for each of
+// them the reason of its failure, empty when the document was processed
+func (es *esClient7) SimulateIngestPipeline(ctx context.Context, model string, documents []string) ([]string, error) {
+	body, err := BuildSimulateBody(model, documents)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, es.Config.GetRequestTimeout())
+	defer cancel()
+
+	response, err := esapi.IngestSimulateRequest{Body: strings.NewReader(body)}.Do(ctx, es.Client)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	result, err := StreamToString(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !is2xxStatusCode(response.StatusCode) {
+		return nil, fmt.Errorf("error while simulating ingest pipeline: %v", GetResponseError(result))
+	}
+	return GetSimulateFailures(result, len(documents)), nil
+}
+
+// CreateOrUpdateSnapshotRepository puts a snapshot repository, that elasticsearch verifies on all the nodes
+func (es *esClient7) CreateOrUpdateSnapshotRepository(ctx context.Context, repositoryName string, model string) (*EsStatus, error) {
+	request := esapi.SnapshotCreateRepositoryRequest{Repository: repositoryName, Body: strings.NewReader(model)}
+	return es.putObject(ctx, "snapshot repository", repositoryName, OperationRepositoryPut, request)
+}
+
+// GetSnapshotRepositoryDrift compares a snapshot repository with its model, and returns what differs
+func (es *esClient7) GetSnapshotRepositoryDrift(ctx context.Context, repositoryName string, model string) (*EsDrift, error) {
+	repositories, found, err := es.getObject(ctx, "snapshot repository", repositoryName, esapi.SnapshotGetRepositoryRequest{Repository: []string{repositoryName}})
+	if err != nil {
+		return nil, err
+	} else if !found {
+		return &EsDrift{Reasons: []string{fmt.Sprintf("snapshot repository %v does not exist", repositoryName)}}, nil
+	}
+	return &EsDrift{Reasons: GetSnapshotRepositoryDriftReasons(repositories, repositoryName, model)}, nil
+}
+
+// DeleteSnapshotRepository unregisters a snapshot repository, its snapshots being kept in the storage
+func (es *esClient7) DeleteSnapshotRepository(ctx context.Context, repositoryName string) error {
+	return es.deleteObject(ctx, "snapshot repository", repositoryName, esapi.SnapshotDeleteRepositoryRequest{Repository: []string{repositoryName}})
+}
+
+// ExistsSnapshotRepository returns true when the snapshot repository exists
+func (es *esClient7) ExistsSnapshotRepository(ctx context.Context, repositoryName string) (bool, error) {
+	_, found, err := es.getObject(ctx, "snapshot repository", repositoryName, esapi.SnapshotGetRepositoryRequest{Repository: []string{repositoryName}})
+	return found, err
+}
+
+// putObject performs the request creating or updating an elasticsearch object, and returns its status with operation
+// when it succeeds
+func (es *esClient7) putObject(ctx context.Context, objectType string, name string, operation string, request esapi.Request) (*EsStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, es.Config.GetRequestTimeout())
+	defer cancel()
+
+	response, err := request.Do(ctx, es.Client)
+	if err != nil {
+		es.log.Error(err, "error while putting "+objectType, "name", name)
+		return &EsStatus{Status: StatusError, Message: err.Error()}, err
+	}
+	defer response.Body.Close()
+
+	status := BuildEsStatus(response.StatusCode, response.String())
+	if !is2xxStatusCode(response.StatusCode) {
+		es.log.Error(nil, "error while putting "+objectType, "name", name, "http-response", response)
+		return status, fmt.Errorf("error while putting %v %v", objectType, name)
+	}
+	es.log.Info(objectType+" was put successfully", "name", name)
+	status.Operations = []string{operation}
+	return status, nil
+}
+
+// getObject performs the request getting an elasticsearch object, and returns the response body. found is false when
+// the object does not exist
+func (es *esClient7) getObject(ctx context.Context, objectType string, name string, request esapi.Request) (body string, found bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, es.Config.GetRequestTimeout())
+	defer cancel()
+
+	response, err := request.Do(ctx, es.Client)
+	if err != nil {
+		return "", false, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	if body, err = StreamToString(response.Body); err != nil {
+		return "", false, err
+	}
+	if !is2xxStatusCode(response.StatusCode) {
+		return "", false, fmt.Errorf("error while getting %v %v: %v", objectType, name, body)
+	}
+	return body, true, nil
+}
+
+// deleteObject performs the request deleting an elasticsearch object, an object that does not exist being already deleted
+func (es *esClient7) deleteObject(ctx context.Context, objectType string, name string, request esapi.Request) error {
+	ctx, cancel := context.WithTimeout(ctx, es.Config.GetRequestTimeout())
+	defer cancel()
+
+	response, err := request.Do(ctx, es.Client)
+	if err != nil {
+		es.log.Error(err, "error while deleting "+objectType, "name", name)
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusNotFound {
+		es.log.Info(objectType+" cannot be deleted because it does not exist", "name", name)
+		return nil
+	}
+	if !is2xxStatusCode(response.StatusCode) {
+		es.log.Error(nil, "error while deleting "+objectType, "name", name, "http-response", response)
+		return fmt.Errorf("error while deleting %v %v: %v", objectType, name, response)
+	}
+	es.log.Info(objectType+" was deleted successfully", "name", name)
+	return nil
+}
+
+// esRequest7 is a request of an API the elasticsearch 7 client does not provide, like GET _data_stream/<name>
+type esRequest7 struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+func (r esRequest7) Do(ctx context.Context, transport esapi.Transport) (*esapi.Response, error) {
+	request, err := http.NewRequest(r.Method, r.Path, strings.NewReader(r.Body))
+	if err != nil {
+		return nil, err
+	}
+	request = request.WithContext(ctx)
+	if r.Body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	response, err := transport.Perform(request)
+	if err != nil {
+		return nil, err
+	}
+	return &esapi.Response{StatusCode: response.StatusCode, Body: response.Body, Header: response.Header}, nil
+}
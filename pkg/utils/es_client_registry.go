@@ -0,0 +1,248 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultVersionTTL time.Duration = 10 * time.Minute
+
+// EsClientRegistry shares transports, and so connection pools, clients and detected elasticsearch versions between
+// reconciliations. Connections are identified by their node URLs, and hashes of their credentials and TLS configuration
+type EsClientRegistry struct {
+	versionTTL  time.Duration
+	mutex       sync.Mutex
+	connections map[string]*esConnection
+	// connection key of each secret key, to invalidate connections when secrets change
+	secrets map[string]string
+	// ref used by each owner, an object or a connection, to remove the connections no longer used by any owner
+	owners map[string]string
+}
+
+type esConnection struct {
+	mutex         sync.Mutex
+	transport     http.RoundTripper
+	version       int
+	versionNumber string
+	distribution  string
+	backend       string
+	clusterUUID   string
+	versionTime   time.Time
+	// clients by version and sniffing configuration, kept for the life of the connection as sniffing clients
+	// discover nodes periodically
+	clients map[string]interface{}
+	// closed stops the node discoveries of the sniffing clients when the connection is removed
+	closed chan struct{}
+}
+
+// nodeDiscoverer is implemented by the elasticsearch clients, which update their nodes from the cluster when sniffing
+type nodeDiscoverer interface {
+	DiscoverNodes() error
+}
+
+func NewEsClientRegistry(versionTTL time.Duration) *EsClientRegistry {
+	return &EsClientRegistry{versionTTL: versionTTL, connections: map[string]*esConnection{}, secrets: map[string]string{}, owners: map[string]string{}}
+}
+
+// Connect returns the config of a connection used by owner, like ElasticIndex/<namespace>/<name>, with its shared
+// transport, its version and its cluster UUID, detected again once versionTTL is expired
+func (r *EsClientRegistry) Connect(esConnection *EsConnection, owner string) (*EsConfig, error) {
+	esConfig, err := esConnection.Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	connection, err := r.getConnection(esConnection.Ref, owner, esConfig)
+	if err != nil {
+		return nil, err
+	}
+	connection.mutex.Lock()
+	defer connection.mutex.Unlock()
+
+	if connection.versionTime.IsZero() || time.Since(connection.versionTime) >= r.versionTTL {
+		if err := probeCluster(esConfig, connection.transport); err != nil {
+			return esConfig, err
+		}
+		connection.version, connection.versionNumber, connection.clusterUUID = esConfig.Version, esConfig.VersionNumber, esConfig.ClusterUUID
+		connection.distribution, connection.backend = esConfig.Distribution, esConfig.Backend
+		connection.versionTime = time.Now()
+	}
+
+	esConfig.Version, esConfig.VersionNumber, esConfig.ClusterUUID = connection.version, connection.versionNumber, connection.clusterUUID
+	esConfig.Distribution, esConfig.Backend = connection.distribution, connection.backend
+	esConfig.Transport = connection.transport
+	esConfig.connection = connection
+	return esConfig, nil
+}
+
+// InvalidateSecret removes the connections read from a secret, so that the next reconciliations detect the version
+// again and open new connections
+func (r *EsClientRegistry) InvalidateSecret(namespace string, secretName string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	prefix := secretRef(namespace, secretName, "")
+	for ref, key := range r.secrets {
+		if strings.HasPrefix(ref, prefix) {
+			delete(r.secrets, ref)
+			r.removeConnectionIfUnused(key)
+		}
+	}
+}
+
+// Release removes the connection used by an owner that was deleted, when no other owner uses its ref, stopping the node
+// discoveries of its sniffing clients
+func (r *EsClientRegistry) Release(owner string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if ref, ok := r.owners[owner]; ok {
+		delete(r.owners, owner)
+		r.removeRefIfUnused(ref)
+	}
+}
+
+// InvalidateConnection removes the connection of ref, like ElasticConnection/<namespace>/<name>, when the connection is
+// deleted, whatever the owners still using it
+func (r *EsClientRegistry) InvalidateConnection(ref string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for owner, ownerRef := range r.owners {
+		if ownerRef == ref {
+			delete(r.owners, owner)
+		}
+	}
+	r.removeRefIfUnused(ref)
+}
+
+func (r *EsClientRegistry) getConnection(ref string, owner string, esConfig *EsConfig) (*esConnection, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	key := connectionKey(esConfig)
+	previousKey, ok := r.secrets[ref]
+	r.secrets[ref] = key
+	if ok && previousKey != key {
+		r.removeConnectionIfUnused(previousKey)
+	}
+	previousRef, ok := r.owners[owner]
+	r.owners[owner] = ref
+	if ok && previousRef != ref {
+		r.removeRefIfUnused(previousRef)
+	}
+
+	connection, ok := r.connections[key]
+	if !ok {
+		transport, err := newTransport(esConfig)
+		if err != nil {
+			return nil, err
+		}
+		connection = &esConnection{transport: transport}
+		r.connections[key] = connection
+	}
+	return connection, nil
+}
+
+// client returns the client of key, created by create when it does not exist yet. The nodes of a new client are
+// discovered every sniffingInterval, when it is not 0, until the connection is closed
+func (c *esConnection) client(key string, sniffingInterval time.Duration, create func() (interface{}, error)) (interface{}, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if client, ok := c.clients[key]; ok {
+		return client, nil
+	}
+	client, err := create()
+	if err != nil {
+		return nil, err
+	}
+	if c.clients == nil {
+		c.clients = map[string]interface{}{}
+		c.closed = make(chan struct{})
+	}
+	c.clients[key] = client
+	if discoverer, ok := client.(nodeDiscoverer); ok && sniffingInterval > 0 {
+		go discoverNodes(discoverer, sniffingInterval, c.closed)
+	}
+	return client, nil
+}
+
+// discoverNodes updates the nodes of a sniffing client every interval until closed is closed. The clients are not
+// given their own discovery interval, as their discovery timer cannot be stopped
+func discoverNodes(client nodeDiscoverer, interval time.Duration, closed <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			client.DiscoverNodes()
+		case <-closed:
+			return
+		}
+	}
+}
+
+// close stops the node discoveries of the clients of the connection and closes its idle connections
+func (c *esConnection) close() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed != nil {
+		close(c.closed)
+	}
+	c.clients, c.closed = nil, nil
+	closeIdleConnections(c.transport)
+}
+
+// removeRefIfUnused removes a ref, and its connection when no other ref uses it, once no owner uses the ref
+func (r *EsClientRegistry) removeRefIfUnused(ref string) {
+	for _, ownerRef := range r.owners {
+		if ownerRef == ref {
+			return
+		}
+	}
+	if key, ok := r.secrets[ref]; ok {
+		delete(r.secrets, ref)
+		r.removeConnectionIfUnused(key)
+	}
+}
+
+func (r *EsClientRegistry) removeConnectionIfUnused(key string) {
+	for _, k := range r.secrets {
+		if k == key {
+			return
+		}
+	}
+	if connection, ok := r.connections[key]; ok {
+		connection.close()
+		delete(r.connections, key)
+	}
+}
+
+func secretRef(namespace string, secretName string, secretKey string) string {
+	return fmt.Sprintf("%v/%v/%v", namespace, secretName, secretKey)
+}
+
+// connectionKey identifies a connection by its node URLs, a hash of its credentials and a hash of its TLS configuration
+func connectionKey(esConfig *EsConfig) string {
+	credentials := HashString(esConfig.Username + ":" + esConfig.Password + ":" + esConfig.APIKey + ":" + esConfig.BearerToken)
+	return esConfig.String() + "#" + credentials + "#" + esConfig.TLS.Hash() + "#" + esConfig.HTTP.Hash()
+}
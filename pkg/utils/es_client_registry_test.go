@@ -0,0 +1,220 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"net/http"
+	"net/http/httptest"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newVersionServer(probes *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(probes, 1)
+		fmt.Fprint(w, `{"version":{"number":"7.8.0"}}`)
+	}))
+}
+
+func newUriSecret(name string, uri string) *v1.Secret {
+	return &v1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: name}, Data: map[string][]byte{"uri": []byte(uri)}}
+}
+
+func TestEsClientRegistry_Connect(t *testing.T) {
+	assert := assert.New(t)
+	var probes int32
+	server := newVersionServer(&probes)
+	defer server.Close()
+	uri := strings.Replace(server.URL, "http://", "http://elastic:pass@", 1)
+
+	registry := NewEsClientRegistry(time.Hour)
+	first, err := registry.Connect(NewEsConnectionFromSecret(newUriSecret("secret", uri), "uri", nil, nil), "ElasticIndex/ns/secret")
+	assert.Nil(err)
+	assert.Equal(7, first.Version)
+	assert.Equal("elastic", first.Username)
+	assert.NotNil(first.Transport)
+
+	second, err := registry.Connect(NewEsConnectionFromSecret(newUriSecret("other-secret", uri), "uri", nil, nil), "ElasticIndex/ns/other-secret")
+	assert.Nil(err)
+	assert.Equal(int32(1), atomic.LoadInt32(&probes), "version is cached for the same connection")
+	assert.True(first.Transport == second.Transport, "transport is shared by the same connection")
+
+	third, err := registry.Connect(NewEsConnectionFromSecret(newUriSecret("secret", server.URL), "uri", nil, nil), "ElasticIndex/ns/secret")
+	assert.Nil(err)
+	assert.Equal(int32(2), atomic.LoadInt32(&probes), "other credentials are another connection")
+	assert.False(first.Transport == third.Transport)
+
+	_, err = registry.Connect(NewEsConnectionFromSecret(newUriSecret("secret", "myhost:9200"), "uri", nil, nil), "ElasticIndex/ns/secret")
+	assert.NotNil(err)
+}
+
+func TestEsClientRegistry_VersionTTL(t *testing.T) {
+	assert := assert.New(t)
+	var probes int32
+	server := newVersionServer(&probes)
+	defer server.Close()
+
+	registry := NewEsClientRegistry(0)
+	_, _ = registry.Connect(NewEsConnectionFromSecret(newUriSecret("secret", server.URL), "uri", nil, nil), "ElasticIndex/ns/secret")
+	_, _ = registry.Connect(NewEsConnectionFromSecret(newUriSecret("secret", server.URL), "uri", nil, nil), "ElasticIndex/ns/secret")
+	assert.Equal(int32(2), atomic.LoadInt32(&probes), "version is detected again once expired")
+}
+
+func TestEsClientRegistry_InvalidateSecret(t *testing.T) {
+	assert := assert.New(t)
+	var probes int32
+	server := newVersionServer(&probes)
+	defer server.Close()
+
+	registry := NewEsClientRegistry(time.Hour)
+	first, _ := registry.Connect(NewEsConnectionFromSecret(newUriSecret("secret", server.URL), "uri", nil, nil), "ElasticIndex/ns/secret")
+	_, _ = registry.Connect(NewEsConnectionFromSecret(newUriSecret("secret-2", server.URL), "uri", nil, nil), "ElasticIndex/ns/secret-2")
+
+	registry.InvalidateSecret("ns", "secret")
+	assert.Equal(1, len(registry.connections), "connection still used by secret-2 is kept")
+
+	registry.InvalidateSecret("ns", "secret-2")
+	assert.Equal(0, len(registry.connections))
+	assert.Equal(0, len(registry.secrets))
+
+	second, _ := registry.Connect(NewEsConnectionFromSecret(newUriSecret("secret", server.URL), "uri", nil, nil), "ElasticIndex/ns/secret")
+	assert.Equal(int32(2), atomic.LoadInt32(&probes))
+	assert.False(first.Transport == second.Transport)
+}
+
+func TestEsClientRegistry_Release(t *testing.T) {
+	assert := assert.New(t)
+	var probes int32
+	server := newVersionServer(&probes)
+	defer server.Close()
+
+	registry := NewEsClientRegistry(time.Hour)
+	_, _ = registry.Connect(NewEsConnectionFromSecret(newUriSecret("secret", server.URL), "uri", nil, nil), "ElasticIndex/ns/index-1")
+	_, _ = registry.Connect(NewEsConnectionFromSecret(newUriSecret("secret", server.URL), "uri", nil, nil), "ElasticIndex/ns/index-2")
+	_, _ = registry.Connect(NewEsConnectionFromSecret(newUriSecret("secret-2", server.URL), "uri", nil, nil), "ElasticIndex/ns/index-2")
+	assert.Equal(2, len(registry.secrets), "secret is still used by index-1")
+
+	registry.Release("ElasticIndex/ns/index-1")
+	assert.Equal(1, len(registry.secrets))
+	assert.Equal(1, len(registry.connections), "connection still used by secret-2 is kept")
+
+	registry.Release("ElasticIndex/ns/index-2")
+	registry.Release("ElasticIndex/ns/unknown")
+	assert.Equal(0, len(registry.connections))
+	assert.Equal(0, len(registry.secrets))
+	assert.Equal(0, len(registry.owners))
+}
+
+func TestEsClientRegistry_InvalidateConnection(t *testing.T) {
+	assert := assert.New(t)
+	var probes int32
+	server := newVersionServer(&probes)
+	defer server.Close()
+
+	registry := NewEsClientRegistry(time.Hour)
+	connection := &EsConnection{Ref: "ElasticConnection/ns/production", URI: server.URL}
+	_, _ = registry.Connect(connection, connection.Ref)
+	_, _ = registry.Connect(connection, "ElasticIndex/ns/index")
+	_, _ = registry.Connect(NewEsConnectionFromSecret(newUriSecret("secret", server.URL), "uri", nil, nil), "ElasticIndex/ns/other")
+
+	registry.InvalidateConnection(connection.Ref)
+	assert.Equal(1, len(registry.owners), "owners of the deleted connection are removed")
+	assert.Equal(1, len(registry.connections), "connection still used by secret is kept")
+
+	registry.Release("ElasticIndex/ns/other")
+	assert.Equal(0, len(registry.connections))
+}
+
+func TestEsClientRegistry_MultipleNodes(t *testing.T) {
+	assert := assert.New(t)
+	var probes int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&probes, 1)
+		fmt.Fprint(w, `{"cluster_uuid":"wLyZGB","version":{"number":"8.4.0"}}`)
+	}))
+	defer server.Close()
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	registry := NewEsClientRegistry(time.Hour)
+	esConfig, err := registry.Connect(NewEsConnectionFromSecret(newUriSecret("secret", down.URL+","+server.URL), "uri", nil, &EsSniffing{}), "ElasticIndex/ns/secret")
+	assert.Nil(err)
+	assert.Equal(8, esConfig.Version, "version is detected from the next node when a node is down")
+	assert.Equal("wLyZGB", esConfig.ClusterUUID)
+	assert.Equal([]string{down.URL, server.URL}, esConfig.Addresses)
+
+	cached, err := registry.Connect(NewEsConnectionFromSecret(newUriSecret("secret", down.URL+","+server.URL), "uri", nil, &EsSniffing{}), "ElasticIndex/ns/secret")
+	assert.Nil(err)
+	assert.Equal("wLyZGB", cached.ClusterUUID, "cluster uuid is cached with the version")
+	assert.Equal(int32(1), atomic.LoadInt32(&probes))
+}
+
+func TestEsClientRegistry_SharedClients(t *testing.T) {
+	assert := assert.New(t)
+	var probes int32
+	server := newVersionServer(&probes)
+	defer server.Close()
+
+	registry := NewEsClientRegistry(time.Hour)
+	log := zap.New(zap.UseDevMode(true))
+	clientOf := func(sniffing *EsSniffing) interface{} {
+		esConfig, _ := registry.Connect(NewEsConnectionFromSecret(newUriSecret("secret", server.URL), "uri", nil, sniffing), "ElasticIndex/ns/secret")
+		es := &Elasticsearch7{}
+		assert.Nil(es.NewClient(esConfig, log))
+		return es.Client
+	}
+
+	first := clientOf(nil)
+	assert.True(first == clientOf(nil), "client is shared by reconciliations of the same connection")
+	assert.False(first == clientOf(&EsSniffing{Interval: time.Minute}), "sniffing clients are other clients")
+}
+
+func TestEsClientRegistry_StopNodeDiscovery(t *testing.T) {
+	assert := assert.New(t)
+	var discoveries int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/_nodes") {
+			atomic.AddInt32(&discoveries, 1)
+			fmt.Fprintf(w, `{"nodes":{"node-1":{"name":"node-1","roles":["master","data","ingest"],"http":{"publish_address":"%s"}}}}`,
+				strings.TrimPrefix(server.URL, "http://"))
+			return
+		}
+		fmt.Fprint(w, `{"version":{"number":"7.8.0"}}`)
+	}))
+	defer server.Close()
+
+	registry := NewEsClientRegistry(time.Hour)
+	esConfig, err := registry.Connect(NewEsConnectionFromSecret(newUriSecret("secret", server.URL), "uri", nil, &EsSniffing{Interval: 10 * time.Millisecond}), "ElasticIndex/ns/secret")
+	assert.Nil(err)
+	es := &Elasticsearch7{}
+	assert.Nil(es.NewClient(esConfig, zap.New(zap.UseDevMode(true))))
+
+	time.Sleep(100 * time.Millisecond)
+	assert.True(atomic.LoadInt32(&discoveries) > 1, "nodes are discovered periodically")
+
+	registry.Release("ElasticIndex/ns/secret")
+	time.Sleep(50 * time.Millisecond)
+	stopped := atomic.LoadInt32(&discoveries)
+	time.Sleep(100 * time.Millisecond)
+	assert.Equal(stopped, atomic.LoadInt32(&discoveries), "nodes are no longer discovered once the connection is removed")
+}
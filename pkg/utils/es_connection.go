@@ -0,0 +1,90 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	v1 "k8s.io/api/core/v1"
+	"strings"
+)
+
+// EsConnection is where and how to connect to an elasticsearch cluster, read from an elasticURI secret or from an
+// ElasticConnection
+type EsConnection struct {
+	// Ref identifies the connection in an EsClientRegistry
+	Ref string
+	// URI is a comma separated list of node URLs, or an Elastic Cloud ID
+	URI string
+	// Options holds the credentials, TLS, sniffing, timeout and HTTP configuration applied to the config of URI
+	Options *EsConfig
+	// ExpectedVersion is a prefix of the elasticsearch version number, like 8 or 8.4, any version being accepted when empty
+	ExpectedVersion string
+	// ResourceVersion of the kubernetes objects the connection is read from, models being applied again when it changes
+	ResourceVersion string
+}
+
+// NewEsConnectionFromSecret returns the connection of the elasticsearch uri of a secret key, authenticating with the
+// credentials of the secret when set
+func NewEsConnectionFromSecret(secret *v1.Secret, key string, tlsConfig *EsTLSConfig, sniffing *EsSniffing) *EsConnection {
+	options := &EsConfig{TLS: tlsConfig, Sniffing: sniffing}
+	ReadSecretCredentials(options, secret)
+	return &EsConnection{
+		Ref:             secretRef(secret.Namespace, secret.Name, key),
+		URI:             string(secret.Data[key]),
+		Options:         options,
+		ResourceVersion: secret.ResourceVersion,
+	}
+}
+
+// Parse returns the config of the connection, without detecting the elasticsearch version
+func (c *EsConnection) Parse() (*EsConfig, error) {
+	esConfig, err := ParseEsURI(c.URI)
+	if err != nil {
+		return nil, err
+	}
+	if options := c.Options; options != nil {
+		esConfig.TLS, esConfig.APIKey, esConfig.BearerToken = options.TLS, options.APIKey, options.BearerToken
+		esConfig.Sniffing, esConfig.RequestTimeout, esConfig.HTTP = options.Sniffing, options.RequestTimeout, options.HTTP
+		if options.Username != "" {
+			esConfig.Username, esConfig.Password = options.Username, options.Password
+		}
+	}
+	return esConfig, nil
+}
+
+// Probe returns the config of the connection with its detected version, connecting with a new transport
+func (c *EsConnection) Probe() (*EsConfig, error) {
+	esConfig, err := c.Parse()
+	if err != nil {
+		return nil, err
+	}
+	transport, err := newTransport(esConfig)
+	if err != nil {
+		return esConfig, err
+	}
+	if err := probeCluster(esConfig, transport); err != nil {
+		return esConfig, err
+	}
+	return esConfig, nil
+}
+
+// CheckVersion returns an error when the detected version of esConfig does not match the expected version
+func (c *EsConnection) CheckVersion(esConfig *EsConfig) error {
+	expected := c.ExpectedVersion
+	if expected == "" || esConfig.VersionNumber == expected || strings.HasPrefix(esConfig.VersionNumber, expected+".") {
+		return nil
+	}
+	return fmt.Errorf("elasticsearch version %v does not match expected version %v", esConfig.VersionNumber, expected)
+}
@@ -0,0 +1,173 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+// EsDataStream is a data stream read from GET _data_stream/<name>
+type EsDataStream struct {
+	Name string
+	// Generation is incremented by each rollover
+	Generation int64
+	// Template is the composable index template the data stream was created from
+	Template string
+	// Indices are the backing indices, the write index being the last one
+	Indices []string
+	// Lifecycle is the data stream lifecycle of elasticsearch 8.11 and later, nil when it is not enabled
+	Lifecycle *EsDataStreamLifecycle
+}
+
+// EsDataStreamLifecycle is the data stream lifecycle of a data stream
+type EsDataStreamLifecycle struct {
+	// DataRetention is the time the documents are kept, empty when they are kept forever
+	DataRetention string
+}
+
+// ParseDataStream returns the data stream named name from a GET _data_stream/<name> response, nil when it is missing
+func ParseDataStream(response string, name string) *EsDataStream {
+	for _, item := range gjson.Get(response, "data_streams").Array() {
+		if item.Get("name").String() != name {
+			continue
+		}
+		dataStream := &EsDataStream{
+			Name:       name,
+			Generation: item.Get("generation").Int(),
+			Template:   item.Get("template").String(),
+			Indices:    []string{},
+		}
+		for _, index := range item.Get("indices").Array() {
+			dataStream.Indices = append(dataStream.Indices, index.Get("index_name").String())
+		}
+		if lifecycle := item.Get("lifecycle"); lifecycle.IsObject() && lifecycle.Get("enabled").String() != "false" {
+			dataStream.Lifecycle = &EsDataStreamLifecycle{DataRetention: lifecycle.Get("data_retention").String()}
+		}
+		return dataStream
+	}
+	return nil
+}
+
+// BuildDataStreamModel returns the model of a data stream, {"lifecycle":{"data_retention":"30d"}} when its lifecycle is
+// managed, {} otherwise
+func BuildDataStreamModel(lifecycle *EsDataStreamLifecycle) string {
+	switch {
+	case lifecycle == nil:
+		return "{}"
+	case lifecycle.DataRetention == "":
+		return `{"lifecycle":{}}`
+	default:
+		return fmt.Sprintf(`{"lifecycle":{"data_retention":%q}}`, lifecycle.DataRetention)
+	}
+}
+
+// getDataStreamModelLifecycle returns the lifecycle of a data stream model, nil when the model does not manage it
+func getDataStreamModelLifecycle(model string) *EsDataStreamLifecycle {
+	lifecycle := gjson.Get(model, "lifecycle")
+	if !lifecycle.IsObject() {
+		return nil
+	}
+	return &EsDataStreamLifecycle{DataRetention: lifecycle.Get("data_retention").String()}
+}
+
+// GetDataStreamDriftReasons compares a data stream, nil when it does not exist, with its model, and returns what
+// differs. The lifecycle is only compared when the model manages it
+func GetDataStreamDriftReasons(live *EsDataStream, name string, model string) []string {
+	if live == nil {
+		return []string{fmt.Sprintf("data stream %v does not exist", name)}
+	}
+	expected := getDataStreamModelLifecycle(model)
+	switch {
+	case expected == nil:
+		return nil
+	case live.Lifecycle == nil:
+		return []string{"lifecycle is not enabled"}
+	case live.Lifecycle.DataRetention != expected.DataRetention:
+		return []string{"data_retention differs"}
+	}
+	return nil
+}
+
+// ValidateDataRetention checks the data_retention of a data stream lifecycle, a time value like 30d
+func ValidateDataRetention(dataRetention string) error {
+	// data retentions are time values like ILM min_age
+	if !ilmMinAgeRegex.MatchString(dataRetention) {
+		return fmt.Errorf("data_retention %q should be a time value, like 30d or 12h", dataRetention)
+	}
+	return nil
+}
+
+// GetDataStreamTemplate returns the composable index template applied to the data stream named name, from a
+// GET _index_template response: the matching template with the highest priority. dataStream is true when it has a
+// data_stream field, and templateName is empty when no template matches
+func GetDataStreamTemplate(templates string, name string) (templateName string, dataStream bool) {
+	models := map[string]string{}
+	for _, item := range gjson.Get(templates, "index_templates").Array() {
+		models[item.Get("name").String()] = item.Get("index_template").Raw
+	}
+	return GetDataStreamTemplateModel(models, name)
+}
+
+// GetDataStreamTemplateModel returns the composable index template applied to the data stream named name, among
+// template models by name including their priority and data_stream fields: the matching model with the highest
+// priority. dataStream is true when it has a data_stream field, and templateName is empty when no model matches
+func GetDataStreamTemplateModel(models map[string]string, name string) (templateName string, dataStream bool) {
+	names := make([]string, 0, len(models))
+	for templateName := range models {
+		names = append(names, templateName)
+	}
+	sort.Strings(names)
+
+	priority := int64(-1)
+	for _, item := range names {
+		template := gjson.Parse(models[item])
+		if !IndexPatternsMatch(getStrings(template.Get("index_patterns")), name) || template.Get("priority").Int() <= priority {
+			continue
+		}
+		priority = template.Get("priority").Int()
+		templateName = item
+		dataStream = template.Get("data_stream").Exists()
+	}
+	return templateName, dataStream
+}
+
+// TemplateModelMatches returns true when one of the index_patterns of a template model matches name
+func TemplateModelMatches(model string, name string) bool {
+	return IndexPatternsMatch(getStrings(gjson.Get(model, "index_patterns")), name)
+}
+
+// IndexPatternsMatch returns true when one of the index patterns, like logs-*, matches name
+func IndexPatternsMatch(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		quoted := strings.Split(pattern, "*")
+		for i := range quoted {
+			quoted[i] = regexp.QuoteMeta(quoted[i])
+		}
+		if regexp.MustCompile("^" + strings.Join(quoted, ".*") + "$").MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRolloverIndices returns the previous and the new write index of a POST <data stream>/_rollover response
+func GetRolloverIndices(response string) (oldIndex string, newIndex string) {
+	return gjson.Get(response, "old_index").String(), gjson.Get(response, "new_index").String()
+}
@@ -0,0 +1,208 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDataStream(t *testing.T) {
+	assert := assert.New(t)
+	response := `{"data_streams":[{"name":"logs-app","timestamp_field":{"name":"@timestamp"},"indices":[{"index_name":".ds-logs-app-2024.10.01-000001","index_uuid":"a"},
+		{"index_name":".ds-logs-app-2024.10.08-000002","index_uuid":"b"}],"generation":2,"status":"GREEN","template":"logs","lifecycle":{"enabled":true,"data_retention":"30d"}}]}`
+
+	assert.Equal(&EsDataStream{Name: "logs-app", Generation: 2, Template: "logs", Indices: []string{".ds-logs-app-2024.10.01-000001", ".ds-logs-app-2024.10.08-000002"},
+		Lifecycle: &EsDataStreamLifecycle{DataRetention: "30d"}}, ParseDataStream(response, "logs-app"))
+	assert.Nil(ParseDataStream(response, "logs-other"))
+
+	dataStream := ParseDataStream(`{"data_streams":[{"name":"logs-app","indices":[],"generation":1,"template":"logs","lifecycle":{"enabled":false}}]}`, "logs-app")
+	assert.Nil(dataStream.Lifecycle)
+	assert.Equal([]string{}, dataStream.Indices)
+}
+
+func TestBuildDataStreamModel(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(`{}`, BuildDataStreamModel(nil))
+	assert.Equal(`{"lifecycle":{}}`, BuildDataStreamModel(&EsDataStreamLifecycle{}))
+	assert.Equal(`{"lifecycle":{"data_retention":"7d"}}`, BuildDataStreamModel(&EsDataStreamLifecycle{DataRetention: "7d"}))
+}
+
+func TestGetDataStreamDriftReasons(t *testing.T) {
+	assert := assert.New(t)
+	live := &EsDataStream{Name: "logs-app", Lifecycle: &EsDataStreamLifecycle{DataRetention: "30d"}}
+
+	assert.Nil(GetDataStreamDriftReasons(live, "logs-app", `{"lifecycle":{"data_retention":"30d"}}`))
+	assert.Nil(GetDataStreamDriftReasons(live, "logs-app", `{}`), "lifecycles not managed by the model are not compared")
+	assert.Equal([]string{"data_retention differs"}, GetDataStreamDriftReasons(live, "logs-app", `{"lifecycle":{"data_retention":"7d"}}`))
+	assert.Equal([]string{"data_retention differs"}, GetDataStreamDriftReasons(live, "logs-app", `{"lifecycle":{}}`))
+	assert.Equal([]string{"lifecycle is not enabled"}, GetDataStreamDriftReasons(&EsDataStream{Name: "logs-app"}, "logs-app", `{"lifecycle":{}}`))
+	assert.Equal([]string{"data stream logs-app does not exist"}, GetDataStreamDriftReasons(nil, "logs-app", `{}`))
+}
+
+func TestValidateDataRetention(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(ValidateDataRetention("30d"))
+	assert.Nil(ValidateDataRetention("12h"))
+	assert.NotNil(ValidateDataRetention("30"))
+	assert.NotNil(ValidateDataRetention("one month"))
+}
+
+func TestGetDataStreamTemplate(t *testing.T) {
+	assert := assert.New(t)
+	templates := `{"index_templates":[
+		{"name":"logs","index_template":{"index_patterns":["logs-*"],"priority":100,"data_stream":{}}},
+		{"name":"logs-legacy","index_template":{"index_patterns":["logs-legacy-*"],"priority":200}},
+		{"name":"all","index_template":{"index_patterns":["*"]}}]}`
+
+	name, dataStream := GetDataStreamTemplate(templates, "logs-app")
+	assert.Equal("logs", name)
+	assert.True(dataStream)
+
+	name, dataStream = GetDataStreamTemplate(templates, "logs-legacy-app")
+	assert.Equal("logs-legacy", name)
+	assert.False(dataStream)
+
+	name, dataStream = GetDataStreamTemplate(templates, "metrics-app")
+	assert.Equal("all", name)
+	assert.False(dataStream)
+
+	name, _ = GetDataStreamTemplate(`{"index_templates":[]}`, "logs-app")
+	assert.Equal("", name)
+}
+
+func TestGetDataStreamTemplateModel(t *testing.T) {
+	assert := assert.New(t)
+	models := map[string]string{
+		"logs":      `{"index_patterns":["logs-*"],"priority":100,"data_stream":{}}`,
+		"logs-app":  `{"index_patterns":["logs-app*"],"priority":150}`,
+		"logs-prod": `{"index_patterns":["logs-*-prod"],"priority":50}`,
+	}
+
+	name, dataStream := GetDataStreamTemplateModel(models, "logs-app")
+	assert.Equal("logs-app", name, "highest priority")
+	assert.False(dataStream)
+
+	name, dataStream = GetDataStreamTemplateModel(models, "logs-web-prod")
+	assert.Equal("logs", name)
+	assert.True(dataStream)
+
+	name, _ = GetDataStreamTemplateModel(models, "metrics-app")
+	assert.Equal("", name)
+}
+
+func TestIndexPatternsMatch(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.True(IndexPatternsMatch([]string{"metrics-*", "logs-*"}, "logs-app"))
+	assert.True(IndexPatternsMatch([]string{"logs-*-prod"}, "logs-app-prod"))
+	assert.True(IndexPatternsMatch([]string{"logs.app"}, "logs.app"))
+	assert.False(IndexPatternsMatch([]string{"logs.app"}, "logsxapp"))
+	assert.False(IndexPatternsMatch([]string{"logs-*"}, "app-logs-1"))
+	assert.False(IndexPatternsMatch(nil, "logs-app"))
+	assert.True(TemplateModelMatches(`{"index_patterns":["logs-*"],"template":{}}`, "logs-app"))
+}
+
+func TestGetRolloverIndices(t *testing.T) {
+	assert := assert.New(t)
+
+	oldIndex, newIndex := GetRolloverIndices(`{"acknowledged":true,"old_index":".ds-logs-app-2024.10.01-000001","new_index":".ds-logs-app-2024.10.17-000002","rolled_over":true}`)
+	assert.Equal(".ds-logs-app-2024.10.01-000001", oldIndex)
+	assert.Equal(".ds-logs-app-2024.10.17-000002", newIndex)
+}
+
+// dataStreamBackend is a backend with the composable index templates the data streams are created from
+type dataStreamBackend interface {
+	EsIndexTemplates
+	EsDataStreams
+}
+
+// testDataStreamBackend creates, rolls over and deletes a data stream on a test cluster. The data retention of its
+// lifecycle is applied when the cluster has data stream lifecycles, and rejected otherwise
+func testDataStreamBackend(t *testing.T, elasticsearch dataStreamBackend, lifecycle bool) {
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	templateName, dataStreamName := "k8s_epo_test_stream_template", "k8s_epo_test_stream"
+	defer elasticsearch.DeleteIndexTemplate(ctx, templateName)
+	defer elasticsearch.DeleteDataStream(ctx, dataStreamName)
+	model, updatedModel := "{}", "{}"
+	expectOperations := []string{OperationDataStreamCreated}
+	if lifecycle {
+		model, updatedModel = `{"lifecycle": {"data_retention": "30d"}}`, `{"lifecycle": {"data_retention": "7d"}}`
+		expectOperations = []string{OperationDataStreamCreated, OperationDataLifecyclePut}
+	}
+
+	_, err := elasticsearch.CreateOrUpdateDataStream(ctx, dataStreamName, model)
+	assert.NotNil(err, "no template with data_stream matches")
+	_, err = elasticsearch.CreateOrUpdateIndexTemplate(ctx, templateName, `{"index_patterns": ["k8s_epo_test_stream*"], "data_stream": {}, "priority": 500}`)
+	assert.Nil(err)
+	template, dataStream, err := elasticsearch.GetDataStreamTemplate(ctx, dataStreamName)
+	assert.Nil(err)
+	assert.Equal(templateName, template)
+	assert.True(dataStream)
+
+	drift, err := elasticsearch.GetDataStreamDrift(ctx, dataStreamName, model)
+	assert.Nil(err)
+	assert.Equal([]string{"data stream k8s_epo_test_stream does not exist"}, drift.Reasons)
+	status, err := elasticsearch.CreateOrUpdateDataStream(ctx, dataStreamName, model)
+	assert.Nil(err)
+	assert.Equal(expectOperations, status.Operations)
+	drift, err = elasticsearch.GetDataStreamDrift(ctx, dataStreamName, model)
+	assert.Nil(err)
+	assert.False(drift.IsDrifted())
+	status, err = elasticsearch.CreateOrUpdateDataStream(ctx, dataStreamName, model)
+	assert.Nil(err)
+	assert.Empty(status.Operations, "already up to date")
+
+	if lifecycle {
+		drift, err = elasticsearch.GetDataStreamDrift(ctx, dataStreamName, updatedModel)
+		assert.Nil(err)
+		assert.Equal([]string{"data_retention differs"}, drift.Reasons)
+		status, err = elasticsearch.CreateOrUpdateDataStream(ctx, dataStreamName, updatedModel)
+		assert.Nil(err)
+		assert.Equal([]string{OperationDataLifecyclePut}, status.Operations)
+	} else {
+		_, err = elasticsearch.CreateOrUpdateDataStream(ctx, dataStreamName, `{"lifecycle": {"data_retention": "30d"}}`)
+		assert.NotNil(err, "data stream lifecycles require elasticsearch 8.11")
+	}
+
+	status, err = elasticsearch.RolloverDataStream(ctx, dataStreamName)
+	assert.Nil(err)
+	assert.Equal([]string{OperationRolledOver}, status.Operations)
+	live, err := elasticsearch.GetDataStream(ctx, dataStreamName)
+	assert.Nil(err)
+	assert.Equal(int64(2), live.Generation)
+	assert.Equal(templateName, live.Template)
+	assert.Len(live.Indices, 2)
+	if lifecycle {
+		assert.Equal(&EsDataStreamLifecycle{DataRetention: "7d"}, live.Lifecycle)
+	} else {
+		assert.Nil(live.Lifecycle)
+	}
+	drift, err = elasticsearch.GetDataStreamDrift(ctx, dataStreamName, updatedModel)
+	assert.Nil(err)
+	assert.False(drift.IsDrifted(), "rollover keeps the lifecycle")
+
+	assert.Nil(elasticsearch.DeleteDataStream(ctx, dataStreamName))
+	live, err = elasticsearch.GetDataStream(ctx, dataStreamName)
+	assert.Nil(err)
+	assert.Nil(live)
+	assert.Nil(elasticsearch.DeleteDataStream(ctx, dataStreamName), "already deleted")
+}
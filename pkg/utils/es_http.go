@@ -0,0 +1,129 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// EsHTTPConfig customizes the HTTP requests sent to an elasticsearch cluster
+type EsHTTPConfig struct {
+	// ProxyURL of the http, https or socks5 proxy the cluster is reached through, the cluster being reached directly
+	// when empty
+	ProxyURL string
+	// Headers added to every request, like a tenant id expected by a gateway. An Authorization header is only sent
+	// when the connection has no credentials
+	Headers map[string]string
+	// Compression gzips the request bodies
+	Compression bool
+	// UserAgent of the requests, the one of the elasticsearch client when empty
+	UserAgent string
+}
+
+// Hash returns a hash of the configuration, empty when nil
+func (c *EsHTTPConfig) Hash() string {
+	if c == nil {
+		return ""
+	}
+	var headers []string
+	for name, value := range c.Headers {
+		headers = append(headers, http.CanonicalHeaderKey(name)+"="+value)
+	}
+	sort.Strings(headers)
+	return HashString(fmt.Sprintf("%s|%s|%v|%s", c.ProxyURL, strings.Join(headers, "\n"), c.Compression, c.UserAgent))
+}
+
+// GetProxy returns the proxy function of an http.Transport, nil when the cluster is reached directly
+func (c *EsHTTPConfig) GetProxy() (func(*http.Request) (*url.URL, error), error) {
+	if c == nil || c.ProxyURL == "" {
+		return nil, nil
+	}
+	proxyURL, err := url.Parse(c.ProxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %v", err)
+	}
+	if !ContainsString([]string{"http", "https", "socks5"}, proxyURL.Scheme) || proxyURL.Host == "" {
+		return nil, fmt.Errorf("invalid proxy url %q: expected http, https or socks5 scheme and a host", c.ProxyURL)
+	}
+	return http.ProxyURL(proxyURL), nil
+}
+
+// isEmpty returns true when the configuration changes nothing to the requests
+func (c *EsHTTPConfig) isEmpty() bool {
+	return c == nil || (len(c.Headers) == 0 && !c.Compression && c.UserAgent == "")
+}
+
+// httpTransport sets the headers and the User-Agent of the requests, and gzips their bodies. It wraps the authTransport,
+// whose Authorization header takes precedence
+type httpTransport struct {
+	next   http.RoundTripper
+	config *EsHTTPConfig
+}
+
+func (t *httpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range t.config.Headers {
+		if http.CanonicalHeaderKey(name) == "Authorization" && req.Header.Get("Authorization") != "" {
+			continue
+		}
+		req.Header.Set(name, value)
+	}
+	if t.config.UserAgent != "" {
+		req.Header.Set("User-Agent", t.config.UserAgent)
+	}
+	if t.config.Compression && req.Body != nil && req.Body != http.NoBody && req.Header.Get("Content-Encoding") == "" {
+		if err := gzipBody(req); err != nil {
+			return nil, err
+		}
+	}
+	return t.next.RoundTrip(req)
+}
+
+func (t *httpTransport) CloseIdleConnections() {
+	closeIdleConnections(t.next)
+}
+
+// gzipBody replaces the body of a request by its gzip compression
+func gzipBody(req *http.Request) error {
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(body); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	compressed := buffer.Bytes()
+	req.Body = ioutil.NopCloser(bytes.NewReader(compressed))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(compressed)), nil
+	}
+	req.ContentLength = int64(len(compressed))
+	req.Header.Set("Content-Encoding", "gzip")
+	return nil
+}
@@ -0,0 +1,134 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"strings"
+	"testing"
+)
+
+func TestEsHTTPConfig_GetProxy(t *testing.T) {
+	assert := assert.New(t)
+
+	proxy, err := (*EsHTTPConfig)(nil).GetProxy()
+	assert.Nil(err)
+	assert.Nil(proxy, "the cluster is reached directly by default")
+
+	proxy, err = (&EsHTTPConfig{ProxyURL: "http://proxy.corp:3128"}).GetProxy()
+	assert.Nil(err)
+	request, _ := http.NewRequest(http.MethodGet, "https://elastic:9200", nil)
+	proxyURL, err := proxy(request)
+	assert.Nil(err)
+	assert.Equal("http://proxy.corp:3128", proxyURL.String())
+
+	for _, invalid := range []string{"proxy.corp:3128", "ftp://proxy.corp", "http://"} {
+		_, err = (&EsHTTPConfig{ProxyURL: invalid}).GetProxy()
+		assert.NotNil(err, invalid)
+	}
+}
+
+func TestEsHTTPConfig_Hash(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("", (*EsHTTPConfig)(nil).Hash())
+	first := &EsHTTPConfig{Headers: map[string]string{"X-Tenant": "a", "X-Team": "b"}}
+	second := &EsHTTPConfig{Headers: map[string]string{"x-team": "b", "x-tenant": "a"}}
+	assert.Equal(first.Hash(), second.Hash(), "header names are case insensitive")
+	assert.NotEqual(first.Hash(), (&EsHTTPConfig{Headers: first.Headers, Compression: true}).Hash())
+}
+
+func TestEsConfig_EsVersion_HTTP(t *testing.T) {
+	assert := assert.New(t)
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		fmt.Fprint(w, `{"version":{"number":"8.4.0"}}`)
+	}))
+	defer server.Close()
+
+	conf := &EsConfig{HTTP: &EsHTTPConfig{Headers: map[string]string{"X-Tenant": "team-a"}, UserAgent: "phenix"}}
+	version, err := conf.EsVersion(server.URL)
+	assert.Nil(err)
+	assert.Equal(8, version)
+	assert.Equal("team-a", headers.Get("X-Tenant"))
+	assert.Equal("phenix", headers.Get("User-Agent"))
+
+	conf = &EsConfig{Username: "elastic", Password: "pass", HTTP: &EsHTTPConfig{Headers: map[string]string{"Authorization": "Gateway token"}}}
+	_, err = conf.EsVersion(server.URL)
+	assert.Nil(err)
+	assert.True(strings.HasPrefix(headers.Get("Authorization"), "Basic "), "credentials take precedence")
+
+	conf = &EsConfig{HTTP: &EsHTTPConfig{Headers: map[string]string{"Authorization": "Gateway token"}}}
+	_, err = conf.EsVersion(server.URL)
+	assert.Nil(err)
+	assert.Equal("Gateway token", headers.Get("Authorization"), "sent when the connection has no credentials")
+}
+
+func TestEsConfig_EsVersion_Proxy(t *testing.T) {
+	assert := assert.New(t)
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+		fmt.Fprint(w, `{"version":{"number":"7.8.0"}}`)
+	}))
+	defer proxy.Close()
+
+	version, err := (&EsConfig{HTTP: &EsHTTPConfig{ProxyURL: proxy.URL}}).EsVersion("http://elastic.unreachable:9200")
+	assert.Nil(err)
+	assert.Equal(7, version)
+	assert.Equal("http://elastic.unreachable:9200/", proxied, "the proxy receives the absolute url")
+}
+
+func TestHTTPTransport_Elasticsearch7(t *testing.T) {
+	assert := assert.New(t)
+	var headers http.Header
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			fmt.Fprint(w, `{"version":{"number":"7.8.0"}}`)
+			return
+		}
+		headers = r.Header.Clone()
+		reader, err := gzip.NewReader(r.Body)
+		if assert.Nil(err) {
+			decompressed, _ := ioutil.ReadAll(reader)
+			body = string(decompressed)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"acknowledged":true}`)
+	}))
+	defer server.Close()
+
+	conf := &EsConfig{HTTP: &EsHTTPConfig{Headers: map[string]string{"X-Tenant": "team-a"}, Compression: true, UserAgent: "phenix"}}
+	esConfig, err := conf.FromURI(server.URL)
+	assert.Nil(err)
+	es := &Elasticsearch7{}
+	assert.Nil(es.NewClient(esConfig, zap.New(zap.UseDevMode(true))))
+
+	_, err = es.CreateOrUpdateTemplate(context.Background(), "template", `{"index_patterns":["logs-*"]}`, nil)
+	assert.Nil(err)
+	assert.Equal("gzip", headers.Get("Content-Encoding"))
+	assert.Equal("team-a", headers.Get("X-Tenant"))
+	assert.Equal("phenix", headers.Get("User-Agent"))
+	assert.JSONEq(`{"index_patterns":["logs-*"]}`, body)
+}
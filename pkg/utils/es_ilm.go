@@ -0,0 +1,205 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	funk "github.com/thoas/go-funk"
+	"github.com/tidwall/gjson"
+)
+
+// ilmPhases are the phases of an ILM policy in their execution order, with the actions allowed in each of them
+var ilmPhases = []struct {
+	name    string
+	actions []string
+}{
+	{"hot", []string{"set_priority", "unfollow", "rollover", "readonly", "downsample", "shrink", "forcemerge", "searchable_snapshot"}},
+	{"warm", []string{"set_priority", "unfollow", "readonly", "downsample", "allocate", "migrate", "shrink", "forcemerge"}},
+	{"cold", []string{"set_priority", "unfollow", "readonly", "downsample", "searchable_snapshot", "allocate", "migrate", "freeze"}},
+	{"frozen", []string{"unfollow", "searchable_snapshot"}},
+	{"delete", []string{"wait_for_snapshot", "delete"}},
+}
+
+// ilmHotActionsRequiringRollover are the actions of the hot phase that elasticsearch only accepts with a rollover action
+var ilmHotActionsRequiringRollover = []string{"downsample", "forcemerge", "searchable_snapshot", "shrink"}
+
+var ilmMinAgeRegex = regexp.MustCompile(`^\d+(d|h|m|s|ms|micros|nanos)$`)
+
+// ValidateILMPolicy checks the phases and the actions of an ILM policy model, a PUT _ilm/policy body like
+// {"policy":{"phases":{"hot":{"actions":{"rollover":{"max_age":"1d"}}}}}}
+func ValidateILMPolicy(model string) error {
+	policy := gjson.Get(model, "policy")
+	if !policy.IsObject() {
+		return fmt.Errorf("ILMPolicy model policy should be an object")
+	}
+	for key := range policy.Map() {
+		if key != "phases" && key != "_meta" {
+			return fmt.Errorf("ILMPolicy model policy should contain only fields from this list [phases _meta]")
+		}
+	}
+	phases := policy.Get("phases")
+	if !phases.IsObject() || len(phases.Map()) == 0 {
+		return fmt.Errorf("ILMPolicy model policy should contain at least one phase")
+	}
+
+	var phaseNames []string
+	for _, phase := range ilmPhases {
+		phaseNames = append(phaseNames, phase.name)
+	}
+	for name, phase := range phases.Map() {
+		index := funk.IndexOfString(phaseNames, name)
+		if index < 0 {
+			return fmt.Errorf("ILMPolicy phase %v should be one of %v", name, phaseNames)
+		}
+		if err := validateILMPhase(name, phase, ilmPhases[index].actions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateILMPhase(name string, phase gjson.Result, allowedActions []string) error {
+	if !phase.IsObject() {
+		return fmt.Errorf("ILMPolicy phase %v should be an object", name)
+	}
+	for key, value := range phase.Map() {
+		switch key {
+		case "min_age":
+			if !ilmMinAgeRegex.MatchString(value.String()) {
+				return fmt.Errorf("ILMPolicy phase %v min_age %v should be a time value like 30d, 12h or 0ms", name, value.Raw)
+			}
+		case "actions":
+			if !value.IsObject() {
+				return fmt.Errorf("ILMPolicy phase %v actions should be an object", name)
+			}
+		default:
+			return fmt.Errorf("ILMPolicy phase %v should contain only fields from this list [min_age actions]", name)
+		}
+	}
+
+	actions := phase.Get("actions").Map()
+	for action, body := range actions {
+		if !funk.ContainsString(allowedActions, action) {
+			return fmt.Errorf("ILMPolicy action %v is not allowed in the %v phase, allowed actions are %v", action, name, allowedActions)
+		}
+		if !body.IsObject() {
+			return fmt.Errorf("ILMPolicy action %v of the %v phase should be an object", action, name)
+		}
+	}
+	if rollover, ok := actions["rollover"]; ok {
+		hasCondition := false
+		for condition := range rollover.Map() {
+			hasCondition = hasCondition || strings.HasPrefix(condition, "max_")
+		}
+		if !hasCondition {
+			return fmt.Errorf("ILMPolicy rollover action of the %v phase should contain a max_* condition", name)
+		}
+	} else if name == "hot" {
+		for _, action := range ilmHotActionsRequiringRollover {
+			if _, ok := actions[action]; ok {
+				return fmt.Errorf("ILMPolicy action %v of the hot phase requires a rollover action", action)
+			}
+		}
+	}
+	return nil
+}
+
+// GetILMPolicyDriftReasons compares an ILM policy, from a GET _ilm/policy/<name> response, with its model, and returns
+// what differs. Only the fields of the model actions are compared, elasticsearch adding their default values
+func GetILMPolicyDriftReasons(livePolicies string, policyName string, model string) []string {
+	live := gjson.Get(livePolicies, escapePath(policyName)+".policy")
+	if !live.Exists() {
+		return []string{fmt.Sprintf("ILM policy %v does not exist", policyName)}
+	}
+	expected := gjson.Get(model, "policy")
+
+	var reasons []string
+	livePhases, expectedPhases := live.Get("phases").Map(), expected.Get("phases").Map()
+	for _, phase := range ilmPhases {
+		livePhase, liveExists := livePhases[phase.name]
+		expectedPhase, expectedExists := expectedPhases[phase.name]
+		if !liveExists && !expectedExists {
+			continue
+		} else if !liveExists {
+			reasons = append(reasons, fmt.Sprintf("phase %v is missing", phase.name))
+			continue
+		} else if !expectedExists {
+			reasons = append(reasons, fmt.Sprintf("phase %v is not in the model", phase.name))
+			continue
+		}
+		reasons = append(reasons, getILMPhaseDriftReasons(phase.name, livePhase, expectedPhase)...)
+	}
+	if jsonDiffers(live.Get("_meta"), expected.Get("_meta")) {
+		reasons = append(reasons, "_meta differs")
+	}
+	return reasons
+}
+
+func getILMPhaseDriftReasons(name string, live gjson.Result, expected gjson.Result) []string {
+	var reasons []string
+	expectedMinAge := "0ms"
+	if expected.Get("min_age").Exists() {
+		expectedMinAge = expected.Get("min_age").String()
+	}
+	if liveMinAge := live.Get("min_age").String(); liveMinAge != expectedMinAge {
+		reasons = append(reasons, fmt.Sprintf("min_age of phase %v is %v instead of %v", name, liveMinAge, expectedMinAge))
+	}
+
+	liveActions, expectedActions := live.Get("actions").Map(), expected.Get("actions").Map()
+	var actions []string
+	for action := range liveActions {
+		actions = append(actions, action)
+	}
+	for action := range expectedActions {
+		if _, ok := liveActions[action]; !ok {
+			actions = append(actions, action)
+		}
+	}
+	sort.Strings(actions)
+	for _, action := range actions {
+		liveAction, liveExists := liveActions[action]
+		expectedAction, expectedExists := expectedActions[action]
+		if !liveExists {
+			reasons = append(reasons, fmt.Sprintf("action %v of phase %v is missing", action, name))
+		} else if !expectedExists {
+			reasons = append(reasons, fmt.Sprintf("action %v of phase %v is not in the model", action, name))
+		} else {
+			for field, value := range expectedAction.Map() {
+				if jsonDiffers(liveAction.Get(escapePath(field)), value) {
+					reasons = append(reasons, fmt.Sprintf("action %v of phase %v differs", action, name))
+					break
+				}
+			}
+		}
+	}
+	return reasons
+}
+
+// GetILMPolicyIndices returns the sorted names of the indices managed by an ILM policy, from a GET <index>/_ilm/explain
+// response
+func GetILMPolicyIndices(explain string, policyName string) []string {
+	indices := []string{}
+	for name, index := range gjson.Get(explain, "indices").Map() {
+		if index.Get("policy").String() == policyName {
+			indices = append(indices, name)
+		}
+	}
+	sort.Strings(indices)
+	return indices
+}
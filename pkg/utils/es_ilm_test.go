@@ -0,0 +1,85 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateILMPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	scenarios := []struct {
+		model string
+		error string
+	}{
+		{model: `{"policy":{"phases":{"hot":{"actions":{"rollover":{"max_primary_shard_size":"50gb"},"forcemerge":{"max_num_segments":1}}},"warm":{"min_age":"7d","actions":{"allocate":{"number_of_replicas":1}}},"delete":{"min_age":"30d","actions":{"delete":{}}}},"_meta":{"owner":"team-a"}}}`},
+		{model: `{"policy":"logs"}`, error: "ILMPolicy model policy should be an object"},
+		{model: `{"policy":{"phases":{},"name":"logs"}}`, error: "ILMPolicy model policy should contain only fields from this list [phases _meta]"},
+		{model: `{"policy":{"phases":{}}}`, error: "ILMPolicy model policy should contain at least one phase"},
+		{model: `{"policy":{"phases":{"archive":{"actions":{}}}}}`, error: "ILMPolicy phase archive should be one of [hot warm cold frozen delete]"},
+		{model: `{"policy":{"phases":{"warm":{"min_age":"1 week","actions":{}}}}}`, error: `ILMPolicy phase warm min_age "1 week" should be a time value like 30d, 12h or 0ms`},
+		{model: `{"policy":{"phases":{"warm":{"after":"1d","actions":{}}}}}`, error: "ILMPolicy phase warm should contain only fields from this list [min_age actions]"},
+		{model: `{"policy":{"phases":{"delete":{"actions":{"rollover":{"max_age":"1d"}}}}}}`, error: "ILMPolicy action rollover is not allowed in the delete phase, allowed actions are [wait_for_snapshot delete]"},
+		{model: `{"policy":{"phases":{"delete":{"actions":{"delete":true}}}}}`, error: "ILMPolicy action delete of the delete phase should be an object"},
+		{model: `{"policy":{"phases":{"hot":{"actions":{"rollover":{"min_docs":1}}}}}}`, error: "ILMPolicy rollover action of the hot phase should contain a max_* condition"},
+		{model: `{"policy":{"phases":{"hot":{"actions":{"shrink":{"number_of_shards":1}}}}}}`, error: "ILMPolicy action shrink of the hot phase requires a rollover action"},
+	}
+
+	for _, scenario := range scenarios {
+		err := ValidateILMPolicy(scenario.model)
+		if scenario.error == "" {
+			assert.Nil(err, scenario.model)
+		} else if assert.NotNil(err, scenario.model) {
+			assert.Equal(scenario.error, err.Error())
+		}
+	}
+}
+
+func TestGetILMPolicyDriftReasons(t *testing.T) {
+	assert := assert.New(t)
+	live := `{"logs":{"version":2,"modified_date":"2024-01-01T00:00:00.000Z","policy":{"phases":{
+		"hot":{"min_age":"0ms","actions":{"rollover":{"max_age":"1d","max_primary_shard_size":"50gb"},"set_priority":{"priority":100}}},
+		"delete":{"min_age":"30d","actions":{"delete":{"delete_searchable_snapshot":true}}}},"_meta":{"owner":"team-a"}}}}`
+
+	model := `{"policy":{"phases":{"hot":{"actions":{"rollover":{"max_age":"1d","max_primary_shard_size":"50gb"},"set_priority":{"priority":100}}},
+		"delete":{"min_age":"30d","actions":{"delete":{}}}},"_meta":{"owner":"team-a"}}}`
+	assert.Nil(GetILMPolicyDriftReasons(live, "logs", model))
+
+	model = `{"policy":{"phases":{"hot":{"min_age":"1h","actions":{"rollover":{"max_age":"7d"},"forcemerge":{"max_num_segments":1}}},
+		"warm":{"actions":{"readonly":{}}}}}}`
+	assert.Equal([]string{
+		"min_age of phase hot is 0ms instead of 1h",
+		"action forcemerge of phase hot is missing",
+		"action rollover of phase hot differs",
+		"action set_priority of phase hot is not in the model",
+		"phase warm is missing",
+		"phase delete is not in the model",
+		"_meta differs",
+	}, GetILMPolicyDriftReasons(live, "logs", model))
+
+	assert.Equal([]string{"ILM policy metrics does not exist"}, GetILMPolicyDriftReasons(live, "metrics", model))
+}
+
+func TestGetILMPolicyIndices(t *testing.T) {
+	assert := assert.New(t)
+	explain := `{"indices":{"logs-000002":{"policy":"logs"},".ds-logs-app-000001":{"policy":"logs"},"metrics-000001":{"policy":"metrics"}}}`
+
+	assert.Equal([]string{".ds-logs-app-000001", "logs-000002"}, GetILMPolicyIndices(explain, "logs"))
+	assert.Equal([]string{}, GetILMPolicyIndices(explain, "traces"))
+	assert.Equal([]string{}, GetILMPolicyIndices(`{}`, "logs"))
+}
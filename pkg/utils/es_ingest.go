@@ -0,0 +1,118 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/tidwall/gjson"
+)
+
+// ingestPipelineFields are the fields of an ingest pipeline compared on resync, with the drift reason of each of them
+var ingestPipelineFields = []struct {
+	name   string
+	reason string
+}{
+	{"description", "description differs"},
+	{"processors", "processors differ"},
+	{"on_failure", "on_failure differs"},
+	{"version", "version differs"},
+	{"_meta", "_meta differs"},
+}
+
+// GetIngestPipelineDriftReasons compares an ingest pipeline, from a GET _ingest/pipeline/<id> response, with its model,
+// and returns what differs
+func GetIngestPipelineDriftReasons(livePipelines string, pipelineName string, model string) []string {
+	live := gjson.Get(livePipelines, escapePath(pipelineName))
+	if !live.Exists() {
+		return []string{fmt.Sprintf("ingest pipeline %v does not exist", pipelineName)}
+	}
+	expected := gjson.Parse(model)
+
+	var reasons []string
+	for _, field := range ingestPipelineFields {
+		if jsonDiffers(live.Get(field.name), expected.Get(field.name)) {
+			reasons = append(reasons, field.reason)
+		}
+	}
+	return reasons
+}
+
+// GetIngestPipelineVersion returns the version of an ingest pipeline from a GET _ingest/pipeline/<id> response, nil
+// when the pipeline has no version
+func GetIngestPipelineVersion(livePipelines string, pipelineName string) *int64 {
+	version := gjson.Get(livePipelines, escapePath(pipelineName)+".version")
+	if !version.Exists() {
+		return nil
+	}
+	value := version.Int()
+	return &value
+}
+
+// BuildSimulateBody returns the body of a POST _ingest/pipeline/_simulate request, running the documents through the
+// pipeline model. Each document is the _source of a simulated document
+func BuildSimulateBody(model string, documents []string) (string, error) {
+	type simulatedDocument struct {
+		Source json.RawMessage `json:"_source"`
+	}
+	body := struct {
+		Pipeline json.RawMessage     `json:"pipeline"`
+		Docs     []simulatedDocument `json:"docs"`
+	}{Pipeline: json.RawMessage(model), Docs: []simulatedDocument{}}
+	for _, document := range documents {
+		body.Docs = append(body.Docs, simulatedDocument{Source: json.RawMessage(document)})
+	}
+	result, err := json.Marshal(body)
+	if err != nil {
+		return "", err
+	}
+	return string(result), nil
+}
+
+// GetSimulateFailures returns, for each of the documents of a POST _ingest/pipeline/_simulate response, the reason of
+// its failure, empty when the document was processed
+func GetSimulateFailures(response string, documents int) []string {
+	failures := make([]string, documents)
+	for i, document := range gjson.Get(response, "docs").Array() {
+		if i >= documents {
+			break
+		}
+		if failure := document.Get("error"); failure.Exists() {
+			failures[i] = getErrorReason(failure)
+		}
+	}
+	return failures
+}
+
+// getErrorReason returns the reason of an elasticsearch error, prefixed by its type
+func getErrorReason(failure gjson.Result) string {
+	if !failure.IsObject() {
+		return failure.String()
+	}
+	if failure.Get("reason").Exists() {
+		return fmt.Sprintf("%v: %v", failure.Get("type").String(), failure.Get("reason").String())
+	}
+	return failure.Raw
+}
+
+// GetResponseError returns the reason of the error of an elasticsearch response body, or the body when it is not an
+// elasticsearch error
+func GetResponseError(response string) string {
+	if failure := gjson.Get(response, "error"); failure.Exists() {
+		return getErrorReason(failure)
+	}
+	return response
+}
@@ -0,0 +1,67 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetIngestPipelineDriftReasons(t *testing.T) {
+	assert := assert.New(t)
+	live := `{"logs":{"description":"parse logs","processors":[{"set":{"field":"env","value":"prod"}}],"version":2}}`
+
+	assert.Nil(GetIngestPipelineDriftReasons(live, "logs", `{"description":"parse logs","processors":[{"set":{"field":"env","value":"prod"}}],"version":2}`))
+	assert.Equal([]string{"description differs", "processors differ", "on_failure differs", "version differs", "_meta differs"},
+		GetIngestPipelineDriftReasons(live, "logs", `{"processors":[{"set":{"field":"env","value":"dev"}}],"on_failure":[{"set":{"field":"error","value":"{{ _ingest.on_failure_message }}"}}],"_meta":{"owner":"team-a"}}`))
+	assert.Equal([]string{"ingest pipeline metrics does not exist"}, GetIngestPipelineDriftReasons(live, "metrics", `{"processors":[]}`))
+}
+
+func TestGetIngestPipelineVersion(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal(int64(2), *GetIngestPipelineVersion(`{"logs":{"processors":[],"version":2}}`, "logs"))
+	assert.Nil(GetIngestPipelineVersion(`{"logs":{"processors":[]}}`, "logs"))
+	assert.Nil(GetIngestPipelineVersion(`{}`, "logs"))
+}
+
+func TestBuildSimulateBody(t *testing.T) {
+	assert := assert.New(t)
+
+	body, err := BuildSimulateBody(`{"processors":[{"lowercase":{"field":"level"}}]}`, []string{`{"level":"INFO"}`, `{"message":"no level"}`})
+	assert.Nil(err)
+	assert.JSONEq(`{"pipeline":{"processors":[{"lowercase":{"field":"level"}}]},"docs":[{"_source":{"level":"INFO"}},{"_source":{"message":"no level"}}]}`, body)
+
+	_, err = BuildSimulateBody(`{"processors":[]}`, []string{`not json`})
+	assert.NotNil(err)
+}
+
+func TestGetSimulateFailures(t *testing.T) {
+	assert := assert.New(t)
+	response := `{"docs":[{"doc":{"_source":{"level":"info"}}},{"error":{"root_cause":[{"type":"illegal_argument_exception","reason":"field [level] not present as part of path [level]"}],
+		"type":"illegal_argument_exception","reason":"field [level] not present as part of path [level]"}}]}`
+
+	assert.Equal([]string{"", "illegal_argument_exception: field [level] not present as part of path [level]"}, GetSimulateFailures(response, 2))
+	assert.Equal([]string{"", ""}, GetSimulateFailures(`{"docs":[]}`, 2))
+}
+
+func TestGetResponseError(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("parse_exception: No processor type exists with name [unknown]",
+		GetResponseError(`{"error":{"type":"parse_exception","reason":"No processor type exists with name [unknown]"},"status":400}`))
+	assert.Equal("Unauthorized", GetResponseError("Unauthorized"))
+}
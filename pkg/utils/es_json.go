@@ -17,7 +17,11 @@ package utils
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 
 	funk "github.com/thoas/go-funk"
 	"github.com/tidwall/gjson"
@@ -47,20 +51,372 @@ func IsValidUpdateProperties(oldProperties string, newProperties string) bool {
 	return false
 }
 
+// IsPropertiesTypeUpdated returns true when at least one existing field changes its type, which cannot be applied in place
+func IsPropertiesTypeUpdated(oldProperties string, newProperties string) bool {
+	for field, oldBody := range gjson.Get(oldProperties, "properties").Map() {
+		newBody := gjson.Get(newProperties, fmt.Sprintf("properties.%v", escapePath(field)))
+		if !newBody.Exists() {
+			continue
+		}
+		if oldBody.Get("type").String() != newBody.Get("type").String() {
+			return true
+		}
+		if oldBody.Get("properties").Exists() && newBody.Get("properties").Exists() &&
+			IsPropertiesTypeUpdated(oldBody.Raw, newBody.Raw) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetMissingOrUpdatedProperties returns paths of model fields that are missing in the live properties, or whose type differs.
+// Other field parameters are not compared, because elasticsearch does not return parameters set to their default value
+func GetMissingOrUpdatedProperties(liveProperties string, modelProperties string) []string {
+	var fields []string
+	for field, modelBody := range gjson.Get(modelProperties, "properties").Map() {
+		liveBody := gjson.Get(liveProperties, fmt.Sprintf("properties.%v", escapePath(field)))
+		if !liveBody.Exists() || getFieldType(liveBody) != getFieldType(modelBody) {
+			fields = append(fields, field)
+			continue
+		}
+		if modelBody.Get("properties").Exists() {
+			for _, subField := range GetMissingOrUpdatedProperties(liveBody.Raw, modelBody.Raw) {
+				fields = append(fields, fmt.Sprintf("%v.%v", field, subField))
+			}
+		}
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// GetTemplateDriftReasons compares a template from a GET _template/<name> response with the model and the order,
+// and returns what differs
+func GetTemplateDriftReasons(liveTemplates string, templateName string, model string, order *int) ([]string, error) {
+	var reasons []string
+	live := gjson.Get(liveTemplates, escapePath(templateName))
+	if !live.Exists() {
+		return []string{fmt.Sprintf("template %v does not exist", templateName)}, nil
+	}
+
+	if !reflect.DeepEqual(getStrings(live.Get("index_patterns")), getStrings(gjson.Get(model, "index_patterns"))) {
+		reasons = append(reasons, "index_patterns differ")
+	}
+
+	expectedOrder := int64(0)
+	if order != nil {
+		expectedOrder = int64(*order)
+	}
+	if live.Get("order").Int() != expectedOrder {
+		reasons = append(reasons, fmt.Sprintf("order is %v instead of %v", live.Get("order").Int(), expectedOrder))
+	}
+
+	if live.Get("version").Raw != gjson.Get(model, "version").Raw {
+		reasons = append(reasons, "version differs")
+	}
+
+	contentReasons, err := getTemplateContentDriftReasons(live, gjson.Parse(model))
+	if err != nil {
+		return nil, err
+	}
+	return append(reasons, contentReasons...), nil
+}
+
+// GetIndexTemplateDriftReasons compares a composable index template, from a GET _index_template response, with its
+// model including the priority, composed_of and data_stream fields, and returns what differs
+func GetIndexTemplateDriftReasons(liveTemplates string, templateName string, model string) ([]string, error) {
+	var reasons []string
+	live := getNamedObject(liveTemplates, "index_templates", "index_template", templateName)
+	if !live.Exists() {
+		return []string{fmt.Sprintf("index template %v does not exist", templateName)}, nil
+	}
+	expected := gjson.Parse(model)
+
+	if !reflect.DeepEqual(getStrings(live.Get("index_patterns")), getStrings(expected.Get("index_patterns"))) {
+		reasons = append(reasons, "index_patterns differ")
+	}
+	if live.Get("priority").Int() != expected.Get("priority").Int() {
+		reasons = append(reasons, fmt.Sprintf("priority is %v instead of %v", live.Get("priority").Int(), expected.Get("priority").Int()))
+	}
+	if !reflect.DeepEqual(getStrings(live.Get("composed_of")), getStrings(expected.Get("composed_of"))) {
+		reasons = append(reasons, "composed_of differs")
+	}
+	if live.Get("version").Raw != expected.Get("version").Raw {
+		reasons = append(reasons, "version differs")
+	}
+	if jsonDiffers(live.Get("_meta"), expected.Get("_meta")) {
+		reasons = append(reasons, "_meta differs")
+	}
+	liveDataStream, expectedDataStream := live.Get("data_stream"), expected.Get("data_stream")
+	if liveDataStream.Exists() != expectedDataStream.Exists() ||
+		liveDataStream.Get("hidden").Bool() != expectedDataStream.Get("hidden").Bool() ||
+		liveDataStream.Get("allow_custom_routing").Bool() != expectedDataStream.Get("allow_custom_routing").Bool() {
+		reasons = append(reasons, "data_stream differs")
+	}
+
+	contentReasons, err := getTemplateContentDriftReasons(live.Get("template"), expected.Get("template"))
+	if err != nil {
+		return nil, err
+	}
+	return append(reasons, contentReasons...), nil
+}
+
+// GetComponentTemplateDriftReasons compares a component template, from a GET _component_template response, with its
+// model, and returns what differs
+func GetComponentTemplateDriftReasons(liveTemplates string, templateName string, model string) ([]string, error) {
+	var reasons []string
+	live := getNamedObject(liveTemplates, "component_templates", "component_template", templateName)
+	if !live.Exists() {
+		return []string{fmt.Sprintf("component template %v does not exist", templateName)}, nil
+	}
+	expected := gjson.Parse(model)
+
+	if live.Get("version").Raw != expected.Get("version").Raw {
+		reasons = append(reasons, "version differs")
+	}
+	if jsonDiffers(live.Get("_meta"), expected.Get("_meta")) {
+		reasons = append(reasons, "_meta differs")
+	}
+
+	contentReasons, err := getTemplateContentDriftReasons(live.Get("template"), expected.Get("template"))
+	if err != nil {
+		return nil, err
+	}
+	return append(reasons, contentReasons...), nil
+}
+
+// getTemplateContentDriftReasons compares the settings, the mappings and the aliases of a template with the ones of
+// its model
+func getTemplateContentDriftReasons(live gjson.Result, model gjson.Result) ([]string, error) {
+	var reasons []string
+	liveSettings, err := FlattenSettings(live.Get("settings").Raw)
+	if err != nil {
+		return nil, err
+	}
+	modelSettings, err := FlattenSettings(model.Get("settings").Raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(DiffSettings(liveSettings, modelSettings, nil)) > 0 || len(DiffSettings(modelSettings, liveSettings, nil)) > 0 {
+		reasons = append(reasons, "settings differ")
+	}
+
+	if !CompareJson(getTypelessMappings(live.Get("mappings")), getTypelessMappings(model.Get("mappings"))) {
+		reasons = append(reasons, "mappings differ")
+	}
+
+	liveAliases := live.Get("aliases").Map()
+	modelAliases := model.Get("aliases").Map()
+	aliasesUpdated := len(liveAliases) != len(modelAliases)
+	for name, modelBody := range modelAliases {
+		liveBody, ok := liveAliases[name]
+		if !ok {
+			aliasesUpdated = true
+			break
+		}
+		liveAlias, err := normalizeAlias(liveBody.Raw)
+		if err != nil {
+			return nil, err
+		}
+		modelAlias, err := normalizeAlias(modelBody.Raw)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(liveAlias, modelAlias) {
+			aliasesUpdated = true
+			break
+		}
+	}
+	if aliasesUpdated {
+		reasons = append(reasons, "aliases differ")
+	}
+
+	return reasons, nil
+}
+
+// NextBackingIndexName returns the name of the next versioned index behind an alias, like <alias>-v2, <alias>-v3...
+func NextBackingIndexName(alias string, currentIndex string) string {
+	matches := regexp.MustCompile(fmt.Sprintf(`^%v-v(\d+)$`, regexp.QuoteMeta(alias))).FindStringSubmatch(currentIndex)
+	if matches == nil {
+		return fmt.Sprintf("%v-v2", alias)
+	}
+	version, _ := strconv.Atoi(matches[1])
+	return fmt.Sprintf("%v-v%v", alias, version+1)
+}
+
+// GetWriteIndexFromAliases returns the index behind an alias from a GET _alias/<alias> response.
+// When the alias points to several indices, the write index is returned
+func GetWriteIndexFromAliases(jsonBody string, alias string) *string {
+	var indices []string
+	var writeIndex string
+	for index, body := range gjson.Parse(jsonBody).Map() {
+		aliasBody := body.Get(fmt.Sprintf("aliases.%v", escapePath(alias)))
+		if !aliasBody.Exists() {
+			continue
+		}
+		indices = append(indices, index)
+		if aliasBody.Get("is_write_index").Bool() {
+			writeIndex = index
+		}
+	}
+	if writeIndex != "" {
+		return &writeIndex
+	}
+	if len(indices) == 1 {
+		return &indices[0]
+	}
+	return nil
+}
+
+// GetAliasNames returns alias names of an index from a GET <index>/_alias response
+func GetAliasNames(jsonBody string, indexName string) []string {
+	return funk.Keys(gjson.Get(jsonBody, fmt.Sprintf("%v.aliases", escapePath(indexName))).Map()).([]string)
+}
+
+// BuildSwapAliasActions builds the body of an atomic POST _aliases call moving the alias and the model aliases
+// from the source index to the target index. The source index is removed when it holds the alias name or when deleteSource is true
+func BuildSwapAliasActions(alias string, sourceIndex string, targetIndex string, sourceAliases []string, modelAliases map[string]string, deleteSource bool) (string, error) {
+	var actions []map[string]interface{}
+
+	for name, rawBody := range modelAliases {
+		body := map[string]interface{}{}
+		if err := json.Unmarshal([]byte(rawBody), &body); err != nil {
+			return "", fmt.Errorf("alias %v is not a valid json: %v", name, err)
+		}
+		body["index"] = targetIndex
+		body["alias"] = name
+		actions = append(actions, map[string]interface{}{"add": body})
+	}
+	actions = append(actions, map[string]interface{}{"add": map[string]interface{}{"index": targetIndex, "alias": alias, "is_write_index": true}})
+
+	if sourceIndex == alias || deleteSource {
+		actions = append(actions, map[string]interface{}{"remove_index": map[string]interface{}{"index": sourceIndex}})
+	} else {
+		for _, name := range sourceAliases {
+			actions = append(actions, map[string]interface{}{"remove": map[string]interface{}{"index": sourceIndex, "alias": name}})
+		}
+	}
+
+	js, err := json.Marshal(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return "", err
+	}
+
+	return string(js), nil
+}
+
+// BuildUpdateAliasesActions builds the body of an atomic POST _aliases call reconciling the aliases of an index, from a
+// GET <index>/_alias response, with the model aliases. Aliases in the ignored list are left untouched.
+// It returns an empty body when aliases are in sync, and the action applied on each updated alias
+func BuildUpdateAliasesActions(indexName string, liveAliases string, modelAliases map[string]string, ignored []string) (string, map[string]string, error) {
+	var actions []map[string]interface{}
+	updates := map[string]string{}
+	live := gjson.Get(liveAliases, fmt.Sprintf("%v.aliases", escapePath(indexName))).Map()
+
+	modelNames := funk.Keys(modelAliases).([]string)
+	sort.Strings(modelNames)
+	for _, name := range modelNames {
+		modelAlias, err := normalizeAlias(modelAliases[name])
+		if err != nil {
+			return "", nil, fmt.Errorf("alias %v is not a valid json: %v", name, err)
+		}
+		action := "add"
+		if liveBody, ok := live[name]; ok {
+			liveAlias, err := normalizeAlias(liveBody.Raw)
+			if err != nil {
+				return "", nil, fmt.Errorf("alias %v of index %v is not a valid json: %v", name, indexName, err)
+			}
+			if reflect.DeepEqual(modelAlias, liveAlias) {
+				continue
+			}
+			action = "update"
+		}
+		modelAlias["index"] = indexName
+		modelAlias["alias"] = name
+		actions = append(actions, map[string]interface{}{"add": modelAlias})
+		updates[name] = action
+	}
+
+	liveNames := funk.Keys(live).([]string)
+	sort.Strings(liveNames)
+	for _, name := range liveNames {
+		if _, ok := modelAliases[name]; ok || funk.ContainsString(ignored, name) {
+			continue
+		}
+		actions = append(actions, map[string]interface{}{"remove": map[string]interface{}{"index": indexName, "alias": name}})
+		updates[name] = "remove"
+	}
+
+	if len(actions) == 0 {
+		return "", updates, nil
+	}
+
+	js, err := json.Marshal(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return string(js), updates, nil
+}
+
+// normalizeAlias returns an alias definition comparable with the one returned by elasticsearch: routing is split
+// into index_routing and search_routing, and routing values are strings
+func normalizeAlias(rawAlias string) (map[string]interface{}, error) {
+	alias := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(rawAlias), &alias); err != nil {
+		return nil, err
+	}
+	if routing, ok := alias["routing"]; ok {
+		for _, key := range []string{"index_routing", "search_routing"} {
+			if _, ok := alias[key]; !ok {
+				alias[key] = routing
+			}
+		}
+		delete(alias, "routing")
+	}
+	for key, value := range alias {
+		if value == nil {
+			delete(alias, key)
+		} else if key == "index_routing" || key == "search_routing" {
+			alias[key] = fmt.Sprintf("%v", value)
+		}
+	}
+	return alias, nil
+}
+
+// GetElasticsearchVersion returns the major version of the response of GET /, when a backend serves its distribution
+// and version (see GetEsBackend)
 func GetElasticsearchVersion(jsonBody string) (int, error) {
 	if maybeValue := gjson.Get(jsonBody, "version.number"); maybeValue.Exists() {
-		esVersion, err := strconv.Atoi(maybeValue.String()[0:1])
+		version, err := ParseVersionNumber(maybeValue.String())
 		if err != nil {
 			return -1, fmt.Errorf("cannot retrieve elasticsearch version from this json %v", jsonBody)
 		}
-		if esVersion > 8 || esVersion < 6 {
-			return -1, fmt.Errorf("elasticsearch version %v not supported", maybeValue.String())
+		if _, err := GetEsBackend(GetDistribution(jsonBody), maybeValue.String()); err != nil {
+			return -1, err
 		}
-		return esVersion, nil
+		return version[0], nil
 	}
 	return -1, fmt.Errorf("cannot retrieve elasticsearch version from this json %v", jsonBody)
 }
 
+// GetDistribution returns the distribution of the response of GET /, opensearch when version.distribution says so,
+// elasticsearch otherwise
+func GetDistribution(jsonBody string) string {
+	if gjson.Get(jsonBody, "version.distribution").String() == DistributionOpenSearch {
+		return DistributionOpenSearch
+	}
+	return DistributionElasticsearch
+}
+
+// GetClusterUUID returns the cluster_uuid of the response of GET /, or an empty string when the cluster has no elected
+// master yet
+func GetClusterUUID(jsonBody string) string {
+	if uuid := gjson.Get(jsonBody, "cluster_uuid").String(); uuid != "_na_" {
+		return uuid
+	}
+	return ""
+}
+
 type EsModel struct {
 	Model string
 }
@@ -109,6 +465,184 @@ func (m *EsModel) GetProperties() *string {
 	return getPropertiesFromPath(path, m.Model)
 }
 
+// GetFlatSettings returns the model settings flattened (see FlattenSettings)
+func (m *EsModel) GetFlatSettings() (map[string]interface{}, error) {
+	return FlattenSettings(gjson.Get(m.Model, "settings").Raw)
+}
+
+// WithoutAliases returns the model without its aliases block
+func (m *EsModel) WithoutAliases() (string, error) {
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(m.Model), &result); err != nil {
+		return "", err
+	}
+	delete(result, "aliases")
+
+	js, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+
+	return string(js), nil
+}
+
+// GetAliases returns the aliases block of the model, indexed by alias name
+func (m *EsModel) GetAliases() map[string]string {
+	aliases := map[string]string{}
+	for alias, body := range gjson.Get(m.Model, "aliases").Map() {
+		aliases[alias] = body.Raw
+	}
+	return aliases
+}
+
+// WithMappingType returns the model with its typeless mappings under mappingType, as elasticsearch 6 requires. Models
+// without mappings or with typed mappings are returned as is
+func (m *EsModel) WithMappingType(mappingType string) (string, error) {
+	if isMappingWithType := m.IsMappingWithType(); isMappingWithType == nil || *isMappingWithType {
+		return m.Model, nil
+	}
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(m.Model), &result); err != nil {
+		return "", err
+	}
+	result["mappings"] = json.RawMessage(fmt.Sprintf(`{%q:%v}`, mappingType, gjson.Get(m.Model, "mappings").Raw))
+
+	js, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return string(js), nil
+}
+
+// EsIndexTemplateOptions are the fields of a composable index template set by the spec of an object rather than by
+// its model
+type EsIndexTemplateOptions struct {
+	// Priority of the template, 0 when nil
+	Priority *int
+	// ComposedOf are the names of the component templates merged into the template, in order
+	ComposedOf []string
+	// DataStream makes the indices matching the template data streams, when not nil
+	DataStream *EsDataStreamOptions
+}
+
+// EsDataStreamOptions configures the data streams created from a composable index template
+type EsDataStreamOptions struct {
+	Hidden             bool
+	AllowCustomRouting bool
+}
+
+// WithIndexTemplateOptions returns the model of a composable index template with the priority, composed_of and
+// data_stream fields of options. Only the data_stream flags that are set are sent, as elasticsearch 7 has no
+// allow_custom_routing
+func (m *EsModel) WithIndexTemplateOptions(options EsIndexTemplateOptions) (string, error) {
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(m.Model), &result); err != nil {
+		return "", err
+	}
+	if options.Priority != nil {
+		result["priority"] = json.RawMessage(strconv.Itoa(*options.Priority))
+	}
+	if len(options.ComposedOf) > 0 {
+		composedOf, err := json.Marshal(options.ComposedOf)
+		if err != nil {
+			return "", err
+		}
+		result["composed_of"] = composedOf
+	}
+	if options.DataStream != nil {
+		dataStream := map[string]bool{}
+		if options.DataStream.Hidden {
+			dataStream["hidden"] = true
+		}
+		if options.DataStream.AllowCustomRouting {
+			dataStream["allow_custom_routing"] = true
+		}
+		js, err := json.Marshal(dataStream)
+		if err != nil {
+			return "", err
+		}
+		result["data_stream"] = js
+	}
+
+	js, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return string(js), nil
+}
+
+// GetMappingType returns the type of the typed mappings of the model, or defaultType
+func (m *EsModel) GetMappingType(defaultType string) string {
+	if isMappingWithType := m.IsMappingWithType(); isMappingWithType != nil && *isMappingWithType {
+		for mappingType := range gjson.Get(m.Model, "mappings").Map() {
+			return mappingType
+		}
+	}
+	return defaultType
+}
+
+// ForOpenSearch returns the model with typeless mappings, as opensearch 2 has no mapping type, and with the
+// index.lifecycle.rollover_alias setting renamed to its ISM equivalent. The ILM index.lifecycle.name setting is removed
+// from the model and returned apart, because an ISM policy is attached to an index by the ISM API instead of a setting
+func (m *EsModel) ForOpenSearch() (string, string, error) {
+	var result map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(m.Model))
+	decoder.UseNumber()
+	if err := decoder.Decode(&result); err != nil {
+		return "", "", err
+	}
+
+	if isMappingWithType := m.IsMappingWithType(); isMappingWithType != nil && *isMappingWithType {
+		for _, body := range result["mappings"].(map[string]interface{}) {
+			result["mappings"] = body
+		}
+	}
+
+	policyID := ""
+	if settings, ok := result["settings"].(map[string]interface{}); ok {
+		if name, found := removeSetting(settings, "", IlmPolicySetting); found {
+			policyID = fmt.Sprintf("%v", name)
+		}
+		if rolloverAlias, found := removeSetting(settings, "", IlmRolloverAliasSetting); found {
+			settings[IsmRolloverAliasSetting] = rolloverAlias
+		}
+	}
+
+	js, err := json.Marshal(result)
+	if err != nil {
+		return "", "", err
+	}
+	return string(js), policyID, nil
+}
+
+// removeSetting removes the setting whose flat name (see FlattenSettings) is key from nested or flat settings, and
+// returns its value. Objects left empty are removed too
+func removeSetting(settings map[string]interface{}, prefix string, key string) (interface{}, bool) {
+	for name, value := range settings {
+		flatName := name
+		if prefix != "" {
+			flatName = prefix + "." + name
+		}
+		fullName := withIndexPrefix(flatName)
+		if flatName == "index" {
+			fullName = flatName
+		}
+		if fullName == key {
+			delete(settings, name)
+			return value, true
+		}
+		if subSettings, ok := value.(map[string]interface{}); ok && strings.HasPrefix(key, fullName+".") {
+			if removed, found := removeSetting(subSettings, flatName, key); found {
+				if len(subSettings) == 0 {
+					delete(settings, name)
+				}
+				return removed, true
+			}
+		}
+	}
+	return nil, false
+}
+
 func (m *EsModel) IsMappingWithType() *bool {
 	if maybeMappings := gjson.Get(m.Model, "mappings"); maybeMappings.Exists() {
 		if mappings := gjson.Get(m.Model, "mappings").Map(); len(mappings) == 0 {
@@ -134,6 +668,21 @@ func (m *EsModel) IsValid(mType string) (bool, error) {
 	} else if mType == "Template" {
 		keywords = []string{"aliases", "mappings", "settings", "index_patterns", "version"}
 		requiredField = "index_patterns"
+	} else if mType == "IndexTemplate" {
+		keywords = []string{"index_patterns", "template", "version", "_meta"}
+		requiredField = "index_patterns"
+	} else if mType == "ComponentTemplate" {
+		keywords = []string{"template", "version", "_meta"}
+		requiredField = "template"
+	} else if mType == "ILMPolicy" {
+		keywords = []string{"policy"}
+		requiredField = "policy"
+	} else if mType == "IngestPipeline" {
+		keywords = []string{"description", "processors", "on_failure", "version", "_meta"}
+		requiredField = "processors"
+	} else if mType == "SLMPolicy" {
+		keywords = []string{"name", "schedule", "repository", "config", "retention"}
+		requiredField = "schedule"
 	}
 
 	var result map[string]interface{}
@@ -174,6 +723,177 @@ func (s *EsSettings) GetNumberOfReplicas(indexName string) (*int32, error) {
 	return getIntFromPath(s.Settings, path)
 }
 
+// GetRoutingNumShards returns the routing_num_shards of the index of a _cluster/state/metadata response filtered on an
+// index, which is the backing index when the index name is an alias
+func GetRoutingNumShards(clusterState string) (*int32, error) {
+	for _, index := range gjson.Get(clusterState, "metadata.indices").Map() {
+		return getIntFromPath(index.Raw, "routing_num_shards")
+	}
+	return nil, fmt.Errorf("no index found in cluster state %v", clusterState)
+}
+
+// GetAllocationRequireName returns the node the index shards are required to be allocated on, if any
+func (s *EsSettings) GetAllocationRequireName(indexName string) *string {
+	path := fmt.Sprintf("%v.settings.index.routing.allocation.require._name", escapePath(indexName))
+	if maybeValue := gjson.Get(s.Settings, path); maybeValue.Exists() && maybeValue.String() != "" {
+		node := maybeValue.String()
+		return &node
+	}
+	return nil
+}
+
+// GetShrinkNode returns the node holding the most started shards from a GET _cat/shards?format=json response
+func GetShrinkNode(catShards string) *string {
+	shardsByNode := map[string]int{}
+	for _, shard := range gjson.Parse(catShards).Array() {
+		if shard.Get("state").String() == "STARTED" && shard.Get("node").String() != "" {
+			shardsByNode[shard.Get("node").String()]++
+		}
+	}
+
+	var shrinkNode *string
+	for _, node := range funk.Keys(shardsByNode).([]string) {
+		node := node
+		if shrinkNode == nil || shardsByNode[node] > shardsByNode[*shrinkNode] ||
+			(shardsByNode[node] == shardsByNode[*shrinkNode] && node < *shrinkNode) {
+			shrinkNode = &node
+		}
+	}
+	return shrinkNode
+}
+
+// AreAllShardsOnNode returns true when a started copy of every shard is on the node and no shard is relocating,
+// from a GET _cat/shards?format=json response
+func AreAllShardsOnNode(catShards string, node string) bool {
+	shards := gjson.Parse(catShards).Array()
+	if len(shards) == 0 {
+		return false
+	}
+
+	shardsOnNode := map[string]bool{}
+	for _, shard := range shards {
+		if shard.Get("state").String() == "RELOCATING" {
+			return false
+		}
+		shardsOnNode[shard.Get("shard").String()] = shardsOnNode[shard.Get("shard").String()] ||
+			(shard.Get("state").String() == "STARTED" && shard.Get("node").String() == node)
+	}
+	for _, onNode := range shardsOnNode {
+		if !onNode {
+			return false
+		}
+	}
+	return true
+}
+
+// GetFlatSettings returns index settings merged with default settings, flattened (see FlattenSettings),
+// from a GET <index>/_settings?include_defaults=true response
+func (s *EsSettings) GetFlatSettings(indexName string) (map[string]interface{}, error) {
+	settings, err := FlattenSettings(gjson.Get(s.Settings, fmt.Sprintf("%v.defaults", escapePath(indexName))).Raw)
+	if err != nil {
+		return nil, err
+	}
+	indexSettings, err := FlattenSettings(gjson.Get(s.Settings, fmt.Sprintf("%v.settings", escapePath(indexName))).Raw)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range indexSettings {
+		settings[key] = value
+	}
+	return settings, nil
+}
+
+// FlattenSettings returns settings with flat keys prefixed by "index.", whatever settings are nested
+// ({"index":{"refresh_interval":"1s"}}), flat ({"index.refresh_interval":"1s"}) or without prefix ({"refresh_interval":"1s"}).
+// Values are converted to strings like elasticsearch does, and null values are ignored
+func FlattenSettings(settings string) (map[string]interface{}, error) {
+	flatSettings := map[string]interface{}{}
+	if settings == "" {
+		return flatSettings, nil
+	}
+
+	var result map[string]interface{}
+	decoder := json.NewDecoder(strings.NewReader(settings))
+	decoder.UseNumber()
+	if err := decoder.Decode(&result); err != nil {
+		return nil, err
+	}
+
+	var flatten func(prefix string, value interface{})
+	flatten = func(prefix string, value interface{}) {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			for key, subValue := range v {
+				if prefix == "" {
+					flatten(key, subValue)
+				} else {
+					flatten(prefix+"."+key, subValue)
+				}
+			}
+		case []interface{}:
+			values := []string{}
+			for _, item := range v {
+				values = append(values, fmt.Sprintf("%v", item))
+			}
+			flatSettings[withIndexPrefix(prefix)] = values
+		case nil:
+		default:
+			flatSettings[withIndexPrefix(prefix)] = fmt.Sprintf("%v", v)
+		}
+	}
+	flatten("", result)
+
+	return flatSettings, nil
+}
+
+// DiffSettings returns the new settings that are missing or different in old settings, ignoring keys of the ignored list.
+// Settings should be flattened with FlattenSettings
+func DiffSettings(oldSettings map[string]interface{}, newSettings map[string]interface{}, ignored []string) map[string]interface{} {
+	diff := map[string]interface{}{}
+	for key, value := range newSettings {
+		if funk.ContainsString(ignored, key) {
+			continue
+		}
+		if oldValue, ok := oldSettings[key]; !ok || !reflect.DeepEqual(oldValue, value) {
+			diff[key] = value
+		}
+	}
+	return diff
+}
+
+// GetStaticSettings returns the keys of settings that can only be updated on a closed index, sorted
+func GetStaticSettings(settings map[string]interface{}) []string {
+	var staticSettings []string
+	for key := range settings {
+		for _, prefix := range staticSettingsPrefixes {
+			if key == prefix || strings.HasPrefix(key, prefix+".") {
+				staticSettings = append(staticSettings, key)
+				break
+			}
+		}
+	}
+	sort.Strings(staticSettings)
+	return staticSettings
+}
+
+// staticSettingsPrefixes lists index settings that cannot be updated on an open index
+var staticSettingsPrefixes = []string{
+	"index.analysis",
+	"index.codec",
+	"index.load_fixed_bitset_filters_eagerly",
+	"index.shard.check_on_startup",
+	"index.similarity",
+	"index.soft_deletes",
+	"index.store",
+}
+
+func withIndexPrefix(key string) string {
+	if strings.HasPrefix(key, "index.") {
+		return key
+	}
+	return "index." + key
+}
+
 type EsMappings struct {
 	Mappings string
 }
@@ -183,6 +903,63 @@ func (m EsMappings) GetProperties(indexName string) *string {
 	return getPropertiesFromPath(path, m.Mappings)
 }
 
+// GetTypedProperties returns the properties of the single type of an elasticsearch 6 mapping, and the type. The type
+// is empty when the index has no mapping
+func (m EsMappings) GetTypedProperties(indexName string) (*string, string) {
+	for mappingType, body := range gjson.Get(m.Mappings, escapePath(indexName)+".mappings").Map() {
+		return getPropertiesFromPath("properties", body.Raw), mappingType
+	}
+	return nil, ""
+}
+
+// GetIsmPolicyID returns the ISM policy of an index from a GET _plugins/_ism/explain/<index> response, empty when the
+// index is not managed by ISM
+func GetIsmPolicyID(explain string, indexName string) string {
+	index := gjson.Get(explain, escapePath(indexName))
+	if policyID := index.Get("policy_id").String(); policyID != "" {
+		return policyID
+	}
+	return index.Get(escapePath("index.plugins.index_state_management.policy_id")).String()
+}
+
+// GetIsmFailure returns the failure reason of a POST _plugins/_ism/add or change_policy response, nil when the policy
+// was applied
+func GetIsmFailure(response string) *string {
+	if !gjson.Get(response, "failures").Bool() {
+		return nil
+	}
+	reason := gjson.Get(response, "failed_indices.0.reason").String()
+	if reason == "" {
+		reason = response
+	}
+	return &reason
+}
+
+type EsTask struct {
+	Task string
+}
+
+// IsCompleted returns whether the task is over, with the number of created and total documents
+func (t *EsTask) IsCompleted() (bool, int64, int64) {
+	completed := gjson.Get(t.Task, "completed").Bool()
+	created := gjson.Get(t.Task, "task.status.created").Int()
+	total := gjson.Get(t.Task, "task.status.total").Int()
+	return completed, created, total
+}
+
+// GetError returns the task error, or the first failure of the task response, if any
+func (t *EsTask) GetError() *string {
+	if maybeError := gjson.Get(t.Task, "error"); maybeError.Exists() {
+		taskError := maybeError.Raw
+		return &taskError
+	}
+	if failures := gjson.Get(t.Task, "response.failures"); failures.Exists() && len(failures.Array()) > 0 {
+		taskError := failures.Array()[0].Raw
+		return &taskError
+	}
+	return nil
+}
+
 func getIntFromPath(json string, path string) (*int32, error) {
 	if maybeValue := gjson.Get(json, path); maybeValue.Exists() {
 		valueToReturn, err := strconv.Atoi(maybeValue.String())
@@ -213,3 +990,62 @@ func getNestedFieldsWithProperties(properties string) []string {
 	}
 	return fieldsWithProperties
 }
+
+// getFieldType returns the type of a field mapping, object fields having no type
+func getFieldType(field gjson.Result) string {
+	if fieldType := field.Get("type"); fieldType.Exists() {
+		return fieldType.String()
+	}
+	return "object"
+}
+
+// getStrings returns a json string or array of strings as a slice
+func getStrings(value gjson.Result) []string {
+	values := []string{}
+	if value.IsArray() {
+		for _, item := range value.Array() {
+			values = append(values, item.String())
+		}
+	} else if value.Exists() {
+		values = append(values, value.String())
+	}
+	return values
+}
+
+// getTypelessMappings returns mappings without their type, like {"_doc":{"properties":{}}} becoming {"properties":{}}
+func getTypelessMappings(mappings gjson.Result) string {
+	isMappingWithType := (&EsModel{Model: fmt.Sprintf(`{"mappings":%v}`, mappings.Raw)}).IsMappingWithType()
+	if !mappings.Exists() || isMappingWithType == nil {
+		return "{}"
+	}
+	if *isMappingWithType {
+		for _, body := range mappings.Map() {
+			return body.Raw
+		}
+	}
+	return mappings.Raw
+}
+
+// escapePath escapes gjson special characters of a single path component, like dots in index names
+func escapePath(component string) string {
+	return regexp.MustCompile(`([.*?|#@\\])`).ReplaceAllString(component, `\$1`)
+}
+
+// getNamedObject returns the body of the object named name, from a response listing objects under arrayField like
+// {"index_templates":[{"name":"logs","index_template":{}}]}
+func getNamedObject(response string, arrayField string, bodyField string, name string) gjson.Result {
+	for _, item := range gjson.Get(response, arrayField).Array() {
+		if item.Get("name").String() == name {
+			return item.Get(bodyField)
+		}
+	}
+	return gjson.Result{}
+}
+
+// jsonDiffers returns true when only one of the values exists, or when both exist with different json
+func jsonDiffers(live gjson.Result, model gjson.Result) bool {
+	if !live.Exists() || !model.Exists() {
+		return live.Exists() != model.Exists()
+	}
+	return !CompareJson(live.Raw, model.Raw)
+}
@@ -58,6 +58,13 @@ func TestGetElasticsearchVersion(t *testing.T) {
 		{jsonBody: `{"name":"adf8","cluster_name":"es","cluster_uuid":"wLyZGB","version":{"number":"7.9.2"}}`, esVersion: 7, error: false},
 		{jsonBody: `{"name":"adf8","cluster_name":"es","cluster_uuid":"wLyZGB","version":{"number":"6.7.1"}}`, esVersion: 6, error: false},
 		{jsonBody: `{"name":"adf8","cluster_name":"es","cluster_uuid":"wLyZGB","version":{"number":"5.4.1"}}`, esVersion: -1, error: true},
+		{jsonBody: `{"name":"adf8","cluster_name":"os","cluster_uuid":"wLyZGB","version":{"distribution":"opensearch","number":"2.11.1"}}`, esVersion: 2, error: false},
+		{jsonBody: `{"name":"adf8","cluster_name":"os","cluster_uuid":"wLyZGB","version":{"distribution":"opensearch","number":"1.3.14"}}`, esVersion: 1, error: false},
+		{jsonBody: `{"name":"adf8","cluster_name":"os","cluster_uuid":"wLyZGB","version":{"distribution":"opensearch","number":"7.10.2"}}`, esVersion: -1, error: true},
+		{jsonBody: `{"name":"adf8","cluster_name":"es","cluster_uuid":"wLyZGB","version":{"number":"1.7.6"}}`, esVersion: -1, error: true},
+		{jsonBody: `{"name":"adf8","cluster_name":"es","cluster_uuid":"wLyZGB","version":{"number":"9.0.0"}}`, esVersion: 9, error: false},
+		{jsonBody: `{"name":"adf8","cluster_name":"es","cluster_uuid":"wLyZGB","version":{"number":"10.1.0"}}`, esVersion: -1, error: true},
+		{jsonBody: `{"name":"adf8","cluster_name":"es","cluster_uuid":"wLyZGB","version":{"number":"not-a-version"}}`, esVersion: -1, error: true},
 		{jsonBody: `{"name":"adf8","cluster_name":"es","cluster_uuid":"wLyZGB"}`, esVersion: -1, error: true},
 		{jsonBody: `{"name":"adf8","clust`, esVersion: -1, error: true},
 	}
@@ -74,6 +81,20 @@ func TestGetElasticsearchVersion(t *testing.T) {
 	}
 }
 
+func TestGetDistribution(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(DistributionOpenSearch, GetDistribution(`{"version":{"distribution":"opensearch","number":"2.11.1"}}`))
+	assert.Equal(DistributionElasticsearch, GetDistribution(`{"version":{"build_flavor":"default","number":"8.4.2"}}`))
+	assert.Equal(DistributionElasticsearch, GetDistribution(`{"vers`))
+}
+
+func TestGetClusterUUID(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("wLyZGB", GetClusterUUID(`{"name":"adf8","cluster_name":"es","cluster_uuid":"wLyZGB","version":{"number":"8.4.2"}}`))
+	assert.Equal("", GetClusterUUID(`{"name":"adf8","cluster_name":"es","cluster_uuid":"_na_","version":{"number":"8.4.2"}}`))
+	assert.Equal("", GetClusterUUID(`{"name":"adf8","cluster_name":"es","version":{"number":"6.7.1"}}`))
+}
+
 func TestEsModel_AddSettings(t *testing.T) {
 	assert := assert.New(t)
 	scenarios := []struct {
@@ -193,6 +214,57 @@ func TestEsModel_IsMappingWithType(t *testing.T) {
 	}
 }
 
+func TestEsModel_WithMappingType(t *testing.T) {
+	assert := assert.New(t)
+	scenarios := []struct {
+		model       string
+		expectModel string
+	}{
+		{model: `{}`, expectModel: `{}`},
+		{model: `{"settings":{"number_of_shards":1},"mappings":{"properties":{"cityCode":{"type":"keyword"}}}}`, expectModel: `{"settings":{"number_of_shards":1},"mappings":{"_doc":{"properties":{"cityCode":{"type":"keyword"}}}}}`},
+		{model: `{"mappings":{"city":{"properties":{"cityCode":{"type":"keyword"}}}}}`, expectModel: `{"mappings":{"city":{"properties":{"cityCode":{"type":"keyword"}}}}}`},
+	}
+
+	for _, s := range scenarios {
+		got, err := (&EsModel{Model: s.model}).WithMappingType(DefaultMappingType)
+		assert.Nil(err)
+		assert.JSONEq(s.expectModel, got)
+	}
+}
+
+func TestEsModel_GetMappingType(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Equal("_doc", (&EsModel{Model: `{}`}).GetMappingType(DefaultMappingType))
+	assert.Equal("_doc", (&EsModel{Model: `{"mappings":{"properties":{}}}`}).GetMappingType(DefaultMappingType))
+	assert.Equal("city", (&EsModel{Model: `{"mappings":{"city":{"properties":{}}}}`}).GetMappingType(DefaultMappingType))
+}
+
+func TestEsModel_ForOpenSearch(t *testing.T) {
+	assert := assert.New(t)
+	scenarios := []struct {
+		model          string
+		expectModel    string
+		expectPolicyID string
+	}{
+		{model: `{}`, expectModel: `{}`},
+		{model: `{"mappings":{"_doc":{"properties":{"cityCode":{"type":"keyword"}}}}}`, expectModel: `{"mappings":{"properties":{"cityCode":{"type":"keyword"}}}}`},
+		{model: `{"settings":{"number_of_shards":1,"index":{"lifecycle":{"name":"logs","rollover_alias":"logs"}}}}`, expectModel: `{"settings":{"number_of_shards":1,"index.plugins.index_state_management.rollover_alias":"logs"}}`, expectPolicyID: "logs"},
+		{model: `{"settings":{"index.lifecycle.name":"logs","index":{"refresh_interval":"5s"}}}`, expectModel: `{"settings":{"index":{"refresh_interval":"5s"}}}`, expectPolicyID: "logs"},
+		{model: `{"settings":{"lifecycle.name":"logs"}}`, expectModel: `{"settings":{}}`, expectPolicyID: "logs"},
+	}
+
+	for _, s := range scenarios {
+		got, policyID, err := (&EsModel{Model: s.model}).ForOpenSearch()
+		assert.Nil(err)
+		assert.JSONEq(s.expectModel, got)
+		assert.Equal(s.expectPolicyID, policyID)
+	}
+
+	_, _, err := (&EsModel{Model: `{"sett`}).ForOpenSearch()
+	assert.NotNil(err)
+}
+
 func TestEsModel_IsValid(t *testing.T) {
 	assert := assert.New(t)
 	scenarios := []struct {
@@ -217,6 +289,16 @@ func TestEsModel_IsValid(t *testing.T) {
 		{esType: "Template", model: `{"aliases":{}}`, valid: false, error: true},
 		{esType: "Template", model: `{"aliases":{},"index_patterns":{}}`, valid: true, error: false},
 		{esType: "Template", model: `{"alia`, valid: false, error: true},
+		{esType: "IndexTemplate", model: `{"index_patterns":["logs-*"],"template":{"settings":{}},"version":1,"_meta":{}}`, valid: true, error: false},
+		{esType: "IndexTemplate", model: `{"template":{}}`, error: true},
+		{esType: "IndexTemplate", model: `{"index_patterns":["logs-*"],"priority":1}`, error: true},
+		{esType: "ComponentTemplate", model: `{"template":{"mappings":{}},"version":1}`, valid: true, error: false},
+		{esType: "ComponentTemplate", model: `{"version":1}`, error: true},
+		{esType: "ComponentTemplate", model: `{"template":{},"index_patterns":["logs-*"]}`, error: true},
+		{esType: "ILMPolicy", model: `{"policy":{"phases":{"delete":{"min_age":"30d","actions":{"delete":{}}}}}}`, valid: true, error: false},
+		{esType: "ILMPolicy", model: `{"phases":{}}`, error: true},
+		{esType: "IngestPipeline", model: `{"description":"parse logs","processors":[{"lowercase":{"field":"level"}}],"version":1}`, valid: true, error: false},
+		{esType: "IngestPipeline", model: `{"description":"parse logs"}`, error: true},
 	}
 
 	for _, s := range scenarios {
@@ -313,3 +395,458 @@ func TestEsMappings_GetProperties(t *testing.T) {
 		}
 	}
 }
+
+func TestEsMappings_GetTypedProperties(t *testing.T) {
+	assert := assert.New(t)
+
+	properties, mappingType := (&EsMappings{Mappings: `{"city":{"mappings":{"doc":{"properties":{"cityCode":{"type":"keyword"}}}}}}`}).GetTypedProperties("city")
+	assert.Equal("doc", mappingType)
+	assert.NotNil(properties)
+	assert.True(CompareJson(`{"properties":{"cityCode":{"type":"keyword"}}}`, *properties))
+
+	properties2, mappingType2 := (&EsMappings{Mappings: `{"city":{"mappings":{}}}`}).GetTypedProperties("city")
+	assert.Nil(properties2)
+	assert.Equal("", mappingType2)
+}
+
+func TestGetIsmPolicyID(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("logs", GetIsmPolicyID(`{"logs.2024":{"index.plugins.index_state_management.policy_id":"logs","policy_id":"logs"},"total_managed_indices":1}`, "logs.2024"))
+	assert.Equal("logs", GetIsmPolicyID(`{"logs":{"index.plugins.index_state_management.policy_id":"logs"},"total_managed_indices":1}`, "logs"))
+	assert.Equal("", GetIsmPolicyID(`{"logs":{"index.plugins.index_state_management.policy_id":null},"total_managed_indices":0}`, "logs"))
+}
+
+func TestGetIsmFailure(t *testing.T) {
+	assert := assert.New(t)
+	assert.Nil(GetIsmFailure(`{"updated_indices":1,"failures":false,"failed_indices":[]}`))
+	failure := GetIsmFailure(`{"updated_indices":0,"failures":true,"failed_indices":[{"index_name":"logs","reason":"Policy logs does not exist"}]}`)
+	assert.NotNil(failure)
+	assert.Equal("Policy logs does not exist", *failure)
+}
+
+func TestIsPropertiesTypeUpdated(t *testing.T) {
+	scenarios := []struct {
+		oldProperties string
+		newProperties string
+		typeUpdated   bool
+	}{
+		{oldProperties: `{"properties":{}}`, newProperties: `{"properties":{"cityName":{"type":"keyword"}}}`, typeUpdated: false},
+		{oldProperties: `{"properties":{"cityName":{"type":"keyword"}}}`, newProperties: `{"properties":{"cityName":{"type":"keyword"}}}`, typeUpdated: false},
+		{oldProperties: `{"properties":{"cityName":{"type":"keyword"}}}`, newProperties: `{"properties":{"cityName":{"type":"text"}}}`, typeUpdated: true},
+		{oldProperties: `{"properties":{"cityName":{"type":"keyword"}}}`, newProperties: `{"properties":{"cityCode":{"type":"text"}}}`, typeUpdated: false},
+		{oldProperties: `{"properties":{"cityAddress":{"properties":{"line1":{"type":"keyword"}}}}}`, newProperties: `{"properties":{"cityAddress":{"properties":{"line1":{"type":"text"}}}}}`, typeUpdated: true},
+		{oldProperties: `{"properties":{"cityAddress":{"properties":{"line1":{"type":"keyword"}}}}}`, newProperties: `{"properties":{"cityAddress":{"type":"nested","properties":{"line1":{"type":"keyword"}}}}}`, typeUpdated: true},
+	}
+
+	for _, s := range scenarios {
+		got := IsPropertiesTypeUpdated(s.oldProperties, s.newProperties)
+		assert.Equal(t, s.typeUpdated, got, fmt.Sprintf("oldProperties: %v, newProperties: %v", s.oldProperties, s.newProperties))
+	}
+}
+
+func TestNextBackingIndexName(t *testing.T) {
+	assert := assert.New(t)
+	scenarios := []struct {
+		alias        string
+		currentIndex string
+		expect       string
+	}{
+		{alias: "products", currentIndex: "products", expect: "products-v2"},
+		{alias: "products", currentIndex: "products-v2", expect: "products-v3"},
+		{alias: "products", currentIndex: "products-v19", expect: "products-v20"},
+		{alias: "products", currentIndex: "products-vx", expect: "products-v2"},
+		{alias: "my.products", currentIndex: "myXproducts-v4", expect: "my.products-v2"},
+	}
+
+	for _, s := range scenarios {
+		assert.Equal(s.expect, NextBackingIndexName(s.alias, s.currentIndex))
+	}
+}
+
+func TestGetWriteIndexFromAliases(t *testing.T) {
+	assert := assert.New(t)
+	scenarios := []struct {
+		jsonBody string
+		alias    string
+		expect   *string
+	}{
+		{jsonBody: `{}`, alias: "products", expect: nil},
+		{jsonBody: `{"products-v2":{"aliases":{"products":{}}}}`, alias: "products", expect: ptrToStr("products-v2")},
+		{jsonBody: `{"products-v2":{"aliases":{"products":{"is_write_index":true}}},"products-v1":{"aliases":{"products":{}}}}`, alias: "products", expect: ptrToStr("products-v2")},
+		{jsonBody: `{"products-v2":{"aliases":{"products":{}}},"products-v1":{"aliases":{"products":{}}}}`, alias: "products", expect: nil},
+		{jsonBody: `{"products-v2":{"aliases":{"other":{}}}}`, alias: "products", expect: nil},
+		{jsonBody: `{"my.products-v2":{"aliases":{"my.products":{}}}}`, alias: "my.products", expect: ptrToStr("my.products-v2")},
+	}
+
+	for _, s := range scenarios {
+		assert.Equal(s.expect, GetWriteIndexFromAliases(s.jsonBody, s.alias))
+	}
+}
+
+func TestGetAliasNames(t *testing.T) {
+	assert := assert.New(t)
+	assert.ElementsMatch([]string{"a", "b"}, GetAliasNames(`{"products":{"aliases":{"a":{},"b":{"is_write_index":true}}}}`, "products"))
+	assert.Empty(GetAliasNames(`{"products":{"aliases":{}}}`, "products"))
+	assert.Empty(GetAliasNames(`{}`, "products"))
+}
+
+func TestBuildSwapAliasActions(t *testing.T) {
+	assert := assert.New(t)
+	scenarios := []struct {
+		sourceIndex   string
+		sourceAliases []string
+		modelAliases  map[string]string
+		deleteSource  bool
+		output        string
+		error         bool
+	}{
+		{sourceIndex: "products", deleteSource: false,
+			output: `{"actions":[{"add":{"index":"products-v2","alias":"products","is_write_index":true}},{"remove_index":{"index":"products"}}]}`},
+		{sourceIndex: "products-v2", sourceAliases: []string{"products", "search"}, deleteSource: false,
+			output: `{"actions":[{"add":{"index":"products-v3","alias":"products","is_write_index":true}},{"remove":{"index":"products-v2","alias":"products"}},{"remove":{"index":"products-v2","alias":"search"}}]}`},
+		{sourceIndex: "products-v2", sourceAliases: []string{"products"}, modelAliases: map[string]string{"search": `{"filter":{"term":{"active":true}}}`}, deleteSource: true,
+			output: `{"actions":[{"add":{"index":"products-v3","alias":"search","filter":{"term":{"active":true}}}},{"add":{"index":"products-v3","alias":"products","is_write_index":true}},{"remove_index":{"index":"products-v2"}}]}`},
+		{sourceIndex: "products", modelAliases: map[string]string{"search": `{"filter`}, error: true},
+	}
+
+	for _, s := range scenarios {
+		got, err := BuildSwapAliasActions("products", s.sourceIndex, NextBackingIndexName("products", s.sourceIndex), s.sourceAliases, s.modelAliases, s.deleteSource)
+		if s.error {
+			assert.NotNil(err)
+		} else {
+			assert.Nil(err)
+			assert.JSONEq(s.output, got)
+		}
+	}
+}
+
+func TestEsModel_WithoutAliases(t *testing.T) {
+	assert := assert.New(t)
+	scenarios := []struct {
+		model  string
+		output string
+		error  bool
+	}{
+		{model: `{}`, output: `{}`},
+		{model: `{"aliases":{"search":{}},"settings":{"number_of_replicas":1}}`, output: `{"settings":{"number_of_replicas":1}}`},
+		{model: `{"aliases":`, error: true},
+	}
+
+	for _, s := range scenarios {
+		got, err := (&EsModel{Model: s.model}).WithoutAliases()
+		if s.error {
+			assert.NotNil(err)
+		} else {
+			assert.Nil(err)
+			assert.JSONEq(s.output, got)
+		}
+	}
+}
+
+func TestEsModel_GetAliases(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(map[string]string{}, (&EsModel{Model: `{}`}).GetAliases())
+	assert.Equal(map[string]string{"search": `{}`, "active": `{"filter":{"term":{"active":true}}}`},
+		(&EsModel{Model: `{"aliases":{"search":{},"active":{"filter":{"term":{"active":true}}}}}`}).GetAliases())
+}
+
+func TestEsTask_IsCompleted(t *testing.T) {
+	assert := assert.New(t)
+	scenarios := []struct {
+		task      string
+		completed bool
+		created   int64
+		total     int64
+		error     bool
+	}{
+		{task: `{"completed":false,"task":{"status":{"total":100,"created":40}}}`, completed: false, created: 40, total: 100},
+		{task: `{"completed":true,"task":{"status":{"total":100,"created":100}},"response":{"failures":[]}}`, completed: true, created: 100, total: 100},
+		{task: `{"completed":true,"task":{"status":{"total":100,"created":10}},"response":{"failures":[{"cause":{"type":"mapper_parsing_exception"}}]}}`, completed: true, created: 10, total: 100, error: true},
+		{task: `{"completed":true,"task":{"status":{}},"error":{"type":"index_not_found_exception"}}`, completed: true, error: true},
+	}
+
+	for _, s := range scenarios {
+		task := &EsTask{Task: s.task}
+		completed, created, total := task.IsCompleted()
+		assert.Equal(s.completed, completed)
+		assert.Equal(s.created, created)
+		assert.Equal(s.total, total)
+		assert.Equal(s.error, task.GetError() != nil)
+	}
+}
+
+func ptrToStr(s string) *string {
+	return &s
+}
+
+func TestEsSettings_GetAllocationRequireName(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal(ptrToStr("node-1"), (&EsSettings{Settings: `{"products":{"settings":{"index":{"routing":{"allocation":{"require":{"_name":"node-1"}}}}}}}`}).GetAllocationRequireName("products"))
+	assert.Nil((&EsSettings{Settings: `{"products":{"settings":{"index":{"routing":{"allocation":{"require":{"_name":""}}}}}}}`}).GetAllocationRequireName("products"))
+	assert.Nil((&EsSettings{Settings: `{"products":{"settings":{}}}`}).GetAllocationRequireName("products"))
+}
+
+func TestGetRoutingNumShards(t *testing.T) {
+	assert := assert.New(t)
+	routingShards, err := GetRoutingNumShards(`{"metadata":{"indices":{"products-v2":{"routing_num_shards":1024}}}}`)
+	assert.Nil(err)
+	assert.Equal(int32(1024), *routingShards)
+	_, err = GetRoutingNumShards(`{"metadata":{"indices":{}}}`)
+	assert.NotNil(err)
+}
+
+func TestGetShrinkNode(t *testing.T) {
+	assert := assert.New(t)
+	scenarios := []struct {
+		catShards string
+		expect    *string
+	}{
+		{catShards: `[]`, expect: nil},
+		{catShards: `[{"shard":"0","prirep":"p","state":"UNASSIGNED","node":null}]`, expect: nil},
+		{catShards: `[{"shard":"0","prirep":"p","state":"STARTED","node":"node-2"},{"shard":"1","prirep":"p","state":"STARTED","node":"node-1"}]`, expect: ptrToStr("node-1")},
+		{catShards: `[{"shard":"0","prirep":"p","state":"STARTED","node":"node-2"},{"shard":"1","prirep":"p","state":"STARTED","node":"node-1"},{"shard":"1","prirep":"r","state":"STARTED","node":"node-2"}]`, expect: ptrToStr("node-2")},
+	}
+
+	for _, s := range scenarios {
+		assert.Equal(s.expect, GetShrinkNode(s.catShards))
+	}
+}
+
+func TestAreAllShardsOnNode(t *testing.T) {
+	assert := assert.New(t)
+	scenarios := []struct {
+		catShards string
+		expect    bool
+	}{
+		{catShards: `[]`, expect: false},
+		{catShards: `[{"shard":"0","prirep":"p","state":"STARTED","node":"node-1"},{"shard":"1","prirep":"p","state":"STARTED","node":"node-1"}]`, expect: true},
+		{catShards: `[{"shard":"0","prirep":"p","state":"STARTED","node":"node-1"},{"shard":"1","prirep":"p","state":"STARTED","node":"node-2"},{"shard":"1","prirep":"r","state":"STARTED","node":"node-1"}]`, expect: true},
+		{catShards: `[{"shard":"0","prirep":"p","state":"STARTED","node":"node-1"},{"shard":"1","prirep":"p","state":"STARTED","node":"node-2"}]`, expect: false},
+		{catShards: `[{"shard":"0","prirep":"p","state":"STARTED","node":"node-1"},{"shard":"1","prirep":"p","state":"RELOCATING","node":"node-2 -> 10.0.0.1 node-1"}]`, expect: false},
+		{catShards: `[{"shard":"0","prirep":"p","state":"STARTED","node":"node-1"},{"shard":"1","prirep":"p","state":"INITIALIZING","node":"node-1"}]`, expect: false},
+	}
+
+	for _, s := range scenarios {
+		assert.Equal(s.expect, AreAllShardsOnNode(s.catShards, "node-1"), s.catShards)
+	}
+}
+
+func TestBuildUpdateAliasesActions(t *testing.T) {
+	assert := assert.New(t)
+	scenarios := []struct {
+		indexName    string
+		liveAliases  string
+		modelAliases map[string]string
+		ignored      []string
+		output       string
+		updates      map[string]string
+		error        bool
+	}{
+		{indexName: "products", liveAliases: `{"products":{"aliases":{}}}`, output: "", updates: map[string]string{}},
+		{indexName: "products", liveAliases: `{"products":{"aliases":{"search":{},"filtered":{"filter":{"term":{"active":true}},"index_routing":"1","search_routing":"1"}}}}`,
+			modelAliases: map[string]string{"search": `{}`, "filtered": `{"filter":{"term":{"active":true}},"routing":1}`},
+			output:       "", updates: map[string]string{}},
+		{indexName: "products", liveAliases: `{"products":{"aliases":{"search":{"is_write_index":true},"old":{}}}}`,
+			modelAliases: map[string]string{"search": `{"is_write_index":false}`, "hidden": `{"is_hidden":true}`},
+			output:       `{"actions":[{"add":{"index":"products","alias":"hidden","is_hidden":true}},{"add":{"index":"products","alias":"search","is_write_index":false}},{"remove":{"index":"products","alias":"old"}}]}`,
+			updates:      map[string]string{"hidden": "add", "search": "update", "old": "remove"}},
+		{indexName: "products-v2", liveAliases: `{"products-v2":{"aliases":{"products":{"is_write_index":true},"search":{"filter":{"term":{"active":true}}}}}}`,
+			modelAliases: map[string]string{"search": `{"filter":{"term":{"active":false}},"index_routing":"2"}`}, ignored: []string{"products"},
+			output:  `{"actions":[{"add":{"index":"products-v2","alias":"search","filter":{"term":{"active":false}},"index_routing":"2"}}]}`,
+			updates: map[string]string{"search": "update"}},
+		{indexName: "products", liveAliases: `{"products":{"aliases":{}}}`, modelAliases: map[string]string{"search": `{"filter`}, error: true},
+	}
+
+	for _, s := range scenarios {
+		got, updates, err := BuildUpdateAliasesActions(s.indexName, s.liveAliases, s.modelAliases, s.ignored)
+		if s.error {
+			assert.NotNil(err)
+		} else {
+			assert.Nil(err)
+			if s.output == "" {
+				assert.Equal("", got)
+			} else {
+				assert.JSONEq(s.output, got)
+			}
+			assert.Equal(s.updates, updates)
+		}
+	}
+}
+
+func TestFlattenSettings(t *testing.T) {
+	assert := assert.New(t)
+	scenarios := []struct {
+		settings string
+		output   map[string]interface{}
+		error    bool
+	}{
+		{settings: ``, output: map[string]interface{}{}},
+		{settings: `{"refresh_interval":"1s","number_of_replicas":1}`, output: map[string]interface{}{"index.refresh_interval": "1s", "index.number_of_replicas": "1"}},
+		{settings: `{"index":{"refresh_interval":"1s","blocks":{"read_only":true}}}`, output: map[string]interface{}{"index.refresh_interval": "1s", "index.blocks.read_only": "true"}},
+		{settings: `{"index.max_result_window":20000,"index.routing.allocation":{"include":{"_tier_preference":"data_hot"}},"codec":null}`,
+			output: map[string]interface{}{"index.max_result_window": "20000", "index.routing.allocation.include._tier_preference": "data_hot"}},
+		{settings: `{"analysis":{"analyzer":{"folding":{"tokenizer":"standard","filter":["lowercase","asciifolding"]}}}}`,
+			output: map[string]interface{}{"index.analysis.analyzer.folding.tokenizer": "standard", "index.analysis.analyzer.folding.filter": []string{"lowercase", "asciifolding"}}},
+		{settings: `{"refresh_interval"`, error: true},
+	}
+
+	for _, s := range scenarios {
+		got, err := FlattenSettings(s.settings)
+		if s.error {
+			assert.NotNil(err)
+		} else {
+			assert.Nil(err)
+			assert.Equal(s.output, got, s.settings)
+		}
+	}
+}
+
+func TestEsSettings_GetFlatSettings(t *testing.T) {
+	assert := assert.New(t)
+	settings := `{"products":{"settings":{"index":{"refresh_interval":"5s","number_of_shards":"1"}},"defaults":{"index":{"refresh_interval":"1s","codec":"default"}}}}`
+	got, err := (&EsSettings{Settings: settings}).GetFlatSettings("products")
+	assert.Nil(err)
+	assert.Equal(map[string]interface{}{"index.refresh_interval": "5s", "index.number_of_shards": "1", "index.codec": "default"}, got)
+}
+
+func TestDiffSettings(t *testing.T) {
+	assert := assert.New(t)
+	oldSettings := map[string]interface{}{"index.refresh_interval": "1s", "index.number_of_replicas": "1", "index.analysis.analyzer.folding.filter": []string{"lowercase"}}
+	scenarios := []struct {
+		newSettings map[string]interface{}
+		output      map[string]interface{}
+	}{
+		{newSettings: map[string]interface{}{}, output: map[string]interface{}{}},
+		{newSettings: map[string]interface{}{"index.refresh_interval": "1s", "index.number_of_replicas": "2"}, output: map[string]interface{}{}},
+		{newSettings: map[string]interface{}{"index.refresh_interval": "5s", "index.max_result_window": "20000"},
+			output: map[string]interface{}{"index.refresh_interval": "5s", "index.max_result_window": "20000"}},
+		{newSettings: map[string]interface{}{"index.analysis.analyzer.folding.filter": []string{"lowercase", "asciifolding"}},
+			output: map[string]interface{}{"index.analysis.analyzer.folding.filter": []string{"lowercase", "asciifolding"}}},
+	}
+
+	for _, s := range scenarios {
+		assert.Equal(s.output, DiffSettings(oldSettings, s.newSettings, []string{"index.number_of_replicas"}))
+	}
+}
+
+func TestGetStaticSettings(t *testing.T) {
+	assert := assert.New(t)
+	assert.Nil(GetStaticSettings(map[string]interface{}{"index.refresh_interval": "1s", "index.codec_custom": "x"}))
+	assert.Equal([]string{"index.analysis.analyzer.folding.tokenizer", "index.codec"},
+		GetStaticSettings(map[string]interface{}{"index.codec": "best_compression", "index.refresh_interval": "1s", "index.analysis.analyzer.folding.tokenizer": "standard"}))
+}
+
+func TestGetMissingOrUpdatedProperties(t *testing.T) {
+	assert := assert.New(t)
+	live := `{"properties":{"name":{"type":"keyword"},"price":{"type":"float"},"dynamic_field":{"type":"text"},"address":{"properties":{"city":{"type":"keyword"}}}}}`
+	scenarios := []struct {
+		model  string
+		output []string
+	}{
+		{model: `{"properties":{}}`, output: nil},
+		{model: `{"properties":{"name":{"type":"keyword","doc_values":true},"address":{"type":"object","properties":{"city":{"type":"keyword"}}}}}`, output: nil},
+		{model: `{"properties":{"name":{"type":"text"},"stock":{"type":"integer"}}}`, output: []string{"name", "stock"}},
+		{model: `{"properties":{"address":{"properties":{"city":{"type":"keyword"},"zip":{"type":"keyword"}}}}}`, output: []string{"address.zip"}},
+		{model: `{"properties":{"price":{"properties":{"value":{"type":"float"}}}}}`, output: []string{"price"}},
+	}
+
+	for _, s := range scenarios {
+		assert.Equal(s.output, GetMissingOrUpdatedProperties(live, s.model), s.model)
+	}
+}
+
+func TestGetTemplateDriftReasons(t *testing.T) {
+	assert := assert.New(t)
+	order := 2
+	live := `{"products":{"order":2,"index_patterns":["products-*"],"settings":{"index":{"number_of_shards":"1","number_of_replicas":"1"}},"mappings":{"properties":{"name":{"type":"keyword"}}},"aliases":{"search":{"index_routing":"1","search_routing":"1"}}}}`
+	scenarios := []struct {
+		model  string
+		order  *int
+		output []string
+	}{
+		{model: `{"index_patterns":"products-*","settings":{"number_of_shards":1,"number_of_replicas":1},"mappings":{"properties":{"name":{"type":"keyword"}}},"aliases":{"search":{"routing":"1"}}}`,
+			order: &order, output: nil},
+		{model: `{"index_patterns":["products-*"],"settings":{"number_of_shards":1,"number_of_replicas":1},"mappings":{"_doc":{"properties":{"name":{"type":"keyword"}}}},"aliases":{"search":{"routing":"1"}}}`,
+			order: &order, output: nil},
+		{model: `{"index_patterns":["products-*","items-*"],"settings":{"number_of_shards":1},"mappings":{"properties":{"name":{"type":"text"}}},"version":3}`,
+			output: []string{"index_patterns differ", "order is 2 instead of 0", "version differs", "settings differ", "mappings differ", "aliases differ"}},
+	}
+
+	for _, s := range scenarios {
+		reasons, err := GetTemplateDriftReasons(live, "products", s.model, s.order)
+		assert.Nil(err)
+		assert.Equal(s.output, reasons, s.model)
+	}
+
+	reasons, err := GetTemplateDriftReasons(`{}`, "products", `{"index_patterns":["products-*"]}`, nil)
+	assert.Nil(err)
+	assert.Equal([]string{"template products does not exist"}, reasons)
+}
+
+func TestEsModel_WithIndexTemplateOptions(t *testing.T) {
+	assert := assert.New(t)
+	model := `{"index_patterns":["logs-*"],"template":{"settings":{"number_of_shards":1}}}`
+	priority := 200
+
+	output, err := (&EsModel{Model: model}).WithIndexTemplateOptions(EsIndexTemplateOptions{})
+	assert.Nil(err)
+	assert.JSONEq(model, output)
+
+	output, err = (&EsModel{Model: model}).WithIndexTemplateOptions(EsIndexTemplateOptions{Priority: &priority, ComposedOf: []string{"mappings", "settings"},
+		DataStream: &EsDataStreamOptions{Hidden: true}})
+	assert.Nil(err)
+	assert.JSONEq(`{"index_patterns":["logs-*"],"template":{"settings":{"number_of_shards":1}},"priority":200,"composed_of":["mappings","settings"],"data_stream":{"hidden":true}}`, output)
+
+	output, err = (&EsModel{Model: model}).WithIndexTemplateOptions(EsIndexTemplateOptions{DataStream: &EsDataStreamOptions{}})
+	assert.Nil(err)
+	assert.JSONEq(`{"index_patterns":["logs-*"],"template":{"settings":{"number_of_shards":1}},"data_stream":{}}`, output)
+
+	_, err = (&EsModel{Model: `{"index_`}).WithIndexTemplateOptions(EsIndexTemplateOptions{})
+	assert.NotNil(err)
+}
+
+func TestGetIndexTemplateDriftReasons(t *testing.T) {
+	assert := assert.New(t)
+	live := `{"index_templates":[{"name":"logs","index_template":{"index_patterns":["logs-*"],"priority":200,"composed_of":["mappings"],
+		"template":{"settings":{"index":{"number_of_shards":"1"}},"mappings":{"properties":{"message":{"type":"text"}}}},
+		"data_stream":{"hidden":false,"allow_custom_routing":false},"_meta":{"owner":"team-a"}}}]}`
+	scenarios := []struct {
+		model  string
+		output []string
+	}{
+		{model: `{"index_patterns":["logs-*"],"priority":200,"composed_of":["mappings"],"template":{"settings":{"number_of_shards":1},"mappings":{"properties":{"message":{"type":"text"}}}},"data_stream":{},"_meta":{"owner":"team-a"}}`,
+			output: nil},
+		{model: `{"index_patterns":["logs-*","metrics-*"],"composed_of":["mappings","settings"],"template":{"settings":{"number_of_shards":2}},"version":2}`,
+			output: []string{"index_patterns differ", "priority is 200 instead of 0", "composed_of differs", "version differs", "_meta differs", "data_stream differs", "settings differ", "mappings differ"}},
+		{model: `{"index_patterns":["logs-*"],"priority":200,"composed_of":["mappings"],"template":{"settings":{"number_of_shards":1},"mappings":{"properties":{"message":{"type":"text"}}}},"data_stream":{"hidden":true},"_meta":{"owner":"team-a"}}`,
+			output: []string{"data_stream differs"}},
+	}
+
+	for _, s := range scenarios {
+		reasons, err := GetIndexTemplateDriftReasons(live, "logs", s.model)
+		assert.Nil(err)
+		assert.Equal(s.output, reasons, s.model)
+	}
+
+	reasons, err := GetIndexTemplateDriftReasons(live, "metrics", `{"index_patterns":["metrics-*"]}`)
+	assert.Nil(err)
+	assert.Equal([]string{"index template metrics does not exist"}, reasons)
+}
+
+func TestGetComponentTemplateDriftReasons(t *testing.T) {
+	assert := assert.New(t)
+	live := `{"component_templates":[{"name":"mappings","component_template":{"template":{"mappings":{"properties":{"message":{"type":"text"}}},
+		"aliases":{"logs":{}}},"version":1}}]}`
+
+	reasons, err := GetComponentTemplateDriftReasons(live, "mappings", `{"template":{"mappings":{"properties":{"message":{"type":"text"}}},"aliases":{"logs":{}}},"version":1}`)
+	assert.Nil(err)
+	assert.Nil(reasons)
+
+	reasons, err = GetComponentTemplateDriftReasons(live, "mappings", `{"template":{"mappings":{"properties":{"message":{"type":"keyword"}}}},"_meta":{"owner":"team-a"}}`)
+	assert.Nil(err)
+	assert.Equal([]string{"version differs", "_meta differs", "mappings differ", "aliases differ"}, reasons)
+
+	reasons, err = GetComponentTemplateDriftReasons(`{"component_templates":[]}`, "mappings", `{"template":{}}`)
+	assert.Nil(err)
+	assert.Equal([]string{"component template mappings does not exist"}, reasons)
+}
@@ -0,0 +1,241 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	funk "github.com/thoas/go-funk"
+	"github.com/tidwall/gjson"
+)
+
+// snapshotRepositoryTypes are the types of snapshot repositories, with their required settings. s3, gcs and azure
+// repositories require the repository plugin of their type on elasticsearch 7 and earlier
+var snapshotRepositoryTypes = map[string][]string{
+	"fs":    {"location"},
+	"s3":    {"bucket"},
+	"gcs":   {"bucket"},
+	"azure": {},
+	"url":   {"url"},
+}
+
+// slmPolicyFields are the fields of an SLM policy compared on resync
+var slmPolicyFields = []string{"name", "schedule", "repository", "config", "retention"}
+
+// BuildSnapshotRepositoryModel returns the body of a PUT _snapshot/<repository> request, the settings completed with the
+// settings read from secrets, like {"type":"s3","settings":{"bucket":"backups","secret_key":"..."}}
+func BuildSnapshotRepositoryModel(repositoryType string, settings string, secretSettings map[string]string) (string, error) {
+	values := map[string]interface{}{}
+	if settings != "" {
+		decoder := json.NewDecoder(strings.NewReader(settings))
+		decoder.UseNumber()
+		if err := decoder.Decode(&values); err != nil || values == nil {
+			return "", fmt.Errorf("SnapshotRepository settings should be a json object")
+		}
+	}
+	for name, value := range secretSettings {
+		values[name] = value
+	}
+	body := struct {
+		Type     string                 `json:"type"`
+		Settings map[string]interface{} `json:"settings"`
+	}{Type: repositoryType, Settings: values}
+	buffer := new(bytes.Buffer)
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(body); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
+
+// ValidateSnapshotRepository checks the type of a snapshot repository, and that its settings, a json object, and the
+// names of the settings read from secrets contain the settings required by the type
+func ValidateSnapshotRepository(repositoryType string, settings string, secretSettingNames []string) error {
+	required, ok := snapshotRepositoryTypes[repositoryType]
+	if !ok {
+		types := funk.Keys(snapshotRepositoryTypes).([]string)
+		sort.Strings(types)
+		return fmt.Errorf("SnapshotRepository type should be one of %v", types)
+	}
+	parsed := gjson.Parse(settings)
+	if settings != "" && (!gjson.Valid(settings) || !parsed.IsObject()) {
+		return fmt.Errorf("SnapshotRepository settings should be a json object")
+	}
+	for _, name := range secretSettingNames {
+		if parsed.Get(escapePath(name)).Exists() {
+			return fmt.Errorf("SnapshotRepository setting %v should be set either in settings or from a secret", name)
+		}
+	}
+	for _, name := range required {
+		if !parsed.Get(escapePath(name)).Exists() && !funk.ContainsString(secretSettingNames, name) {
+			return fmt.Errorf("%v SnapshotRepository settings should contain required setting %v", repositoryType, name)
+		}
+	}
+	return nil
+}
+
+// GetSnapshotRepositoryDriftReasons compares a snapshot repository, from a GET _snapshot/<repository> response, with
+// its model, and returns what differs. Settings are compared as strings, elasticsearch returning them as strings
+func GetSnapshotRepositoryDriftReasons(liveRepositories string, repositoryName string, model string) []string {
+	live := gjson.Get(liveRepositories, escapePath(repositoryName))
+	if !live.Exists() {
+		return []string{fmt.Sprintf("snapshot repository %v does not exist", repositoryName)}
+	}
+	expected := gjson.Parse(model)
+
+	var reasons []string
+	if live.Get("type").String() != expected.Get("type").String() {
+		reasons = append(reasons, "type differs")
+	}
+	liveSettings, expectedSettings := live.Get("settings").Map(), expected.Get("settings").Map()
+	names := funk.UniqString(append(funk.Keys(liveSettings).([]string), funk.Keys(expectedSettings).([]string)...))
+	sort.Strings(names)
+	for _, name := range names {
+		liveValue, liveExists := liveSettings[name]
+		expectedValue, expectedExists := expectedSettings[name]
+		if liveExists != expectedExists || liveValue.String() != expectedValue.String() {
+			reasons = append(reasons, fmt.Sprintf("setting %v differs", name))
+		}
+	}
+	return reasons
+}
+
+// ValidateSLMPolicy checks an SLM policy model, a PUT _slm/policy body like
+// {"name":"<daily-{now/d}>","schedule":"0 30 1 * * ?","repository":"backups","retention":{"expire_after":"30d"}}
+func ValidateSLMPolicy(model string) error {
+	policy := gjson.Parse(model)
+	for _, field := range []string{"name", "schedule", "repository"} {
+		if value := policy.Get(field); value.Type != gjson.String || value.String() == "" {
+			return fmt.Errorf("SLMPolicy model should contain the string field %v", field)
+		}
+	}
+	schedule := strings.Fields(policy.Get("schedule").String())
+	if (len(schedule) < 6 || len(schedule) > 7) && !(len(schedule) == 1 && ilmMinAgeRegex.MatchString(schedule[0])) {
+		return fmt.Errorf("SLMPolicy schedule should be a cron expression with seconds, like 0 30 1 * * ?")
+	}
+	if config := policy.Get("config"); config.Exists() && !config.IsObject() {
+		return fmt.Errorf("SLMPolicy config should be an object")
+	}
+	return validateSLMRetention(policy.Get("retention"))
+}
+
+// GetSLMPolicyRepository returns the repository the snapshots of an SLM policy model are taken to
+func GetSLMPolicyRepository(model string) string {
+	return gjson.Get(model, "repository").String()
+}
+
+// validateSLMRetention checks the expire_after, min_count and max_count fields of an SLM policy retention
+func validateSLMRetention(retention gjson.Result) error {
+	if !retention.Exists() {
+		return nil
+	}
+	if !retention.IsObject() {
+		return fmt.Errorf("SLMPolicy retention should be an object")
+	}
+	for key, value := range retention.Map() {
+		switch key {
+		case "expire_after":
+			if !ilmMinAgeRegex.MatchString(value.String()) {
+				return fmt.Errorf("SLMPolicy retention expire_after should be a time value, like 30d")
+			}
+		case "min_count", "max_count":
+			if value.Type != gjson.Number || value.Int() < 1 {
+				return fmt.Errorf("SLMPolicy retention %v should be a positive number", key)
+			}
+		default:
+			return fmt.Errorf("SLMPolicy retention should contain only fields from this list [expire_after min_count max_count]")
+		}
+	}
+	if minCount, maxCount := retention.Get("min_count"), retention.Get("max_count"); minCount.Exists() && maxCount.Exists() && minCount.Int() > maxCount.Int() {
+		return fmt.Errorf("SLMPolicy retention min_count should not be greater than max_count")
+	}
+	return nil
+}
+
+// GetSLMPolicyDriftReasons compares an SLM policy, from a GET _slm/policy/<id> response, with its model, and returns
+// what differs
+func GetSLMPolicyDriftReasons(livePolicies string, policyName string, model string) []string {
+	live := gjson.Get(livePolicies, escapePath(policyName)+".policy")
+	if !live.Exists() {
+		return []string{fmt.Sprintf("SLM policy %v does not exist", policyName)}
+	}
+	expected := gjson.Parse(model)
+
+	var reasons []string
+	for _, field := range slmPolicyFields {
+		if jsonDiffers(live.Get(field), expected.Get(field)) {
+			reasons = append(reasons, field+" differs")
+		}
+	}
+	return reasons
+}
+
+// EsSLMPolicyStatus is the execution status of an SLM policy
+type EsSLMPolicyStatus struct {
+	// LastSuccess and LastFailure are the last snapshots of the policy that succeeded and failed, nil when there is none
+	LastSuccess *EsSnapshotExecution
+	LastFailure *EsSnapshotExecution
+	// NextExecution is the time of the next snapshot in milliseconds since epoch, 0 when unknown
+	NextExecution int64
+	// SnapshotsTaken and SnapshotsFailed are the counts of _slm/stats since the policy was created
+	SnapshotsTaken  int64
+	SnapshotsFailed int64
+}
+
+// EsSnapshotExecution is a snapshot taken by an SLM policy
+type EsSnapshotExecution struct {
+	SnapshotName string
+	// Time in milliseconds since epoch
+	Time int64
+	// Details is the reason of the failure of failed snapshots
+	Details string
+}
+
+// ParseSLMPolicyStatus returns the execution status of an SLM policy, from GET _slm/policy/<id> and GET _slm/stats
+// responses, nil when the policy does not exist
+func ParseSLMPolicyStatus(policies string, stats string, policyName string) *EsSLMPolicyStatus {
+	policy := gjson.Get(policies, escapePath(policyName))
+	if !policy.Exists() {
+		return nil
+	}
+	status := &EsSLMPolicyStatus{
+		LastSuccess:   parseSnapshotExecution(policy.Get("last_success")),
+		LastFailure:   parseSnapshotExecution(policy.Get("last_failure")),
+		NextExecution: policy.Get("next_execution_millis").Int(),
+	}
+	for _, policyStats := range gjson.Get(stats, "policy_stats").Array() {
+		if policyStats.Get("policy").String() == policyName {
+			status.SnapshotsTaken = policyStats.Get("snapshots_taken").Int()
+			status.SnapshotsFailed = policyStats.Get("snapshots_failed").Int()
+		}
+	}
+	return status
+}
+
+func parseSnapshotExecution(execution gjson.Result) *EsSnapshotExecution {
+	if !execution.IsObject() {
+		return nil
+	}
+	return &EsSnapshotExecution{
+		SnapshotName: execution.Get("snapshot_name").String(),
+		Time:         execution.Get("time").Int(),
+		Details:      execution.Get("details").String(),
+	}
+}
@@ -0,0 +1,196 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildSnapshotRepositoryModel(t *testing.T) {
+	assert := assert.New(t)
+
+	model, err := BuildSnapshotRepositoryModel("fs", `{"location":"/mnt/backups","compress":true,"max_snapshot_bytes_per_sec":"40mb"}`, nil)
+	assert.Nil(err)
+	assert.Equal(`{"type":"fs","settings":{"compress":true,"location":"/mnt/backups","max_snapshot_bytes_per_sec":"40mb"}}`, model)
+
+	model, err = BuildSnapshotRepositoryModel("s3", `{"bucket":"backups"}`, map[string]string{"access_key": "AKIA", "secret_key": "s3cr3t<&>"})
+	assert.Nil(err)
+	assert.Equal(`{"type":"s3","settings":{"access_key":"AKIA","bucket":"backups","secret_key":"s3cr3t<&>"}}`, model)
+
+	model, err = BuildSnapshotRepositoryModel("azure", "", nil)
+	assert.Nil(err)
+	assert.Equal(`{"type":"azure","settings":{}}`, model)
+
+	_, err = BuildSnapshotRepositoryModel("fs", `["location"]`, nil)
+	assert.NotNil(err)
+}
+
+func TestValidateSnapshotRepository(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(ValidateSnapshotRepository("fs", `{"location":"/mnt/backups"}`, nil))
+	assert.Nil(ValidateSnapshotRepository("s3", `{"bucket":"backups"}`, []string{"access_key", "secret_key"}))
+	assert.Nil(ValidateSnapshotRepository("url", `{}`, []string{"url"}), "required settings can be read from secrets")
+	assert.Nil(ValidateSnapshotRepository("azure", "", nil))
+
+	assert.EqualError(ValidateSnapshotRepository("hdfs", `{}`, nil), "SnapshotRepository type should be one of [azure fs gcs s3 url]")
+	assert.EqualError(ValidateSnapshotRepository("fs", `{"compress":true}`, nil), "fs SnapshotRepository settings should contain required setting location")
+	assert.EqualError(ValidateSnapshotRepository("s3", `{"bucket":"backups","secret_key":"x"}`, []string{"secret_key"}),
+		"SnapshotRepository setting secret_key should be set either in settings or from a secret")
+	assert.NotNil(ValidateSnapshotRepository("fs", `not json`, nil))
+}
+
+func TestGetSnapshotRepositoryDriftReasons(t *testing.T) {
+	assert := assert.New(t)
+	live := `{"backups":{"type":"fs","settings":{"compress":"true","location":"/mnt/backups"}}}`
+
+	assert.Nil(GetSnapshotRepositoryDriftReasons(live, "backups", `{"type":"fs","settings":{"compress":true,"location":"/mnt/backups"}}`))
+	assert.Equal([]string{"type differs", "setting bucket differs", "setting compress differs", "setting location differs"},
+		GetSnapshotRepositoryDriftReasons(live, "backups", `{"type":"s3","settings":{"bucket":"backups"}}`))
+	assert.Equal([]string{"snapshot repository archives does not exist"}, GetSnapshotRepositoryDriftReasons(live, "archives", `{"type":"fs","settings":{}}`))
+}
+
+func TestValidateSLMPolicy(t *testing.T) {
+	assert := assert.New(t)
+
+	assert.Nil(ValidateSLMPolicy(`{"name":"<daily-{now/d}>","schedule":"0 30 1 * * ?","repository":"backups","config":{"indices":["*"]},
+		"retention":{"expire_after":"30d","min_count":5,"max_count":50}}`))
+	assert.Nil(ValidateSLMPolicy(`{"name":"hourly","schedule":"1h","repository":"backups"}`))
+	assert.Equal("backups", GetSLMPolicyRepository(`{"name":"hourly","schedule":"1h","repository":"backups"}`))
+
+	assert.EqualError(ValidateSLMPolicy(`{"schedule":"0 30 1 * * ?","repository":"backups"}`), "SLMPolicy model should contain the string field name")
+	assert.NotNil(ValidateSLMPolicy(`{"name":"daily","schedule":"30 1 * * *","repository":"backups"}`), "cron expressions have seconds")
+	assert.NotNil(ValidateSLMPolicy(`{"name":"daily","schedule":"0 30 1 * * ?","repository":"backups","config":[]}`))
+	assert.NotNil(ValidateSLMPolicy(`{"name":"daily","schedule":"0 30 1 * * ?","repository":"backups","retention":{"expire_after":"30"}}`))
+	assert.NotNil(ValidateSLMPolicy(`{"name":"daily","schedule":"0 30 1 * * ?","repository":"backups","retention":{"max_count":0}}`))
+	assert.NotNil(ValidateSLMPolicy(`{"name":"daily","schedule":"0 30 1 * * ?","repository":"backups","retention":{"min_count":10,"max_count":5}}`))
+	assert.NotNil(ValidateSLMPolicy(`{"name":"daily","schedule":"0 30 1 * * ?","repository":"backups","retention":{"keep":5}}`))
+}
+
+func TestGetSLMPolicyDriftReasons(t *testing.T) {
+	assert := assert.New(t)
+	live := `{"daily":{"version":1,"modified_date_millis":1729152000000,"policy":{"name":"<daily-{now/d}>","schedule":"0 30 1 * * ?","repository":"backups",
+		"retention":{"expire_after":"30d"}}}}`
+
+	assert.Nil(GetSLMPolicyDriftReasons(live, "daily", `{"name":"<daily-{now/d}>","schedule":"0 30 1 * * ?","repository":"backups","retention":{"expire_after":"30d"}}`))
+	assert.Equal([]string{"schedule differs", "config differs", "retention differs"},
+		GetSLMPolicyDriftReasons(live, "daily", `{"name":"<daily-{now/d}>","schedule":"0 0 2 * * ?","repository":"backups","config":{"indices":["logs-*"]}}`))
+	assert.Equal([]string{"SLM policy weekly does not exist"}, GetSLMPolicyDriftReasons(live, "weekly", `{}`))
+}
+
+func TestParseSLMPolicyStatus(t *testing.T) {
+	assert := assert.New(t)
+	policies := `{"daily":{"policy":{"name":"<daily-{now/d}>"},"next_execution_millis":1729215000000,
+		"last_success":{"snapshot_name":"daily-2024.10.17-a","start_time":1729128600000,"time":1729128660000},
+		"last_failure":{"snapshot_name":"daily-2024.10.16-b","time":1729042260000,"details":"{\"type\":\"repository_exception\"}"}}}`
+	stats := `{"retention_runs":0,"policy_stats":[{"policy":"weekly","snapshots_taken":1},{"policy":"daily","snapshots_taken":12,"snapshots_failed":1,"snapshots_deleted":3}]}`
+
+	assert.Equal(&EsSLMPolicyStatus{
+		LastSuccess:     &EsSnapshotExecution{SnapshotName: "daily-2024.10.17-a", Time: 1729128660000},
+		LastFailure:     &EsSnapshotExecution{SnapshotName: "daily-2024.10.16-b", Time: 1729042260000, Details: `{"type":"repository_exception"}`},
+		NextExecution:   1729215000000,
+		SnapshotsTaken:  12,
+		SnapshotsFailed: 1,
+	}, ParseSLMPolicyStatus(policies, stats, "daily"))
+	assert.Equal(&EsSLMPolicyStatus{}, ParseSLMPolicyStatus(`{"daily":{"policy":{}}}`, `{}`, "daily"))
+	assert.Nil(ParseSLMPolicyStatus(policies, stats, "monthly"))
+}
+
+// testSnapshotRepositoryBackend puts, compares and deletes a snapshot repository on a test cluster, whose path.repo is
+// the root of the fs repositories
+func testSnapshotRepositoryBackend(t *testing.T, elasticsearch EsSnapshotRepositories) {
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	repositoryName := "k8s_epo_test_repository"
+	defer elasticsearch.DeleteSnapshotRepository(ctx, repositoryName)
+	model := `{"type": "fs", "settings": {"location": "k8s_epo_test_repository", "compress": "true"}}`
+
+	drift, err := elasticsearch.GetSnapshotRepositoryDrift(ctx, repositoryName, model)
+	assert.Nil(err)
+	assert.Equal([]string{"snapshot repository k8s_epo_test_repository does not exist"}, drift.Reasons)
+	status, err := elasticsearch.CreateOrUpdateSnapshotRepository(ctx, repositoryName, model)
+	assert.Nil(err)
+	assert.Equal("200", status.HttpCodeStatus)
+	assert.Equal([]string{OperationRepositoryPut}, status.Operations)
+	exists, err := elasticsearch.ExistsSnapshotRepository(ctx, repositoryName)
+	assert.Nil(err)
+	assert.True(exists)
+	drift, err = elasticsearch.GetSnapshotRepositoryDrift(ctx, repositoryName, model)
+	assert.Nil(err)
+	assert.False(drift.IsDrifted())
+	drift, err = elasticsearch.GetSnapshotRepositoryDrift(ctx, repositoryName, `{"type": "fs", "settings": {"location": "k8s_epo_test_repository", "compress": "false"}}`)
+	assert.Nil(err)
+	assert.Equal([]string{"setting compress differs"}, drift.Reasons)
+
+	_, err = elasticsearch.CreateOrUpdateSnapshotRepository(ctx, "k8s_epo_test_outside", `{"type": "fs", "settings": {"location": "/tmp/k8s_epo_test_outside"}}`)
+	assert.NotNil(err, "location outside of path.repo")
+
+	assert.Nil(elasticsearch.DeleteSnapshotRepository(ctx, repositoryName))
+	exists, err = elasticsearch.ExistsSnapshotRepository(ctx, repositoryName)
+	assert.Nil(err)
+	assert.False(exists)
+	assert.Nil(elasticsearch.DeleteSnapshotRepository(ctx, repositoryName), "already deleted")
+}
+
+// slmPolicyBackend is a backend with the snapshot repositories the SLM policies take their snapshots to
+type slmPolicyBackend interface {
+	EsSnapshotRepositories
+	EsSLMPolicies
+}
+
+// testSLMPolicyBackend puts, compares and deletes an SLM policy on a test cluster
+func testSLMPolicyBackend(t *testing.T, elasticsearch slmPolicyBackend) {
+	ctx := context.Background()
+	assert := assert.New(t)
+
+	repositoryName, policyName := "k8s_epo_test_slm_repository", "k8s_epo_test_slm_policy"
+	defer elasticsearch.DeleteSnapshotRepository(ctx, repositoryName)
+	defer elasticsearch.DeleteSLMPolicy(ctx, policyName)
+	_, err := elasticsearch.CreateOrUpdateSnapshotRepository(ctx, repositoryName, `{"type": "fs", "settings": {"location": "k8s_epo_test_slm_repository"}}`)
+	assert.Nil(err)
+	model := `{"name": "<k8s_epo_test_snapshot-{now/d}>", "schedule": "0 30 1 * * ?", "repository": "k8s_epo_test_slm_repository", "retention": {"expire_after": "30d"}}`
+
+	drift, err := elasticsearch.GetSLMPolicyDrift(ctx, policyName, model)
+	assert.Nil(err)
+	assert.Equal([]string{"SLM policy k8s_epo_test_slm_policy does not exist"}, drift.Reasons)
+	status, err := elasticsearch.CreateOrUpdateSLMPolicy(ctx, policyName, model)
+	assert.Nil(err)
+	assert.Equal("200", status.HttpCodeStatus)
+	assert.Equal([]string{OperationSLMPolicyPut}, status.Operations)
+	drift, err = elasticsearch.GetSLMPolicyDrift(ctx, policyName, model)
+	assert.Nil(err)
+	assert.False(drift.IsDrifted())
+	drift, err = elasticsearch.GetSLMPolicyDrift(ctx, policyName, `{"name": "<k8s_epo_test_snapshot-{now/d}>", "schedule": "0 30 2 * * ?", "repository": "k8s_epo_test_slm_repository"}`)
+	assert.Nil(err)
+	assert.Equal([]string{"schedule differs", "retention differs"}, drift.Reasons)
+	policyStatus, err := elasticsearch.GetSLMPolicyStatus(ctx, policyName)
+	assert.Nil(err)
+	assert.NotNil(policyStatus)
+	assert.Nil(policyStatus.LastSuccess)
+	assert.Equal(int64(0), policyStatus.SnapshotsTaken)
+
+	_, err = elasticsearch.CreateOrUpdateSLMPolicy(ctx, "k8s_epo_test_slm_missing", `{"name": "<snapshot-{now/d}>", "schedule": "0 30 1 * * ?", "repository": "k8s_epo_test_missing"}`)
+	assert.NotNil(err, "repository does not exist")
+
+	assert.Nil(elasticsearch.DeleteSLMPolicy(ctx, policyName))
+	policyStatus, err = elasticsearch.GetSLMPolicyStatus(ctx, policyName)
+	assert.Nil(err)
+	assert.Nil(policyStatus)
+	assert.Nil(elasticsearch.DeleteSLMPolicy(ctx, policyName), "already deleted")
+}
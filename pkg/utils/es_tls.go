@@ -0,0 +1,64 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+)
+
+// EsTLSConfig configures the TLS connection to elasticsearch. Elasticsearch certificate is verified with system CAs when
+// CACert is empty, and is not verified only when InsecureSkipVerify is explicitly set
+type EsTLSConfig struct {
+	CACert             []byte
+	ClientCert         []byte
+	ClientKey          []byte
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// BuildTLSConfig returns the tls.Config of an EsTLSConfig, verifying elasticsearch certificate with system CAs when nil
+func BuildTLSConfig(config *EsTLSConfig) (*tls.Config, error) {
+	if config == nil {
+		return &tls.Config{}, nil
+	}
+
+	tlsConfig := &tls.Config{ServerName: config.ServerName, InsecureSkipVerify: config.InsecureSkipVerify}
+	if len(config.CACert) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(config.CACert) {
+			return nil, errors.New("no PEM certificate found in CA certificate")
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if len(config.ClientCert) > 0 || len(config.ClientKey) > 0 {
+		certificate, err := tls.X509KeyPair(config.ClientCert, config.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return tlsConfig, nil
+}
+
+// Hash identifies an EsTLSConfig, so that connections with different TLS configurations are not shared
+func (c *EsTLSConfig) Hash() string {
+	if c == nil {
+		return ""
+	}
+	return HashString(fmt.Sprintf("%s|%s|%s|%v|%v", c.CACert, c.ClientCert, c.ClientKey, c.ServerName, c.InsecureSkipVerify))
+}
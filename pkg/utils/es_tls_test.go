@@ -0,0 +1,92 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func generateCertificate(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "elasticsearch"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer})
+}
+
+func TestBuildTLSConfig(t *testing.T) {
+	assert := assert.New(t)
+	cert, key := generateCertificate(t)
+	otherCert, _ := generateCertificate(t)
+
+	tlsConfig, err := BuildTLSConfig(nil)
+	assert.Nil(err)
+	assert.False(tlsConfig.InsecureSkipVerify, "certificate is verified by default")
+	assert.Nil(tlsConfig.RootCAs)
+
+	tlsConfig, err = BuildTLSConfig(&EsTLSConfig{CACert: cert, ServerName: "es.local"})
+	assert.Nil(err)
+	assert.NotNil(tlsConfig.RootCAs)
+	assert.Equal("es.local", tlsConfig.ServerName)
+	assert.False(tlsConfig.InsecureSkipVerify)
+
+	tlsConfig, err = BuildTLSConfig(&EsTLSConfig{ClientCert: cert, ClientKey: key})
+	assert.Nil(err)
+	assert.Equal(1, len(tlsConfig.Certificates))
+
+	tlsConfig, err = BuildTLSConfig(&EsTLSConfig{InsecureSkipVerify: true})
+	assert.Nil(err)
+	assert.True(tlsConfig.InsecureSkipVerify)
+
+	_, err = BuildTLSConfig(&EsTLSConfig{CACert: []byte("not a certificate")})
+	assert.NotNil(err)
+
+	_, err = BuildTLSConfig(&EsTLSConfig{ClientCert: otherCert, ClientKey: key})
+	assert.NotNil(err, "certificate does not match key")
+}
+
+func TestEsTLSConfig_Hash(t *testing.T) {
+	assert := assert.New(t)
+	var nilConfig *EsTLSConfig
+	assert.Equal("", nilConfig.Hash())
+	assert.Equal((&EsTLSConfig{CACert: []byte("ca")}).Hash(), (&EsTLSConfig{CACert: []byte("ca")}).Hash())
+	assert.NotEqual((&EsTLSConfig{CACert: []byte("ca")}).Hash(), (&EsTLSConfig{CACert: []byte("ca2")}).Hash())
+	assert.NotEqual((&EsTLSConfig{}).Hash(), (&EsTLSConfig{InsecureSkipVerify: true}).Hash())
+}
@@ -16,6 +16,7 @@ package utils
 
 import (
 	"context"
+	"fmt"
 	"github.com/go-logr/logr"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,7 +25,15 @@ import (
 	"strings"
 )
 
+// key of the CA certificate in ECK and cert-manager secrets
+const caCertKey = "ca.crt"
+
+// FilterByNamespacesRegex returns true when the namespace of the object matches regex. Cluster-scoped objects, without
+// namespace, are never filtered
 func FilterByNamespacesRegex(meta metav1.Object, regex string, log logr.Logger) bool {
+	if meta.GetNamespace() == "" {
+		return true
+	}
 	match, _ := regexp.MatchString(regex, meta.GetNamespace())
 	if !match {
 		log.Info("/!\\ event filtered", "namespace", meta.GetNamespace(), "name", meta.GetName())
@@ -41,19 +50,44 @@ func GetSecret(namespace string, secretKeySelector *v1.SecretKeySelector, k8sCli
 	return &secret, nil
 }
 
-func BuildEsConfigFromSecretSelector(namespace string, secretKeySelector *v1.SecretKeySelector, k8sClient client.Client) (*EsConfig, error) {
-	secret, err := GetSecret(namespace, secretKeySelector, k8sClient)
+// BuildEsConfigFromExistingSecret returns the config of the elasticsearch uri of a secret key, authenticating with the
+// api key or the bearer token of the secret when set
+func BuildEsConfigFromExistingSecret(secret *v1.Secret, key string, tlsConfig *EsTLSConfig) (*EsConfig, error) {
+	elasticURI := string(secret.Data[key])
+	conf := &EsConfig{TLS: tlsConfig}
+	ReadSecretCredentials(conf, secret)
+	esConfig, err := conf.FromURI(elasticURI)
 	if err != nil {
 		return nil, err
 	}
-	return BuildEsConfigFromExistingSecret(secret, secretKeySelector.Key)
+	return esConfig, nil
 }
 
-func BuildEsConfigFromExistingSecret(secret *v1.Secret, key string) (*EsConfig, error) {
-	elasticURI := string(secret.Data[key])
-	esConfig, err := (&EsConfig{}).FromURI(elasticURI)
-	if err != nil {
-		return nil, err
+// BuildEsTLSConfig reads the CA certificate, in ca.crt key or tls.crt key when missing, and the client certificate and
+// key of a kubernetes.io/tls secret. Secret names are optional
+func BuildEsTLSConfig(namespace string, caSecretName string, clientCertSecretName string, serverName string, insecureSkipVerify bool, k8sClient client.Client) (*EsTLSConfig, error) {
+	tlsConfig := &EsTLSConfig{ServerName: serverName, InsecureSkipVerify: insecureSkipVerify}
+	if caSecretName != "" {
+		secret, err := GetSecret(namespace, &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: caSecretName}}, k8sClient)
+		if err != nil {
+			return nil, err
+		}
+		if tlsConfig.CACert = secret.Data[caCertKey]; len(tlsConfig.CACert) == 0 {
+			tlsConfig.CACert = secret.Data[v1.TLSCertKey]
+		}
+		if len(tlsConfig.CACert) == 0 {
+			return nil, fmt.Errorf("secret %v has no %v or %v key", caSecretName, caCertKey, v1.TLSCertKey)
+		}
 	}
-	return esConfig, nil
+	if clientCertSecretName != "" {
+		secret, err := GetSecret(namespace, &v1.SecretKeySelector{LocalObjectReference: v1.LocalObjectReference{Name: clientCertSecretName}}, k8sClient)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ClientCert, tlsConfig.ClientKey = secret.Data[v1.TLSCertKey], secret.Data[v1.TLSPrivateKeyKey]
+		if len(tlsConfig.ClientCert) == 0 || len(tlsConfig.ClientKey) == 0 {
+			return nil, fmt.Errorf("secret %v has no %v or %v key", clientCertSecretName, v1.TLSCertKey, v1.TLSPrivateKeyKey)
+		}
+	}
+	return tlsConfig, nil
 }
@@ -0,0 +1,72 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"net/http"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var esRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "elastic_phenix_operator",
+	Name:      "elasticsearch_request_duration_seconds",
+	Help:      "Latency of elasticsearch API calls by operation, cluster and http status code",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"operation", "cluster", "code"})
+
+func init() {
+	metrics.Registry.MustRegister(esRequestDuration)
+}
+
+// instrumentedTransport observes the latency of each elasticsearch API call in esRequestDuration
+type instrumentedTransport struct {
+	next    http.RoundTripper
+	cluster string
+}
+
+func newInstrumentedTransport(next http.RoundTripper, config *EsConfig) http.RoundTripper {
+	return &instrumentedTransport{next: next, cluster: config.String()}
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	response, err := t.next.RoundTrip(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(response.StatusCode)
+	}
+	esRequestDuration.WithLabelValues(GetEsOperation(req.Method, req.URL.Path), t.cluster, code).Observe(time.Since(start).Seconds())
+	return response, err
+}
+
+func (t *instrumentedTransport) CloseIdleConnections() {
+	closeIdleConnections(t.next)
+}
+
+// GetEsOperation returns the operation of an elasticsearch API call, made of the http method and the path in which
+// index, alias and template names are replaced by {name}, like "PUT /{name}/_settings"
+func GetEsOperation(method string, path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if segment != "" && !strings.HasPrefix(segment, "_") {
+			segments[i] = "{name}"
+		}
+	}
+	return method + " /" + strings.Join(segments, "/")
+}
@@ -0,0 +1,30 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestGetEsOperation(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("GET /", GetEsOperation("GET", "/"))
+	assert.Equal("HEAD /{name}", GetEsOperation("HEAD", "/product"))
+	assert.Equal("PUT /{name}/_settings", GetEsOperation("PUT", "/product-v2/_settings"))
+	assert.Equal("PUT /_template/{name}", GetEsOperation("PUT", "/_template/invoice"))
+	assert.Equal("POST /_aliases", GetEsOperation("POST", "/_aliases"))
+	assert.Equal("POST /{name}/_shrink/{name}", GetEsOperation("POST", "/product/_shrink/product-v2"))
+}
@@ -0,0 +1,217 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-logr/logr"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// OpenSearch manages indices and templates of opensearch 1 and 2 clusters with the elasticsearch 7 client, opensearch
+// being wire compatible with elasticsearch 7.10. Models are applied without mapping type, and the ILM settings of index
+// models are translated to ISM
+type OpenSearch struct {
+	esClient7
+}
+
+func (es *OpenSearch) NewClient(config *EsConfig, log logr.Logger) error {
+	es.mapping = mappingTypeless
+	return es.newClient(config, log, config.clientKey(config.Version), "opensearch")
+}
+
+// CreateOrUpdateIndex applies the model converted for opensearch (see EsModel.ForOpenSearch), then attaches the ISM policy
+// of its index.lifecycle.name setting to the backing index once it is created and not migrating
+func (es *OpenSearch) CreateOrUpdateIndex(ctx context.Context, indexName string, model string, migration *EsMigration) (*EsStatus, error) {
+	openSearchModel, policyID, err := (&EsModel{Model: model}).ForOpenSearch()
+	if err != nil {
+		es.log.Error(err, "error while converting model for opensearch", "indexName", indexName)
+		return &EsStatus{Status: StatusError, Message: err.Error()}, err
+	}
+
+	status, err := es.esClient7.CreateOrUpdateIndex(ctx, indexName, openSearchModel, migration)
+	if err != nil || status.Status != StatusCreated || policyID == "" {
+		return status, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, es.Config.GetRequestTimeout())
+	defer cancel()
+
+	backingIndex := es.getBackingIndex(ctx, indexName)
+	if updated, err := es.applyIsmPolicy(ctx, backingIndex, policyID); err != nil {
+		es.log.Error(err, "error while attaching ISM policy", "indexName", indexName, "policyID", policyID)
+		return &EsStatus{Status: StatusRetry, Message: err.Error()}, err
+	} else if updated {
+		status.Operations = append(status.Operations, OperationIsmPolicyAttached)
+	}
+	return status, nil
+}
+
+// GetIndexDrift compares the index with the model converted for opensearch and returns what differs: missing index,
+// settings, missing or updated properties, aliases and ISM policy
+func (es *OpenSearch) GetIndexDrift(ctx context.Context, indexName string, model string) (*EsDrift, error) {
+	ctx, cancel := context.WithTimeout(ctx, es.Config.GetRequestTimeout())
+	defer cancel()
+
+	model, policyID, err := (&EsModel{Model: model}).ForOpenSearch()
+	if err != nil {
+		return nil, err
+	}
+
+	exists := es.existsIndex(ctx, indexName)
+	if exists == nil {
+		return nil, errors.New("error while checking index exists")
+	} else if !*exists {
+		return &EsDrift{Reasons: []string{fmt.Sprintf("index %v does not exist", indexName)}}, nil
+	}
+
+	backingIndex := es.getBackingIndex(ctx, indexName)
+	drift, err := es.getIndexDrift(ctx, indexName, backingIndex, model)
+	if err != nil {
+		return nil, err
+	}
+
+	if policyID != "" {
+		livePolicyID, err := es.getIsmPolicyID(ctx, backingIndex)
+		if err != nil {
+			return nil, err
+		}
+		if livePolicyID != policyID {
+			drift.Reasons = append(drift.Reasons, fmt.Sprintf("ISM policy is %q instead of %q", livePolicyID, policyID))
+		}
+	}
+
+	return drift, nil
+}
+
+// CreateOrUpdateTemplate puts the model converted for opensearch as a legacy template, still supported by opensearch 2.
+// The ILM index.lifecycle.name setting is rejected, ISM policies selecting their indices with an ism_template instead
+func (es *OpenSearch) CreateOrUpdateTemplate(ctx context.Context, templateName string, model string, order *int) (*EsStatus, error) {
+	openSearchModel, policyID, err := (&EsModel{Model: model}).ForOpenSearch()
+	if err != nil {
+		es.log.Error(err, "error while converting model for opensearch", "templateName", templateName)
+		return &EsStatus{Status: StatusError, Message: err.Error()}, err
+	}
+	if policyID != "" {
+		errMsg := fmt.Sprintf("template %v cannot set %v on opensearch, add an ism_template to the ISM policy %v instead", templateName, IlmPolicySetting, policyID)
+		es.log.Error(nil, errMsg, "templateName", templateName)
+		return &EsStatus{Status: StatusError, HttpCodeStatus: "400", Message: errMsg}, errors.New(errMsg)
+	}
+	return es.esClient7.CreateOrUpdateTemplate(ctx, templateName, openSearchModel, order)
+}
+
+// GetTemplateDrift compares the template with the model converted for opensearch and the order, and returns what differs
+func (es *OpenSearch) GetTemplateDrift(ctx context.Context, templateName string, model string, order *int) (*EsDrift, error) {
+	openSearchModel, _, err := (&EsModel{Model: model}).ForOpenSearch()
+	if err != nil {
+		return nil, err
+	}
+	return es.esClient7.GetTemplateDrift(ctx, templateName, openSearchModel, order)
+}
+
+// CreateOrUpdateIndexTemplate puts a composable index template, whose model includes its priority, composed_of and
+// data_stream fields
+func (es *OpenSearch) CreateOrUpdateIndexTemplate(ctx context.Context, templateName string, model string) (*EsStatus, error) {
+	return es.createOrUpdateIndexTemplate(ctx, templateName, model)
+}
+
+// GetIndexTemplateDrift compares a composable index template with its model, and returns what differs
+func (es *OpenSearch) GetIndexTemplateDrift(ctx context.Context, templateName string, model string) (*EsDrift, error) {
+	return es.getIndexTemplateDrift(ctx, templateName, model)
+}
+
+func (es *OpenSearch) DeleteIndexTemplate(ctx context.Context, templateName string) error {
+	return es.deleteIndexTemplate(ctx, templateName)
+}
+
+// CreateOrUpdateComponentTemplate puts a component template
+func (es *OpenSearch) CreateOrUpdateComponentTemplate(ctx context.Context, templateName string, model string) (*EsStatus, error) {
+	return es.createOrUpdateComponentTemplate(ctx, templateName, model)
+}
+
+// GetComponentTemplateDrift compares a component template with its model, and returns what differs
+func (es *OpenSearch) GetComponentTemplateDrift(ctx context.Context, templateName string, model string) (*EsDrift, error) {
+	return es.getComponentTemplateDrift(ctx, templateName, model)
+}
+
+func (es *OpenSearch) DeleteComponentTemplate(ctx context.Context, templateName string) error {
+	return es.deleteComponentTemplate(ctx, templateName)
+}
+
+// ExistsComponentTemplate returns true when the component template exists
+func (es *OpenSearch) ExistsComponentTemplate(ctx context.Context, templateName string) (bool, error) {
+	return es.existsComponentTemplate(ctx, templateName)
+}
+
+// getIsmPolicyID returns the ISM policy attached to the index, empty when the index is not managed by ISM
+func (es *OpenSearch) getIsmPolicyID(ctx context.Context, indexName string) (string, error) {
+	explain, err := es.performIsmRequest(ctx, http.MethodGet, "explain", indexName, "")
+	if err != nil {
+		return "", err
+	}
+	return GetIsmPolicyID(explain, indexName), nil
+}
+
+// applyIsmPolicy attaches the ISM policy to the index, or changes the policy already attached, and returns false when
+// the index was already managed by the policy
+func (es *OpenSearch) applyIsmPolicy(ctx context.Context, indexName string, policyID string) (bool, error) {
+	livePolicyID, err := es.getIsmPolicyID(ctx, indexName)
+	if err != nil || livePolicyID == policyID {
+		return false, err
+	}
+
+	action := "add"
+	if livePolicyID != "" {
+		action = "change_policy"
+	}
+	response, err := es.performIsmRequest(ctx, http.MethodPost, action, indexName, fmt.Sprintf(`{"policy_id":%q}`, policyID))
+	if err != nil {
+		return false, err
+	}
+	if failure := GetIsmFailure(response); failure != nil {
+		return false, fmt.Errorf("error while attaching ISM policy %v to index %v: %v", policyID, indexName, *failure)
+	}
+	es.log.Info("ISM policy was attached successfully", "indexName", indexName, "policyID", policyID)
+	return true, nil
+}
+
+// performIsmRequest calls the ISM plugin API, which the elasticsearch client has no request for
+func (es *OpenSearch) performIsmRequest(ctx context.Context, method string, action string, indexName string, body string) (string, error) {
+	request, err := http.NewRequest(method, fmt.Sprintf("/_plugins/_ism/%v/%v", action, url.PathEscape(indexName)), strings.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	request = request.WithContext(ctx)
+	if body != "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	response, err := es.Client.Perform(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+	responseStr, err := StreamToString(response.Body)
+	if err != nil {
+		return "", err
+	}
+	if !is2xxStatusCode(response.StatusCode) {
+		return "", fmt.Errorf("error while calling ISM %v API on index %v: %v", action, indexName, responseStr)
+	}
+	return responseStr, nil
+}
@@ -0,0 +1,242 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"testing"
+	"time"
+)
+
+const (
+	osScheme  = "http"
+	osHost    = "opensearch"
+	osPort    = "9200"
+	osVersion = 2
+)
+
+func buildOpenSearch(t *testing.T, rawUrl string) *OpenSearch {
+	assert := assert.New(t)
+
+	esConfig, err1 := (&EsConfig{}).FromURI(rawUrl)
+	assert.Nil(err1)
+	assert.Equal(DistributionOpenSearch, esConfig.Distribution)
+	assert.Equal("opensearch", esConfig.Backend)
+	log := zap.New(zap.UseDevMode(true))
+
+	opensearch := &OpenSearch{}
+	err2 := opensearch.NewClient(esConfig, log)
+	assert.Nil(err2)
+	assert.NotNil(opensearch)
+
+	return opensearch
+}
+
+func deleteAllOpenSearch(opensearch *OpenSearch) {
+	ctx := context.Background()
+	opensearch.DeleteIndex(ctx, "k8s_epo_*")
+	opensearch.DeleteTemplate(ctx, "k8s_epo_*")
+}
+
+// newIsmServer answers like an opensearch 2 cluster with the ISM plugin, the index being managed by policyID
+func newIsmServer(policyID *string, actions *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/":
+			fmt.Fprint(w, `{"cluster_uuid":"wLyZGB","version":{"distribution":"opensearch","number":"2.11.1"}}`)
+		case r.URL.Path == "/_plugins/_ism/explain/k8s_epo_logs":
+			fmt.Fprintf(w, `{"k8s_epo_logs":{"index.plugins.index_state_management.policy_id":%q,"policy_id":%q}}`, *policyID, *policyID)
+		case r.Method == http.MethodPost && (r.URL.Path == "/_plugins/_ism/add/k8s_epo_logs" || r.URL.Path == "/_plugins/_ism/change_policy/k8s_epo_logs"):
+			body, _ := ioutil.ReadAll(r.Body)
+			*actions = append(*actions, r.URL.Path+" "+string(body))
+			if string(body) == `{"policy_id":"unknown"}` {
+				fmt.Fprint(w, `{"updated_indices":0,"failures":true,"failed_indices":[{"index_name":"k8s_epo_logs","reason":"Policy unknown does not exist"}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"updated_indices":1,"failures":false,"failed_indices":[]}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestOpenSearch_ApplyIsmPolicy(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	policyID := ""
+	var actions []string
+	server := newIsmServer(&policyID, &actions)
+	defer server.Close()
+	opensearch := buildOpenSearch(t, server.URL)
+
+	//unmanaged index => policy added
+	updated, err := opensearch.applyIsmPolicy(ctx, "k8s_epo_logs", "logs")
+	assert.Nil(err)
+	assert.True(updated)
+	assert.Equal([]string{`/_plugins/_ism/add/k8s_epo_logs {"policy_id":"logs"}`}, actions)
+
+	//same policy => nothing to do
+	policyID = "logs"
+	updated, err = opensearch.applyIsmPolicy(ctx, "k8s_epo_logs", "logs")
+	assert.Nil(err)
+	assert.False(updated)
+	assert.Equal(1, len(actions))
+
+	//other policy => policy changed
+	updated, err = opensearch.applyIsmPolicy(ctx, "k8s_epo_logs", "metrics")
+	assert.Nil(err)
+	assert.True(updated)
+	assert.Equal(`/_plugins/_ism/change_policy/k8s_epo_logs {"policy_id":"metrics"}`, actions[1])
+
+	//unknown policy => error
+	_, err = opensearch.applyIsmPolicy(ctx, "k8s_epo_logs", "unknown")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "Policy unknown does not exist")
+}
+
+func TestEsVersion_OpenSearch(t *testing.T) {
+	assert := assert.New(t)
+
+	esVersion, err := EsVersion(fmt.Sprintf("%v://%v:%v", osScheme, osHost, osPort))
+	assert.Nil(err)
+	assert.Equal(osVersion, esVersion)
+}
+
+func TestOpenSearch_CreateOrUpdateIndex(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	opensearch := buildOpenSearch(t, fmt.Sprintf("%v://%v:%v", osScheme, osHost, osPort))
+	defer deleteAllOpenSearch(opensearch)
+
+	indexName := "k8s_epo_test_create_index"
+
+	status, err := opensearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "0", "number_of_shards": "3"}, "mappings":{"properties":{"description":{"type":"keyword"}}}}`, nil)
+	assert.Nil(err)
+	assert.Equal("200", status.HttpCodeStatus)
+	assert.Equal(StatusCreated, status.Status)
+	assert.True(*opensearch.existsIndex(ctx, indexName))
+	replicas, shards := opensearch.getNumberOfReplicasAndShards(ctx, indexName)
+	assert.Equal(int32(0), *replicas)
+	assert.Equal(int32(3), *shards)
+
+	//new field in properties
+	status2, err := opensearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "0", "number_of_shards": "3"}, "mappings":{"properties":{"description":{"type":"keyword"}, "newField":{"type":"text"}}}}`, nil)
+	assert.Nil(err)
+	assert.Equal(StatusCreated, status2.Status)
+	properties, err := opensearch.getProperties(ctx, indexName)
+	assert.Nil(err)
+	assert.True(CompareJson(*properties, `{"properties" :{"description":{"type":"keyword"}, "newField":{"type":"text"}}}`))
+}
+
+func TestOpenSearch_CreateOrUpdateIndex_WithType(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	opensearch := buildOpenSearch(t, fmt.Sprintf("%v://%v:%v", osScheme, osHost, osPort))
+	defer deleteAllOpenSearch(opensearch)
+
+	indexName := "k8s_epo_test_create_index_with_type"
+
+	//the type is removed, opensearch 2 having no mapping type
+	status, err := opensearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "0", "number_of_shards": "1"}, "mappings":{"_doc": {"properties":{"description":{"type":"keyword"}}}}}`, nil)
+	assert.Nil(err)
+	assert.Equal(StatusCreated, status.Status)
+	properties, err := opensearch.getProperties(ctx, indexName)
+	assert.Nil(err)
+	assert.True(CompareJson(*properties, `{"properties" :{"description":{"type":"keyword"}}}`))
+}
+
+func TestOpenSearch_GetIndexDrift(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	opensearch := buildOpenSearch(t, fmt.Sprintf("%v://%v:%v", osScheme, osHost, osPort))
+	defer deleteAllOpenSearch(opensearch)
+
+	indexName := "k8s_epo_test_index_drift"
+	model := `{"settings":{"number_of_replicas": "0", "number_of_shards": "1"}, "mappings":{"properties":{"description":{"type":"keyword"}}}, "aliases":{"k8s_epo_drift":{}}}`
+
+	_, err := opensearch.CreateOrUpdateIndex(ctx, indexName, model, nil)
+	assert.Nil(err)
+	drift, err := opensearch.GetIndexDrift(ctx, indexName, model)
+	assert.Nil(err)
+	assert.False(drift.IsDrifted())
+
+	//manual changes
+	assert.Nil(opensearch.putIndexSettings(ctx, indexName, `{"index.number_of_replicas": 1}`))
+	drift2, err := opensearch.GetIndexDrift(ctx, indexName, model)
+	assert.Nil(err)
+	assert.Equal([]string{"settings [index.number_of_replicas] differ"}, drift2.Reasons)
+}
+
+func TestOpenSearch_MigrateIndex(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	opensearch := buildOpenSearch(t, fmt.Sprintf("%v://%v:%v", osScheme, osHost, osPort))
+	defer deleteAllOpenSearch(opensearch)
+
+	indexName := "k8s_epo_test_migrate_index"
+	migration := &EsMigration{Strategy: MigrationStrategyReindex, OldIndexPolicy: OldIndexPolicyDelete}
+
+	status, err := opensearch.CreateOrUpdateIndex(ctx, indexName, `{"settings":{"number_of_replicas": "0", "number_of_shards": "1"}, "mappings":{"properties":{"description":{"type":"keyword"}}}}`, migration)
+	assert.Nil(err)
+	assert.Equal(StatusCreated, status.Status)
+
+	//change field type in properties => migration to a new index
+	model := `{"settings":{"number_of_replicas": "0", "number_of_shards": "1"}, "mappings":{"properties":{"description":{"type":"text"}}}}`
+	status2, err := opensearch.CreateOrUpdateIndex(ctx, indexName, model, migration)
+	assert.Nil(err)
+	assert.Equal(StatusMigrating, status2.Status)
+	for i := 0; i < 10 && migration.Phase == MigrationPhaseReindexing; i++ {
+		time.Sleep(time.Second)
+		status2, err = opensearch.CreateOrUpdateIndex(ctx, indexName, model, migration)
+		assert.Nil(err)
+	}
+	assert.Equal(MigrationPhaseCompleted, migration.Phase)
+	assert.Equal(StatusCreated, status2.Status)
+	assert.Equal(indexName+"-v2", opensearch.getBackingIndex(ctx, indexName))
+}
+
+func TestOpenSearch_CreateOrUpdateTemplate(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	opensearch := buildOpenSearch(t, fmt.Sprintf("%v://%v:%v", osScheme, osHost, osPort))
+	defer deleteAllOpenSearch(opensearch)
+
+	templateName := "k8s_epo_test_create_template"
+	model := `{"index_patterns": ["k8s_epo_test_*"], "settings": {"number_of_shards": 1}, "mappings": {"_doc": {"properties": {"description":{"type":"keyword"}}}}}`
+	order := 1
+
+	status, err := opensearch.CreateOrUpdateTemplate(ctx, templateName, model, &order)
+	assert.Nil(err)
+	assert.Equal("200", status.HttpCodeStatus)
+	assert.True(*opensearch.existsTemplate(ctx, templateName))
+	drift, err := opensearch.GetTemplateDrift(ctx, templateName, model, &order)
+	assert.Nil(err)
+	assert.False(drift.IsDrifted())
+
+	//ILM policy in a template => bad request
+	status2, err := opensearch.CreateOrUpdateTemplate(ctx, templateName, `{"index_patterns": ["k8s_epo_test_*"], "settings": {"index.lifecycle.name": "logs"}}`, &order)
+	assert.NotNil(err)
+	assert.Equal(StatusError, status2.Status)
+
+	err2 := opensearch.DeleteTemplate(ctx, templateName)
+	assert.Nil(err2)
+	assert.False(*opensearch.existsTemplate(ctx, templateName))
+}
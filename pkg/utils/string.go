@@ -15,6 +15,8 @@ limitations under the License.
 package utils
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"io"
 	"strings"
 )
@@ -45,3 +47,9 @@ func StreamToString(r io.Reader) (res string, err error) {
 	}
 	return
 }
+
+// HashString returns the hex encoded sha256 of a string
+func HashString(s string) string {
+	hash := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(hash[:])
+}
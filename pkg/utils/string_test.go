@@ -72,3 +72,10 @@ func TestStreamToString(t *testing.T) {
 	assert.Nil(err, "error should be nil")
 	assert.Equal("Hello !", got)
 }
+
+func TestHashString(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", HashString(""))
+	assert.Equal(HashString(`{"settings":{}}`), HashString(`{"settings":{}}`))
+	assert.NotEqual(HashString(`{"settings":{}}`), HashString(`{"settings":{"number_of_replicas":1}}`))
+}